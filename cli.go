@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"book-tracker/models"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+const usage = `Usage:
+  book-tracker                  Run the server
+  book-tracker backup [flags]   Take a backup of the SQLite database (DB_PATH) into BACKUP_DIR
+  book-tracker restore FILE     Replace the SQLite database with a backup. Stop the server first!
+  book-tracker import-kindle [-create] FILE
+                                Add the highlights and notes of a Kindle My Clippings.txt to the books
+`
+
+// runCommand runs one of the maintenance commands and returns the exit code
+func runCommand(cfg Config, args []string) int {
+	commands := map[string]func(Config, []string) error{
+		"backup":        backupCommand,
+		"restore":       restoreCommand,
+		"import-kindle": importKindleCommand,
+	}
+	command, ok := commands[args[0]]
+	switch {
+	case args[0] == "help" || args[0] == "-h" || args[0] == "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return 0
+	case !ok:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", args[0], usage)
+		return 2
+	case cfg.StoreBackend != "sqlite":
+		fmt.Fprintf(os.Stderr, "%s is only supported with the sqlite store backend, not %s\n", args[0], cfg.StoreBackend)
+		return 2
+	}
+
+	err := command(cfg, args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func backupCommand(cfg Config, args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ContinueOnError)
+	flags.StringVar(&cfg.Backup.Dir, "dir", cfg.Backup.Dir, "directory the backup is written to")
+	flags.BoolVar(&cfg.Backup.Compress, "gzip", cfg.Backup.Compress, "gzip the backup")
+	flags.IntVar(&cfg.Backup.Keep, "keep", cfg.Backup.Keep, "number of backups to keep, 0 keeps all")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// NOTE: The server can keep running, SQLite takes care of the locking
+	db, closeDB, err := store.OpenSQLite(cfg.SQLite)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	backup, err := services.NewBackupService(store.NewBackupStore(db), cfg.Backup).CreateBackup(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%s (%d bytes)\n", backup.Name, backup.Size)
+	return nil
+}
+
+func restoreCommand(cfg Config, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("expected the backup file to restore\n\n%s", usage)
+	}
+	if err := store.RestoreSQLite(args[0], cfg.SQLite.Path); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "restored %s to %s\n", args[0], cfg.SQLite.Path)
+	return nil
+}
+
+func importKindleCommand(cfg Config, args []string) error {
+	flags := flag.NewFlagSet("import-kindle", flag.ContinueOnError)
+	create := flags.Bool("create", false, "add the books that are not in the library yet")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected the My Clippings.txt to import\n\n%s", usage)
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// NOTE: Like backup this works next to a running server, its stats cache catches up once it expires
+	db, closeDB, err := store.OpenSQLite(cfg.SQLite)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	service := services.NewClippingsService(services.NewBookService(store.NewBookStore(db)), store.NewNoteStore(db))
+	result, err := service.ImportClippings(context.Background(), file, models.ClippingsOptions{CreateBooks: *create})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stdout, "%d imported, %d already imported, %d duplicates, %d bookmarks skipped\n",
+		result.Imported, result.AlreadyImported, result.Duplicates, result.Bookmarks)
+	fmt.Fprintf(os.Stdout, "%d books matched, %d created\n", result.BooksMatched, result.BooksCreated)
+	for _, book := range result.Unmatched {
+		fmt.Fprintf(os.Stdout, "unmatched: %s (%s), %d clippings\n", book.Title, book.Author, book.Clippings)
+	}
+	for _, e := range result.Errors {
+		fmt.Fprintf(os.Stdout, "line %d: %s\n", e.Line, e.Message)
+	}
+	return nil
+}
+
+// scheduleBackups takes a backup every interval until ctx is cancelled. A failed backup is logged and
+// retried at the next tick
+func scheduleBackups(ctx context.Context, service services.BackupService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			backup, err := service.CreateBackup(ctx)
+			if err != nil {
+				logger.Error("Scheduled backup failed", "error", err)
+				continue
+			}
+			logger.Info("Scheduled backup done", "name", backup.Name, "size", backup.Size)
+		}
+	}
+}
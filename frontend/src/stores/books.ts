@@ -48,7 +48,7 @@ export const useBookStore = defineStore('book', () => {
       const response = await axios.post('http://localhost:8080/api/v1/books', book)
       books.value = [...books.value, response.data]
     } catch (err: any) {
-      throw new Error(err.response?.data?.error || 'Failed to create book')
+      throw new Error(err.response?.data?.detail || 'Failed to create book')
     }
   }
 
@@ -57,7 +57,7 @@ export const useBookStore = defineStore('book', () => {
       await axios.put(`http://localhost:8080/api/v1/books/${book.id}`, book)
       books.value = books.value.map((b) => (b.id === book.id ? book : b))
     } catch (err: any) {
-      throw new Error(err.response?.data?.error || 'Failed to update book')
+      throw new Error(err.response?.data?.detail || 'Failed to update book')
     }
   }
 
@@ -66,7 +66,7 @@ export const useBookStore = defineStore('book', () => {
       await axios.delete(`http://localhost:8080/api/v1/books/${id}`)
       books.value = books.value.filter((b) => b.id !== id)
     } catch (err: any) {
-      throw new Error(err.response?.data?.error || 'Failed to delete book')
+      throw new Error(err.response?.data?.detail || 'Failed to delete book')
     }
   }
 
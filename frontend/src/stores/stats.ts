@@ -2,14 +2,26 @@ import { defineStore } from 'pinia'
 import axios from 'axios'
 import { ref } from 'vue'
 
+interface AuthorStats {
+  author: string
+  rank: number
+  total: number
+  to_read: number
+  reading: number
+  complete: number
+  abandoned: number
+  completion_rate: number
+}
+
 interface Stats {
   total_read: number
+  total_abandoned: number
   reading_progress: number
-  popular_author: string
+  top_authors: AuthorStats[] | null
 }
 
 export const useStatsStore = defineStore('stats', () => {
-  const stats = ref<Stats>({ total_read: 0, reading_progress: 0, popular_author: 'N/A' })
+  const stats = ref<Stats>({ total_read: 0, total_abandoned: 0, reading_progress: 0, top_authors: null })
   const loading = ref(false)
   const error = ref<string | null>(null)
 
@@ -23,7 +35,7 @@ export const useStatsStore = defineStore('stats', () => {
     } catch (err: any) {
       error.value = err.message || 'Failed to fetch stats'
       console.error('Stats fetch error:', err) // Debug
-      stats.value = { total_read: 0, reading_progress: 0, popular_author: 'N/A' }
+      stats.value = { total_read: 0, total_abandoned: 0, reading_progress: 0, top_authors: null }
     } finally {
       loading.value = false
     }
@@ -2,11 +2,11 @@ export interface Book {
   id: number
   title: string
   author: string
-  status: 'unread' | 'reading' | 'complete'
+  status: 'unread' | 'reading' | 'complete' | 'abandoned'
 }
 
 export interface BookFormData {
   title: string
   author: string
-  status: 'unread' | 'reading' | 'complete'
+  status: 'unread' | 'reading' | 'complete' | 'abandoned'
 }
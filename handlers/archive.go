@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxImportSize keeps an import from filling up the memory, archives are decoded whole before validation
+const maxImportSize = 64 << 20
+
+type ArchiveHandler struct {
+	service services.ArchiveService
+}
+
+func NewArchiveHandler(service services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{service: service}
+}
+
+// Export streams the library as a JSON archive, as a download
+func (h *ArchiveHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filename := fmt.Sprintf("book-tracker-%s.json", time.Now().UTC().Format(models.DateLayout))
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	// NOTE: Once the first bytes are out the status cant change anymore. A failure halfway leaves a truncated
+	// document behind, which is invalid JSON, so it cant be mistaken for a complete archive
+	cw := &countingWriter{w: w}
+	if err := h.service.Export(r.Context(), cw); err != nil && cw.n == 0 {
+		w.Header().Del("Content-Disposition")
+		writeError(w, r, fmt.Errorf("export: %w", err))
+	}
+}
+
+// countingWriter tells whether anything was sent yet
+type countingWriter struct {
+	w io.Writer
+	n int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += n
+	return n, err
+}
+
+// Import reads a JSON archive. ?mode=merge|replace and ?ids=keep|new, see models.ParseImportOptions
+func (h *ArchiveHandler) Import(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseImportOptions(r.URL.Query().Get("mode"), r.URL.Query().Get("ids"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var archive models.Archive
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportSize)).Decode(&archive); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	result, err := h.service.Import(r.Context(), &archive, opts)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("import: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"book-tracker/store"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type AuthorHandler struct {
+	service services.AuthorService
+}
+
+func NewAuthorHandler(service services.AuthorService) *AuthorHandler {
+	return &AuthorHandler{service: service}
+}
+
+// parseAuthorID turns the id from the path into an author id, no author can have an id that is not a number
+func parseAuthorID(id string) (int64, error) {
+	authorID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, store.ErrAuthorNotFound
+	}
+	return authorID, nil
+}
+
+func (h *AuthorHandler) CreateAuthor(w http.ResponseWriter, r *http.Request) {
+	var author models.Author
+	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.CreateAuthor(r.Context(), &author); err != nil {
+		writeError(w, r, fmt.Errorf("create author: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, author)
+}
+
+// ListAuthors returns every author by sort name with the number of books they are on
+func (h *AuthorHandler) ListAuthors(w http.ResponseWriter, r *http.Request) {
+	authors, err := h.service.ListAuthors(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list authors: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, authors)
+}
+
+func (h *AuthorHandler) GetAuthor(w http.ResponseWriter, r *http.Request, id string) {
+	authorID, err := parseAuthorID(id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get author: %w", err))
+		return
+	}
+	author, err := h.service.GetAuthor(r.Context(), authorID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get author: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, author)
+}
+
+// UpdateAuthor renames the author, the author string of every one of their books changes with it
+func (h *AuthorHandler) UpdateAuthor(w http.ResponseWriter, r *http.Request, id string) {
+	var author models.Author
+	if err := json.NewDecoder(r.Body).Decode(&author); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	authorID, err := parseAuthorID(id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("update author: %w", err))
+		return
+	}
+	author.ID = authorID
+	if err := h.service.UpdateAuthor(r.Context(), &author); err != nil {
+		writeError(w, r, fmt.Errorf("update author: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, author)
+}
+
+func (h *AuthorHandler) DeleteAuthor(w http.ResponseWriter, r *http.Request, id string) {
+	authorID, err := parseAuthorID(id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("delete author: %w", err))
+		return
+	}
+	if err := h.service.DeleteAuthor(r.Context(), authorID); err != nil {
+		writeError(w, r, fmt.Errorf("delete author: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MergeAuthors moves the books of an author over to the one in the body as {"into": 42}
+func (h *AuthorHandler) MergeAuthors(w http.ResponseWriter, r *http.Request, id string) {
+	var merge models.AuthorMerge
+	if err := json.NewDecoder(r.Body).Decode(&merge); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	authorID, err := parseAuthorID(id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("merge authors: %w", err))
+		return
+	}
+	if err := h.service.MergeAuthors(r.Context(), authorID, &merge); err != nil {
+		writeError(w, r, fmt.Errorf("merge authors: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
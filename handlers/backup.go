@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"book-tracker/services"
+	"fmt"
+	"net/http"
+)
+
+type BackupHandler struct {
+	service services.BackupService
+}
+
+func NewBackupHandler(service services.BackupService) *BackupHandler {
+	return &BackupHandler{service: service}
+}
+
+// CreateBackup takes a snapshot of the database right away, on top of the scheduled ones
+func (h *BackupHandler) CreateBackup(w http.ResponseWriter, r *http.Request) {
+	backup, err := h.service.CreateBackup(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("create backup: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, backup)
+}
+
+func (h *BackupHandler) ListBackups(w http.ResponseWriter, r *http.Request) {
+	backups, err := h.service.ListBackups(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list backups: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, backups)
+}
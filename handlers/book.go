@@ -3,12 +3,11 @@ package handlers
 import (
 	"book-tracker/models"
 	"book-tracker/services"
-	"book-tracker/store"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
-	"strconv"
+	"net/url"
+	"strings"
 )
 
 type BookHandler struct {
@@ -19,108 +18,80 @@ func NewBookHandler(service services.BookService) *BookHandler {
 	return &BookHandler{service: service}
 }
 
-type errorResponse struct {
-	Error string `json:"error"`
-}
-
-// Just a small utility for error serialization/encoding
-func writeError(w http.ResponseWriter, status int, err error) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
-}
-
 func (h *BookHandler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	var book models.Book
 	if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
 		return
 	}
 	if err := h.service.CreateBook(r.Context(), &book); err != nil {
-		if errors.Is(err, models.ErrMissingID) || errors.Is(err, models.ErrInvalidID) ||
-			errors.Is(err, models.ErrMissingTitle) || errors.Is(err, models.ErrMissingAuthor) ||
-			errors.Is(err, models.ErrInvalidStatus) || errors.Is(err, models.ErrEmptyStatus) {
-			writeError(w, http.StatusBadRequest, err)
-		} else {
-			writeError(w, http.StatusInternalServerError, fmt.Errorf("create book error: %v", err))
-		}
+		writeError(w, r, fmt.Errorf("create book: %w", err))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(book); err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode response"))
-	}
+	json.NewEncoder(w).Encode(book) // NOTE: Headers are already sent so an encoding error cant be reported anymore
 }
 
 func (h *BookHandler) ListBooks(w http.ResponseWriter, r *http.Request) {
-	status := r.URL.Query().Get("status")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	query := r.URL.Query()
+	filter := models.BookFilter{
+		Status:        models.BookStatus(query.Get("status")),
+		Tags:          queryList(query, "tag"),
+		AnyTags:       queryList(query, "tag_any"),
+		CompletedFrom: query.Get("completed_from"),
+		CompletedTo:   query.Get("completed_to"),
+		Sort:          models.BookSort(query.Get("sort")),
+	}
 	// here, we could also later implement author and title if we wish
 	// the store has the functionality for it but its whitespaced in the service
 
-	limit := 10
-	if limitStr != "" {
-		var err error
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil || limit <= 0 || limit > 1000 { // this is cardcoded for now but this can later be moved to Config
-			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: must be a number between 1 and 1000"))
-			return
-		}
-	}
-	offset := 0
-	if offsetStr != "" {
-		var err error
-		offset, err = strconv.Atoi(offsetStr)
-		if err != nil || offset < 0 {
-			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid offset: must be a non-negative number"))
-			return
-		}
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
 	}
 
-	books, err := h.service.ListBooks(r.Context(), status, limit, offset)
+	books, err := h.service.ListBooks(r.Context(), filter, limit, offset)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Errorf("list books error: %v", err))
+		writeError(w, r, fmt.Errorf("list books: %w", err))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(books); err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode response"))
-	}
+	json.NewEncoder(w).Encode(books)
 }
 func (h *BookHandler) UpdateBook(w http.ResponseWriter, r *http.Request, id string) {
 	var book models.Book
 	if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
 		return
 	}
 	book.ID = id
 	if err := h.service.UpdateBook(r.Context(), &book); err != nil {
-		if errors.Is(err, store.ErrBookNotFound) {
-			writeError(w, http.StatusNotFound, err)
-		} else if errors.Is(err, models.ErrMissingTitle) || errors.Is(err, models.ErrMissingAuthor) ||
-			errors.Is(err, models.ErrInvalidStatus) || errors.Is(err, models.ErrInvalidID) {
-			writeError(w, http.StatusBadRequest, err)
-		} else {
-			writeError(w, http.StatusInternalServerError, fmt.Errorf("update book error: %v", err))
-		}
+		writeError(w, r, fmt.Errorf("update book: %w", err))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(book); err != nil {
-		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode response"))
-	}
+	json.NewEncoder(w).Encode(book) // NOTE: Headers are already sent so an encoding error cant be reported anymore
 }
 
 func (h *BookHandler) DeleteBook(w http.ResponseWriter, r *http.Request, id string) {
 	if err := h.service.DeleteBook(r.Context(), id); err != nil {
-		if errors.Is(err, store.ErrBookNotFound) {
-			writeError(w, http.StatusNotFound, err)
-		} else {
-			writeError(w, http.StatusInternalServerError, fmt.Errorf("delete book error: %v", err))
-		}
+		writeError(w, r, fmt.Errorf("delete book: %w", err))
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// queryList reads a parameter that can be repeated and/or comma separated, ?tag=a&tag=b is ?tag=a,b
+func queryList(query url.Values, key string) []string {
+	var values []string
+	for _, value := range query[key] {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type ClippingsHandler struct {
+	service services.ClippingsService
+}
+
+func NewClippingsHandler(service services.ClippingsService) *ClippingsHandler {
+	return &ClippingsHandler{service: service}
+}
+
+// ImportKindle reads a Kindle My Clippings.txt sent as the request body, i.e.
+// curl --data-binary "@My Clippings.txt". ?create=true adds the books that are not in the library yet
+func (h *ClippingsHandler) ImportKindle(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseClippingsOptions(r.URL.Query().Get("create"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	result, err := h.service.ImportClippings(r.Context(), http.MaxBytesReader(w, r.Body, maxImportSize), opts)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = fmt.Errorf("%w: %w", errInvalidBody, err)
+		}
+		writeError(w, r, fmt.Errorf("import clippings: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/store"
+	"errors"
+	"net/http"
+)
+
+// Errors raised by the handlers themselves while reading the request
+var (
+	errInvalidBody   = errors.New("invalid request body")
+	errInvalidLimit  = errors.New("invalid limit: must be a number between 1 and 1000")
+	errInvalidOffset = errors.New("invalid offset: must be a non-negative number")
+	errMissingBookID = errors.New("book id required")
+	errInvalidActive = errors.New("invalid active: must be true or false")
+)
+
+// errorMapping ties a sentinel error to its HTTP status and stable code.
+// Field is set for errors that concern a single field of the request body
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+	field  string
+}
+
+// NOTE:
+// This is the one place that decides how an error is presented, so the handlers can just call
+// writeError instead of repeating errors.Is chains. Order matters as the first match wins,
+// i.e. an invalid status inside an invalid body is reported as the more specific status problem
+var errorMappings = []errorMapping{
+	{models.ErrMissingID, http.StatusBadRequest, models.CodeIDMissing, "id"},
+	{models.ErrInvalidID, http.StatusBadRequest, models.CodeIDInvalid, "id"},
+	{models.ErrMissingTitle, http.StatusBadRequest, models.CodeTitleMissing, "title"},
+	{models.ErrMissingAuthor, http.StatusBadRequest, models.CodeAuthorMissing, "author"},
+	{models.ErrInvalidStatus, http.StatusBadRequest, models.CodeStatusInvalid, "status"},
+	{models.ErrEmptyStatus, http.StatusBadRequest, models.CodeStatusMissing, "status"},
+	{store.ErrBookNotFound, http.StatusNotFound, "book.not_found", ""},
+	{store.ErrGoalNotFound, http.StatusNotFound, "goal.not_found", ""},
+	{store.ErrTagNotFound, http.StatusNotFound, "tag.not_found", ""},
+	{store.ErrTagExists, http.StatusConflict, "tag.exists", "name"},
+	{store.ErrShelfNotFound, http.StatusNotFound, "shelf.not_found", ""},
+	{store.ErrBookOnShelf, http.StatusConflict, "shelf.book_exists", "book_id"},
+	{store.ErrBookNotOnShelf, http.StatusNotFound, "shelf.book_not_found", ""},
+	{store.ErrSearchNotFound, http.StatusNotFound, "search.not_found", ""},
+	{store.ErrSeriesNotFound, http.StatusNotFound, "series.not_found", ""},
+	{store.ErrBookInOtherSeries, http.StatusConflict, "series.book_in_other_series", "book_id"},
+	{store.ErrBookNotInSeries, http.StatusNotFound, "series.book_not_found", ""},
+	{store.ErrAuthorNotFound, http.StatusNotFound, "author.not_found", ""},
+	{store.ErrAuthorExists, http.StatusConflict, "author.exists", "name"},
+	{store.ErrAuthorInUse, http.StatusConflict, "author.in_use", ""},
+	{store.ErrReviewNotFound, http.StatusNotFound, "review.not_found", ""},
+	{store.ErrBookNotComplete, http.StatusConflict, "review.book_not_complete", ""},
+	{store.ErrNoteNotFound, http.StatusNotFound, "note.not_found", ""},
+	{store.ErrNotHighlight, http.StatusConflict, "highlight.not_highlight", ""},
+	{errInvalidBody, http.StatusBadRequest, "request.invalid_body", ""},
+	{errInvalidLimit, http.StatusBadRequest, "request.invalid_limit", "limit"},
+	{errInvalidOffset, http.StatusBadRequest, "request.invalid_offset", "offset"},
+	{errMissingBookID, http.StatusBadRequest, models.CodeIDMissing, "id"},
+	{errInvalidActive, http.StatusBadRequest, "request.invalid_active", "active"},
+}
+
+const codeValidationFailed = "validation_failed"
+
+// problemFor converts err into a problem. Validation errors become a 422 listing every violation,
+// everything else goes through errorMappings. Anything unknown is a 500 and its message is not
+// exposed as it can contain internals like SQL errors
+func problemFor(err error) *problem.Problem {
+	if ve, ok := models.AsValidationError(err); ok {
+		p := problem.New(http.StatusUnprocessableEntity, codeValidationFailed, ve.Error())
+		for _, fe := range ve.Errors {
+			p.Errors = append(p.Errors, problem.FieldError{Field: fe.Field, Code: fe.Code, Message: fe.Message})
+		}
+		return p
+	}
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			p := problem.New(m.status, m.code, err.Error())
+			if m.field != "" {
+				p.Errors = []problem.FieldError{{Field: m.field, Code: m.code, Message: m.err.Error()}}
+			}
+			return p
+		}
+	}
+	return problem.New(http.StatusInternalServerError, problem.CodeInternal, "internal server error")
+}
+
+// Just a small utility for error serialization/encoding
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	problemFor(err).Write(w, r)
+}
+
+// MissingBookID answers requests to /api/v1/books/ without an id
+func MissingBookID(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, errMissingBookID)
+}
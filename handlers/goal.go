@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type GoalHandler struct {
+	service services.GoalService
+}
+
+func NewGoalHandler(service services.GoalService) *GoalHandler {
+	return &GoalHandler{service: service}
+}
+
+func (h *GoalHandler) CreateGoal(w http.ResponseWriter, r *http.Request) {
+	var goal models.Goal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	progress, err := h.service.CreateGoal(r.Context(), &goal)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("create goal: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, progress)
+}
+
+// ListGoals returns every goal with its progress. ?active=true limits it to goals running today
+func (h *GoalHandler) ListGoals(w http.ResponseWriter, r *http.Request) {
+	activeOnly := false
+	if active := r.URL.Query().Get("active"); active != "" {
+		var err error
+		activeOnly, err = strconv.ParseBool(active)
+		if err != nil {
+			writeError(w, r, errInvalidActive)
+			return
+		}
+	}
+	goals, err := h.service.ListGoals(r.Context(), activeOnly)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list goals: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, goals)
+}
+
+func (h *GoalHandler) GetGoal(w http.ResponseWriter, r *http.Request, id string) {
+	progress, err := h.service.GetGoal(r.Context(), id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get goal: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func (h *GoalHandler) UpdateGoal(w http.ResponseWriter, r *http.Request, id string) {
+	var goal models.Goal
+	if err := json.NewDecoder(r.Body).Decode(&goal); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	goal.ID = id
+	progress, err := h.service.UpdateGoal(r.Context(), &goal)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("update goal: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, progress)
+}
+
+func (h *GoalHandler) DeleteGoal(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteGoal(r.Context(), id); err != nil {
+		writeError(w, r, fmt.Errorf("delete goal: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type HighlightHandler struct {
+	service services.HighlightService
+}
+
+func NewHighlightHandler(service services.HighlightService) *HighlightHandler {
+	return &HighlightHandler{service: service}
+}
+
+// DailyHighlights returns the highlights and quotes still to review today
+func (h *HighlightHandler) DailyHighlights(w http.ResponseWriter, r *http.Request) {
+	daily, err := h.service.DailyHighlights(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("daily highlights: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, daily)
+}
+
+// ReviewHighlight records the feedback on a highlight, {"feedback": "keep"}, and returns when it comes back
+func (h *HighlightHandler) ReviewHighlight(w http.ResponseWriter, r *http.Request, id string) {
+	var body struct {
+		Feedback string `json:"feedback"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	schedule, err := h.service.ReviewHighlight(r.Context(), id, body.Feedback)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("review highlight: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, schedule)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type NoteHandler struct {
+	service services.NoteService
+}
+
+func NewNoteHandler(service services.NoteService) *NoteHandler {
+	return &NoteHandler{service: service}
+}
+
+// CreateNote adds a note to a book, {"type": "highlight", "body": "...", "page": 12, "location": "1234-1240"}
+func (h *NoteHandler) CreateNote(w http.ResponseWriter, r *http.Request, bookID string) {
+	var note models.Note
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	note.BookID = bookID
+	if err := h.service.CreateNote(r.Context(), &note); err != nil {
+		writeError(w, r, fmt.Errorf("create note: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, note)
+}
+
+// ListBookNotes returns the notes of one book in reading order. Supports ?type=, ?q=, ?limit= and ?offset=
+func (h *NoteHandler) ListBookNotes(w http.ResponseWriter, r *http.Request, bookID string) {
+	h.listNotes(w, r, bookID)
+}
+
+// SearchNotes returns the notes of all books, the last written first. ?q= searches their text, every word
+// has to be there, a word also matches longer ones starting with it. Supports ?type=, ?limit= and ?offset=
+func (h *NoteHandler) SearchNotes(w http.ResponseWriter, r *http.Request) {
+	h.listNotes(w, r, "")
+}
+
+func (h *NoteHandler) listNotes(w http.ResponseWriter, r *http.Request, bookID string) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query, err := models.ParseNoteQuery(r.URL.Query().Get("q"), r.URL.Query().Get("type"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	query.BookID = bookID
+	notes, err := h.service.ListNotes(r.Context(), query, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list notes: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, notes)
+}
+
+func (h *NoteHandler) GetNote(w http.ResponseWriter, r *http.Request, id string) {
+	note, err := h.service.GetNote(r.Context(), id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get note: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, note)
+}
+
+func (h *NoteHandler) UpdateNote(w http.ResponseWriter, r *http.Request, id string) {
+	var note models.Note
+	if err := json.NewDecoder(r.Body).Decode(&note); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	note.ID = id
+	if err := h.service.UpdateNote(r.Context(), &note); err != nil {
+		writeError(w, r, fmt.Errorf("update note: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, note)
+}
+
+func (h *NoteHandler) DeleteNote(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteNote(r.Context(), id); err != nil {
+		writeError(w, r, fmt.Errorf("delete note: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
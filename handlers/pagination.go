@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 1000 // this is cardcoded for now but this can later be moved to Config
+)
+
+// parsePagination reads ?limit= and ?offset=, both optional
+func parsePagination(r *http.Request) (limit, offset int, err error) {
+	limit = defaultLimit
+	if s := r.URL.Query().Get("limit"); s != "" {
+		limit, err = strconv.Atoi(s)
+		if err != nil || limit <= 0 || limit > maxLimit {
+			return 0, 0, errInvalidLimit
+		}
+	}
+	if s := r.URL.Query().Get("offset"); s != "" {
+		offset, err = strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return 0, 0, errInvalidOffset
+		}
+	}
+	return limit, offset, nil
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"book-tracker/middleware"
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type ReviewHandler struct {
+	service services.ReviewService
+}
+
+func NewReviewHandler(service services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{service: service}
+}
+
+// SetReview rates and reviews a complete or abandoned book, {"rating": 4.5, "body": "..."}. Sending it again
+// replaces the review. Every user has their own review of a book
+func (h *ReviewHandler) SetReview(w http.ResponseWriter, r *http.Request, bookID string) {
+	var review models.Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	review.BookID = bookID
+	if err := h.service.SetReview(r.Context(), middleware.UserFrom(r.Context()), &review); err != nil {
+		writeError(w, r, fmt.Errorf("set review: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, review)
+}
+
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request, bookID string) {
+	review, err := h.service.GetReview(r.Context(), middleware.UserFrom(r.Context()), bookID)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get review: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, review)
+}
+
+// ListReviews returns a page of the reviews of the user, the last written first
+func (h *ReviewHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	reviews, err := h.service.ListReviews(r.Context(), middleware.UserFrom(r.Context()), limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list reviews: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, reviews)
+}
+
+func (h *ReviewHandler) DeleteReview(w http.ResponseWriter, r *http.Request, bookID string) {
+	if err := h.service.DeleteReview(r.Context(), middleware.UserFrom(r.Context()), bookID); err != nil {
+		writeError(w, r, fmt.Errorf("delete review: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"book-tracker/middleware"
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// SearchHandler only ever sees the saved searches of the user of the request, see middleware.User
+type SearchHandler struct {
+	service services.SearchService
+}
+
+func NewSearchHandler(service services.SearchService) *SearchHandler {
+	return &SearchHandler{service: service}
+}
+
+func (h *SearchHandler) CreateSearch(w http.ResponseWriter, r *http.Request) {
+	var search models.SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.CreateSearch(r.Context(), middleware.UserFrom(r.Context()), &search); err != nil {
+		writeError(w, r, fmt.Errorf("create saved search: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, search)
+}
+
+// ListSearches returns every saved search of the user with the number of books it matches right now
+func (h *SearchHandler) ListSearches(w http.ResponseWriter, r *http.Request) {
+	searches, err := h.service.ListSearches(r.Context(), middleware.UserFrom(r.Context()))
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list saved searches: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, searches)
+}
+
+func (h *SearchHandler) GetSearch(w http.ResponseWriter, r *http.Request, id string) {
+	search, err := h.service.GetSearch(r.Context(), middleware.UserFrom(r.Context()), id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get saved search: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, search)
+}
+
+func (h *SearchHandler) UpdateSearch(w http.ResponseWriter, r *http.Request, id string) {
+	var search models.SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	search.ID = id
+	if err := h.service.UpdateSearch(r.Context(), middleware.UserFrom(r.Context()), &search); err != nil {
+		writeError(w, r, fmt.Errorf("update saved search: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, search)
+}
+
+func (h *SearchHandler) DeleteSearch(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteSearch(r.Context(), middleware.UserFrom(r.Context()), id); err != nil {
+		writeError(w, r, fmt.Errorf("delete saved search: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListSearchBooks runs the saved search, paginated like the book list
+func (h *SearchHandler) ListSearchBooks(w http.ResponseWriter, r *http.Request, id string) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	books, err := h.service.ListSearchBooks(r.Context(), middleware.UserFrom(r.Context()), id, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list saved search books: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, books)
+}
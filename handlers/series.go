@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type SeriesHandler struct {
+	service services.SeriesService
+}
+
+func NewSeriesHandler(service services.SeriesService) *SeriesHandler {
+	return &SeriesHandler{service: service}
+}
+
+func (h *SeriesHandler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	var series models.Series
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.CreateSeries(r.Context(), &series); err != nil {
+		writeError(w, r, fmt.Errorf("create series: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, series)
+}
+
+func (h *SeriesHandler) ListSeries(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.ListSeries(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list series: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetSeries returns the series with its volumes (and their status) in order and next_unread
+func (h *SeriesHandler) GetSeries(w http.ResponseWriter, r *http.Request, id string) {
+	series, err := h.service.GetSeries(r.Context(), id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get series: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+func (h *SeriesHandler) UpdateSeries(w http.ResponseWriter, r *http.Request, id string) {
+	var series models.Series
+	if err := json.NewDecoder(r.Body).Decode(&series); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	series.ID = id
+	if err := h.service.UpdateSeries(r.Context(), &series); err != nil {
+		writeError(w, r, fmt.Errorf("update series: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, series)
+}
+
+func (h *SeriesHandler) DeleteSeries(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteSeries(r.Context(), id); err != nil {
+		writeError(w, r, fmt.Errorf("delete series: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetVolume puts the book into the series at the position in the body ({"position": 2.5}), or moves it there
+func (h *SeriesHandler) SetVolume(w http.ResponseWriter, r *http.Request, id, bookID string) {
+	var volume models.SeriesVolume
+	if err := json.NewDecoder(r.Body).Decode(&volume); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	volume.BookID = bookID
+	if err := h.service.SetVolume(r.Context(), id, &volume); err != nil {
+		writeError(w, r, fmt.Errorf("set series volume: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *SeriesHandler) RemoveVolume(w http.ResponseWriter, r *http.Request, id, bookID string) {
+	if err := h.service.RemoveVolume(r.Context(), id, bookID); err != nil {
+		writeError(w, r, fmt.Errorf("remove series volume: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"book-tracker/models"
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type ShelfHandler struct {
+	service services.ShelfService
+}
+
+func NewShelfHandler(service services.ShelfService) *ShelfHandler {
+	return &ShelfHandler{service: service}
+}
+
+func (h *ShelfHandler) CreateShelf(w http.ResponseWriter, r *http.Request) {
+	var shelf models.Shelf
+	if err := json.NewDecoder(r.Body).Decode(&shelf); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.CreateShelf(r.Context(), &shelf); err != nil {
+		writeError(w, r, fmt.Errorf("create shelf: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusCreated, shelf)
+}
+
+func (h *ShelfHandler) ListShelves(w http.ResponseWriter, r *http.Request) {
+	shelves, err := h.service.ListShelves(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list shelves: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, shelves)
+}
+
+func (h *ShelfHandler) GetShelf(w http.ResponseWriter, r *http.Request, id string) {
+	shelf, err := h.service.GetShelf(r.Context(), id)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get shelf: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, shelf)
+}
+
+func (h *ShelfHandler) UpdateShelf(w http.ResponseWriter, r *http.Request, id string) {
+	var shelf models.Shelf
+	if err := json.NewDecoder(r.Body).Decode(&shelf); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	shelf.ID = id
+	if err := h.service.UpdateShelf(r.Context(), &shelf); err != nil {
+		writeError(w, r, fmt.Errorf("update shelf: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, shelf)
+}
+
+func (h *ShelfHandler) DeleteShelf(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.service.DeleteShelf(r.Context(), id); err != nil {
+		writeError(w, r, fmt.Errorf("delete shelf: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListShelfBooks returns the books on the shelf in shelf order, paginated like the book list
+func (h *ShelfHandler) ListShelfBooks(w http.ResponseWriter, r *http.Request, id string) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	books, err := h.service.ListShelfBooks(r.Context(), id, limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list shelf books: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, books)
+}
+
+// AddBook puts the book from the body ({"book_id": "...", "position": 0}) on the shelf
+func (h *ShelfHandler) AddBook(w http.ResponseWriter, r *http.Request, id string) {
+	var placement models.ShelfPlacement
+	if err := json.NewDecoder(r.Body).Decode(&placement); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.AddBook(r.Context(), id, &placement); err != nil {
+		writeError(w, r, fmt.Errorf("add book to shelf: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MoveBook moves a book on the shelf to the position in the body ({"position": 0})
+func (h *ShelfHandler) MoveBook(w http.ResponseWriter, r *http.Request, id, bookID string) {
+	var placement models.ShelfPlacement
+	if err := json.NewDecoder(r.Body).Decode(&placement); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	placement.BookID = bookID
+	if err := h.service.MoveBook(r.Context(), id, &placement); err != nil {
+		writeError(w, r, fmt.Errorf("move book on shelf: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ShelfHandler) RemoveBook(w http.ResponseWriter, r *http.Request, id, bookID string) {
+	if err := h.service.RemoveBook(r.Context(), id, bookID); err != nil {
+		writeError(w, r, fmt.Errorf("remove book from shelf: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
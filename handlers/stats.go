@@ -18,11 +18,45 @@ func NewStatsHandler(service services.StatsService) *StatsHandler {
 func (h *StatsHandler) GetStats(w http.ResponseWriter, r *http.Request) {
 	stats, err := h.service.GetStats(r.Context())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Get stats error: %v", err), http.StatusInternalServerError)
+		writeError(w, r, fmt.Errorf("get stats: %w", err))
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(stats); err != nil {
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GetTimeline handles ?granularity=week|month|year&from=YYYY-MM-DD&to=YYYY-MM-DD, all optional
+func (h *StatsHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	timeline, err := h.service.Timeline(r.Context(), query.Get("granularity"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get timeline: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, timeline)
+}
+
+// GetActivity handles ?tz=Europe/Stockholm, the timezone decides where a day starts and defaults to UTC
+func (h *StatsHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	activity, err := h.service.Activity(r.Context(), r.URL.Query().Get("tz"))
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get activity: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, activity)
+}
+
+// GetAuthorStats handles ?limit=&offset= the same way as the book list
+func (h *StatsHandler) GetAuthorStats(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	page, err := h.service.AuthorStats(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, r, fmt.Errorf("get author stats: %w", err))
+		return
 	}
+	writeJSON(w, http.StatusOK, page)
 }
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"book-tracker/services"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type TagHandler struct {
+	service services.TagService
+}
+
+func NewTagHandler(service services.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+// ListTags returns every tag in use with the number of books that have it, most used first
+func (h *TagHandler) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.service.ListTags(r.Context())
+	if err != nil {
+		writeError(w, r, fmt.Errorf("list tags: %w", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, tags)
+}
+
+// RenameTag renames a tag on every book, the new name is in the body as {"name": "..."}
+func (h *TagHandler) RenameTag(w http.ResponseWriter, r *http.Request, name string) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.RenameTag(r.Context(), name, body.Name); err != nil {
+		writeError(w, r, fmt.Errorf("rename tag: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MergeTags moves the books of a tag over to the one in the body as {"into": "..."}
+func (h *TagHandler) MergeTags(w http.ResponseWriter, r *http.Request, name string) {
+	var body struct {
+		Into string `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, r, fmt.Errorf("%w: %w", errInvalidBody, err))
+		return
+	}
+	if err := h.service.MergeTags(r.Context(), name, body.Into); err != nil {
+		writeError(w, r, fmt.Errorf("merge tags: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request, name string) {
+	if err := h.service.DeleteTag(r.Context(), name); err != nil {
+		writeError(w, r, fmt.Errorf("delete tag: %w", err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
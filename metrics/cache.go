@@ -0,0 +1,27 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// CacheCounters exports the hit and miss counts of an in process cache, the hit rate is
+// rate(hits) / (rate(hits) + rate(misses)). stats is read on every scrape
+func CacheCounters(cache string, stats func() (hits, misses uint64)) []prometheus.Collector {
+	labels := prometheus.Labels{"cache": cache}
+	return []prometheus.Collector{
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "book_tracker_cache_hits_total",
+			Help:        "Number of lookups served from the cache",
+			ConstLabels: labels,
+		}, func() float64 {
+			hits, _ := stats()
+			return float64(hits)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name:        "book_tracker_cache_misses_total",
+			Help:        "Number of lookups that had to be computed",
+			ConstLabels: labels,
+		}, func() float64 {
+			_, misses := stats()
+			return float64(misses)
+		}),
+	}
+}
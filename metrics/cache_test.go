@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCacheCounters(t *testing.T) {
+	var hits, misses uint64 = 3, 1
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(CacheCounters("stats", func() (uint64, uint64) { return hits, misses })...)
+
+	expected := `
+# HELP book_tracker_cache_hits_total Number of lookups served from the cache
+# TYPE book_tracker_cache_hits_total counter
+book_tracker_cache_hits_total{cache="stats"} 3
+# HELP book_tracker_cache_misses_total Number of lookups that had to be computed
+# TYPE book_tracker_cache_misses_total counter
+book_tracker_cache_misses_total{cache="stats"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+
+	hits = 10
+	if got := testutil.ToFloat64(CacheCounters("stats", func() (uint64, uint64) { return hits, misses })[0]); got != 10 {
+		t.Errorf("hits = %v, want 10", got)
+	}
+}
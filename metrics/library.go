@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"book-tracker/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LibraryConfig bounds how much the library collector exports and how often it hits the database
+type LibraryConfig struct {
+	TopAuthors     int           // Only the N authors with most books get their own series
+	CompletionDays int           // Number of days (including today) exported for completions
+	CacheTTL       time.Duration // Scrapes within this window reuse the previous snapshot
+	Timeout        time.Duration // Upper bound for computing a snapshot
+}
+
+func DefaultLibraryConfig() LibraryConfig {
+	return LibraryConfig{
+		TopAuthors:     10,
+		CompletionDays: 7,
+		CacheTTL:       30 * time.Second,
+		Timeout:        5 * time.Second,
+	}
+}
+
+// LibraryCollector exports gauges about the books themselves and the database they live in.
+// NOTE: Implements prometheus.Collector so values are computed on scrape instead of being kept up to date
+// on every write. Documentation: https://pkg.go.dev/github.com/prometheus/client_golang/prometheus#Collector
+type LibraryCollector struct {
+	store   store.MetricsStore
+	dbStats func() sql.DBStats
+	cfg     LibraryConfig
+	now     func() time.Time
+
+	mu       sync.Mutex
+	cached   *store.LibrarySnapshot
+	cachedAt time.Time
+
+	books        *prometheus.Desc
+	authorBooks  *prometheus.Desc
+	completions  *prometheus.Desc
+	dbSize       *prometheus.Desc
+	dbOpen       *prometheus.Desc
+	dbInUse      *prometheus.Desc
+	dbIdle       *prometheus.Desc
+	dbWaitCount  *prometheus.Desc
+	dbWaitTime   *prometheus.Desc
+	scrapeErrors *prometheus.Desc
+}
+
+// NewLibraryCollector creates the collector. dbStats is optional, i.e. pass db.Stats to export pool stats
+func NewLibraryCollector(s store.MetricsStore, dbStats func() sql.DBStats, cfg LibraryConfig) *LibraryCollector {
+	return &LibraryCollector{
+		store:   s,
+		dbStats: dbStats,
+		cfg:     cfg,
+		now:     time.Now,
+
+		books:       prometheus.NewDesc("book_tracker_books", "Number of books by status", []string{"status"}, nil),
+		authorBooks: prometheus.NewDesc("book_tracker_author_books", "Number of books for the top authors", []string{"author"}, nil),
+		completions: prometheus.NewDesc("book_tracker_completions", "Number of books completed per UTC day", []string{"day"}, nil),
+		dbSize:      prometheus.NewDesc("book_tracker_db_size_bytes", "Size of the database file", nil, nil),
+		dbOpen:      prometheus.NewDesc("book_tracker_db_connections_open", "Number of open database connections", nil, nil),
+		dbInUse:     prometheus.NewDesc("book_tracker_db_connections_in_use", "Number of database connections in use", nil, nil),
+		dbIdle:      prometheus.NewDesc("book_tracker_db_connections_idle", "Number of idle database connections", nil, nil),
+		dbWaitCount: prometheus.NewDesc("book_tracker_db_wait_count_total", "Total number of connections waited for", nil, nil),
+		dbWaitTime:  prometheus.NewDesc("book_tracker_db_wait_duration_seconds_total", "Total time blocked waiting for a connection", nil, nil),
+		scrapeErrors: prometheus.NewDesc("book_tracker_library_scrape_error",
+			"1 if the last library snapshot failed, 0 otherwise", nil, nil),
+	}
+}
+
+func (c *LibraryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.books
+	ch <- c.authorBooks
+	ch <- c.completions
+	ch <- c.dbSize
+	ch <- c.dbOpen
+	ch <- c.dbInUse
+	ch <- c.dbIdle
+	ch <- c.dbWaitCount
+	ch <- c.dbWaitTime
+	ch <- c.scrapeErrors
+}
+
+func (c *LibraryCollector) Collect(ch chan<- prometheus.Metric) {
+	snapshot, err := c.snapshot()
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.GaugeValue, 1)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.scrapeErrors, prometheus.GaugeValue, 0)
+		c.collectSnapshot(ch, snapshot)
+	}
+
+	// NOTE: Pool stats are in-process counters so they are cheap and always fresh
+	if c.dbStats != nil {
+		stats := c.dbStats()
+		ch <- prometheus.MustNewConstMetric(c.dbOpen, prometheus.GaugeValue, float64(stats.OpenConnections))
+		ch <- prometheus.MustNewConstMetric(c.dbInUse, prometheus.GaugeValue, float64(stats.InUse))
+		ch <- prometheus.MustNewConstMetric(c.dbIdle, prometheus.GaugeValue, float64(stats.Idle))
+		ch <- prometheus.MustNewConstMetric(c.dbWaitCount, prometheus.CounterValue, float64(stats.WaitCount))
+		ch <- prometheus.MustNewConstMetric(c.dbWaitTime, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	}
+}
+
+func (c *LibraryCollector) collectSnapshot(ch chan<- prometheus.Metric, snapshot *store.LibrarySnapshot) {
+	for status, count := range snapshot.BooksByStatus {
+		ch <- prometheus.MustNewConstMetric(c.books, prometheus.GaugeValue, float64(count), status)
+	}
+	for _, ac := range snapshot.TopAuthors {
+		ch <- prometheus.MustNewConstMetric(c.authorBooks, prometheus.GaugeValue, float64(ac.Count), ac.Author)
+	}
+	// NOTE: Days without completions are exported as 0 so the series dont come and go
+	today := c.now().UTC()
+	for i := 0; i < c.cfg.CompletionDays; i++ {
+		day := today.AddDate(0, 0, -i).Format(time.DateOnly)
+		ch <- prometheus.MustNewConstMetric(c.completions, prometheus.GaugeValue, float64(snapshot.CompletionsByDay[day]), day)
+	}
+	ch <- prometheus.MustNewConstMetric(c.dbSize, prometheus.GaugeValue, float64(snapshot.DBSizeBytes))
+}
+
+// snapshot returns the cached snapshot while its fresh and otherwise queries the store.
+// NOTE: The lock is held during the query so concurrent scrapes wait for one query instead of running their own
+func (c *LibraryCollector) snapshot() (*store.LibrarySnapshot, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if c.cached != nil && now.Sub(c.cachedAt) < c.cfg.CacheTTL {
+		return c.cached, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.Timeout)
+	defer cancel()
+
+	y, m, d := now.UTC().Date()
+	since := time.Date(y, m, d, 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(c.cfg.CompletionDays - 1))
+	snapshot, err := c.store.LibrarySnapshot(ctx, c.cfg.TopAuthors, since)
+	if err != nil {
+		return nil, err
+	}
+	c.cached = snapshot
+	c.cachedAt = now
+	return snapshot, nil
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"book-tracker/store"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakeMetricsStore struct {
+	calls    int
+	err      error
+	snapshot *store.LibrarySnapshot
+}
+
+func (f *fakeMetricsStore) LibrarySnapshot(ctx context.Context, topAuthors int, since time.Time) (*store.LibrarySnapshot, error) {
+	f.calls++
+	return f.snapshot, f.err
+}
+
+func TestLibraryCollector(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	fake := &fakeMetricsStore{snapshot: &store.LibrarySnapshot{
+		BooksByStatus:    map[string]int{"complete": 3, "reading": 1},
+		TopAuthors:       []store.AuthorCount{{Author: "Jane Austen", Count: 3}},
+		CompletionsByDay: map[string]int{"2026-10-19": 2},
+		DBSizeBytes:      4096,
+	}}
+
+	cfg := DefaultLibraryConfig()
+	cfg.CacheTTL = time.Minute
+	collector := NewLibraryCollector(fake, nil, cfg)
+	collector.now = func() time.Time { return now }
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collector)
+
+	t.Run("ExportsSnapshot", func(t *testing.T) {
+		families, err := reg.Gather()
+		if err != nil {
+			t.Fatalf("Gather failed: %v", err)
+		}
+		got := map[string]int{}
+		for _, mf := range families {
+			got[mf.GetName()] = len(mf.GetMetric())
+		}
+		if got["book_tracker_books"] != 2 {
+			t.Errorf("book_tracker_books has %d series, want 2", got["book_tracker_books"])
+		}
+		if got["book_tracker_completions"] != cfg.CompletionDays {
+			t.Errorf("book_tracker_completions has %d series, want %d (zero filled)", got["book_tracker_completions"], cfg.CompletionDays)
+		}
+		if got["book_tracker_author_books"] != 1 || got["book_tracker_db_size_bytes"] != 1 {
+			t.Errorf("Unexpected series counts: %+v", got)
+		}
+	})
+
+	t.Run("CachesWithinTTL", func(t *testing.T) {
+		fake.calls = 0
+		collector.cached = nil
+
+		testutil.CollectAndCount(collector)
+		testutil.CollectAndCount(collector)
+		if fake.calls != 1 {
+			t.Errorf("Store called %d times within TTL, want 1", fake.calls)
+		}
+
+		now = now.Add(2 * time.Minute)
+		testutil.CollectAndCount(collector)
+		if fake.calls != 2 {
+			t.Errorf("Store called %d times after TTL, want 2", fake.calls)
+		}
+	})
+
+	t.Run("ReportsErrors", func(t *testing.T) {
+		collector.cached = nil
+		fake.err = errors.New("database is locked")
+		if got := testutil.CollectAndCount(collector, "book_tracker_library_scrape_error"); got != 1 {
+			t.Fatalf("scrape error series = %d, want 1", got)
+		}
+		if got := testutil.CollectAndCount(collector, "book_tracker_books"); got != 0 {
+			t.Errorf("book_tracker_books series = %d, want 0 when the snapshot fails", got)
+		}
+	})
+}
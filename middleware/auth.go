@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"book-tracker/problem"
+)
+
+const codeUnauthorized = "request.unauthorized"
+
+// RequireToken only lets requests through that send "Authorization: Bearer <token>".
+// NOTE: There are no users, this is a shared secret for the admin endpoints only
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			// NOTE: Constant time so the token cant be guessed byte by byte from the response times
+			if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				problem.Write(w, r, http.StatusUnauthorized, codeUnauthorized, "a valid admin token is required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,21 +2,106 @@ package middleware
 
 import (
 	"log/slog"
+	"math/rand/v2"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// LogConfig controls what the access log emits.
+// NOTE: Levels are keyed by status class i.e. 2 for 2xx, 4 for 4xx and so on
+type LogConfig struct {
+	Levels        map[int]slog.Level
+	SampleRate    float64  // Fraction (0-1) of successful (< 400) requests that are logged. Errors are always logged
+	RedactHeaders []string // Header names whose values are replaced before logging
+}
+
+func DefaultLogConfig() LogConfig {
+	return LogConfig{
+		Levels: map[int]slog.Level{
+			1: slog.LevelInfo,
+			2: slog.LevelInfo,
+			3: slog.LevelInfo,
+			4: slog.LevelWarn,
+			5: slog.LevelError,
+		},
+		SampleRate:    1,
+		RedactHeaders: []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization", "X-Api-Key"},
+	}
+}
+
+const redacted = "[REDACTED]"
+
+// NOTE: Swappable in tests so sampling becomes deterministic
+var sampleRand = rand.Float64
+
 // NOTE: Using slog as its able to produce JSON logs which is good for production
-func Logging(logger *slog.Logger) func(http.Handler) http.Handler {
+func Logging(logger *slog.Logger, cfg LogConfig) func(http.Handler) http.Handler {
+	redact := make(map[string]bool, len(cfg.RedactHeaders))
+	for _, h := range cfg.RedactHeaders {
+		redact[http.CanonicalHeaderKey(h)] = true
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 			requestID := uuid.NewString() // lets add a identifier
-			logger.Info("request started", "method", r.Method, "path", r.URL.Path, "request_id", requestID)
-			next.ServeHTTP(w, r)
-			logger.Info("request completed", "method", r.Method, "path", r.URL.Path, "request_id", requestID, "duration", time.Since(start))
+			logger.DebugContext(r.Context(), "request started", "method", r.Method, "path", r.URL.Path, "request_id", requestID)
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			status := rec.Status()
+			if status < 400 && !sampled(cfg.SampleRate) {
+				return
+			}
+
+			level, ok := cfg.Levels[status/100]
+			if !ok {
+				level = slog.LevelInfo
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.String("query", r.URL.RawQuery),
+				slog.String("request_id", requestID),
+				slog.Int("status", status),
+				slog.Int("bytes", rec.BytesWritten()),
+				slog.Duration("duration", time.Since(start)),
+				slog.String("remote_addr", r.RemoteAddr),
+				slog.String("user_agent", r.UserAgent()),
+				slog.Any("headers", redactHeaders(r.Header, redact)),
+			}
+			if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+				attrs = append(attrs, slog.String("forwarded_for", fwd))
+			}
+			logger.LogAttrs(r.Context(), level, "request completed", attrs...)
 		})
 	}
 }
+
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	if rate <= 0 {
+		return false
+	}
+	return sampleRand() < rate
+}
+
+// redactHeaders flattens the request headers for logging and hides the sensitive ones
+func redactHeaders(h http.Header, redact map[string]bool) map[string]string {
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if redact[http.CanonicalHeaderKey(name)] {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
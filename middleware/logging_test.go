@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to decode log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestLogging(t *testing.T) {
+	t.Run("RecordsStatusBytesAndClientInfo", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		handler := Logging(logger, DefaultLogConfig())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("not here"))
+		}))
+
+		req := httptest.NewRequest("GET", "/api/v1/books?status=reading", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		req.Header.Set("Authorization", "Bearer secret")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		entries := decodeLogs(t, &buf)
+		if len(entries) != 1 {
+			t.Fatalf("Expected 1 log entry, got %d", len(entries))
+		}
+		entry := entries[0]
+		if entry["level"] != "WARN" {
+			t.Errorf("level = %v, want WARN", entry["level"])
+		}
+		if entry["status"] != float64(404) {
+			t.Errorf("status = %v, want 404", entry["status"])
+		}
+		if entry["bytes"] != float64(len("not here")) {
+			t.Errorf("bytes = %v, want %d", entry["bytes"], len("not here"))
+		}
+		if entry["query"] != "status=reading" {
+			t.Errorf("query = %v, want status=reading", entry["query"])
+		}
+		if entry["user_agent"] != "test-agent" {
+			t.Errorf("user_agent = %v, want test-agent", entry["user_agent"])
+		}
+		if entry["remote_addr"] != req.RemoteAddr {
+			t.Errorf("remote_addr = %v, want %s", entry["remote_addr"], req.RemoteAddr)
+		}
+		headers, _ := entry["headers"].(map[string]any)
+		if headers["Authorization"] != redacted {
+			t.Errorf("Authorization header = %v, want %s", headers["Authorization"], redacted)
+		}
+	})
+
+	t.Run("SamplesSuccessfulRequestsOnly", func(t *testing.T) {
+		defer func(orig func() float64) { sampleRand = orig }(sampleRand)
+		sampleRand = func() float64 { return 0.9 }
+
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+		cfg := DefaultLogConfig()
+		cfg.SampleRate = 0.5
+
+		status := http.StatusOK
+		handler := Logging(logger, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		if entries := decodeLogs(t, &buf); len(entries) != 0 {
+			t.Errorf("Expected successful request to be sampled out, got %d entries", len(entries))
+		}
+
+		status = http.StatusInternalServerError
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		entries := decodeLogs(t, &buf)
+		if len(entries) != 1 {
+			t.Fatalf("Expected error request to always be logged, got %d entries", len(entries))
+		}
+		if entries[0]["level"] != "ERROR" {
+			t.Errorf("level = %v, want ERROR", entries[0]["level"])
+		}
+	})
+}
+
+func TestResponseRecorder(t *testing.T) {
+	rr := httptest.NewRecorder()
+	rec := newResponseRecorder(rr)
+
+	if rec.Status() != http.StatusOK {
+		t.Errorf("Status() before write = %d, want 200", rec.Status())
+	}
+
+	rec.Write([]byte("hello"))
+	rec.WriteHeader(http.StatusTeapot) // Ignored by net/http once the body is written so should be ignored here too
+	rec.Flush()
+
+	if rec.Status() != http.StatusOK {
+		t.Errorf("Status() = %d, want 200", rec.Status())
+	}
+	if rec.BytesWritten() != 5 {
+		t.Errorf("BytesWritten() = %d, want 5", rec.BytesWritten())
+	}
+	if !rr.Flushed {
+		t.Errorf("Flush was not passed through to the underlying writer")
+	}
+	if _, _, err := rec.Hijack(); err == nil {
+		t.Errorf("Hijack() on a non-hijackable writer should return an error")
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -9,60 +10,103 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// NOTE: Documentation: https://prometheus.io/docs/guides/go-application/
-var (
-	RequestsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_requests_total",
-			Help: "Total number of HTTP requests",
-		},
-		[]string{"method", "path"},
-	)
+// RouteMatcher resolves the route pattern a request will be served by. *http.ServeMux implements it
+type RouteMatcher interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
 
-	RequestDuration = promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_duration_seconds",
-			Help:    "Duration of HTTP requests",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "path"},
-	)
+const unmatchedRoute = "unmatched"
 
-	ErrorsTotal = promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_errors_total",
-			Help: "Total number of HTTP errors",
-		},
-		[]string{"method", "path"},
-	)
-)
+// NOTE: Documentation: https://prometheus.io/docs/guides/go-application/
+// NOTE: Collectors are registered on an injected registry instead of the promauto globals
+// so tests can create their own registry and assert on the values
+type Metrics struct {
+	RequestsTotal   *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	ResponseSize    *prometheus.HistogramVec
+	ErrorsTotal     *prometheus.CounterVec
+	InFlight        prometheus.Gauge
+}
 
-func Metrics(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-		sw := &statusWriter{ResponseWriter: w}
-		next.ServeHTTP(sw, r)
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	factory := promauto.With(reg)
+	return &Metrics{
+		RequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_requests_total",
+				Help: "Total number of HTTP requests",
+			},
+			[]string{"method", "route", "status"},
+		),
+		RequestDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_request_duration_seconds",
+				Help:    "Duration of HTTP requests",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"method", "route"},
+		),
+		ResponseSize: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "http_response_size_bytes",
+				Help:    "Size of HTTP response bodies",
+				Buckets: prometheus.ExponentialBuckets(64, 4, 8), // 64B up to ~1MB
+			},
+			[]string{"method", "route"},
+		),
+		ErrorsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "http_errors_total",
+				Help: "Total number of HTTP errors",
+			},
+			[]string{"method", "route", "status"},
+		),
+		InFlight: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "http_requests_in_flight",
+				Help: "Number of HTTP requests currently being served",
+			},
+		),
+	}
+}
 
-		path := r.URL.Path // NOTE: Here one can be more specific
+// Middleware labels every request by its route pattern (i.e. "PUT /api/v1/books/{id}") rather than
+// the raw path so the number of time series doesnt grow with the number of books
+func (m *Metrics) Middleware(routes RouteMatcher) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			m.InFlight.Inc()
+			defer m.InFlight.Dec()
 
-		RequestsTotal.WithLabelValues(r.Method, path).Inc()
-		RequestDuration.WithLabelValues(r.Method, path).Observe(time.Since(start).Seconds())
-		if sw.status >= 400 {
-			ErrorsTotal.WithLabelValues(r.Method, path).Inc()
-		}
-	})
-}
+			// NOTE: Resolving up front as http.TimeoutHandler serves a copy of the request
+			// so r.Pattern set by the mux further down is not visible here afterwards
+			route := ""
+			if routes != nil {
+				_, route = routes.Handler(r)
+			}
 
-func MetricsHandler() http.Handler {
-	return promhttp.Handler()
-}
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			if route == "" {
+				route = r.Pattern
+			}
+			if route == "" {
+				route = unmatchedRoute
+			}
+			status := strconv.Itoa(rec.Status())
 
-type statusWriter struct {
-	http.ResponseWriter
-	status int
+			m.RequestsTotal.WithLabelValues(r.Method, route, status).Inc()
+			m.RequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+			m.ResponseSize.WithLabelValues(r.Method, route).Observe(float64(rec.BytesWritten()))
+			if rec.Status() >= 400 {
+				m.ErrorsTotal.WithLabelValues(r.Method, route, status).Inc()
+			}
+		})
+	}
 }
 
-func (w *statusWriter) WriteHeader(status int) {
-	w.status = status
-	w.ResponseWriter.WriteHeader(status)
+func MetricsHandler(reg prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
 }
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := NewMetrics(reg)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"` + r.PathValue("id") + `"}`))
+	})
+	handler := metrics.Middleware(mux)(mux)
+
+	for _, path := range []string{"/api/v1/books/a", "/api/v1/books/b", "/api/v1/books/c", "/nope"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	t.Run("LabelsByRoutePattern", func(t *testing.T) {
+		got := testutil.ToFloat64(metrics.RequestsTotal.WithLabelValues("GET", "GET /api/v1/books/{id}", "200"))
+		if got != 3 {
+			t.Errorf("http_requests_total for route = %v, want 3", got)
+		}
+		if n := testutil.CollectAndCount(metrics.RequestsTotal); n != 2 {
+			t.Errorf("http_requests_total has %d series, want 2 (route + unmatched)", n)
+		}
+	})
+
+	t.Run("UnmatchedRoutesAndErrors", func(t *testing.T) {
+		got := testutil.ToFloat64(metrics.ErrorsTotal.WithLabelValues("GET", unmatchedRoute, "404"))
+		if got != 1 {
+			t.Errorf("http_errors_total for unmatched = %v, want 1", got)
+		}
+	})
+
+	t.Run("ResponseSizeAndInFlight", func(t *testing.T) {
+		if n := testutil.CollectAndCount(metrics.ResponseSize); n != 2 {
+			t.Errorf("http_response_size_bytes has %d series, want 2", n)
+		}
+		if got := testutil.ToFloat64(metrics.InFlight); got != 0 {
+			t.Errorf("http_requests_in_flight = %v, want 0 after all requests finished", got)
+		}
+	})
+}
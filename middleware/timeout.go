@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"book-tracker/problem"
+	"encoding/json"
 	"net/http"
 	"time"
 )
@@ -9,7 +11,25 @@ import (
 // I think the server itself also handled timeout so this might be a duplicate
 // Lets investigate more
 func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	body, _ := json.Marshal(problem.New(http.StatusServiceUnavailable, problem.CodeTimeout, "request timed out"))
 	return func(next http.Handler) http.Handler {
-		return http.TimeoutHandler(next, timeout, "Request timed out")
+		th := http.TimeoutHandler(next, timeout, string(body))
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			th.ServeHTTP(&timeoutWriter{ResponseWriter: w}, r)
+		})
 	}
 }
+
+// timeoutWriter sets the problem content type on the 503 written by http.TimeoutHandler.
+// NOTE: Responses from the handler itself have their headers copied over before WriteHeader
+// so a Content-Type is already present for those and is left alone
+type timeoutWriter struct {
+	http.ResponseWriter
+}
+
+func (w *timeoutWriter) WriteHeader(status int) {
+	if status == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", problem.ContentType)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
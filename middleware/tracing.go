@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span per request. An incoming W3C traceparent header is continued
+// so the span joins the callers trace. Span names use the route pattern for the same cardinality
+// reasons as the metrics labels
+func Tracing(routes RouteMatcher) func(http.Handler) http.Handler {
+	tracer := otel.Tracer("book-tracker/middleware")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			route := ""
+			if routes != nil {
+				_, route = routes.Handler(r)
+			}
+			name := route
+			if name == "" {
+				name = fmt.Sprintf("%s %s", r.Method, unmatchedRoute)
+			}
+
+			ctx, span := tracer.Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("http.route", route),
+					attribute.String("user_agent.original", r.UserAgent()),
+				),
+			)
+			defer span.End()
+
+			rec := newResponseRecorder(w)
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			span.SetAttributes(
+				attribute.Int("http.response.status_code", rec.Status()),
+				attribute.Int("http.response.body.size", rec.BytesWritten()),
+			)
+			// NOTE: Following the semantic conventions, only 5xx marks a server span as failed
+			if rec.Status() >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(rec.Status()))
+			}
+		})
+	}
+}
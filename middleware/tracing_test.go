@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	mux := http.NewServeMux()
+	var handlerSpan trace.SpanContext
+	mux.HandleFunc("GET /api/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := Tracing(mux)(mux)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest("GET", "/api/v1/books/123", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/books/{id}" {
+		t.Errorf("Span name = %q, want the route pattern", span.Name())
+	}
+	if span.SpanContext().TraceID().String() != traceID {
+		t.Errorf("Trace ID = %s, want %s from traceparent", span.SpanContext().TraceID(), traceID)
+	}
+	if span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Parent span ID = %s, want 00f067aa0ba902b7", span.Parent().SpanID())
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("Handler context does not carry the server span")
+	}
+	if span.Status().Code.String() != "Error" {
+		t.Errorf("Span status = %v, want Error for a 500", span.Status().Code)
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type userKey struct{}
+
+// User takes the user of a request from header, as set by an authenticating reverse proxy in front of the
+// service (i.e. X-Forwarded-User of oauth2-proxy).
+// NOTE: There is no login here, the header is only as trustworthy as the proxy that sets it. Only use it
+// when every request goes through that proxy, otherwise anyone can claim to be anyone
+func User(header string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if user := strings.TrimSpace(r.Header.Get(header)); user != "" {
+				r = r.WithContext(context.WithValue(r.Context(), userKey{}, user))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserFrom returns the user User found for the request, "" for anonymous requests and without User
+func UserFrom(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
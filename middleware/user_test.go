@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUser(t *testing.T) {
+	var got string
+	handler := User("X-Forwarded-User")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = UserFrom(r.Context())
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{"FromHeader", "alice", "alice"},
+		{"Trimmed", "  bob ", "bob"},
+		{"Anonymous", "", ""},
+		{"Blank", "   ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/searches", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Forwarded-User", tt.header)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			if got != tt.want {
+				t.Errorf("UserFrom() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrInvalidTimezone = errors.New("invalid timezone: must be an IANA name like Europe/Stockholm")
+
+const CodeActivityTimezone = "activity.timezone_invalid"
+
+// Number of days, including today, covered by the activity map
+const ActivityDays = 365
+
+// Activity is the data behind a GitHub style heatmap. A day counts as active when at least one
+// progress or status change event happened on it, in the requested timezone
+type Activity struct {
+	Timezone      string         `json:"timezone"`
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	CurrentStreak int            `json:"current_streak"`
+	LongestStreak int            `json:"longest_streak"`
+	Days          map[string]int `json:"days"` // Only active days are included, keyed by YYYY-MM-DD
+}
+
+// ParseTimezone resolves an IANA timezone name. Empty means UTC
+func ParseTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	// NOTE: LoadLocation also accepts "Local" which would leak the servers timezone, so its refused
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		ve := &ValidationError{}
+		ve.add("tz", CodeActivityTimezone, ErrInvalidTimezone)
+		return nil, ve
+	}
+	return loc, nil
+}
+
+// NewActivity builds the activity map for the last ActivityDays days and the streaks from the given
+// event times. events may be in any order and may go back further than the map, the longest streak uses all of them
+func NewActivity(events []time.Time, loc *time.Location, now time.Time) Activity {
+	today := localDay(now, loc)
+	from := today.AddDate(0, 0, -(ActivityDays - 1))
+
+	a := Activity{
+		Timezone: loc.String(),
+		From:     from.Format(DateLayout),
+		To:       today.Format(DateLayout),
+		Days:     map[string]int{},
+	}
+
+	counts := map[time.Time]int{}
+	for _, e := range events {
+		day := localDay(e, loc)
+		counts[day]++
+		if !day.Before(from) && !day.After(today) {
+			a.Days[day.Format(DateLayout)]++
+		}
+	}
+
+	days := make([]time.Time, 0, len(counts))
+	for day := range counts {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, func(x, y time.Time) int { return x.Compare(y) })
+
+	run := 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		a.LongestStreak = max(a.LongestStreak, run)
+	}
+
+	// NOTE: Nothing logged yet today doesnt break the streak, the day isnt over
+	day := today
+	if counts[day] == 0 {
+		day = day.AddDate(0, 0, -1)
+	}
+	for counts[day] > 0 {
+		a.CurrentStreak++
+		day = day.AddDate(0, 0, -1)
+	}
+	return a
+}
+
+// localDay returns the calendar day t falls on in loc, as midnight UTC so days can be compared and
+// stepped with AddDate without DST getting in the way
+func localDay(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
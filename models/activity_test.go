@@ -0,0 +1,61 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewActivity(t *testing.T) {
+	now := time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d, hour int) time.Time {
+		return time.Date(2026, month, d, hour, 0, 0, 0, time.UTC)
+	}
+
+	events := []time.Time{
+		// 2 day streak ending yesterday, today has nothing yet
+		day(time.October, 17, 12), day(time.October, 18, 12), day(time.October, 18, 13),
+		// 4 day streak
+		day(time.January, 1, 12), day(time.January, 2, 12), day(time.January, 3, 12), day(time.January, 4, 12),
+		// Older than the map
+		day(time.October, 16, 12).AddDate(-1, 0, 0),
+	}
+
+	a := NewActivity(events, time.UTC, now)
+	if a.CurrentStreak != 2 {
+		t.Errorf("CurrentStreak = %d, want 2", a.CurrentStreak)
+	}
+	if a.LongestStreak != 4 {
+		t.Errorf("LongestStreak = %d, want 4", a.LongestStreak)
+	}
+	if a.Days["2026-10-18"] != 2 {
+		t.Errorf("Days[2026-10-18] = %d, want 2", a.Days["2026-10-18"])
+	}
+	if _, ok := a.Days["2025-10-16"]; ok {
+		t.Errorf("Days includes 2025-10-16 which is outside %s..%s", a.From, a.To)
+	}
+	if a.From != "2025-10-20" || a.To != "2026-10-19" {
+		t.Errorf("Range = %s..%s, want 2025-10-20..2026-10-19", a.From, a.To)
+	}
+
+	t.Run("Timezone", func(t *testing.T) {
+		loc, err := ParseTimezone("Asia/Tokyo")
+		if err != nil {
+			t.Fatalf("ParseTimezone failed: %v", err)
+		}
+		// 20:00 UTC on the 18th is already the 19th in Tokyo, so today counts and the streak grows
+		events := []time.Time{day(time.October, 18, 0), day(time.October, 18, 20)}
+		a := NewActivity(events, loc, day(time.October, 19, 1))
+		if a.CurrentStreak != 2 || a.Days["2026-10-19"] != 1 {
+			t.Errorf("Activity in Tokyo = %+v, want a 2 day streak with 2026-10-19 active", a)
+		}
+	})
+
+	t.Run("InvalidTimezone", func(t *testing.T) {
+		for _, tz := range []string{"Mars/Olympus", "Local"} {
+			_, err := ParseTimezone(tz)
+			if ve, ok := AsValidationError(err); !ok || ve.Errors[0].Code != CodeActivityTimezone {
+				t.Errorf("ParseTimezone(%q) error = %v, want %s", tz, err, CodeActivityTimezone)
+			}
+		}
+	})
+}
@@ -0,0 +1,371 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NOTE:
+// An archive is the whole library in one JSON document, for moving it to another instance or keeping a
+// copy outside the database. Version is the version of this format, not of the database schema, and only
+// changes when an archive written by the new format can no longer be read the old way
+const (
+	ArchiveFormat  = "book-tracker"
+	ArchiveVersion = 1
+)
+
+var (
+	ErrArchiveFormat       = errors.New("not a book-tracker archive")
+	ErrArchiveVersion      = fmt.Errorf("unsupported archive version: must be %d", ArchiveVersion)
+	ErrArchiveDuplicate    = errors.New("id appears more than once in the archive")
+	ErrEventUnknownBook    = errors.New("event refers to a book that is not in the archive")
+	ErrEventTimeMissing    = errors.New("occurred_at is missing")
+	ErrNoteUnknownBook     = errors.New("note refers to a book that is not in the archive")
+	ErrShelfUnknownBook    = errors.New("shelf refers to a book that is not in the archive")
+	ErrSeriesUnknownBook   = errors.New("series refers to a book that is not in the archive")
+	ErrReviewUnknownBook   = errors.New("review refers to a book that is not in the archive")
+	ErrScheduleNoteUnknown = errors.New("schedule refers to a highlight or quote that is not in the archive")
+	ErrInvalidImportMode   = errors.New("invalid mode: must be merge or replace")
+	ErrInvalidImportIDs    = errors.New("invalid ids: must be keep or new")
+)
+
+const (
+	CodeArchiveFormat       = "archive.format_invalid"
+	CodeArchiveVersion      = "archive.version_unsupported"
+	CodeArchiveDuplicate    = "archive.duplicate_id"
+	CodeEventUnknownBook    = "archive.event_book_unknown"
+	CodeEventTimeMissing    = "archive.event_time_missing"
+	CodeNoteUnknownBook     = "archive.note_book_unknown"
+	CodeShelfUnknownBook    = "archive.shelf_book_unknown"
+	CodeSeriesUnknownBook   = "archive.series_book_unknown"
+	CodeReviewUnknownBook   = "archive.review_book_unknown"
+	CodeScheduleNoteUnknown = "archive.schedule_note_unknown"
+	CodeImportMode          = "import.mode_invalid"
+	CodeImportIDs           = "import.ids_invalid"
+)
+
+// Archive is the export format. Events are the status history of the books that the stats are built from.
+// Notes, shelves, series, reviews and the highlight schedules were added later, an archive without them is still read
+type Archive struct {
+	Format     string              `json:"format"`
+	Version    int                 `json:"version"`
+	ExportedAt time.Time           `json:"exported_at"`
+	Books      []Book              `json:"books"`
+	Events     []BookEvent         `json:"events"`
+	Goals      []Goal              `json:"goals"`
+	Notes      []Note              `json:"notes"`
+	Shelves    []ArchiveShelf      `json:"shelves"`
+	Series     []ArchiveSeries     `json:"series"`
+	Reviews    []Review            `json:"reviews"`
+	Schedules  []HighlightSchedule `json:"schedules"`
+}
+
+// BookEvent is one status change or new page count of a book. From is empty for the event that added the book
+// and the same as To for a page count
+type BookEvent struct {
+	BookID     string     `json:"book_id"`
+	From       BookStatus `json:"from_status,omitempty"`
+	To         BookStatus `json:"to_status"`
+	Pages      int        `json:"pages,omitempty"`
+	OccurredAt time.Time  `json:"occurred_at"`
+}
+
+// ArchiveShelf is a shelf with the books on it, the top of the shelf first
+type ArchiveShelf struct {
+	Shelf
+	Entries []ShelfEntry `json:"entries"`
+}
+
+// ShelfEntry is a book on a shelf. AddedAt only breaks ties in the order, an entry without one is added
+// as of the import
+type ShelfEntry struct {
+	BookID  string    `json:"book_id"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// ArchiveSeries is a series with its volumes. The books keep their place in it through the volumes,
+// Book.Series is only ever set by the store and not read back
+type ArchiveSeries struct {
+	Series
+	Volumes []ArchiveVolume `json:"volumes"`
+}
+
+type ArchiveVolume struct {
+	BookID   string  `json:"book_id"`
+	Position float64 `json:"position"`
+}
+
+// Validate checks the archive as a whole before anything is written. Every book and goal goes through
+// its own Validate, violations are reported with their position, i.e. books[3].title
+func (a *Archive) Validate() error {
+	ve := &ValidationError{}
+
+	if a.Format != ArchiveFormat {
+		ve.add("format", CodeArchiveFormat, ErrArchiveFormat)
+		return ve // NOTE: Whatever this is, checking the rest would only add noise
+	}
+	if a.Version != ArchiveVersion {
+		ve.add("version", CodeArchiveVersion, ErrArchiveVersion)
+		return ve
+	}
+
+	books := make(map[string]bool, len(a.Books))
+	for i := range a.Books {
+		prefix := fmt.Sprintf("books[%d].", i)
+		ve.merge(prefix, a.Books[i].Validate())
+		if books[a.Books[i].ID] {
+			ve.add(prefix+"id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		books[a.Books[i].ID] = true
+	}
+
+	for i, event := range a.Events {
+		prefix := fmt.Sprintf("events[%d].", i)
+		if !books[event.BookID] {
+			ve.add(prefix+"book_id", CodeEventUnknownBook, ErrEventUnknownBook)
+		}
+		if event.To == "" {
+			ve.add(prefix+"to_status", CodeStatusMissing, ErrEmptyStatus)
+		}
+		if event.OccurredAt.IsZero() {
+			ve.add(prefix+"occurred_at", CodeEventTimeMissing, ErrEventTimeMissing)
+		}
+		if event.Pages < 0 {
+			ve.add(prefix+"pages", CodePagesInvalid, ErrInvalidPages)
+		}
+	}
+
+	goals := make(map[string]bool, len(a.Goals))
+	for i := range a.Goals {
+		prefix := fmt.Sprintf("goals[%d].", i)
+		ve.merge(prefix, a.Goals[i].Validate())
+		if goals[a.Goals[i].ID] {
+			ve.add(prefix+"id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		goals[a.Goals[i].ID] = true
+	}
+
+	notes := make(map[string]bool, len(a.Notes))
+	highlights := map[string]bool{}
+	for i := range a.Notes {
+		prefix := fmt.Sprintf("notes[%d].", i)
+		ve.merge(prefix, a.Notes[i].Validate())
+		if notes[a.Notes[i].ID] {
+			ve.add(prefix+"id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		if a.Notes[i].BookID != "" && !books[a.Notes[i].BookID] {
+			ve.add(prefix+"book_id", CodeNoteUnknownBook, ErrNoteUnknownBook)
+		}
+		notes[a.Notes[i].ID] = true
+		if a.Notes[i].Type == NoteHighlight || a.Notes[i].Type == NoteQuote {
+			highlights[a.Notes[i].ID] = true
+		}
+	}
+
+	shelves := make(map[string]bool, len(a.Shelves))
+	for i := range a.Shelves {
+		prefix := fmt.Sprintf("shelves[%d].", i)
+		ve.merge(prefix, a.Shelves[i].Validate())
+		if shelves[a.Shelves[i].ID] {
+			ve.add(prefix+"id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		shelves[a.Shelves[i].ID] = true
+		onShelf := make(map[string]bool, len(a.Shelves[i].Entries))
+		for j, entry := range a.Shelves[i].Entries {
+			field := fmt.Sprintf("%sentries[%d].book_id", prefix, j)
+			switch {
+			case !books[entry.BookID]:
+				ve.add(field, CodeShelfUnknownBook, ErrShelfUnknownBook)
+			case onShelf[entry.BookID]:
+				ve.add(field, CodeArchiveDuplicate, ErrArchiveDuplicate)
+			}
+			onShelf[entry.BookID] = true
+		}
+	}
+
+	seriesIDs := make(map[string]bool, len(a.Series))
+	inSeries := map[string]bool{} // NOTE: A book is in at most one series, not just once per series
+	for i := range a.Series {
+		prefix := fmt.Sprintf("series[%d].", i)
+		ve.merge(prefix, a.Series[i].Validate())
+		if seriesIDs[a.Series[i].ID] {
+			ve.add(prefix+"id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		seriesIDs[a.Series[i].ID] = true
+		for j, volume := range a.Series[i].Volumes {
+			field := fmt.Sprintf("%svolumes[%d].", prefix, j)
+			switch {
+			case !books[volume.BookID]:
+				ve.add(field+"book_id", CodeSeriesUnknownBook, ErrSeriesUnknownBook)
+			case inSeries[volume.BookID]:
+				ve.add(field+"book_id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+			}
+			if volume.Position < 0 {
+				ve.add(field+"position", CodeSeriesPositionInvalid, ErrInvalidSeriesPosition)
+			}
+			inSeries[volume.BookID] = true
+		}
+	}
+
+	// NOTE: Unlike SetReview the book does not have to be finished, a review stays when a book is read again.
+	// Every user has their own review of a book, the archive has them all
+	type reviewKey struct{ bookID, owner string }
+	reviewed := make(map[reviewKey]bool, len(a.Reviews))
+	for i := range a.Reviews {
+		prefix := fmt.Sprintf("reviews[%d].", i)
+		ve.merge(prefix, a.Reviews[i].Validate())
+		key := reviewKey{a.Reviews[i].BookID, a.Reviews[i].Owner}
+		switch {
+		case !books[key.bookID]:
+			ve.add(prefix+"book_id", CodeReviewUnknownBook, ErrReviewUnknownBook)
+		case reviewed[key]:
+			ve.add(prefix+"book_id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		reviewed[key] = true
+	}
+
+	scheduled := make(map[string]bool, len(a.Schedules))
+	for i := range a.Schedules {
+		prefix := fmt.Sprintf("schedules[%d].", i)
+		ve.merge(prefix, a.Schedules[i].Validate())
+		switch {
+		case !highlights[a.Schedules[i].NoteID]:
+			ve.add(prefix+"note_id", CodeScheduleNoteUnknown, ErrScheduleNoteUnknown)
+		case scheduled[a.Schedules[i].NoteID]:
+			ve.add(prefix+"note_id", CodeArchiveDuplicate, ErrArchiveDuplicate)
+		}
+		scheduled[a.Schedules[i].NoteID] = true
+	}
+
+	return ve.errOrNil()
+}
+
+// RemapIDs gives every book, goal, note, shelf and series a new id, the events, notes, shelf entries, volumes
+// and reviews follow their book and the schedules their note.
+// Importing the same archive twice this way adds everything twice instead of overwriting
+func (a *Archive) RemapIDs() error {
+	ids := make(map[string]string, len(a.Books))
+	for i := range a.Books {
+		old := a.Books[i].ID
+		if err := a.Books[i].GenerateID(); err != nil {
+			return err
+		}
+		ids[old] = a.Books[i].ID
+	}
+	for i := range a.Events {
+		a.Events[i].BookID = ids[a.Events[i].BookID]
+	}
+	for i := range a.Goals {
+		if err := a.Goals[i].GenerateID(); err != nil {
+			return err
+		}
+	}
+	noteIDs := make(map[string]string, len(a.Notes))
+	for i := range a.Notes {
+		old := a.Notes[i].ID
+		if err := a.Notes[i].GenerateID(); err != nil {
+			return err
+		}
+		noteIDs[old] = a.Notes[i].ID
+		a.Notes[i].BookID = ids[a.Notes[i].BookID]
+	}
+	for i := range a.Schedules {
+		a.Schedules[i].NoteID = noteIDs[a.Schedules[i].NoteID]
+	}
+	for i := range a.Shelves {
+		if err := a.Shelves[i].GenerateID(); err != nil {
+			return err
+		}
+		for j := range a.Shelves[i].Entries {
+			a.Shelves[i].Entries[j].BookID = ids[a.Shelves[i].Entries[j].BookID]
+		}
+	}
+	for i := range a.Series {
+		if err := a.Series[i].GenerateID(); err != nil {
+			return err
+		}
+		for j := range a.Series[i].Volumes {
+			a.Series[i].Volumes[j].BookID = ids[a.Series[i].Volumes[j].BookID]
+		}
+	}
+	for i := range a.Reviews {
+		a.Reviews[i].BookID = ids[a.Reviews[i].BookID]
+	}
+	return nil
+}
+
+// AddMissingEvents records the book being added for books without any history, i.e. in a hand written
+// archive. Like the migration that introduced the events, completed books count as of their completed_at
+func (a *Archive) AddMissingEvents(now time.Time) {
+	hasEvents := make(map[string]bool, len(a.Books))
+	for _, event := range a.Events {
+		hasEvents[event.BookID] = true
+	}
+	for _, book := range a.Books {
+		if hasEvents[book.ID] {
+			continue
+		}
+		at := now
+		if book.CompletedAt != nil {
+			at = *book.CompletedAt
+		}
+		a.Events = append(a.Events, BookEvent{BookID: book.ID, To: book.Status, Pages: book.Pages, OccurredAt: at})
+	}
+}
+
+type ImportMode string
+
+const (
+	ImportMerge   ImportMode = "merge"   // Books, goals, notes, shelves, series, reviews and schedules with the same id are overwritten, everything else stays
+	ImportReplace ImportMode = "replace" // The library is emptied first
+)
+
+type ImportIDs string
+
+const (
+	ImportKeepIDs ImportIDs = "keep"
+	ImportNewIDs  ImportIDs = "new"
+)
+
+type ImportOptions struct {
+	Mode ImportMode `json:"mode"`
+	IDs  ImportIDs  `json:"ids"`
+}
+
+// ParseImportOptions reads the ?mode= and ?ids= query parameters, merge and keep are the defaults
+func ParseImportOptions(mode, ids string) (ImportOptions, error) {
+	opts := ImportOptions{Mode: ImportMerge, IDs: ImportKeepIDs}
+	ve := &ValidationError{}
+
+	switch ImportMode(mode) {
+	case "":
+	case ImportMerge, ImportReplace:
+		opts.Mode = ImportMode(mode)
+	default:
+		ve.add("mode", CodeImportMode, ErrInvalidImportMode)
+	}
+
+	switch ImportIDs(ids) {
+	case "":
+	case ImportKeepIDs, ImportNewIDs:
+		opts.IDs = ImportIDs(ids)
+	default:
+		ve.add("ids", CodeImportIDs, ErrInvalidImportIDs)
+	}
+
+	return opts, ve.errOrNil()
+}
+
+// ImportResult is what an import wrote
+type ImportResult struct {
+	ImportOptions
+	Books     int `json:"books"`
+	Events    int `json:"events"`
+	Goals     int `json:"goals"`
+	Notes     int `json:"notes"`
+	Shelves   int `json:"shelves"`
+	Series    int `json:"series"`
+	Reviews   int `json:"reviews"`
+	Schedules int `json:"schedules"`
+}
@@ -0,0 +1,182 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestArchive_Validate(t *testing.T) {
+	bookID, noteID := uuid.NewString(), uuid.NewString()
+	validBook := func() Book { return Book{ID: bookID, Title: "Emma", Author: "Jane Austen", Status: BookComplete} }
+	at := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		archive    Archive
+		wantFields []string
+		wantCodes  []string
+	}{
+		{
+			name: "Valid",
+			archive: Archive{Format: ArchiveFormat, Version: ArchiveVersion,
+				Books:     []Book{validBook()},
+				Events:    []BookEvent{{BookID: bookID, To: BookComplete, OccurredAt: at}},
+				Goals:     []Goal{{ID: uuid.NewString(), Metric: GoalBooks, Target: 1, Year: 2026}},
+				Notes:     []Note{{ID: noteID, BookID: bookID, Type: NoteHighlight, Body: "Badly done, Emma!"}},
+				Shelves:   []ArchiveShelf{{Shelf: Shelf{ID: uuid.NewString(), Name: "Book club"}, Entries: []ShelfEntry{{BookID: bookID}}}},
+				Series:    []ArchiveSeries{{Series: Series{ID: uuid.NewString(), Name: "Novels"}, Volumes: []ArchiveVolume{{BookID: bookID, Position: 4}}}},
+				Reviews:   []Review{{BookID: bookID, Rating: 4.5}},
+				Schedules: []HighlightSchedule{{NoteID: noteID, Repetitions: 1, IntervalDays: 1, Ease: 2.5, DueOn: "2026-05-02", ReviewedOn: "2026-05-01"}},
+			},
+		},
+		{
+			name:       "WrongFormat",
+			archive:    Archive{Format: "goodreads", Version: ArchiveVersion, Books: []Book{{}}},
+			wantFields: []string{"format"},
+			wantCodes:  []string{CodeArchiveFormat},
+		},
+		{
+			name:       "NewerVersion",
+			archive:    Archive{Format: ArchiveFormat, Version: ArchiveVersion + 1},
+			wantFields: []string{"version"},
+			wantCodes:  []string{CodeArchiveVersion},
+		},
+		{
+			name: "NestedViolations",
+			archive: Archive{Format: ArchiveFormat, Version: ArchiveVersion,
+				Books:   []Book{validBook(), validBook(), {ID: uuid.NewString(), Author: "Nobody", Status: BookUnread}},
+				Events:  []BookEvent{{BookID: uuid.NewString(), To: BookUnread}},
+				Goals:   []Goal{{ID: uuid.NewString(), Metric: GoalPages, Target: 0, Year: 2026}},
+				Notes:   []Note{{ID: uuid.NewString(), BookID: uuid.NewString(), Body: "Lost"}, {ID: uuid.NewString(), BookID: bookID}},
+				Shelves: []ArchiveShelf{{Shelf: Shelf{ID: uuid.NewString()}, Entries: []ShelfEntry{{BookID: bookID}, {BookID: bookID}, {BookID: uuid.NewString()}}}},
+				Series: []ArchiveSeries{
+					{Series: Series{ID: uuid.NewString(), Name: "Novels"}, Volumes: []ArchiveVolume{{BookID: bookID, Position: -1}}},
+					{Series: Series{ID: uuid.NewString(), Name: "Favourites"}, Volumes: []ArchiveVolume{{BookID: bookID}, {BookID: uuid.NewString()}}},
+				},
+				Reviews: []Review{{BookID: bookID, Rating: 4}, {BookID: bookID, Rating: 3}, {BookID: uuid.NewString(), Rating: 7}},
+				Schedules: []HighlightSchedule{
+					{NoteID: uuid.NewString(), Ease: 2.5, DueOn: "2026-05-02"},
+					{NoteID: noteID, Ease: 1, DueOn: "tomorrow"},
+				},
+			},
+			wantFields: []string{"books[1].id", "books[2].title", "events[0].book_id", "events[0].occurred_at", "goals[0].target", "notes[0].book_id", "notes[1].body",
+				"shelves[0].name", "shelves[0].entries[1].book_id", "shelves[0].entries[2].book_id",
+				"series[0].volumes[0].position", "series[1].volumes[0].book_id", "series[1].volumes[1].book_id",
+				"reviews[1].book_id", "reviews[2].rating", "reviews[2].book_id",
+				"schedules[0].note_id", "schedules[1].ease", "schedules[1].due_on", "schedules[1].note_id"},
+			wantCodes: []string{CodeArchiveDuplicate, CodeTitleMissing, CodeEventUnknownBook, CodeEventTimeMissing, CodeGoalTargetInvalid, CodeNoteUnknownBook, CodeNoteBodyMissing,
+				CodeShelfNameMissing, CodeArchiveDuplicate, CodeShelfUnknownBook,
+				CodeSeriesPositionInvalid, CodeArchiveDuplicate, CodeSeriesUnknownBook,
+				CodeArchiveDuplicate, CodeRatingInvalid, CodeReviewUnknownBook,
+				CodeScheduleNoteUnknown, CodeScheduleEaseInvalid, CodeScheduleDateInvalid, CodeScheduleNoteUnknown},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.archive.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			var fields, codes []string
+			for _, fe := range ve.Errors {
+				fields = append(fields, fe.Field)
+				codes = append(codes, fe.Code)
+			}
+			if !slices.Equal(fields, tt.wantFields) || !slices.Equal(codes, tt.wantCodes) {
+				t.Errorf("Validate() = %v %v, want %v %v", fields, codes, tt.wantFields, tt.wantCodes)
+			}
+		})
+	}
+}
+
+func TestArchive_RemapIDs(t *testing.T) {
+	first, second := uuid.NewString(), uuid.NewString()
+	goalID, noteID, shelfID, seriesID := uuid.NewString(), uuid.NewString(), uuid.NewString(), uuid.NewString()
+	a := Archive{
+		Books:     []Book{{ID: first}, {ID: second}},
+		Events:    []BookEvent{{BookID: second}, {BookID: first}, {BookID: second}},
+		Goals:     []Goal{{ID: goalID}},
+		Notes:     []Note{{ID: noteID, BookID: first}},
+		Shelves:   []ArchiveShelf{{Shelf: Shelf{ID: shelfID}, Entries: []ShelfEntry{{BookID: second}}}},
+		Series:    []ArchiveSeries{{Series: Series{ID: seriesID}, Volumes: []ArchiveVolume{{BookID: first, Position: 1}}}},
+		Reviews:   []Review{{BookID: second}},
+		Schedules: []HighlightSchedule{{NoteID: noteID}},
+	}
+	if err := a.RemapIDs(); err != nil {
+		t.Fatalf("RemapIDs() error = %v", err)
+	}
+	if a.Books[0].ID == first || a.Books[1].ID == second || a.Goals[0].ID == goalID || a.Notes[0].ID == noteID || a.Shelves[0].ID == shelfID || a.Series[0].ID == seriesID {
+		t.Fatalf("Expected new ids, got %+v", a)
+	}
+	if a.Notes[0].BookID != a.Books[0].ID {
+		t.Errorf("notes[0].book_id = %s, want %s", a.Notes[0].BookID, a.Books[0].ID)
+	}
+	if a.Shelves[0].Entries[0].BookID != a.Books[1].ID {
+		t.Errorf("shelves[0].entries[0].book_id = %s, want %s", a.Shelves[0].Entries[0].BookID, a.Books[1].ID)
+	}
+	if a.Series[0].Volumes[0].BookID != a.Books[0].ID {
+		t.Errorf("series[0].volumes[0].book_id = %s, want %s", a.Series[0].Volumes[0].BookID, a.Books[0].ID)
+	}
+	if a.Reviews[0].BookID != a.Books[1].ID {
+		t.Errorf("reviews[0].book_id = %s, want %s", a.Reviews[0].BookID, a.Books[1].ID)
+	}
+	if a.Schedules[0].NoteID != a.Notes[0].ID {
+		t.Errorf("schedules[0].note_id = %s, want %s", a.Schedules[0].NoteID, a.Notes[0].ID)
+	}
+	want := []string{a.Books[1].ID, a.Books[0].ID, a.Books[1].ID}
+	for i, event := range a.Events {
+		if event.BookID != want[i] {
+			t.Errorf("events[%d].book_id = %s, want %s", i, event.BookID, want[i])
+		}
+	}
+}
+
+func TestArchive_AddMissingEvents(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	completed := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	a := Archive{
+		Books: []Book{
+			{ID: "with-history", Status: BookReading},
+			{ID: "complete", Status: BookComplete, Pages: 300, CompletedAt: &completed},
+			{ID: "unread", Status: BookUnread},
+		},
+		Events: []BookEvent{{BookID: "with-history", To: BookReading, OccurredAt: completed}},
+	}
+	a.AddMissingEvents(now)
+
+	want := []BookEvent{
+		{BookID: "with-history", To: BookReading, OccurredAt: completed},
+		{BookID: "complete", To: BookComplete, Pages: 300, OccurredAt: completed},
+		{BookID: "unread", To: BookUnread, OccurredAt: now},
+	}
+	if !slices.Equal(a.Events, want) {
+		t.Errorf("Events = %+v, want %+v", a.Events, want)
+	}
+}
+
+func TestParseImportOptions(t *testing.T) {
+	opts, err := ParseImportOptions("", "")
+	if err != nil || opts != (ImportOptions{Mode: ImportMerge, IDs: ImportKeepIDs}) {
+		t.Errorf("Defaults = %+v, %v", opts, err)
+	}
+	opts, err = ParseImportOptions("replace", "new")
+	if err != nil || opts != (ImportOptions{Mode: ImportReplace, IDs: ImportNewIDs}) {
+		t.Errorf("replace, new = %+v, %v", opts, err)
+	}
+	_, err = ParseImportOptions("append", "random")
+	ve, ok := AsValidationError(err)
+	if !ok || len(ve.Errors) != 2 || ve.Errors[0].Code != CodeImportMode || ve.Errors[1].Code != CodeImportIDs {
+		t.Errorf("Expected mode and ids to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidRole              = errors.New("invalid role: must be author, translator, editor or narrator")
+	ErrMissingContributor       = errors.New("contributor needs a name or an author_id")
+	ErrMissingBookAuthor        = errors.New("contributors need at least one with the author role")
+	ErrTooManyContributors      = fmt.Errorf("too many contributors: max %d", MaxContributorsPerBook)
+	ErrSortNameTooLong          = fmt.Errorf("sort_name is too long: max %d characters", MaxAuthorLength)
+	ErrSortNameControlChars     = errors.New("sort_name contains control characters")
+	ErrAuthorMergeSelf          = errors.New("an author cannot be merged into itself")
+	ErrMissingAuthorMergeTarget = errors.New("into is missing")
+)
+
+const (
+	CodeContributorRoleInvalid = "contributor.role_invalid"
+	CodeContributorMissing     = "contributor.missing"
+	CodeBookAuthorMissing      = "book.contributors_author_missing"
+	CodeTooManyContributors    = "book.too_many_contributors"
+	CodeSortNameTooLong        = "author.sort_name_too_long"
+	CodeSortNameControlChars   = "author.sort_name_control_characters"
+	CodeAuthorMergeSelf        = "author.merge_self"
+	CodeAuthorMergeTarget      = "author.into_missing"
+)
+
+const MaxContributorsPerBook = 20
+
+// ContributorRole is what a person did for a book. Only authors make up Book.Author and count in the
+// author stats, a translator or narrator is listed on the book but not credited as its author
+type ContributorRole string
+
+const (
+	RoleAuthor     ContributorRole = "author"
+	RoleTranslator ContributorRole = "translator"
+	RoleEditor     ContributorRole = "editor"
+	RoleNarrator   ContributorRole = "narrator"
+)
+
+// Author is one person across all their books, so "J.K. Rowling" on two books is the same author.
+// Names are unique ignoring case. SortName is what the author sorts by, i.e. "Rowling, J.K."
+type Author struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	SortName string `json:"sort_name"`
+	Books    int    `json:"books"` // NOTE: Set by the store, never taken from the client
+}
+
+// Validate sanitizes the author and checks every field. Without a sort name one is made from the name
+func (a *Author) Validate() error {
+	a.Name = strings.TrimSpace(a.Name)
+	a.SortName = strings.TrimSpace(a.SortName)
+
+	ve := &ValidationError{}
+	validateName(ve, "name", a.Name)
+
+	if a.SortName == "" {
+		a.SortName = DefaultSortName(a.Name)
+	}
+	switch {
+	case utf8.RuneCountInString(a.SortName) > MaxAuthorLength:
+		ve.add("sort_name", CodeSortNameTooLong, ErrSortNameTooLong)
+	case hasControlChars(a.SortName):
+		ve.add("sort_name", CodeSortNameControlChars, ErrSortNameControlChars)
+	}
+
+	return ve.errOrNil()
+}
+
+// validateName checks a person's name with the same rules Book.Author always had
+func validateName(ve *ValidationError, field, name string) {
+	switch {
+	case name == "":
+		ve.add(field, CodeAuthorMissing, ErrMissingAuthor)
+	case utf8.RuneCountInString(name) > MaxAuthorLength:
+		ve.add(field, CodeAuthorTooLong, ErrAuthorTooLong)
+	case hasControlChars(name):
+		ve.add(field, CodeAuthorControlChars, ErrAuthorControlChars)
+	}
+}
+
+// DefaultSortName puts the last name first, "J. K. Rowling" sorts as "Rowling, J. K.". A single name or one
+// that already has a comma is taken as it is
+func DefaultSortName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) < 2 || strings.Contains(name, ",") {
+		return strings.Join(fields, " ")
+	}
+	return fields[len(fields)-1] + ", " + strings.Join(fields[:len(fields)-1], " ")
+}
+
+// Contributor is one person on a book. Either AuthorID or Name is enough on the way in: a name is looked
+// up ignoring case and the author is created when there is none yet. On the way out both are set
+type Contributor struct {
+	AuthorID int64           `json:"author_id,omitempty"`
+	Name     string          `json:"name"`
+	Role     ContributorRole `json:"role"`
+}
+
+// normalizeContributors trims the contributors, defaults their role to author and drops repeats of the same
+// person in the same role. Violations are reported under field with the index as sent
+func normalizeContributors(ve *ValidationError, field string, contributors []Contributor) []Contributor {
+	type key struct {
+		id   int64
+		name string
+		role ContributorRole
+	}
+	seen := map[key]bool{}
+	out := make([]Contributor, 0, len(contributors))
+	for i, c := range contributors {
+		prefix := fmt.Sprintf("%s[%d].", field, i)
+		c.Name = strings.TrimSpace(c.Name)
+		c.Role = ContributorRole(strings.ToLower(strings.TrimSpace(string(c.Role))))
+		if c.Role == "" {
+			c.Role = RoleAuthor
+		}
+
+		switch c.Role {
+		case RoleAuthor, RoleTranslator, RoleEditor, RoleNarrator:
+			// ALL GOOD
+		default:
+			ve.add(prefix+"role", CodeContributorRoleInvalid, ErrInvalidRole)
+		}
+		if c.AuthorID == 0 && c.Name == "" {
+			ve.add(prefix+"name", CodeContributorMissing, ErrMissingContributor)
+			continue
+		}
+		if c.Name != "" {
+			validateName(ve, prefix+"name", c.Name)
+		}
+
+		k := key{id: c.AuthorID, role: c.Role}
+		if c.AuthorID == 0 {
+			k.name = strings.ToLower(c.Name)
+		}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// AuthorLine is the single author string of a book, the names of its authors in order
+func AuthorLine(contributors []Contributor) string {
+	names := []string{}
+	for _, c := range contributors {
+		if c.Role == RoleAuthor {
+			names = append(names, c.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// KeepContributors is for an update that only has the author string, as sent by clients from before
+// contributors. The same author keeps every contributor of the stored book, a new one replaces its authors
+// but keeps the translators, editors and narrators. An empty author or no stored book returns nil, Validate
+// then goes by the author string alone
+func KeepContributors(stored *Book, author string) []Contributor {
+	author = strings.TrimSpace(author)
+	if author == "" || stored == nil {
+		return nil
+	}
+	if author == stored.Author {
+		return append([]Contributor{}, stored.Contributors...)
+	}
+	contributors := []Contributor{{Name: author, Role: RoleAuthor}}
+	for _, c := range stored.Contributors {
+		if c.Role != RoleAuthor {
+			contributors = append(contributors, c)
+		}
+	}
+	return contributors
+}
+
+// AuthorMerge is the body of an author merge, the author from the path goes into Into
+type AuthorMerge struct {
+	Into int64 `json:"into"`
+}
+
+// Validate checks the merge of the author from
+func (m *AuthorMerge) Validate(from int64) error {
+	ve := &ValidationError{}
+	switch {
+	case m.Into == 0:
+		ve.add("into", CodeAuthorMergeTarget, ErrMissingAuthorMergeTarget)
+	case m.Into == from:
+		ve.add("into", CodeAuthorMergeSelf, ErrAuthorMergeSelf)
+	}
+	return ve.errOrNil()
+}
+
+// AuthorStats is how many books of one author are in each status. Rank uses standard competition
+// ranking (1, 2, 2, 4) on the total number of books so authors with the same count share a rank
+type AuthorStats struct {
+	Author         string  `json:"author"`
+	Rank           int     `json:"rank"`
+	Total          int     `json:"total"`
+	ToRead         int     `json:"to_read"`
+	Reading        int     `json:"reading"`
+	Complete       int     `json:"complete"`
+	Abandoned      int     `json:"abandoned"`
+	CompletionRate float64 `json:"completion_rate"` // Complete / Total, between 0 and 1
+}
+
+// AuthorStatsPage is one page of the author ranking. Total is the number of authors across all pages
+type AuthorStatsPage struct {
+	Authors []AuthorStats `json:"authors"`
+	Total   int           `json:"total"`
+	Limit   int           `json:"limit"`
+	Offset  int           `json:"offset"`
+}
+
+// Rank cut-off for Stats.TopAuthors. Ties at the cut-off are all included so the list can be longer than this
+const TopAuthorsRank = 3
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDefaultSortName(t *testing.T) {
+	tests := map[string]string{
+		"J. K. Rowling":        "Rowling, J. K.",
+		"  Terry   Pratchett ": "Pratchett, Terry",
+		"Homer":                "Homer",
+		"Rowling, J. K.":       "Rowling, J. K.",
+		"":                     "",
+	}
+	for name, want := range tests {
+		if got := DefaultSortName(name); got != want {
+			t.Errorf("DefaultSortName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestAuthor_Validate(t *testing.T) {
+	author := &Author{Name: "  Terry Pratchett "}
+	if err := author.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+	if author.Name != "Terry Pratchett" || author.SortName != "Pratchett, Terry" {
+		t.Errorf("Validate() = %+v, want the name trimmed and the sort name filled in", author)
+	}
+
+	author = &Author{Name: "", SortName: strings.Repeat("x", MaxAuthorLength+1)}
+	ve, ok := AsValidationError(author.Validate())
+	if !ok || len(ve.Errors) != 2 || ve.Errors[0].Code != CodeAuthorMissing || ve.Errors[1].Code != CodeSortNameTooLong {
+		t.Errorf("Validate() = %v, want missing name and too long sort name", ve)
+	}
+}
+
+func TestBook_ValidateContributors(t *testing.T) {
+	book := func(contributors ...Contributor) *Book {
+		return &Book{ID: uuid.NewString(), Title: "Good Omens", Author: "ignored", Status: BookUnread, Contributors: contributors}
+	}
+
+	t.Run("AuthorOnly", func(t *testing.T) {
+		b := &Book{ID: uuid.NewString(), Title: "Mort", Author: " Terry Pratchett ", Status: BookUnread}
+		if err := b.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v, want nil", err)
+		}
+		if want := []Contributor{{Name: "Terry Pratchett", Role: RoleAuthor}}; !slices.Equal(b.Contributors, want) {
+			t.Errorf("Contributors = %+v, want %+v", b.Contributors, want)
+		}
+	})
+
+	t.Run("DerivedAuthorLine", func(t *testing.T) {
+		b := book(
+			Contributor{Name: " Terry Pratchett "},
+			Contributor{Name: "Stephen Briggs", Role: " Narrator"},
+			Contributor{Name: "Neil Gaiman", Role: RoleAuthor},
+			Contributor{Name: "terry pratchett", Role: RoleAuthor},
+		)
+		if err := b.Validate(); err != nil {
+			t.Fatalf("Validate() error = %v, want nil", err)
+		}
+		if b.Author != "Terry Pratchett, Neil Gaiman" || len(b.Contributors) != 3 || b.Contributors[1].Role != RoleNarrator {
+			t.Errorf("Validate() = %q %+v, want the repeat dropped and the authors in order", b.Author, b.Contributors)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		book      *Book
+		wantCodes []string
+	}{
+		{name: "NoAuthorRole", book: book(Contributor{Name: "Stephen Briggs", Role: RoleNarrator}), wantCodes: []string{CodeBookAuthorMissing}},
+		{name: "InvalidRole", book: book(Contributor{Name: "Terry Pratchett"}, Contributor{Name: "Someone", Role: "illustrator"}), wantCodes: []string{CodeContributorRoleInvalid}},
+		{name: "MissingName", book: book(Contributor{Name: "Terry Pratchett"}, Contributor{Role: RoleEditor}), wantCodes: []string{CodeContributorMissing}},
+		{name: "NameControlChars", book: book(Contributor{Name: "Terry\nPratchett"}), wantCodes: []string{CodeAuthorControlChars}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ve, ok := AsValidationError(tt.book.Validate())
+			if !ok {
+				t.Fatalf("Validate() = %v, want a *ValidationError", ve)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestKeepContributors(t *testing.T) {
+	stored := &Book{Author: "Terry Pratchett, Neil Gaiman", Contributors: []Contributor{
+		{AuthorID: 1, Name: "Terry Pratchett", Role: RoleAuthor},
+		{AuthorID: 2, Name: "Neil Gaiman", Role: RoleAuthor},
+		{AuthorID: 3, Name: "Stephen Briggs", Role: RoleNarrator},
+	}}
+
+	if got := KeepContributors(stored, " Terry Pratchett, Neil Gaiman "); !slices.Equal(got, stored.Contributors) {
+		t.Errorf("KeepContributors(same author) = %+v, want the stored contributors", got)
+	}
+	want := []Contributor{{Name: "Neil Gaiman", Role: RoleAuthor}, {AuthorID: 3, Name: "Stephen Briggs", Role: RoleNarrator}}
+	if got := KeepContributors(stored, "Neil Gaiman"); !slices.Equal(got, want) {
+		t.Errorf("KeepContributors(new author) = %+v, want %+v", got, want)
+	}
+	if got := KeepContributors(stored, " "); got != nil {
+		t.Errorf("KeepContributors(empty) = %+v, want nil", got)
+	}
+	if got := KeepContributors(nil, "Neil Gaiman"); got != nil {
+		t.Errorf("KeepContributors(no stored book) = %+v, want nil", got)
+	}
+}
+
+func TestAuthorMerge_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		merge    AuthorMerge
+		wantCode string
+	}{
+		{name: "Valid", merge: AuthorMerge{Into: 2}},
+		{name: "MissingInto", merge: AuthorMerge{}, wantCode: CodeAuthorMergeTarget},
+		{name: "Self", merge: AuthorMerge{Into: 1}, wantCode: CodeAuthorMergeSelf},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.merge.Validate(1)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok || len(ve.Errors) != 1 || ve.Errors[0].Code != tt.wantCode {
+				t.Errorf("Validate() = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
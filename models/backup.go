@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Backup is one snapshot of the SQLite database in the backup directory
+type Backup struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"` // In bytes, after compression
+	Compressed bool      `json:"compressed"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -14,23 +18,47 @@ var (
 	ErrInvalidID     = errors.New("id is invalid")
 	ErrMissingTitle  = errors.New("title is missing")
 	ErrMissingAuthor = errors.New("author is missing")
-	ErrInvalidStatus = errors.New("invalid status: must be unread, reading or complete")
+	ErrInvalidStatus = errors.New("invalid status: must be unread, reading, complete or abandoned")
 	ErrEmptyStatus   = errors.New("status cannot be empty")
+
+	ErrTitleTooLong       = fmt.Errorf("title is too long: max %d characters", MaxTitleLength)
+	ErrAuthorTooLong      = fmt.Errorf("author is too long: max %d characters", MaxAuthorLength)
+	ErrTitleControlChars  = errors.New("title contains control characters")
+	ErrAuthorControlChars = errors.New("author contains control characters")
+	ErrInvalidPages       = errors.New("pages cannot be negative")
+)
+
+const (
+	MaxTitleLength  = 500
+	MaxAuthorLength = 200
 )
 
 type BookStatus string
 
 const (
-	BookUnread   BookStatus = "unread"
-	BookReading  BookStatus = "reading"
-	BookComplete BookStatus = "complete"
+	BookUnread    BookStatus = "unread"
+	BookReading   BookStatus = "reading"
+	BookComplete  BookStatus = "complete"
+	BookAbandoned BookStatus = "abandoned" // Put down for good, it can still be rated like a complete book
 )
 
+// Finished is true for a book that is done with, read to the end or abandoned. Only those can be rated
+func (s BookStatus) Finished() bool {
+	return s == BookComplete || s == BookAbandoned
+}
+
 type Book struct {
-	ID     string     `json:"id"`
-	Title  string     `json:"title"`
-	Author string     `json:"author"`
-	Status BookStatus `json:"status"`
+	ID          string      `json:"id"`
+	Title       string      `json:"title"`
+	Author      string      `json:"author"`
+	Status      BookStatus  `json:"status"`
+	Pages       int         `json:"pages,omitempty"`
+	CompletedAt *time.Time  `json:"completed_at,omitempty"` // NOTE: Set by the store, never taken from the client
+	Tags        []string    `json:"tags,omitempty"`         // Normalized and sorted by Validate, see NormalizeTag
+	Series      *BookSeries `json:"series,omitempty"`       // NOTE: Set by the store, managed through the series endpoints
+	// Contributors are the people behind the book with their role, Author is derived from the ones with the
+	// author role. Sending only Author still works, see Validate
+	Contributors []Contributor `json:"contributors,omitempty"`
 }
 
 func (s *BookStatus) UnmarshalJSON(data []byte) error {
@@ -40,7 +68,7 @@ func (s *BookStatus) UnmarshalJSON(data []byte) error {
 	}
 	str = strings.ToLower(strings.TrimSpace(str))
 	switch BookStatus(str) {
-	case BookUnread, BookReading, BookComplete:
+	case BookUnread, BookReading, BookComplete, BookAbandoned:
 		*s = BookStatus(str)
 		return nil
 	default:
@@ -48,44 +76,81 @@ func (s *BookStatus) UnmarshalJSON(data []byte) error {
 	}
 }
 
+// Validate sanitizes the book and checks every field. All violations are returned together as a *ValidationError
 func (b *Book) Validate() error {
 	b.Title = strings.TrimSpace(b.Title)
 	b.Author = strings.TrimSpace(b.Author)
 
-	if b.ID == "" {
-		return ErrMissingID
-	}
+	ve := &ValidationError{}
 
-	if b.ID != "" {
-		if _, err := uuid.Parse(b.ID); err != nil {
-			return ErrInvalidID
-		}
+	if b.ID == "" {
+		ve.add("id", CodeIDMissing, ErrMissingID)
+	} else if _, err := uuid.Parse(b.ID); err != nil {
+		ve.add("id", CodeIDInvalid, ErrInvalidID)
 	}
 
-	if b.Title == "" {
-		return ErrMissingTitle
+	switch {
+	case b.Title == "":
+		ve.add("title", CodeTitleMissing, ErrMissingTitle)
+	case utf8.RuneCountInString(b.Title) > MaxTitleLength:
+		ve.add("title", CodeTitleTooLong, ErrTitleTooLong)
+	case hasControlChars(b.Title):
+		ve.add("title", CodeTitleControlChars, ErrTitleControlChars)
 	}
 
-	if b.Author == "" {
-		return ErrMissingAuthor
+	// NOTE: Without contributors the author string is the one author of the book, that is what clients from
+	// before contributors send. With them the author string is derived and whatever was sent is ignored
+	if len(b.Contributors) == 0 {
+		violations := len(ve.Errors)
+		validateName(ve, "author", b.Author)
+		if len(ve.Errors) == violations {
+			b.Contributors = []Contributor{{Name: b.Author, Role: RoleAuthor}}
+		}
+	} else {
+		b.Contributors = normalizeContributors(ve, "contributors", b.Contributors)
+		switch {
+		case len(b.Contributors) > MaxContributorsPerBook:
+			ve.add("contributors", CodeTooManyContributors, ErrTooManyContributors)
+		case !slices.ContainsFunc(b.Contributors, func(c Contributor) bool { return c.Role == RoleAuthor }):
+			ve.add("contributors", CodeBookAuthorMissing, ErrMissingBookAuthor)
+		}
+		b.Author = AuthorLine(b.Contributors) // NOTE: Names only known by author_id are filled in by the store
 	}
 
 	trimmedStatus := strings.TrimSpace(string(b.Status))
-	if trimmedStatus == "" {
-		return ErrEmptyStatus
-	}
-
 	status := BookStatus(strings.ToLower(trimmedStatus))
 
 	switch status {
-	case BookUnread, BookReading, BookComplete:
+	case "":
+		ve.add("status", CodeStatusMissing, ErrEmptyStatus)
+	case BookUnread, BookReading, BookComplete, BookAbandoned:
 		// ALL GOOD
 		b.Status = status
 	default:
-		return fmt.Errorf("%w: %s", ErrInvalidStatus, status) // NOTE: %w is a Go feature. Wrapping an existing error
+		ve.add("status", CodeStatusInvalid, fmt.Errorf("%w: %s", ErrInvalidStatus, status)) // NOTE: %w is a Go feature. Wrapping an existing error
 	}
 
-	return nil
+	if b.Pages < 0 {
+		ve.add("pages", CodePagesInvalid, ErrInvalidPages)
+	}
+
+	// NOTE: Invalid tags are reported with their index in the list as sent, before duplicates are dropped
+	b.Tags = normalizeTags(ve, "tags", b.Tags)
+	if len(b.Tags) > MaxTagsPerBook {
+		ve.add("tags", CodeTooManyTags, ErrTooManyTags)
+	}
+
+	return ve.errOrNil()
+}
+
+// NOTE: Titles and authors are single line values so newlines and tabs are rejected as well
+func hasControlChars(s string) bool {
+	for _, r := range s {
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
 }
 
 func (b *Book) GenerateID() error {
@@ -35,6 +35,16 @@ func TestBook_Validate(t *testing.T) {
 			},
 			wantErr: nil,
 		},
+		{
+			name: "ValidBookWithAbandonedStatus",
+			book: &Book{
+				ID:     uuid.NewString(),
+				Title:  "Infinite Jest",
+				Author: "David Foster Wallace",
+				Status: BookAbandoned,
+			},
+			wantErr: nil,
+		},
 		{
 			name: "MissingID",
 			book: &Book{
@@ -153,6 +163,77 @@ func TestBook_Validate(t *testing.T) {
 	}
 }
 
+func TestBook_Validate_Aggregated(t *testing.T) {
+	tests := []struct {
+		name      string
+		book      *Book
+		wantCodes []string
+	}{
+		{
+			name:      "MissingTitleAndAuthor",
+			book:      &Book{ID: uuid.NewString(), Status: BookUnread},
+			wantCodes: []string{CodeTitleMissing, CodeAuthorMissing},
+		},
+		{
+			name:      "EverythingWrong",
+			book:      &Book{ID: "not-a-uuid", Status: "invalid"},
+			wantCodes: []string{CodeIDInvalid, CodeTitleMissing, CodeAuthorMissing, CodeStatusInvalid},
+		},
+		{
+			name: "TooLong",
+			book: &Book{
+				ID:     uuid.NewString(),
+				Title:  strings.Repeat("a", MaxTitleLength+1),
+				Author: strings.Repeat("b", MaxAuthorLength+1),
+				Status: BookUnread,
+			},
+			wantCodes: []string{CodeTitleTooLong, CodeAuthorTooLong},
+		},
+		{
+			name: "MaxLengthInRunes",
+			book: &Book{
+				ID:     uuid.NewString(),
+				Title:  strings.Repeat("å", MaxTitleLength),
+				Author: strings.Repeat("ø", MaxAuthorLength),
+				Status: BookUnread,
+			},
+			wantCodes: nil,
+		},
+		{
+			name:      "ControlCharacters",
+			book:      &Book{ID: uuid.NewString(), Title: "Bad\x00Title", Author: "Bad\nAuthor", Status: BookUnread},
+			wantCodes: []string{CodeTitleControlChars, CodeAuthorControlChars},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.book.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+				if !errors.Is(err, ve.Errors[i].Err) {
+					t.Errorf("errors.Is does not match violation %d sentinel %v", i, ve.Errors[i].Err)
+				}
+			}
+		})
+	}
+}
+
 func TestBook_GenerateID(t *testing.T) {
 	book := &Book{
 		Title:  "The Go Programming Language",
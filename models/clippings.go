@@ -0,0 +1,312 @@
+package models
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NOTE:
+// A Kindle keeps every highlight, note and bookmark in one text file, My Clippings.txt, in the order they
+// were made. Each entry looks like this and ends with a line of ten =
+//
+//	Dune (Frank Herbert)
+//	- Your Highlight on page 8 | Location 112-114 | Added on Monday, March 3, 2025 10:12:45 PM
+//
+//	I must not fear. Fear is the mind-killer.
+//	==========
+//
+// Older Kindles write "- Highlight Loc. 112-14 | Added on ..." instead. Only the English wording is understood
+
+var (
+	ErrClippingMalformed  = errors.New("not a clipping: expected a title line and a - Your Highlight/Note/Bookmark line")
+	ErrClippingKind       = errors.New("unknown clipping type: must be a highlight, note or bookmark (English Kindle wording)")
+	ErrClippingEmpty      = errors.New("clipping has no text")
+	ErrInvalidCreateBooks = errors.New("invalid create: must be true or false")
+)
+
+const CodeImportCreate = "import.create_invalid"
+
+const clippingSeparator = "=========="
+
+// clippingNamespace derives the note ids from the clippings, see Clipping.NoteID
+var clippingNamespace = uuid.MustParse("cd5f4440-8446-4c18-8ea5-94db21fb456f")
+
+type ClippingKind string
+
+const (
+	ClippingHighlight ClippingKind = "highlight"
+	ClippingNote      ClippingKind = "note"
+	ClippingBookmark  ClippingKind = "bookmark"
+)
+
+// Clipping is one entry of My Clippings.txt. Start and End are the location range, both 0 without a
+// location and equal for a single one
+type Clipping struct {
+	Title    string
+	Authors  []string // As "First Last", Kindle writes them "Last, First" separated by ;
+	Kind     ClippingKind
+	Page     int
+	Location string // As written, i.e. 112-114
+	Start    int
+	End      int
+	AddedAt  time.Time // Zero when the date could not be read. Kindle writes local time without a zone, it is taken as UTC
+	Text     string
+	Line     int // Line of the entry in the file, for the error report
+}
+
+// ClippingError is an entry that could not be imported
+type ClippingError struct {
+	Line    int    `json:"line"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	clippingPage     = regexp.MustCompile(`(?i)\bpage\s+(\d+)`)
+	clippingLocation = regexp.MustCompile(`(?i)\b(?:location|loc\.)\s*(\d+)(?:-(\d+))?`)
+	clippingAdded    = regexp.MustCompile(`(?i)\badded on\s+(.+)$`)
+)
+
+// NOTE: US and UK style, with and without seconds
+var clippingDateLayouts = []string{
+	"Monday, January 2, 2006 3:04:05 PM",
+	"Monday, January 2, 2006, 3:04 PM",
+	"Monday, 2 January 2006 15:04:05",
+	"Monday, 2 January 2006 15:04",
+}
+
+// ParseClippings reads a My Clippings.txt. Entries that cant be read are returned as errors with their line
+// and skipped, only a failure to read r fails the whole parse
+func ParseClippings(r io.Reader) ([]Clipping, []ClippingError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxNoteLength*4+1024) // NOTE: A note is a single line and may be long
+
+	var clippings []Clipping
+	var errs []ClippingError
+	var entry []string
+	start, line := 1, 0
+	flush := func() {
+		if len(entry) > 0 {
+			clipping, err := parseClipping(entry, start)
+			if err != nil {
+				errs = append(errs, ClippingError{Line: start, Title: clipping.Title, Message: err.Error()})
+			} else {
+				clippings = append(clippings, clipping)
+			}
+		}
+		entry, start = nil, line+1
+	}
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(strings.TrimPrefix(scanner.Text(), "\ufeff"), "\r")
+		if strings.TrimSpace(text) == clippingSeparator {
+			flush()
+			continue
+		}
+		if len(entry) == 0 && strings.TrimSpace(text) == "" {
+			start = line + 1 // NOTE: Blank lines between entries
+			continue
+		}
+		entry = append(entry, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read clippings: %w", err)
+	}
+	flush()
+	return clippings, errs, nil
+}
+
+func parseClipping(lines []string, line int) (Clipping, error) {
+	c := Clipping{Line: line}
+	c.Title, c.Authors = parseClippingTitle(lines[0])
+	if len(lines) < 2 || !strings.HasPrefix(strings.TrimSpace(lines[1]), "- ") || c.Title == "" {
+		return c, ErrClippingMalformed
+	}
+	meta := strings.TrimSpace(lines[1])
+	kind := strings.ToLower(strings.SplitN(meta, "|", 2)[0])
+	switch {
+	case strings.Contains(kind, "highlight"):
+		c.Kind = ClippingHighlight
+	case strings.Contains(kind, "note"):
+		c.Kind = ClippingNote
+	case strings.Contains(kind, "bookmark"):
+		c.Kind = ClippingBookmark
+	default:
+		return c, ErrClippingKind
+	}
+
+	if m := clippingPage.FindStringSubmatch(meta); m != nil {
+		c.Page, _ = strconv.Atoi(m[1])
+	}
+	if m := clippingLocation.FindStringSubmatch(meta); m != nil {
+		c.Start, _ = strconv.Atoi(m[1])
+		c.End, c.Location = c.Start, m[1]
+		if m[2] != "" {
+			// NOTE: Old Kindles shorten the end to the digits that changed, 112-14 is 112-114
+			end := m[2]
+			if len(end) < len(m[1]) {
+				end = m[1][:len(m[1])-len(end)] + end
+			}
+			c.End, _ = strconv.Atoi(end)
+			c.Location = m[1] + "-" + end
+		}
+	}
+	if m := clippingAdded.FindStringSubmatch(meta); m != nil {
+		for _, layout := range clippingDateLayouts {
+			if at, err := time.Parse(layout, strings.TrimSpace(m[1])); err == nil {
+				c.AddedAt = at
+				break
+			}
+		}
+	}
+
+	c.Text = strings.TrimSpace(strings.Join(lines[2:], "\n"))
+	if c.Text == "" && c.Kind != ClippingBookmark {
+		return c, ErrClippingEmpty
+	}
+	return c, nil
+}
+
+// parseClippingTitle splits "Dune (Dune Chronicles, Book 1) (Frank Herbert)" into the title and the authors
+// in the last parentheses. Without them there is no author, i.e. for personal documents
+func parseClippingTitle(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if !strings.HasSuffix(line, ")") {
+		return line, nil
+	}
+	depth := 0
+	for i := len(line) - 1; i >= 0; i-- {
+		switch line[i] {
+		case ')':
+			depth++
+		case '(':
+			depth--
+		}
+		if depth == 0 {
+			title := strings.TrimSpace(line[:i])
+			if title == "" {
+				return line, nil
+			}
+			var authors []string
+			for _, author := range strings.Split(line[i+1:len(line)-1], ";") {
+				// NOTE: "Austen, Jane" but not "Tolkien, J.R.R., Jr." which has more than one comma
+				if last, first, ok := strings.Cut(author, ","); ok && !strings.Contains(first, ",") {
+					author = strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+				}
+				if author = strings.Join(strings.Fields(author), " "); author != "" {
+					authors = append(authors, author)
+				}
+			}
+			return title, authors
+		}
+	}
+	return line, nil
+}
+
+// SameBook tells whether two clippings are from the same book, Kindle writes title and author the same every time
+func (c Clipping) SameBook(other Clipping) bool {
+	return strings.EqualFold(c.Title, other.Title) && strings.EqualFold(strings.Join(c.Authors, ";"), strings.Join(other.Authors, ";"))
+}
+
+// overlaps tells whether two clippings cover the same part of the book, by location or else by page
+func (c Clipping) overlaps(other Clipping) bool {
+	if c.Start > 0 && other.Start > 0 {
+		return c.Start <= other.End && other.Start <= c.End
+	}
+	return c.Start == 0 && other.Start == 0 && c.Page == other.Page
+}
+
+// DedupeClippings drops what Kindle wrote more than once. Changing a highlight adds a new entry instead of
+// replacing the old one, so of two highlights of the same passage where one text contains the other only the
+// later one is kept. Entries that are the same in every way are kept once
+func DedupeClippings(clippings []Clipping) (kept []Clipping, duplicates int) {
+	for _, c := range clippings {
+		replaced := false
+		for i, k := range kept {
+			if k.Kind != c.Kind || !k.SameBook(c) {
+				continue
+			}
+			same := k.Location == c.Location && k.Page == c.Page && k.Text == c.Text
+			rehighlight := c.Kind == ClippingHighlight && k.overlaps(c) && (strings.Contains(k.Text, c.Text) || strings.Contains(c.Text, k.Text))
+			if !same && !rehighlight {
+				continue
+			}
+			if !c.AddedAt.Before(k.AddedAt) {
+				kept[i] = c
+			}
+			duplicates++
+			replaced = true
+			break
+		}
+		if !replaced {
+			kept = append(kept, c)
+		}
+	}
+	return kept, duplicates
+}
+
+// NoteID is the id of the note the clipping becomes on a book. The same clipping always gets the same id, so
+// importing a file again (it only ever grows) skips what is there already
+func (c Clipping) NoteID(bookID string) string {
+	key := strings.Join([]string{bookID, string(c.Kind), c.Location, strconv.Itoa(c.Page), c.Text}, "\n")
+	return uuid.NewSHA1(clippingNamespace, []byte(key)).String()
+}
+
+// Note turns a highlight or note into a note of the book, created at the time it was made on the Kindle
+func (c Clipping) Note(bookID string) Note {
+	noteType := NoteHighlight
+	if c.Kind == ClippingNote {
+		noteType = NoteText
+	}
+	return Note{ID: c.NoteID(bookID), BookID: bookID, Type: noteType, Body: c.Text, Page: c.Page, Location: c.Location,
+		CreatedAt: c.AddedAt, UpdatedAt: c.AddedAt}
+}
+
+type ClippingsOptions struct {
+	CreateBooks bool `json:"create_books"` // Books that are not in the library are added instead of reported as unmatched
+}
+
+// ParseClippingsOptions reads the ?create= query parameter, false by default
+func ParseClippingsOptions(create string) (ClippingsOptions, error) {
+	var opts ClippingsOptions
+	if create == "" {
+		return opts, nil
+	}
+	value, err := strconv.ParseBool(create)
+	if err != nil {
+		ve := &ValidationError{}
+		ve.add("create", CodeImportCreate, ErrInvalidCreateBooks)
+		return opts, ve
+	}
+	opts.CreateBooks = value
+	return opts, nil
+}
+
+// ClippingsResult is what a clippings import did. Every entry of the file ends up in exactly one of the counts,
+// the unmatched books or the errors
+type ClippingsResult struct {
+	ClippingsOptions
+	Imported        int             `json:"imported"`         // New highlights and notes
+	AlreadyImported int             `json:"already_imported"` // From an earlier import of the same file
+	Duplicates      int             `json:"duplicates"`       // Changed highlights and repeated entries, see DedupeClippings
+	Bookmarks       int             `json:"bookmarks"`        // Skipped, a bookmark has no text
+	BooksMatched    int             `json:"books_matched"`
+	BooksCreated    int             `json:"books_created"`
+	Unmatched       []UnmatchedBook `json:"unmatched"`
+	Errors          []ClippingError `json:"errors"`
+}
+
+// UnmatchedBook is a book of the clippings that is not in the library, with how many of its entries were skipped
+type UnmatchedBook struct {
+	Title     string `json:"title"`
+	Author    string `json:"author,omitempty"`
+	Clippings int    `json:"clippings"`
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// NOTE: A byte order mark and Windows line endings like the real file, plus one entry of every kind
+const sampleClippings = "\ufeffDune (Dune Chronicles, Book 1) (Herbert, Frank)\r\n" +
+	"- Your Highlight on page 8 | Location 112-114 | Added on Monday, March 3, 2025 10:12:45 PM\r\n" +
+	"\r\n" +
+	"I must not fear.\r\n" +
+	"==========\r\n" +
+	"Dune (Dune Chronicles, Book 1) (Herbert, Frank)\r\n" +
+	"- Your Note on page 8 | Location 114 | Added on Monday, March 3, 2025 10:13:02 PM\r\n" +
+	"\r\n" +
+	"The litany\r\nagainst fear\r\n" +
+	"==========\r\n" +
+	"Walden (Henry David Thoreau)\r\n" +
+	"- Highlight Loc. 1998-2001  | Added on Tuesday, 4 March 2025 08:00:00\r\n" +
+	"\r\n" +
+	"Simplify, simplify.\r\n" +
+	"==========\r\n" +
+	"Walden (Henry David Thoreau)\r\n" +
+	"- Your Bookmark on Location 2040 | Added on Tuesday, 4 March 2025 08:05:00\r\n" +
+	"\r\n" +
+	"\r\n" +
+	"==========\r\n" +
+	"Just a title line\r\n" +
+	"==========\r\n" +
+	"Notes (Me)\r\n" +
+	"- Ihre Markierung bei Position 12-13 | Hinzugefügt am Dienstag, 4. März 2025\r\n" +
+	"\r\n" +
+	"Auf Deutsch\r\n" +
+	"==========\r\n"
+
+func TestParseClippings(t *testing.T) {
+	clippings, errs, err := ParseClippings(strings.NewReader(sampleClippings))
+	if err != nil {
+		t.Fatalf("ParseClippings() error = %v", err)
+	}
+	if len(clippings) != 4 {
+		t.Fatalf("ParseClippings() = %d clippings, want 4: %+v", len(clippings), clippings)
+	}
+
+	highlight := clippings[0]
+	if highlight.Title != "Dune (Dune Chronicles, Book 1)" || !slices.Equal(highlight.Authors, []string{"Frank Herbert"}) {
+		t.Errorf("Title and authors = %q %q, want the series kept in the title and the author turned around", highlight.Title, highlight.Authors)
+	}
+	if highlight.Kind != ClippingHighlight || highlight.Page != 8 || highlight.Location != "112-114" || highlight.Start != 112 || highlight.End != 114 {
+		t.Errorf("Highlight = %+v", highlight)
+	}
+	if want := time.Date(2025, 3, 3, 22, 12, 45, 0, time.UTC); !highlight.AddedAt.Equal(want) || highlight.Text != "I must not fear." || highlight.Line != 1 {
+		t.Errorf("Highlight = %+v, want added %v on line 1", highlight, want)
+	}
+	if note := clippings[1]; note.Kind != ClippingNote || note.Location != "114" || note.Text != "The litany\nagainst fear" || note.Line != 6 {
+		t.Errorf("Note = %+v", note)
+	}
+	old := clippings[2]
+	if old.Kind != ClippingHighlight || old.Location != "1998-2001" || old.End != 2001 || !old.AddedAt.Equal(time.Date(2025, 3, 4, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Old style highlight = %+v, want location 1998-2001 and the UK date", old)
+	}
+	if bookmark := clippings[3]; bookmark.Kind != ClippingBookmark || bookmark.Text != "" {
+		t.Errorf("Bookmark = %+v", bookmark)
+	}
+
+	if len(errs) != 2 || errs[0].Line != 22 || errs[0].Message != ErrClippingMalformed.Error() || errs[1].Line != 24 || errs[1].Title != "Notes" {
+		t.Errorf("Errors = %+v, want the title only entry and the German one", errs)
+	}
+}
+
+func TestParseClippingTitle(t *testing.T) {
+	tests := []struct {
+		line    string
+		title   string
+		authors []string
+	}{
+		{"Emma (Jane Austen)", "Emma", []string{"Jane Austen"}},
+		{"Good Omens (Pratchett, Terry;Gaiman, Neil)", "Good Omens", []string{"Terry Pratchett", "Neil Gaiman"}},
+		{"The Hobbit (Tolkien, J.R.R., Jr.)", "The Hobbit", []string{"Tolkien, J.R.R., Jr."}},
+		{"Meeting notes", "Meeting notes", nil},
+		{"(Untitled)", "(Untitled)", nil},
+	}
+	for _, tt := range tests {
+		title, authors := parseClippingTitle(tt.line)
+		if title != tt.title || !slices.Equal(authors, tt.authors) {
+			t.Errorf("parseClippingTitle(%q) = %q %q, want %q %q", tt.line, title, authors, tt.title, tt.authors)
+		}
+	}
+}
+
+func TestDedupeClippings(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2025, 3, 3, 22, minute, 0, 0, time.UTC) }
+	dune := func(kind ClippingKind, start, end int, text string, added time.Time) Clipping {
+		return Clipping{Title: "Dune", Authors: []string{"Frank Herbert"}, Kind: kind, Start: start, End: end, Text: text, AddedAt: added}
+	}
+	clippings := []Clipping{
+		dune(ClippingHighlight, 112, 113, "I must not fear.", at(1)),
+		dune(ClippingHighlight, 200, 201, "Fear is the mind-killer.", at(2)),
+		dune(ClippingHighlight, 112, 114, "I must not fear. Fear is the mind-killer.", at(3)), // Extended, replaces the first
+		dune(ClippingNote, 114, 114, "The litany", at(4)),
+		dune(ClippingNote, 114, 114, "The litany", at(4)), // Same entry twice
+		dune(ClippingNote, 114, 114, "Against fear", at(5)),
+		{Title: "Other", Kind: ClippingHighlight, Start: 112, End: 114, Text: "I must not fear.", AddedAt: at(6)},
+	}
+	kept, duplicates := DedupeClippings(clippings)
+	if duplicates != 2 {
+		t.Errorf("duplicates = %d, want 2", duplicates)
+	}
+	var texts []string
+	for _, c := range kept {
+		texts = append(texts, c.Text)
+	}
+	want := []string{"I must not fear. Fear is the mind-killer.", "Fear is the mind-killer.", "The litany", "Against fear", "I must not fear."}
+	if !slices.Equal(texts, want) {
+		t.Errorf("kept = %q, want %q", texts, want)
+	}
+}
+
+func TestClipping_Note(t *testing.T) {
+	bookID := "0b9c3a4e-8f0a-4a57-9f4e-3f1f2d6c1a11"
+	added := time.Date(2025, 3, 3, 22, 12, 45, 0, time.UTC)
+	c := Clipping{Title: "Dune", Kind: ClippingNote, Page: 8, Location: "114", Start: 114, End: 114, Text: "The litany", AddedAt: added}
+
+	note := c.Note(bookID)
+	if err := note.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if note.Type != NoteText || note.Body != "The litany" || note.Page != 8 || note.Location != "114" || !note.CreatedAt.Equal(added) {
+		t.Errorf("Note() = %+v", note)
+	}
+	if again := c.Note(bookID); again.ID != note.ID {
+		t.Errorf("NoteID() = %s then %s, want the same id every time", note.ID, again.ID)
+	}
+	c.Text = "The litany against fear"
+	if changed := c.Note(bookID); changed.ID == note.ID {
+		t.Error("NoteID() stayed the same for a different text")
+	}
+}
+
+func TestParseClippingsOptions(t *testing.T) {
+	if opts, err := ParseClippingsOptions(""); err != nil || opts.CreateBooks {
+		t.Errorf("Defaults = %+v, %v", opts, err)
+	}
+	if opts, err := ParseClippingsOptions("true"); err != nil || !opts.CreateBooks {
+		t.Errorf("true = %+v, %v", opts, err)
+	}
+	_, err := ParseClippingsOptions("sometimes")
+	if ve, ok := AsValidationError(err); !ok || len(ve.Errors) != 1 || ve.Errors[0].Code != CodeImportCreate {
+		t.Errorf("Expected create to be rejected, got %v", err)
+	}
+}
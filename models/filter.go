@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidFilterDate = errors.New("invalid date: must be YYYY-MM-DD")
+	ErrFilterDateOrder   = errors.New("completed_to must not be before completed_from")
+	ErrInvalidSort       = errors.New("invalid sort: must be title, author, pages, completed_at or series, with a leading - for descending")
+)
+
+const (
+	CodeFilterDateInvalid = "filter.date_invalid"
+	CodeFilterDateOrder   = "filter.date_order"
+	CodeFilterSortInvalid = "filter.sort_invalid"
+)
+
+// BookSort is the order of a book listing, a leading - sorts descending i.e. -completed_at is the most
+// recently completed first. Ties are broken by title, title itself is the default
+type BookSort string
+
+const (
+	SortTitle       BookSort = "title"
+	SortAuthor      BookSort = "author"
+	SortPages       BookSort = "pages"
+	SortCompletedAt BookSort = "completed_at"
+	SortSeries      BookSort = "series" // Series name, then position in the series. Books outside a series go last
+)
+
+// Split returns the field to sort on and whether it is descending
+func (s BookSort) Split() (field BookSort, desc bool) {
+	if s == "" {
+		return SortTitle, false
+	}
+	field, desc = BookSort(strings.TrimPrefix(string(s), "-")), strings.HasPrefix(string(s), "-")
+	return field, desc
+}
+
+// BookFilter narrows down a book listing, empty fields dont filter anything
+type BookFilter struct {
+	Status        BookStatus `json:"status,omitempty"`
+	Title         string     `json:"title,omitempty"`          // Whole title, ignoring case
+	Author        string     `json:"author,omitempty"`         // Whole author, ignoring case
+	Tags          []string   `json:"tag,omitempty"`            // Books with every one of these tags, named like the query parameters
+	AnyTags       []string   `json:"tag_any,omitempty"`        // Books with at least one of these tags
+	CompletedFrom string     `json:"completed_from,omitempty"` // YYYY-MM-DD, inclusive
+	CompletedTo   string     `json:"completed_to,omitempty"`   // YYYY-MM-DD, inclusive
+	Sort          BookSort   `json:"sort,omitempty"`
+}
+
+// Validate sanitizes the filter and checks every field. Tags are normalized the same way Book.Validate does,
+// so a filter on "Distributed Systems" finds the books tagged distributed-systems
+func (f *BookFilter) Validate() error {
+	f.Status = BookStatus(strings.ToLower(strings.TrimSpace(string(f.Status))))
+	f.Title = strings.TrimSpace(f.Title)
+	f.Author = strings.TrimSpace(f.Author)
+	f.Sort = BookSort(strings.ToLower(strings.TrimSpace(string(f.Sort))))
+
+	ve := &ValidationError{}
+
+	switch f.Status {
+	case "", BookUnread, BookReading, BookComplete, BookAbandoned:
+		// ALL GOOD
+	default:
+		ve.add("status", CodeStatusInvalid, ErrInvalidStatus)
+	}
+
+	f.Tags = normalizeTags(ve, "tag", f.Tags)
+	f.AnyTags = normalizeTags(ve, "tag_any", f.AnyTags)
+
+	from, fromErr := time.Parse(DateLayout, f.CompletedFrom)
+	if f.CompletedFrom != "" && fromErr != nil {
+		ve.add("completed_from", CodeFilterDateInvalid, ErrInvalidFilterDate)
+	}
+	to, toErr := time.Parse(DateLayout, f.CompletedTo)
+	if f.CompletedTo != "" && toErr != nil {
+		ve.add("completed_to", CodeFilterDateInvalid, ErrInvalidFilterDate)
+	}
+	if fromErr == nil && toErr == nil && to.Before(from) {
+		ve.add("completed_to", CodeFilterDateOrder, ErrFilterDateOrder)
+	}
+
+	if field, _ := f.Sort.Split(); !slices.Contains([]BookSort{SortTitle, SortAuthor, SortPages, SortCompletedAt, SortSeries}, field) {
+		ve.add("sort", CodeFilterSortInvalid, ErrInvalidSort)
+	}
+
+	return ve.errOrNil()
+}
+
+// CompletedRange returns the start of CompletedFrom and the start of the day after CompletedTo in UTC,
+// nil for the ones that are not set. Only call it on a validated filter
+func (f *BookFilter) CompletedRange() (from, to *time.Time) {
+	if f.CompletedFrom != "" {
+		day, _ := time.Parse(DateLayout, f.CompletedFrom)
+		from = &day
+	}
+	if f.CompletedTo != "" {
+		day, _ := time.Parse(DateLayout, f.CompletedTo)
+		day = day.AddDate(0, 0, 1)
+		to = &day
+	}
+	return from, to
+}
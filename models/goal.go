@@ -0,0 +1,171 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidGoalMetric = errors.New("invalid metric: must be books or pages")
+	ErrInvalidGoalTarget = errors.New("target must be a positive number")
+	ErrInvalidGoalPeriod = errors.New("invalid period: set a year or start_date and end_date (YYYY-MM-DD)")
+	ErrGoalPeriodOrder   = errors.New("end_date must not be before start_date")
+	ErrGoalTitleTooLong  = fmt.Errorf("title is too long: max %d characters", MaxTitleLength)
+)
+
+const (
+	CodeGoalIDInvalid     = "goal.id_invalid"
+	CodeGoalMetricInvalid = "goal.metric_invalid"
+	CodeGoalTargetInvalid = "goal.target_invalid"
+	CodeGoalPeriodInvalid = "goal.period_invalid"
+	CodeGoalPeriodOrder   = "goal.period_order"
+	CodeGoalTitleTooLong  = "goal.title_too_long"
+)
+
+type GoalMetric string
+
+const (
+	GoalBooks GoalMetric = "books"
+	GoalPages GoalMetric = "pages"
+)
+
+// NOTE: Dates are kept as YYYY-MM-DD strings as thats what the API speaks and they sort correctly as text
+const DateLayout = time.DateOnly
+
+// Goal is a target number of books or pages to complete within a period. Both dates are inclusive.
+// Year is a shortcut on input i.e. {"year": 2026} means 2026-01-01 to 2026-12-31
+type Goal struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title,omitempty"`
+	Metric    GoalMetric `json:"metric"`
+	Target    int        `json:"target"`
+	Year      int        `json:"year,omitempty"`
+	StartDate string     `json:"start_date"`
+	EndDate   string     `json:"end_date"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Pace tells whether a goal is on schedule compared to an even spread over the period
+type Pace string
+
+const (
+	PaceNotStarted Pace = "not_started"
+	PaceAhead      Pace = "ahead"
+	PaceOnTrack    Pace = "on_track"
+	PaceBehind     Pace = "behind"
+	PaceCompleted  Pace = "completed"
+	PaceMissed     Pace = "missed"
+)
+
+type GoalProgress struct {
+	Goal
+	Current  int     `json:"current"`
+	Percent  int     `json:"percent"`
+	Expected float64 `json:"expected"` // Where an even pace would be by today
+	Pace     Pace    `json:"pace"`
+}
+
+func (g *Goal) GenerateID() error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("failed to generate id: %w", err)
+	}
+	g.ID = id.String()
+	return nil
+}
+
+// Validate sanitizes the goal and resolves Year into StartDate/EndDate
+func (g *Goal) Validate() error {
+	g.Title = strings.TrimSpace(g.Title)
+	g.Metric = GoalMetric(strings.ToLower(strings.TrimSpace(string(g.Metric))))
+
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(g.ID); err != nil {
+		ve.add("id", CodeGoalIDInvalid, ErrInvalidID)
+	}
+
+	if utf8.RuneCountInString(g.Title) > MaxTitleLength {
+		ve.add("title", CodeGoalTitleTooLong, ErrGoalTitleTooLong)
+	}
+
+	switch g.Metric {
+	case GoalBooks, GoalPages:
+		// ALL GOOD
+	default:
+		ve.add("metric", CodeGoalMetricInvalid, ErrInvalidGoalMetric)
+	}
+
+	if g.Target <= 0 {
+		ve.add("target", CodeGoalTargetInvalid, ErrInvalidGoalTarget)
+	}
+
+	if g.Year != 0 {
+		if g.Year < 1 || g.Year > 9999 {
+			ve.add("year", CodeGoalPeriodInvalid, ErrInvalidGoalPeriod)
+			return ve.errOrNil()
+		}
+		g.StartDate = fmt.Sprintf("%04d-01-01", g.Year)
+		g.EndDate = fmt.Sprintf("%04d-12-31", g.Year)
+		g.Year = 0 // Only an input shortcut, the dates are what gets stored
+	}
+
+	start, startErr := time.Parse(DateLayout, g.StartDate)
+	if startErr != nil {
+		ve.add("start_date", CodeGoalPeriodInvalid, ErrInvalidGoalPeriod)
+	}
+	end, endErr := time.Parse(DateLayout, g.EndDate)
+	if endErr != nil {
+		ve.add("end_date", CodeGoalPeriodInvalid, ErrInvalidGoalPeriod)
+	}
+	if startErr == nil && endErr == nil && end.Before(start) {
+		ve.add("end_date", CodeGoalPeriodOrder, ErrGoalPeriodOrder)
+	}
+
+	return ve.errOrNil()
+}
+
+// Period returns the start of the first day and the start of the day after the last day, in UTC
+func (g *Goal) Period() (from, to time.Time) {
+	from, _ = time.Parse(DateLayout, g.StartDate)
+	end, _ := time.Parse(DateLayout, g.EndDate)
+	return from, end.AddDate(0, 0, 1)
+}
+
+// Progress works out how far along the goal is given the amount completed so far and the current time
+func (g *Goal) Progress(current int, now time.Time) GoalProgress {
+	p := GoalProgress{Goal: *g, Current: current}
+	if g.Target > 0 {
+		p.Percent = min(current*100/g.Target, 100)
+	}
+
+	from, to := g.Period()
+	now = now.UTC()
+	total := to.Sub(from).Hours() / 24
+	elapsed := now.Sub(from).Hours() / 24
+	elapsed = max(0, min(elapsed, total))
+	if total > 0 {
+		p.Expected = float64(g.Target) * elapsed / total
+	}
+
+	switch {
+	case current >= g.Target:
+		p.Pace = PaceCompleted
+	case now.Before(from):
+		p.Pace = PaceNotStarted
+	case !now.Before(to):
+		p.Pace = PaceMissed
+	case float64(current) > p.Expected:
+		p.Pace = PaceAhead
+	case current < int(p.Expected): // NOTE: Less than a whole book/page behind still counts as on track
+		p.Pace = PaceBehind
+	default:
+		p.Pace = PaceOnTrack
+	}
+	return p
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestGoal_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		goal      *Goal
+		wantCodes []string
+		wantStart string
+		wantEnd   string
+	}{
+		{
+			name:      "YearShortcut",
+			goal:      &Goal{ID: uuid.NewString(), Metric: "Books", Target: 24, Year: 2026},
+			wantStart: "2026-01-01",
+			wantEnd:   "2026-12-31",
+		},
+		{
+			name:      "CustomPeriod",
+			goal:      &Goal{ID: uuid.NewString(), Metric: GoalPages, Target: 5000, StartDate: "2026-06-01", EndDate: "2026-08-31"},
+			wantStart: "2026-06-01",
+			wantEnd:   "2026-08-31",
+		},
+		{
+			name:      "Invalid",
+			goal:      &Goal{ID: uuid.NewString(), Metric: "chapters", Target: 0, StartDate: "2026-13-01"},
+			wantCodes: []string{CodeGoalMetricInvalid, CodeGoalTargetInvalid, CodeGoalPeriodInvalid, CodeGoalPeriodInvalid},
+		},
+		{
+			name:      "EndBeforeStart",
+			goal:      &Goal{ID: uuid.NewString(), Metric: GoalBooks, Target: 1, StartDate: "2026-06-01", EndDate: "2026-05-31"},
+			wantCodes: []string{CodeGoalPeriodOrder},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.goal.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if tt.goal.StartDate != tt.wantStart || tt.goal.EndDate != tt.wantEnd {
+					t.Errorf("Period = %s - %s, want %s - %s", tt.goal.StartDate, tt.goal.EndDate, tt.wantStart, tt.wantEnd)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGoal_Progress(t *testing.T) {
+	// NOTE: 2026 is not a leap year so July 2nd 12:00 is exactly half way
+	goal := &Goal{Metric: GoalBooks, Target: 24, StartDate: "2026-01-01", EndDate: "2026-12-31"}
+	halfway := time.Date(2026, 7, 2, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		current  int
+		now      time.Time
+		wantPace Pace
+	}{
+		{name: "Ahead", current: 13, now: halfway, wantPace: PaceAhead},
+		{name: "OnTrack", current: 12, now: halfway, wantPace: PaceOnTrack},
+		{name: "Behind", current: 11, now: halfway, wantPace: PaceBehind},
+		{name: "Completed", current: 24, now: halfway, wantPace: PaceCompleted},
+		{name: "NotStarted", current: 0, now: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), wantPace: PaceNotStarted},
+		{name: "Missed", current: 23, now: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), wantPace: PaceMissed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := goal.Progress(tt.current, tt.now)
+			if p.Pace != tt.wantPace {
+				t.Errorf("Pace = %s, want %s (expected %.2f)", p.Pace, tt.wantPace, p.Expected)
+			}
+		})
+	}
+
+	if p := goal.Progress(12, halfway); p.Expected != 12 || p.Percent != 50 {
+		t.Errorf("Progress = expected %.2f, percent %d, want 12 and 50", p.Expected, p.Percent)
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// NOTE:
+// The highlights and quotes of the books come back a few a day, spaced out like SM-2 spaces out flash cards.
+// SM-2 grades how well something was remembered, here the grade comes from the feedback: keep is a good
+// answer, the interval grows by the ease each time. favorite is a hard one, so the ease shrinks and a
+// favorite comes back more often than the rest. discard takes the highlight out of the rotation
+
+var (
+	ErrInvalidHighlightFeedback = errors.New("invalid feedback: must be keep, discard or favorite")
+	ErrInvalidScheduleDate      = errors.New("invalid date: must be YYYY-MM-DD")
+	ErrInvalidScheduleEase      = fmt.Errorf("invalid ease: must be at least %.1f", MinHighlightEase)
+	ErrInvalidScheduleInterval  = errors.New("cannot be negative")
+)
+
+const (
+	CodeHighlightFeedbackInvalid = "highlight.feedback_invalid"
+	CodeScheduleDateInvalid      = "highlight.date_invalid"
+	CodeScheduleEaseInvalid      = "highlight.ease_invalid"
+	CodeScheduleIntervalInvalid  = "highlight.interval_invalid"
+)
+
+const (
+	DefaultHighlightsPerDay = 5
+	DefaultHighlightEase    = 2.5 // The E-Factor SM-2 starts every item with
+	MinHighlightEase        = 1.3
+)
+
+type HighlightFeedback string
+
+const (
+	FeedbackKeep     HighlightFeedback = "keep"
+	FeedbackDiscard  HighlightFeedback = "discard"
+	FeedbackFavorite HighlightFeedback = "favorite"
+)
+
+// ParseHighlightFeedback reads the feedback of a review, ignoring case
+func ParseHighlightFeedback(feedback string) (HighlightFeedback, error) {
+	f := HighlightFeedback(strings.ToLower(strings.TrimSpace(feedback)))
+	switch f {
+	case FeedbackKeep, FeedbackDiscard, FeedbackFavorite:
+		return f, nil
+	}
+	ve := &ValidationError{}
+	ve.add("feedback", CodeHighlightFeedbackInvalid, ErrInvalidHighlightFeedback)
+	return "", ve
+}
+
+// quality is the SM-2 grade (0-5) of the feedback, see the NOTE above
+func (f HighlightFeedback) quality() int {
+	if f == FeedbackFavorite {
+		return 3
+	}
+	return 4
+}
+
+// HighlightSchedule is when a highlight or quote comes back. The days are UTC days as YYYY-MM-DD
+type HighlightSchedule struct {
+	NoteID       string  `json:"note_id"`
+	Repetitions  int     `json:"repetitions"` // Reviews kept in a row
+	IntervalDays int     `json:"interval_days"`
+	Ease         float64 `json:"ease"`
+	DueOn        string  `json:"due_on"`
+	Favorite     bool    `json:"favorite"`
+	Discarded    bool    `json:"discarded"`
+	ReviewedOn   string  `json:"reviewed_on,omitempty"` // Empty until the first review
+}
+
+// NewHighlightSchedule is the schedule of a highlight that was never reviewed, it is due from the day it was written
+func NewHighlightSchedule(noteID string, createdAt time.Time) HighlightSchedule {
+	return HighlightSchedule{NoteID: noteID, Ease: DefaultHighlightEase, DueOn: createdAt.UTC().Format(time.DateOnly)}
+}
+
+// Validate checks a schedule that comes from outside, i.e. an archive. The store only ever writes the
+// schedules Review made
+func (s *HighlightSchedule) Validate() error {
+	ve := &ValidationError{}
+
+	if s.Repetitions < 0 {
+		ve.add("repetitions", CodeScheduleIntervalInvalid, ErrInvalidScheduleInterval)
+	}
+	if s.IntervalDays < 0 {
+		ve.add("interval_days", CodeScheduleIntervalInvalid, ErrInvalidScheduleInterval)
+	}
+	if s.Ease < MinHighlightEase {
+		ve.add("ease", CodeScheduleEaseInvalid, ErrInvalidScheduleEase)
+	}
+	if _, err := time.Parse(time.DateOnly, s.DueOn); err != nil {
+		ve.add("due_on", CodeScheduleDateInvalid, ErrInvalidScheduleDate)
+	}
+	if _, err := time.Parse(time.DateOnly, s.ReviewedOn); s.ReviewedOn != "" && err != nil {
+		ve.add("reviewed_on", CodeScheduleDateInvalid, ErrInvalidScheduleDate)
+	}
+
+	return ve.errOrNil()
+}
+
+// Review records the feedback given on today (in UTC) and schedules the next review. Keeping a discarded
+// highlight brings it back, a favorite stays one
+func (s *HighlightSchedule) Review(feedback HighlightFeedback, today time.Time) {
+	today = today.UTC()
+	s.ReviewedOn = today.Format(time.DateOnly)
+	if feedback == FeedbackDiscard {
+		s.Discarded = true
+		return
+	}
+	s.Discarded = false
+	if feedback == FeedbackFavorite {
+		s.Favorite = true
+	}
+
+	q := float64(5 - feedback.quality())
+	// NOTE: Rounded so the ease doesnt pick up float noise, keep adds exactly nothing
+	s.Ease = math.Max(MinHighlightEase, math.Round((s.Ease+0.1-q*(0.08+q*0.02))*100)/100)
+	switch s.Repetitions {
+	case 0:
+		s.IntervalDays = 1
+	case 1:
+		s.IntervalDays = 6
+	default:
+		s.IntervalDays = int(math.Round(float64(s.IntervalDays) * s.Ease))
+	}
+	s.Repetitions++
+	s.DueOn = today.AddDate(0, 0, s.IntervalDays).Format(time.DateOnly)
+}
+
+// DailyHighlight is a highlight or quote in the review queue, with the title of its book to show it with
+type DailyHighlight struct {
+	Note
+	BookTitle string            `json:"book_title"`
+	Schedule  HighlightSchedule `json:"schedule"`
+}
+
+// DailyHighlights is the review queue of a day. It holds what is still to review today, the longest due first.
+// Every review of the day counts against PerDay, so the queue only gets shorter until the next day
+type DailyHighlights struct {
+	Date       string           `json:"date"`
+	PerDay     int              `json:"per_day"`
+	Reviewed   int              `json:"reviewed"`
+	Highlights []DailyHighlight `json:"highlights"`
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHighlightFeedback(t *testing.T) {
+	for _, in := range []string{"keep", " Favorite ", "DISCARD"} {
+		if _, err := ParseHighlightFeedback(in); err != nil {
+			t.Errorf("ParseHighlightFeedback(%q) error = %v, want nil", in, err)
+		}
+	}
+	for _, in := range []string{"", "skip"} {
+		_, err := ParseHighlightFeedback(in)
+		if ve, ok := AsValidationError(err); !ok || ve.Errors[0].Code != CodeHighlightFeedbackInvalid {
+			t.Errorf("ParseHighlightFeedback(%q) error = %v, want %s", in, err, CodeHighlightFeedbackInvalid)
+		}
+	}
+}
+
+func TestHighlightSchedule_Review(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 5, d, 23, 30, 0, 0, time.UTC) }
+	created := time.Date(2026, 4, 30, 23, 0, 0, 0, time.FixedZone("EST", -5*3600)) // May 1 in UTC
+
+	tests := []struct {
+		name     string
+		feedback []HighlightFeedback
+		want     HighlightSchedule
+	}{
+		{name: "New", want: HighlightSchedule{Ease: 2.5, DueOn: "2026-05-01"}},
+		{
+			name:     "KeepGrowsByEase",
+			feedback: []HighlightFeedback{FeedbackKeep, FeedbackKeep, FeedbackKeep, FeedbackKeep},
+			want:     HighlightSchedule{Repetitions: 4, IntervalDays: 38, Ease: 2.5, DueOn: "2026-06-11", ReviewedOn: "2026-05-04"},
+		},
+		{
+			name:     "FavoriteComesBackSooner",
+			feedback: []HighlightFeedback{FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite},
+			want:     HighlightSchedule{Repetitions: 4, IntervalDays: 23, Ease: 1.94, DueOn: "2026-05-27", Favorite: true, ReviewedOn: "2026-05-04"},
+		},
+		{
+			name:     "EaseStopsAtMinimum",
+			feedback: []HighlightFeedback{FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite, FeedbackFavorite},
+			want:     HighlightSchedule{Repetitions: 9, IntervalDays: 185, Ease: 1.3, DueOn: "2026-11-10", Favorite: true, ReviewedOn: "2026-05-09"},
+		},
+		{
+			name:     "DiscardKeepsTheSchedule",
+			feedback: []HighlightFeedback{FeedbackKeep, FeedbackDiscard},
+			want:     HighlightSchedule{Repetitions: 1, IntervalDays: 1, Ease: 2.5, DueOn: "2026-05-02", Discarded: true, ReviewedOn: "2026-05-02"},
+		},
+		{
+			name:     "KeepBringsBackDiscarded",
+			feedback: []HighlightFeedback{FeedbackFavorite, FeedbackDiscard, FeedbackKeep},
+			want:     HighlightSchedule{Repetitions: 2, IntervalDays: 6, Ease: 2.36, DueOn: "2026-05-09", Favorite: true, ReviewedOn: "2026-05-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewHighlightSchedule("id", created)
+			for i, f := range tt.feedback {
+				s.Review(f, day(i+1))
+			}
+			tt.want.NoteID = "id"
+			if s != tt.want {
+				t.Errorf("Schedule = %+v, want %+v", s, tt.want)
+			}
+		})
+	}
+}
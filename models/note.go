@@ -0,0 +1,155 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidNoteType     = errors.New("invalid type: must be note, highlight or quote")
+	ErrMissingNoteBody     = errors.New("body is missing")
+	ErrNoteBodyTooLong     = fmt.Errorf("body is too long: max %d characters", MaxNoteLength)
+	ErrNoteLocationTooLong = fmt.Errorf("location is too long: max %d characters", MaxNoteLocationLength)
+	ErrNoteLocationControl = errors.New("location contains control characters")
+	ErrInvalidNoteBookID   = errors.New("book_id is invalid")
+	ErrTooManySearchTerms  = fmt.Errorf("too many search terms: max %d", MaxNoteSearchTerms)
+	ErrInvalidNotePage     = errors.New("page cannot be negative")
+)
+
+const (
+	CodeNoteIDInvalid       = "note.id_invalid"
+	CodeNoteBookIDInvalid   = "note.book_id_invalid"
+	CodeNoteTypeInvalid     = "note.type_invalid"
+	CodeNoteBodyMissing     = "note.body_missing"
+	CodeNoteBodyTooLong     = "note.body_too_long"
+	CodeNotePageInvalid     = "note.page_invalid"
+	CodeNoteLocationTooLong = "note.location_too_long"
+	CodeNoteLocationControl = "note.location_control_characters"
+	CodeNoteSearchTooLong   = "note.search_too_many_terms"
+)
+
+const (
+	MaxNoteLength         = 20000
+	MaxNoteLocationLength = 100
+	MaxNoteSearchTerms    = 10
+)
+
+// NoteType is what kind of note it is. A highlight is a passage marked in the book, a quote one worth
+// repeating and a note is our own words
+type NoteType string
+
+const (
+	NoteText      NoteType = "note"
+	NoteHighlight NoteType = "highlight"
+	NoteQuote     NoteType = "quote"
+)
+
+// Note is something written down while reading a book. Page and Location are both optional, Location is
+// free form for what has no page, i.e. a Kindle location "1234-1240" or a chapter
+type Note struct {
+	ID        string    `json:"id"`
+	BookID    string    `json:"book_id"`
+	Type      NoteType  `json:"type"`
+	Body      string    `json:"body"` // Markdown, stored and returned as written. Rendering is up to the client
+	Page      int       `json:"page,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	CreatedAt time.Time `json:"created_at"` // NOTE: Set by the store, never taken from the client
+	UpdatedAt time.Time `json:"updated_at"` // NOTE: Set by the store, never taken from the client
+}
+
+func (n *Note) GenerateID() error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("failed to generate id: %w", err)
+	}
+	n.ID = id.String()
+	return nil
+}
+
+// Validate sanitizes the note and checks every field. Without a type it is a plain note
+func (n *Note) Validate() error {
+	n.Body = strings.TrimSpace(n.Body)
+	n.Location = strings.TrimSpace(n.Location)
+	n.Type = NoteType(strings.ToLower(strings.TrimSpace(string(n.Type))))
+	if n.Type == "" {
+		n.Type = NoteText
+	}
+
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(n.ID); err != nil {
+		ve.add("id", CodeNoteIDInvalid, ErrInvalidID)
+	}
+	if _, err := uuid.Parse(n.BookID); err != nil {
+		ve.add("book_id", CodeNoteBookIDInvalid, ErrInvalidNoteBookID)
+	}
+
+	switch n.Type {
+	case NoteText, NoteHighlight, NoteQuote:
+		// ALL GOOD
+	default:
+		ve.add("type", CodeNoteTypeInvalid, ErrInvalidNoteType)
+	}
+
+	switch {
+	case n.Body == "":
+		ve.add("body", CodeNoteBodyMissing, ErrMissingNoteBody)
+	case utf8.RuneCountInString(n.Body) > MaxNoteLength:
+		ve.add("body", CodeNoteBodyTooLong, ErrNoteBodyTooLong)
+	}
+
+	if n.Page < 0 {
+		ve.add("page", CodeNotePageInvalid, ErrInvalidNotePage)
+	}
+	switch {
+	case utf8.RuneCountInString(n.Location) > MaxNoteLocationLength:
+		ve.add("location", CodeNoteLocationTooLong, ErrNoteLocationTooLong)
+	case hasControlChars(n.Location):
+		ve.add("location", CodeNoteLocationControl, ErrNoteLocationControl)
+	}
+
+	return ve.errOrNil()
+}
+
+// NoteQuery selects notes. With a BookID they are the notes of that book in reading order, otherwise the
+// notes of all books with the last written first. Terms are matched as word prefixes, all of them have to match
+type NoteQuery struct {
+	BookID string
+	Type   NoteType
+	Terms  []string
+}
+
+// ParseNoteQuery reads the raw ?q= and ?type= values. The search text is split into words the same way the
+// databases index the notes, anything that is not a letter or digit separates words and the case is ignored
+func ParseNoteQuery(q, noteType string) (NoteQuery, error) {
+	query := NoteQuery{
+		Type:  NoteType(strings.ToLower(strings.TrimSpace(noteType))),
+		Terms: NoteSearchTerms(q),
+	}
+	ve := &ValidationError{}
+
+	switch query.Type {
+	case "", NoteText, NoteHighlight, NoteQuote:
+		// ALL GOOD
+	default:
+		ve.add("type", CodeNoteTypeInvalid, ErrInvalidNoteType)
+	}
+	if len(query.Terms) > MaxNoteSearchTerms {
+		ve.add("q", CodeNoteSearchTooLong, ErrTooManySearchTerms)
+	}
+
+	return query, ve.errOrNil()
+}
+
+// NoteSearchTerms splits text into lower case words, see ParseNoteQuery
+func NoteSearchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
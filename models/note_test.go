@@ -0,0 +1,90 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNote_Validate(t *testing.T) {
+	valid := func() *Note { return &Note{ID: uuid.NewString(), BookID: uuid.NewString()} }
+	with := func(f func(n *Note)) *Note {
+		n := valid()
+		f(n)
+		return n
+	}
+
+	tests := []struct {
+		name      string
+		note      *Note
+		wantType  NoteType
+		wantCodes []string
+	}{
+		{name: "DefaultsToNote", note: with(func(n *Note) { n.Body = "  Read the *footnotes*  " }), wantType: NoteText},
+		{name: "Highlight", note: with(func(n *Note) { n.Type = " Highlight "; n.Body = "Call me Ishmael."; n.Location = "1234-1240" }), wantType: NoteHighlight},
+		{name: "QuoteWithPage", note: with(func(n *Note) { n.Type = NoteQuote; n.Body = "It was the best of times"; n.Page = 1 }), wantType: NoteQuote},
+		{name: "MissingBody", note: with(func(n *Note) { n.Body = " \n " }), wantCodes: []string{CodeNoteBodyMissing}},
+		{
+			name: "Invalid",
+			note: &Note{ID: "nope", BookID: "", Type: "bookmark", Body: strings.Repeat("x", MaxNoteLength+1), Page: -1, Location: "loc\x00"},
+			wantCodes: []string{CodeNoteIDInvalid, CodeNoteBookIDInvalid, CodeNoteTypeInvalid, CodeNoteBodyTooLong,
+				CodeNotePageInvalid, CodeNoteLocationControl},
+		},
+		{name: "LongLocation", note: with(func(n *Note) { n.Body = "x"; n.Location = strings.Repeat("1", MaxNoteLocationLength+1) }), wantCodes: []string{CodeNoteLocationTooLong}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.note.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if tt.note.Type != tt.wantType {
+					t.Errorf("Type = %q, want %q", tt.note.Type, tt.wantType)
+				}
+				if strings.TrimSpace(tt.note.Body) != tt.note.Body {
+					t.Errorf("Body = %q, want it trimmed", tt.note.Body)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestParseNoteQuery(t *testing.T) {
+	query, err := ParseNoteQuery(`  "Whale" AND white-ness, 1851! `, "QUOTE")
+	if err != nil {
+		t.Fatalf("ParseNoteQuery() error = %v", err)
+	}
+	if want := []string{"whale", "and", "white", "ness", "1851"}; !slices.Equal(query.Terms, want) || query.Type != NoteQuote {
+		t.Errorf("ParseNoteQuery() = %+v, want terms %v and type quote", query, want)
+	}
+
+	if query, err := ParseNoteQuery("", ""); err != nil || len(query.Terms) != 0 || query.Type != "" {
+		t.Errorf("ParseNoteQuery() without anything = %+v (%v), want no filter", query, err)
+	}
+	if query, err := ParseNoteQuery("Ça ira * ?", ""); err != nil || !slices.Equal(query.Terms, []string{"ça", "ira"}) {
+		t.Errorf("ParseNoteQuery() = %+v (%v), want the words without the operators", query, err)
+	}
+
+	_, err = ParseNoteQuery(strings.Repeat("word ", MaxNoteSearchTerms+1), "bookmark")
+	ve, ok := AsValidationError(err)
+	if !ok || len(ve.Errors) != 2 || ve.Errors[0].Code != CodeNoteTypeInvalid || ve.Errors[1].Code != CodeNoteSearchTooLong {
+		t.Errorf("Expected type and q to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMissingRating     = errors.New("rating is missing")
+	ErrInvalidRating     = fmt.Errorf("invalid rating: must be %.1f to %.0f in steps of %.1f", RatingStep, MaxRating, RatingStep)
+	ErrReviewBodyTooLong = fmt.Errorf("body is too long: max %d characters", MaxReviewLength)
+	ErrInvalidReviewBook = errors.New("book_id is invalid")
+)
+
+const (
+	CodeReviewBookIDInvalid = "review.book_id_invalid"
+	CodeRatingMissing       = "review.rating_missing"
+	CodeRatingInvalid       = "review.rating_invalid"
+	CodeReviewBodyTooLong   = "review.body_too_long"
+)
+
+const (
+	RatingStep      = 0.5
+	MaxRating       = 5.0
+	MaxReviewLength = 20000
+)
+
+// Review is what we thought of a book once it was read, a star rating and optionally a few words about it.
+// A book has at most one review per user
+type Review struct {
+	BookID    string    `json:"book_id"`
+	Owner     string    `json:"owner,omitempty"` // NOTE: The user of the request, never taken from the body. Empty for the anonymous user
+	Rating    float64   `json:"rating"`
+	Body      string    `json:"body,omitempty"` // Markdown, stored and returned as written. Rendering is up to the client
+	CreatedAt time.Time `json:"created_at"`     // NOTE: Set by the store, never taken from the client
+	UpdatedAt time.Time `json:"updated_at"`     // NOTE: Set by the store, never taken from the client
+}
+
+// Validate sanitizes the review and checks every field. Whether the book may be rated at all is up to the
+// store, it depends on the stored status
+func (r *Review) Validate() error {
+	r.Body = strings.TrimSpace(r.Body)
+
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(r.BookID); err != nil {
+		ve.add("book_id", CodeReviewBookIDInvalid, ErrInvalidReviewBook)
+	}
+
+	// NOTE: 0 is what a missing rating decodes to, there is no zero star rating so no need for a pointer
+	switch {
+	case r.Rating == 0:
+		ve.add("rating", CodeRatingMissing, ErrMissingRating)
+	case r.Rating < RatingStep || r.Rating > MaxRating || math.Mod(r.Rating, RatingStep) != 0:
+		ve.add("rating", CodeRatingInvalid, ErrInvalidRating)
+	}
+
+	if utf8.RuneCountInString(r.Body) > MaxReviewLength {
+		ve.add("body", CodeReviewBodyTooLong, ErrReviewBodyTooLong)
+	}
+
+	return ve.errOrNil()
+}
+
+// RatingStats sums up the ratings for the stats. Average is null without any rating, the distribution
+// always has every half star from 0.5 to 5 so a chart needs no gap filling
+type RatingStats struct {
+	Average      *float64       `json:"average"`
+	Count        int            `json:"count"`
+	Distribution []RatingBucket `json:"distribution"`
+}
+
+type RatingBucket struct {
+	Rating float64 `json:"rating"`
+	Books  int     `json:"books"`
+}
+
+// NewRatingStats builds the rating stats from the number of books per rating. The average is rounded to
+// two decimals
+func NewRatingStats(counts map[float64]int) RatingStats {
+	stats := RatingStats{Distribution: make([]RatingBucket, 0, int(MaxRating/RatingStep))}
+	var sum float64
+	for rating := RatingStep; rating <= MaxRating; rating += RatingStep {
+		books := counts[rating]
+		stats.Distribution = append(stats.Distribution, RatingBucket{Rating: rating, Books: books})
+		stats.Count += books
+		sum += rating * float64(books)
+	}
+	if stats.Count > 0 {
+		average := math.Round(sum/float64(stats.Count)*100) / 100
+		stats.Average = &average
+	}
+	return stats
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestReview_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		review    *Review
+		wantCodes []string
+	}{
+		{name: "HalfStar", review: &Review{BookID: uuid.NewString(), Rating: 0.5}},
+		{name: "WithBody", review: &Review{BookID: uuid.NewString(), Rating: 4.5, Body: "  # Loved it\n\n- the ending  "}},
+		{name: "MissingRating", review: &Review{BookID: uuid.NewString(), Body: "No stars"}, wantCodes: []string{CodeRatingMissing}},
+		{name: "NotAHalfStep", review: &Review{BookID: uuid.NewString(), Rating: 3.7}, wantCodes: []string{CodeRatingInvalid}},
+		{name: "TooHigh", review: &Review{BookID: uuid.NewString(), Rating: 5.5}, wantCodes: []string{CodeRatingInvalid}},
+		{name: "Negative", review: &Review{BookID: uuid.NewString(), Rating: -1}, wantCodes: []string{CodeRatingInvalid}},
+		{
+			name:      "Invalid",
+			review:    &Review{BookID: "nope", Rating: 4, Body: strings.Repeat("x", MaxReviewLength+1)},
+			wantCodes: []string{CodeReviewBookIDInvalid, CodeReviewBodyTooLong},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.review.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if strings.TrimSpace(tt.review.Body) != tt.review.Body {
+					t.Errorf("Body = %q, want it trimmed", tt.review.Body)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRatingStats(t *testing.T) {
+	empty := NewRatingStats(nil)
+	if empty.Average != nil || empty.Count != 0 || len(empty.Distribution) != 10 {
+		t.Errorf("NewRatingStats(nil) = %+v, want no average and 10 empty buckets", empty)
+	}
+
+	stats := NewRatingStats(map[float64]int{5: 2, 4.5: 1, 1: 1})
+	if stats.Count != 4 || stats.Average == nil || *stats.Average != 3.88 {
+		t.Errorf("NewRatingStats = %+v, want 4 ratings averaging 3.88", stats)
+	}
+	if first, last := stats.Distribution[0], stats.Distribution[9]; first.Rating != 0.5 || first.Books != 0 || last.Rating != 5 || last.Books != 2 {
+		t.Errorf("Distribution = %+v, want 0.5 to 5 with 2 books at 5", stats.Distribution)
+	}
+	if bucket := stats.Distribution[1]; bucket.Rating != 1 || bucket.Books != 1 {
+		t.Errorf("Distribution[1] = %+v, want 1 book at 1 star", bucket)
+	}
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMissingSearchName      = errors.New("name is missing")
+	ErrSearchNameTooLong      = fmt.Errorf("name is too long: max %d characters", MaxSearchNameLength)
+	ErrSearchNameControlChars = errors.New("name contains control characters")
+)
+
+const (
+	CodeSearchIDInvalid        = "search.id_invalid"
+	CodeSearchNameMissing      = "search.name_missing"
+	CodeSearchNameTooLong      = "search.name_too_long"
+	CodeSearchNameControlChars = "search.name_control_characters"
+)
+
+const MaxSearchNameLength = 100
+
+// SavedSearch is a smart shelf, i.e. "unread books by Orwell tagged classics". Only the filter is stored,
+// the books are whatever matches it at the time it is read
+// NOTE: Unlike the shelves a saved search belongs to the user that made it, see middleware.User
+type SavedSearch struct {
+	ID        string     `json:"id"`
+	Owner     string     `json:"owner,omitempty"` // NOTE: The user of the request, never taken from the body. Empty for the anonymous user
+	Name      string     `json:"name"`
+	Filter    BookFilter `json:"filter"`
+	Books     int        `json:"books"`      // NOTE: Counted when the search is read, never taken from the client
+	CreatedAt time.Time  `json:"created_at"` // NOTE: Set by the store, never taken from the client
+}
+
+func (s *SavedSearch) GenerateID() error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("failed to generate id: %w", err)
+	}
+	s.ID = id.String()
+	return nil
+}
+
+// Validate sanitizes the search and checks every field, violations of the filter are reported as filter.<field>
+func (s *SavedSearch) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(s.ID); err != nil {
+		ve.add("id", CodeSearchIDInvalid, ErrInvalidID)
+	}
+
+	switch {
+	case s.Name == "":
+		ve.add("name", CodeSearchNameMissing, ErrMissingSearchName)
+	case utf8.RuneCountInString(s.Name) > MaxSearchNameLength:
+		ve.add("name", CodeSearchNameTooLong, ErrSearchNameTooLong)
+	case hasControlChars(s.Name):
+		ve.add("name", CodeSearchNameControlChars, ErrSearchNameControlChars)
+	}
+
+	ve.merge("filter.", s.Filter.Validate())
+
+	return ve.errOrNil()
+}
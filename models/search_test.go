@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestSavedSearch_Validate(t *testing.T) {
+	tests := []struct {
+		name       string
+		search     *SavedSearch
+		wantFields []string
+		wantCodes  []string
+	}{
+		{
+			name: "Valid",
+			search: &SavedSearch{ID: uuid.NewString(), Name: "Orwell classics", Filter: BookFilter{
+				Status: " Unread ", Author: "George Orwell", Tags: []string{"Classics"}, CompletedTo: "2026-12-31", Sort: "-Pages",
+			}},
+		},
+		{
+			name:       "MissingName",
+			search:     &SavedSearch{ID: uuid.NewString(), Name: " "},
+			wantFields: []string{"name"},
+			wantCodes:  []string{CodeSearchNameMissing},
+		},
+		{
+			name: "InvalidFilter",
+			search: &SavedSearch{ID: "nope", Name: "Broken", Filter: BookFilter{
+				Status: "lost", Tags: []string{"a,b"}, CompletedFrom: "2026-13-01", Sort: "rating",
+			}},
+			wantFields: []string{"id", "filter.status", "filter.tag[0]", "filter.completed_from", "filter.sort"},
+			wantCodes:  []string{CodeSearchIDInvalid, CodeStatusInvalid, CodeTagInvalid, CodeFilterDateInvalid, CodeFilterSortInvalid},
+		},
+		{
+			name:       "DatesOutOfOrder",
+			search:     &SavedSearch{ID: uuid.NewString(), Name: "Backwards", Filter: BookFilter{CompletedFrom: "2026-06-01", CompletedTo: "2026-05-31"}},
+			wantFields: []string{"filter.completed_to"},
+			wantCodes:  []string{CodeFilterDateOrder},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.search.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				f := tt.search.Filter
+				if f.Status != BookUnread || f.Tags[0] != "classics" || f.Sort != "-pages" {
+					t.Errorf("Filter = %+v, want it normalized", f)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code || ve.Errors[i].Field != tt.wantFields[i] {
+					t.Errorf("violation %d = %s on %s, want %s on %s", i, ve.Errors[i].Code, ve.Errors[i].Field, code, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBookFilter_CompletedRange(t *testing.T) {
+	filter := BookFilter{CompletedFrom: "2026-03-01", CompletedTo: "2026-03-31"}
+	from, to := filter.CompletedRange()
+	if !from.Equal(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)) || !to.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("CompletedRange = %v - %v, want March 2026 with the last day included", from, to)
+	}
+	if from, to := (&BookFilter{}).CompletedRange(); from != nil || to != nil {
+		t.Errorf("CompletedRange of an empty filter = %v - %v, want nil", from, to)
+	}
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMissingSeriesName        = errors.New("name is missing")
+	ErrSeriesNameTooLong        = fmt.Errorf("name is too long: max %d characters", MaxSeriesNameLength)
+	ErrSeriesNameControlChars   = errors.New("name contains control characters")
+	ErrSeriesDescriptionTooLong = fmt.Errorf("description is too long: max %d characters", MaxSeriesDescriptionLength)
+	ErrMissingSeriesPosition    = errors.New("position is missing")
+	ErrInvalidSeriesPosition    = errors.New("position cannot be negative")
+	ErrInvalidSeriesBookID      = errors.New("book_id is invalid")
+)
+
+const (
+	CodeSeriesIDInvalid          = "series.id_invalid"
+	CodeSeriesNameMissing        = "series.name_missing"
+	CodeSeriesNameTooLong        = "series.name_too_long"
+	CodeSeriesNameControlChars   = "series.name_control_characters"
+	CodeSeriesDescriptionTooLong = "series.description_too_long"
+	CodeSeriesPositionMissing    = "series.position_missing"
+	CodeSeriesPositionInvalid    = "series.position_invalid"
+	CodeSeriesBookIDInvalid      = "series.book_id_invalid"
+)
+
+const (
+	MaxSeriesNameLength        = 200
+	MaxSeriesDescriptionLength = 1000
+)
+
+// Series is a run of books meant to be read in order, i.e. "The Expanse". Unlike a shelf a book can only
+// be in one series
+type Series struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Books       int       `json:"books"`      // NOTE: Set by the store, never taken from the client
+	CreatedAt   time.Time `json:"created_at"` // NOTE: Set by the store, never taken from the client
+}
+
+func (s *Series) GenerateID() error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("failed to generate id: %w", err)
+	}
+	s.ID = id.String()
+	return nil
+}
+
+// Validate sanitizes the series and checks every field
+func (s *Series) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Description = strings.TrimSpace(s.Description)
+
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(s.ID); err != nil {
+		ve.add("id", CodeSeriesIDInvalid, ErrInvalidID)
+	}
+
+	switch {
+	case s.Name == "":
+		ve.add("name", CodeSeriesNameMissing, ErrMissingSeriesName)
+	case utf8.RuneCountInString(s.Name) > MaxSeriesNameLength:
+		ve.add("name", CodeSeriesNameTooLong, ErrSeriesNameTooLong)
+	case hasControlChars(s.Name):
+		ve.add("name", CodeSeriesNameControlChars, ErrSeriesNameControlChars)
+	}
+
+	if utf8.RuneCountInString(s.Description) > MaxSeriesDescriptionLength {
+		ve.add("description", CodeSeriesDescriptionTooLong, ErrSeriesDescriptionTooLong)
+	}
+
+	return ve.errOrNil()
+}
+
+// BookSeries is the place of a book in its series as it shows up on the book
+type BookSeries struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Position float64 `json:"position"`
+}
+
+// SeriesVolume puts a book into a series or moves it within one. Positions can be fractional, i.e. 2.5
+// for a novella between the second and third volume
+type SeriesVolume struct {
+	BookID   string   `json:"book_id"`
+	Position *float64 `json:"position"`
+}
+
+func (v *SeriesVolume) Validate() error {
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(v.BookID); err != nil {
+		ve.add("book_id", CodeSeriesBookIDInvalid, ErrInvalidSeriesBookID)
+	}
+
+	switch {
+	case v.Position == nil:
+		ve.add("position", CodeSeriesPositionMissing, ErrMissingSeriesPosition)
+	case *v.Position < 0:
+		ve.add("position", CodeSeriesPositionInvalid, ErrInvalidSeriesPosition)
+	}
+
+	return ve.errOrNil()
+}
+
+// SeriesDetail is a series with its volumes in reading order
+type SeriesDetail struct {
+	Series
+	Volumes    []*Book `json:"volumes"`
+	NextUnread *Book   `json:"next_unread"` // null once there is nothing left to read
+}
+
+// NextUnread returns the volume to read next from volumes in series order: the first unread one after
+// the furthest volume that was started or finished. Without one there (i.e. the latest volume is done but
+// an earlier one was skipped) it falls back to the first unread volume, nil when everything was read
+func NextUnread(volumes []*Book) *Book {
+	furthest := -1
+	for i, book := range volumes {
+		if book.Status != BookUnread {
+			furthest = i
+		}
+	}
+	for _, book := range volumes[furthest+1:] {
+		if book.Status == BookUnread {
+			return book
+		}
+	}
+	for _, book := range volumes {
+		if book.Status == BookUnread {
+			return book
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSeries_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		series    *Series
+		wantCodes []string
+	}{
+		{name: "Valid", series: &Series{ID: uuid.NewString(), Name: "  The Expanse ", Description: "Line one\nLine two"}},
+		{name: "MissingName", series: &Series{ID: uuid.NewString(), Name: "   "}, wantCodes: []string{CodeSeriesNameMissing}},
+		{name: "NameControlChars", series: &Series{ID: uuid.NewString(), Name: "The\tExpanse"}, wantCodes: []string{CodeSeriesNameControlChars}},
+		{
+			name:      "Invalid",
+			series:    &Series{ID: "nope", Name: strings.Repeat("x", MaxSeriesNameLength+1), Description: strings.Repeat("x", MaxSeriesDescriptionLength+1)},
+			wantCodes: []string{CodeSeriesIDInvalid, CodeSeriesNameTooLong, CodeSeriesDescriptionTooLong},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.series.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if tt.series.Name != "The Expanse" {
+					t.Errorf("Name = %q, want it trimmed", tt.series.Name)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestSeriesVolume_Validate(t *testing.T) {
+	at := func(position float64) *float64 { return &position }
+
+	tests := []struct {
+		name      string
+		volume    SeriesVolume
+		wantCodes []string
+	}{
+		{name: "Fractional", volume: SeriesVolume{BookID: uuid.NewString(), Position: at(2.5)}},
+		{name: "Prequel", volume: SeriesVolume{BookID: uuid.NewString(), Position: at(0)}},
+		{name: "MissingPosition", volume: SeriesVolume{BookID: uuid.NewString()}, wantCodes: []string{CodeSeriesPositionMissing}},
+		{name: "Invalid", volume: SeriesVolume{BookID: "nope", Position: at(-1)}, wantCodes: []string{CodeSeriesBookIDInvalid, CodeSeriesPositionInvalid}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.volume.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestNextUnread(t *testing.T) {
+	volumes := func(statuses ...BookStatus) []*Book {
+		books := make([]*Book, len(statuses))
+		for i, status := range statuses {
+			books[i] = &Book{Title: string(rune('A' + i)), Status: status}
+		}
+		return books
+	}
+
+	tests := []struct {
+		name    string
+		volumes []*Book
+		want    string // Title of the next volume, empty for none
+	}{
+		{name: "Empty", volumes: nil},
+		{name: "NothingStarted", volumes: volumes(BookUnread, BookUnread), want: "A"},
+		{name: "AfterCompleted", volumes: volumes(BookComplete, BookUnread, BookUnread), want: "B"},
+		{name: "AfterReading", volumes: volumes(BookComplete, BookReading, BookUnread), want: "C"},
+		{name: "SkippedVolume", volumes: volumes(BookUnread, BookComplete, BookUnread), want: "C"},
+		{name: "OnlySkippedLeft", volumes: volumes(BookUnread, BookComplete, BookComplete), want: "A"},
+		{name: "AllRead", volumes: volumes(BookComplete, BookComplete), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NextUnread(tt.volumes)
+			switch {
+			case got == nil && tt.want != "":
+				t.Errorf("NextUnread() = nil, want %s", tt.want)
+			case got != nil && got.Title != tt.want:
+				t.Errorf("NextUnread() = %s, want %q", got.Title, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMissingShelfName        = errors.New("name is missing")
+	ErrShelfNameTooLong        = fmt.Errorf("name is too long: max %d characters", MaxShelfNameLength)
+	ErrShelfNameControlChars   = errors.New("name contains control characters")
+	ErrShelfDescriptionTooLong = fmt.Errorf("description is too long: max %d characters", MaxShelfDescriptionLength)
+	ErrInvalidShelfPosition    = errors.New("position cannot be negative")
+	ErrMissingShelfPosition    = errors.New("position is missing")
+	ErrInvalidShelfBookID      = errors.New("book_id is invalid")
+)
+
+const (
+	CodeShelfIDInvalid          = "shelf.id_invalid"
+	CodeShelfNameMissing        = "shelf.name_missing"
+	CodeShelfNameTooLong        = "shelf.name_too_long"
+	CodeShelfNameControlChars   = "shelf.name_control_characters"
+	CodeShelfDescriptionTooLong = "shelf.description_too_long"
+	CodeShelfPositionInvalid    = "shelf.position_invalid"
+	CodeShelfPositionMissing    = "shelf.position_missing"
+	CodeShelfBookIDInvalid      = "shelf.book_id_invalid"
+)
+
+const (
+	MaxShelfNameLength        = 100
+	MaxShelfDescriptionLength = 1000
+)
+
+// Shelf is a hand made list of books, i.e. "Team book club 2026". A book can be on any number of shelves
+// and every shelf keeps its own order
+type Shelf struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Books       int       `json:"books"`      // NOTE: Set by the store, never taken from the client
+	CreatedAt   time.Time `json:"created_at"` // NOTE: Set by the store, never taken from the client
+}
+
+func (s *Shelf) GenerateID() error {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return fmt.Errorf("failed to generate id: %w", err)
+	}
+	s.ID = id.String()
+	return nil
+}
+
+// Validate sanitizes the shelf and checks every field
+func (s *Shelf) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Description = strings.TrimSpace(s.Description)
+
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(s.ID); err != nil {
+		ve.add("id", CodeShelfIDInvalid, ErrInvalidID)
+	}
+
+	switch {
+	case s.Name == "":
+		ve.add("name", CodeShelfNameMissing, ErrMissingShelfName)
+	case utf8.RuneCountInString(s.Name) > MaxShelfNameLength:
+		ve.add("name", CodeShelfNameTooLong, ErrShelfNameTooLong)
+	case hasControlChars(s.Name):
+		ve.add("name", CodeShelfNameControlChars, ErrShelfNameControlChars)
+	}
+
+	// NOTE: Unlike the name the description can span several lines
+	if utf8.RuneCountInString(s.Description) > MaxShelfDescriptionLength {
+		ve.add("description", CodeShelfDescriptionTooLong, ErrShelfDescriptionTooLong)
+	}
+
+	return ve.errOrNil()
+}
+
+// ShelfPlacement puts a book on a shelf or moves it there. Position counts from 0 at the top of the shelf,
+// without one the book goes to the bottom. A position past the bottom is the bottom
+type ShelfPlacement struct {
+	BookID   string `json:"book_id"`
+	Position *int   `json:"position,omitempty"`
+}
+
+// Validate checks the placement, requirePosition is for moves where leaving it out means nothing
+func (p *ShelfPlacement) Validate(requirePosition bool) error {
+	ve := &ValidationError{}
+
+	if _, err := uuid.Parse(p.BookID); err != nil {
+		ve.add("book_id", CodeShelfBookIDInvalid, ErrInvalidShelfBookID)
+	}
+
+	switch {
+	case p.Position == nil && requirePosition:
+		ve.add("position", CodeShelfPositionMissing, ErrMissingShelfPosition)
+	case p.Position != nil && *p.Position < 0:
+		ve.add("position", CodeShelfPositionInvalid, ErrInvalidShelfPosition)
+	}
+
+	return ve.errOrNil()
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestShelf_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		shelf     *Shelf
+		wantCodes []string
+	}{
+		{name: "Valid", shelf: &Shelf{ID: uuid.NewString(), Name: "  Book club 2026 ", Description: "Line one\nLine two"}},
+		{name: "MissingName", shelf: &Shelf{ID: uuid.NewString(), Name: "   "}, wantCodes: []string{CodeShelfNameMissing}},
+		{name: "NameControlChars", shelf: &Shelf{ID: uuid.NewString(), Name: "Book\nclub"}, wantCodes: []string{CodeShelfNameControlChars}},
+		{
+			name:      "Invalid",
+			shelf:     &Shelf{ID: "nope", Name: strings.Repeat("x", MaxShelfNameLength+1), Description: strings.Repeat("x", MaxShelfDescriptionLength+1)},
+			wantCodes: []string{CodeShelfIDInvalid, CodeShelfNameTooLong, CodeShelfDescriptionTooLong},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.shelf.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if tt.shelf.Name != "Book club 2026" {
+					t.Errorf("Name = %q, want it trimmed", tt.shelf.Name)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
+
+func TestShelfPlacement_Validate(t *testing.T) {
+	at := func(position int) *int { return &position }
+
+	tests := []struct {
+		name            string
+		placement       ShelfPlacement
+		requirePosition bool
+		wantCodes       []string
+	}{
+		{name: "AddToBottom", placement: ShelfPlacement{BookID: uuid.NewString()}},
+		{name: "AddAtTop", placement: ShelfPlacement{BookID: uuid.NewString(), Position: at(0)}},
+		{name: "MoveWithoutPosition", placement: ShelfPlacement{BookID: uuid.NewString()}, requirePosition: true, wantCodes: []string{CodeShelfPositionMissing}},
+		{name: "Invalid", placement: ShelfPlacement{BookID: "nope", Position: at(-1)}, wantCodes: []string{CodeShelfBookIDInvalid, CodeShelfPositionInvalid}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.placement.Validate(tt.requirePosition)
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok || len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.wantCodes)
+			}
+			for i, code := range tt.wantCodes {
+				if ve.Errors[i].Code != code {
+					t.Errorf("violation %d code = %s, want %s", i, ve.Errors[i].Code, code)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package models
 
 type Stats struct {
-	TotalRead       int    `json:"total_read"`
-	ReadingProgress int    `json:"reading_progress"`
-	PopularAuthor   string `json:"popular_author"`
+	TotalRead       int            `json:"total_read"`
+	TotalAbandoned  int            `json:"total_abandoned"`
+	ReadingProgress int            `json:"reading_progress"`
+	TopAuthors      []AuthorStats  `json:"top_authors"` // nil (null) when there are no books
+	Ratings         RatingStats    `json:"ratings"`
+	Goals           []GoalProgress `json:"goals"` // Only the goals whose period includes today
 }
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidTag   = errors.New("invalid tag: letters, digits and - _ . + # only")
+	ErrTagTooLong   = fmt.Errorf("tag is too long: max %d characters", MaxTagLength)
+	ErrTooManyTags  = fmt.Errorf("too many tags: max %d per book", MaxTagsPerBook)
+	ErrTagMergeSelf = errors.New("a tag cannot be merged into itself")
+)
+
+const (
+	CodeTagInvalid   = "tag.invalid"
+	CodeTagTooLong   = "tag.too_long"
+	CodeTooManyTags  = "book.too_many_tags"
+	CodeTagMergeSelf = "tag.merge_self"
+)
+
+const (
+	MaxTagLength   = 50
+	MaxTagsPerBook = 20
+)
+
+// TagCount is a tag with the number of books that have it
+type TagCount struct {
+	Name  string `json:"name"`
+	Books int    `json:"books"`
+}
+
+// NormalizeTag is how every tag is stored and compared: trimmed, lower case and with the whitespace
+// inside turned into dashes, i.e. "Distributed Systems" is "distributed-systems"
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// validateTag checks an already normalized tag and returns the code and error of the first problem
+func validateTag(tag string) (string, error) {
+	switch {
+	case tag == "":
+		return CodeTagInvalid, ErrInvalidTag
+	case utf8.RuneCountInString(tag) > MaxTagLength:
+		return CodeTagTooLong, ErrTagTooLong
+	}
+	for _, r := range tag {
+		// NOTE: # and + are for c#, c++ and the like. A comma is left out as the filters use it as a separator
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.+#", r) {
+			return CodeTagInvalid, ErrInvalidTag
+		}
+	}
+	return "", nil
+}
+
+// ParseTag normalizes and validates a single tag, i.e. from the path of the tag endpoints
+func ParseTag(field, tag string) (string, error) {
+	tag = NormalizeTag(tag)
+	if code, err := validateTag(tag); err != nil {
+		ve := &ValidationError{}
+		ve.add(field, code, err)
+		return "", ve
+	}
+	return tag, nil
+}
+
+// normalizeTags normalizes, sorts and deduplicates tags in place and adds a violation per invalid one
+func normalizeTags(ve *ValidationError, field string, tags []string) []string {
+	for i := range tags {
+		tags[i] = NormalizeTag(tags[i])
+		if code, err := validateTag(tags[i]); err != nil {
+			ve.add(fmt.Sprintf("%s[%d]", field, i), code, err)
+		}
+	}
+	slices.Sort(tags)
+	return slices.Compact(tags)
+}
+
+// ParseTagMerge parses both tags of a merge, from comes from the path and into from the body
+func ParseTagMerge(from, into string) (string, string, error) {
+	ve := &ValidationError{}
+	from, err := ParseTag("tag", from)
+	ve.merge("", err)
+	into, err = ParseTag("into", into)
+	ve.merge("", err)
+	if err := ve.errOrNil(); err != nil {
+		return "", "", err
+	}
+	if from == into {
+		ve.add("into", CodeTagMergeSelf, ErrTagMergeSelf)
+	}
+	return from, into, ve.errOrNil()
+}
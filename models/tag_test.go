@@ -0,0 +1,88 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBook_ValidateTags(t *testing.T) {
+	tooMany := make([]string, MaxTagsPerBook+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	tests := []struct {
+		name      string
+		tags      []string
+		want      []string
+		wantCodes []string
+		wantField string
+	}{
+		{name: "NormalizedSortedDeduplicated", tags: []string{" Sci Fi ", "classic", "sci-fi", "C#"}, want: []string{"c#", "classic", "sci-fi"}},
+		{name: "Empty", tags: []string{"classic", "  "}, wantCodes: []string{CodeTagInvalid}, wantField: "tags[1]"},
+		{name: "Comma", tags: []string{"a,b"}, wantCodes: []string{CodeTagInvalid}, wantField: "tags[0]"},
+		{name: "TooLong", tags: []string{strings.Repeat("x", MaxTagLength+1)}, wantCodes: []string{CodeTagTooLong}, wantField: "tags[0]"},
+		{name: "TooMany", tags: tooMany, wantCodes: []string{CodeTooManyTags}, wantField: "tags"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := &Book{ID: uuid.NewString(), Title: "Emma", Author: "Jane Austen", Status: BookUnread, Tags: tt.tags}
+			err := book.Validate()
+			if tt.wantCodes == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				if !slices.Equal(book.Tags, tt.want) {
+					t.Errorf("Tags = %v, want %v", book.Tags, tt.want)
+				}
+				return
+			}
+			ve, ok := AsValidationError(err)
+			if !ok {
+				t.Fatalf("Validate() error = %v, want a *ValidationError", err)
+			}
+			if len(ve.Errors) != len(tt.wantCodes) {
+				t.Fatalf("Validate() returned %d violations (%v), want %d", len(ve.Errors), ve, len(tt.wantCodes))
+			}
+			if ve.Errors[0].Code != tt.wantCodes[0] || ve.Errors[0].Field != tt.wantField {
+				t.Errorf("violation = %s on %s, want %s on %s", ve.Errors[0].Code, ve.Errors[0].Field, tt.wantCodes[0], tt.wantField)
+			}
+		})
+	}
+}
+
+func TestParseTagMerge(t *testing.T) {
+	from, into, err := ParseTagMerge("Sci Fi", "science-fiction")
+	if err != nil || from != "sci-fi" || into != "science-fiction" {
+		t.Errorf("ParseTagMerge = %q, %q, %v, want sci-fi, science-fiction", from, into, err)
+	}
+
+	_, _, err = ParseTagMerge("Sci Fi", "sci-fi")
+	if ve, ok := AsValidationError(err); !ok || ve.Errors[0].Code != CodeTagMergeSelf {
+		t.Errorf("ParseTagMerge into itself error = %v, want %s", err, CodeTagMergeSelf)
+	}
+
+	_, _, err = ParseTagMerge("", "a,b")
+	if ve, ok := AsValidationError(err); !ok || len(ve.Errors) != 2 {
+		t.Errorf("ParseTagMerge error = %v, want a violation for both tags", err)
+	}
+}
+
+func TestBookFilter_Validate(t *testing.T) {
+	filter := BookFilter{Tags: []string{"Distributed Systems", "go"}, AnyTags: []string{"x", "X"}}
+	if err := filter.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if !slices.Equal(filter.Tags, []string{"distributed-systems", "go"}) || !slices.Equal(filter.AnyTags, []string{"x"}) {
+		t.Errorf("filter = %+v, want normalized tags", filter)
+	}
+
+	filter = BookFilter{AnyTags: []string{"ok", "not ok!"}}
+	if ve, ok := AsValidationError(filter.Validate()); !ok || ve.Errors[0].Field != "tag_any[1]" {
+		t.Errorf("Validate() = %v, want a violation on tag_any[1]", ve)
+	}
+}
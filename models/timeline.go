@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidGranularity = errors.New("invalid granularity: must be week, month or year")
+	ErrInvalidDateRange   = errors.New("invalid date range: from and to must be YYYY-MM-DD and from must not be after to")
+	ErrTooManyBuckets     = errors.New("date range too large for the chosen granularity")
+)
+
+type Granularity string
+
+const (
+	GranularityWeek  Granularity = "week"
+	GranularityMonth Granularity = "month"
+	GranularityYear  Granularity = "year"
+)
+
+// NOTE: Upper bound so a request like granularity=week&from=0001-01-01 cant make the database generate
+// thousands of rows
+const MaxTimelineBuckets = 520
+
+// Default number of buckets when no from is given, i.e. the last 12 months
+const defaultTimelineBuckets = 12
+
+const (
+	CodeTimelineGranularity = "timeline.granularity_invalid"
+	CodeTimelineRange       = "timeline.range_invalid"
+	CodeTimelineTooLarge    = "timeline.range_too_large"
+)
+
+func ParseGranularity(s string) (Granularity, error) {
+	switch g := Granularity(strings.ToLower(strings.TrimSpace(s))); g {
+	case GranularityWeek, GranularityMonth, GranularityYear:
+		return g, nil
+	case "":
+		return GranularityMonth, nil
+	default:
+		return "", ErrInvalidGranularity
+	}
+}
+
+// BucketStart returns the first day of the bucket t falls in. Weeks start on Monday (ISO 8601)
+func (g Granularity) BucketStart(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	switch g {
+	case GranularityWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // Monday = 0
+		return day.AddDate(0, 0, -offset)
+	case GranularityYear:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// Next returns the start of the bucket after the one starting at start
+func (g Granularity) Next(start time.Time) time.Time {
+	switch g {
+	case GranularityWeek:
+		return start.AddDate(0, 0, 7)
+	case GranularityYear:
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// TimelineBucket holds the reading activity for one week, month or year starting at Start
+type TimelineBucket struct {
+	Start     string `json:"start"`
+	Started   int    `json:"started"`
+	Finished  int    `json:"finished"`
+	PagesRead int    `json:"pages_read"`
+}
+
+type Timeline struct {
+	Granularity Granularity      `json:"granularity"`
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Buckets     []TimelineBucket `json:"buckets"`
+}
+
+// TimelineQuery is a validated timeline request. From is aligned to the start of its bucket and To is inclusive
+type TimelineQuery struct {
+	Granularity Granularity
+	From        time.Time
+	To          time.Time
+}
+
+// ParseTimelineQuery validates the raw query parameters. Empty values fall back to the last 12 buckets up to today
+func ParseTimelineQuery(granularity, from, to string, now time.Time) (TimelineQuery, error) {
+	ve := &ValidationError{}
+	q := TimelineQuery{}
+
+	g, err := ParseGranularity(granularity)
+	if err != nil {
+		ve.add("granularity", CodeTimelineGranularity, err)
+		return q, ve
+	}
+	q.Granularity = g
+
+	y, m, d := now.UTC().Date()
+	q.To = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	if to != "" {
+		if q.To, err = time.Parse(DateLayout, to); err != nil {
+			ve.add("to", CodeTimelineRange, ErrInvalidDateRange)
+		}
+	}
+	if from != "" {
+		if q.From, err = time.Parse(DateLayout, from); err != nil {
+			ve.add("from", CodeTimelineRange, ErrInvalidDateRange)
+		}
+	} else {
+		q.From = g.BucketStart(q.To)
+		for i := 1; i < defaultTimelineBuckets; i++ {
+			q.From = g.BucketStart(q.From.AddDate(0, 0, -1))
+		}
+	}
+	if len(ve.Errors) > 0 {
+		return q, ve
+	}
+	if q.From.After(q.To) {
+		ve.add("from", CodeTimelineRange, ErrInvalidDateRange)
+		return q, ve
+	}
+
+	q.From = g.BucketStart(q.From)
+	buckets := 0
+	for start := q.From; !start.After(q.To); start = g.Next(start) {
+		if buckets++; buckets > MaxTimelineBuckets {
+			ve.add("from", CodeTimelineTooLarge, ErrTooManyBuckets)
+			return q, ve
+		}
+	}
+	return q, nil
+}
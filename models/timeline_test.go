@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimelineQuery(t *testing.T) {
+	now := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC) // A Monday
+
+	tests := []struct {
+		name                  string
+		granularity, from, to string
+		wantFrom, wantTo      string
+		wantCode              string
+	}{
+		{name: "Defaults", wantFrom: "2025-11-01", wantTo: "2026-10-19"},
+		{name: "WeekAlignsToMonday", granularity: "week", from: "2026-10-15", to: "2026-10-19", wantFrom: "2026-10-12", wantTo: "2026-10-19"},
+		{name: "Year", granularity: "YEAR", from: "2020-06-01", to: "2026-01-01", wantFrom: "2020-01-01", wantTo: "2026-01-01"},
+		{name: "InvalidGranularity", granularity: "day", wantCode: CodeTimelineGranularity},
+		{name: "InvalidDate", from: "yesterday", wantCode: CodeTimelineRange},
+		{name: "FromAfterTo", from: "2026-10-20", to: "2026-10-01", wantCode: CodeTimelineRange},
+		{name: "TooManyBuckets", granularity: "week", from: "1990-01-01", to: "2026-01-01", wantCode: CodeTimelineTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseTimelineQuery(tt.granularity, tt.from, tt.to, now)
+			if tt.wantCode != "" {
+				ve, ok := AsValidationError(err)
+				if !ok || ve.Errors[0].Code != tt.wantCode {
+					t.Fatalf("ParseTimelineQuery() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTimelineQuery() error = %v", err)
+			}
+			if got := q.From.Format(DateLayout); got != tt.wantFrom {
+				t.Errorf("From = %s, want %s", got, tt.wantFrom)
+			}
+			if got := q.To.Format(DateLayout); got != tt.wantTo {
+				t.Errorf("To = %s, want %s", got, tt.wantTo)
+			}
+		})
+	}
+}
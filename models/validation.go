@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// Stable codes for field violations. These end up in the API responses so never change them
+const (
+	CodeIDMissing          = "book.id_missing"
+	CodeIDInvalid          = "book.id_invalid"
+	CodeTitleMissing       = "book.title_missing"
+	CodeTitleTooLong       = "book.title_too_long"
+	CodeTitleControlChars  = "book.title_control_characters"
+	CodeAuthorMissing      = "book.author_missing"
+	CodeAuthorTooLong      = "book.author_too_long"
+	CodeAuthorControlChars = "book.author_control_characters"
+	CodeStatusMissing      = "book.status_missing"
+	CodeStatusInvalid      = "book.status_invalid"
+	CodePagesInvalid       = "book.pages_invalid"
+)
+
+// FieldError is a single violation. Err is the sentinel (i.e. ErrMissingTitle) so callers can still use errors.Is
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+	Err     error
+}
+
+// ValidationError collects every violation found instead of stopping at the first one,
+// so a form with several problems can show all of them at once
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// NOTE: Go 1.20+ lets errors.Is and errors.As look through all of these
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe.Err
+	}
+	return errs
+}
+
+func (e *ValidationError) add(field, code string, err error) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Code: code, Message: err.Error(), Err: err})
+}
+
+// merge adds the violations of a nested Validate with their fields prefixed, i.e. books[3].title
+func (e *ValidationError) merge(prefix string, err error) {
+	if nested, ok := AsValidationError(err); ok {
+		for _, fe := range nested.Errors {
+			fe.Field = prefix + fe.Field
+			e.Errors = append(e.Errors, fe)
+		}
+	}
+}
+
+// errOrNil makes sure a ValidationError without violations is returned as a plain nil error
+func (e *ValidationError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// AsValidationError is a small helper around errors.As
+func AsValidationError(err error) (*ValidationError, bool) {
+	var ve *ValidationError
+	ok := errors.As(err, &ve)
+	return ve, ok
+}
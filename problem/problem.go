@@ -0,0 +1,74 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// NOTE: RFC 9457 Problem Details for HTTP APIs. Documentation: https://www.rfc-editor.org/rfc/rfc9457
+const ContentType = "application/problem+json"
+
+// Problem is the body of every error response. Code is a stable machine readable identifier
+// (i.e. book.title_missing) that clients can switch on instead of parsing Detail
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError points at a single invalid field of the request
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Generic codes used by more than one package
+const (
+	CodeNotFound         = "request.not_found"
+	CodeMethodNotAllowed = "request.method_not_allowed"
+	CodeTimeout          = "request.timeout"
+	CodeInternal         = "internal"
+)
+
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "urn:book-tracker:problem:" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func (p *Problem) Write(w http.ResponseWriter, r *http.Request) {
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Write is a shortcut for New(...).Write(w, r)
+func Write(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	New(status, code, detail).Write(w, r)
+}
+
+// NotFound is used as the catch-all handler so unknown paths get a problem body instead of plain text
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusNotFound, CodeNotFound, "no route matches "+r.URL.Path)
+}
+
+// MethodNotAllowed answers for methods a path does not support.
+// NOTE: Register it on the method-less pattern, http.ServeMux prefers the patterns with a method
+func MethodNotAllowed(allowed string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allowed)
+		Write(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not supported, use "+allowed)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"book-tracker/handlers"
+	"book-tracker/middleware"
+	"book-tracker/problem"
+)
+
+// SetupAdminRoutes registers the admin endpoints, every one of them requires the admin token
+func SetupAdminRoutes(mux *http.ServeMux, handler *handlers.BackupHandler, token string) {
+	auth := middleware.RequireToken(token)
+
+	// Note:
+	// Handle POST and GET /api/v1/admin/backups i.e. take a backup now and list the ones in the backup directory
+	mux.Handle("POST /api/v1/admin/backups", auth(http.HandlerFunc(handler.CreateBackup)))
+	mux.Handle("GET /api/v1/admin/backups", auth(http.HandlerFunc(handler.ListBackups)))
+	mux.HandleFunc("/api/v1/admin/backups", problem.MethodNotAllowed("GET, POST"))
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"book-tracker/handlers"
+	"book-tracker/problem"
+)
+
+func SetupArchiveRoutes(mux *http.ServeMux, handler *handlers.ArchiveHandler) {
+	// Note:
+	// Handle GET /api/v1/export i.e. the whole library as one JSON archive
+	mux.HandleFunc("GET /api/v1/export", handler.Export)
+	mux.HandleFunc("/api/v1/export", problem.MethodNotAllowed("GET"))
+
+	// Note:
+	// Handle POST /api/v1/import?mode=merge|replace&ids=keep|new with an archive from GET /api/v1/export
+	mux.HandleFunc("POST /api/v1/import", handler.Import)
+	mux.HandleFunc("/api/v1/import", problem.MethodNotAllowed("POST"))
+}
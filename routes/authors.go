@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupAuthorsRoutes(mux *http.ServeMux, handler *handlers.AuthorHandler) {
+	// NOTE:
+	// Handle GET and POST /api/v1/authors. Authors are also created on the fly by the books that name them
+	mux.HandleFunc("GET /api/v1/authors", handler.ListAuthors)
+	mux.HandleFunc("POST /api/v1/authors", handler.CreateAuthor)
+	mux.HandleFunc("/api/v1/authors", problem.MethodNotAllowed("GET, POST"))
+
+	// NOTE:
+	// Handle GET, PUT and DELETE /api/v1/authors/{id}.
+	mux.HandleFunc("GET /api/v1/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetAuthor(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateAuthor(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteAuthor(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/authors/{id}", problem.MethodNotAllowed("GET, PUT, DELETE"))
+
+	// NOTE:
+	// Handle POST /api/v1/authors/{id}/merge.
+	mux.HandleFunc("POST /api/v1/authors/{id}/merge", func(w http.ResponseWriter, r *http.Request) {
+		handler.MergeAuthors(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/authors/{id}/merge", problem.MethodNotAllowed("POST"))
+}
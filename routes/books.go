@@ -2,45 +2,32 @@ package routes
 
 import (
 	"book-tracker/handlers"
+	"book-tracker/problem"
 	"net/http"
-	"strings"
 )
 
+// NOTE:
+// Using the method and wildcard patterns of http.ServeMux (Go 1.22+) so the matched pattern
+// is available on the request. The metrics middleware uses it as a label instead of the raw path
 func SetupBooksRoutes(mux *http.ServeMux, handler *handlers.BookHandler) {
 	// NOTE:
 	// Handle POST and GET /api/v1/books.
-	mux.HandleFunc("/api/v1/books", func(w http.ResponseWriter, r *http.Request) { // NOTE: this version can later be linked to config and not hardcoded
-		switch r.Method {
-		case "POST":
-			handler.CreateBook(w, r)
-		case "GET":
-			handler.ListBooks(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	mux.HandleFunc("POST /api/v1/books", handler.CreateBook) // NOTE: this version can later be linked to config and not hardcoded
+	mux.HandleFunc("GET /api/v1/books", handler.ListBooks)
+	mux.HandleFunc("/api/v1/books", problem.MethodNotAllowed("GET, POST"))
 
 	// NOTE:
 	// Handle PUT and DELETE /api/v1/books/{id}.
-	mux.HandleFunc("/api/v1/books/", func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-		if !strings.HasPrefix(path, "/api/v1/books/") { // NOTE: this version can later be linked to config and not hardcoded
-			http.Error(w, "Not found", http.StatusNotFound)
-			return
-		}
-		id := strings.TrimPrefix(path, "/api/v1/books/")
-		if id == "" {
-			http.Error(w, "Book ID required", http.StatusBadRequest)
-			return
-		}
-
-		switch r.Method {
-		case "PUT":
-			handler.UpdateBook(w, r, id)
-		case "DELETE":
-			handler.DeleteBook(w, r, id)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
+	mux.HandleFunc("PUT /api/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateBook(w, r, r.PathValue("id"))
 	})
+	mux.HandleFunc("DELETE /api/v1/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteBook(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/books/{id}", problem.MethodNotAllowed("PUT, DELETE"))
+
+	// NOTE: {id} never matches an empty segment so /api/v1/books/ would otherwise be a 404.
+	// Registered per method as a method-less pattern makes the mux redirect /api/v1/books to it
+	mux.HandleFunc("PUT /api/v1/books/{$}", handlers.MissingBookID)
+	mux.HandleFunc("DELETE /api/v1/books/{$}", handlers.MissingBookID)
 }
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupClippingsRoutes(mux *http.ServeMux, handler *handlers.ClippingsHandler) {
+	// NOTE:
+	// Handle POST /api/v1/import/kindle?create=true|false with a My Clippings.txt as the body
+	mux.HandleFunc("POST /api/v1/import/kindle", handler.ImportKindle)
+	mux.HandleFunc("/api/v1/import/kindle", problem.MethodNotAllowed("POST"))
+}
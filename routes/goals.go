@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupGoalsRoutes(mux *http.ServeMux, handler *handlers.GoalHandler) {
+	// NOTE:
+	// Handle POST and GET /api/v1/goals. GET supports ?active=true
+	mux.HandleFunc("POST /api/v1/goals", handler.CreateGoal)
+	mux.HandleFunc("GET /api/v1/goals", handler.ListGoals)
+	mux.HandleFunc("/api/v1/goals", problem.MethodNotAllowed("GET, POST"))
+
+	// NOTE:
+	// Handle GET, PUT and DELETE /api/v1/goals/{id}.
+	mux.HandleFunc("GET /api/v1/goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetGoal(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateGoal(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/goals/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteGoal(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/goals/{id}", problem.MethodNotAllowed("GET, PUT, DELETE"))
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupHighlightsRoutes(mux *http.ServeMux, handler *handlers.HighlightHandler) {
+	// NOTE:
+	// Handle GET /api/v1/highlights/daily, the review queue of today. The highlights themselves are notes
+	// of type highlight or quote, see notes.go
+	mux.HandleFunc("GET /api/v1/highlights/daily", handler.DailyHighlights)
+	mux.HandleFunc("/api/v1/highlights/daily", problem.MethodNotAllowed("GET"))
+
+	// NOTE:
+	// Handle POST /api/v1/highlights/{id}/review with {"feedback": "keep|discard|favorite"}, {id} is the note id
+	mux.HandleFunc("POST /api/v1/highlights/{id}/review", func(w http.ResponseWriter, r *http.Request) {
+		handler.ReviewHighlight(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/highlights/{id}/review", problem.MethodNotAllowed("POST"))
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupNotesRoutes(mux *http.ServeMux, handler *handlers.NoteHandler) {
+	// NOTE:
+	// Handle GET /api/v1/notes, the full text search across the notes of all books (?q=). Notes are created
+	// through their book below
+	mux.HandleFunc("GET /api/v1/notes", handler.SearchNotes)
+	mux.HandleFunc("/api/v1/notes", problem.MethodNotAllowed("GET"))
+
+	// NOTE:
+	// Handle GET, PUT and DELETE /api/v1/notes/{id}.
+	mux.HandleFunc("GET /api/v1/notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetNote(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateNote(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/notes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteNote(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/notes/{id}", problem.MethodNotAllowed("GET, PUT, DELETE"))
+
+	// NOTE:
+	// Handle GET and POST /api/v1/books/{id}/notes. GET lists them in reading order, by page
+	mux.HandleFunc("GET /api/v1/books/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		handler.ListBookNotes(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /api/v1/books/{id}/notes", func(w http.ResponseWriter, r *http.Request) {
+		handler.CreateNote(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/books/{id}/notes", problem.MethodNotAllowed("GET, POST"))
+}
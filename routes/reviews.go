@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupReviewsRoutes(mux *http.ServeMux, handler *handlers.ReviewHandler) {
+	// NOTE:
+	// Handle GET /api/v1/reviews. Reviews are written through their book below
+	mux.HandleFunc("GET /api/v1/reviews", handler.ListReviews)
+	mux.HandleFunc("/api/v1/reviews", problem.MethodNotAllowed("GET"))
+
+	// NOTE:
+	// Handle GET, PUT (create or replace) and DELETE /api/v1/books/{id}/review. A book has at most one review
+	mux.HandleFunc("GET /api/v1/books/{id}/review", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetReview(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/books/{id}/review", func(w http.ResponseWriter, r *http.Request) {
+		handler.SetReview(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/books/{id}/review", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteReview(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/books/{id}/review", problem.MethodNotAllowed("GET, PUT, DELETE"))
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupSearchesRoutes(mux *http.ServeMux, handler *handlers.SearchHandler) {
+	// NOTE:
+	// Handle POST and GET /api/v1/searches.
+	mux.HandleFunc("POST /api/v1/searches", handler.CreateSearch)
+	mux.HandleFunc("GET /api/v1/searches", handler.ListSearches)
+	mux.HandleFunc("/api/v1/searches", problem.MethodNotAllowed("GET, POST"))
+
+	// NOTE:
+	// Handle GET, PUT and DELETE /api/v1/searches/{id}.
+	mux.HandleFunc("GET /api/v1/searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetSearch(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateSearch(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/searches/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteSearch(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/searches/{id}", problem.MethodNotAllowed("GET, PUT, DELETE"))
+
+	// NOTE:
+	// Handle GET /api/v1/searches/{id}/books. Supports ?limit= and ?offset= like the book list
+	mux.HandleFunc("GET /api/v1/searches/{id}/books", func(w http.ResponseWriter, r *http.Request) {
+		handler.ListSearchBooks(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/searches/{id}/books", problem.MethodNotAllowed("GET"))
+}
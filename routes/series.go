@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupSeriesRoutes(mux *http.ServeMux, handler *handlers.SeriesHandler) {
+	// NOTE:
+	// Handle POST and GET /api/v1/series.
+	mux.HandleFunc("POST /api/v1/series", handler.CreateSeries)
+	mux.HandleFunc("GET /api/v1/series", handler.ListSeries)
+	mux.HandleFunc("/api/v1/series", problem.MethodNotAllowed("GET, POST"))
+
+	// NOTE:
+	// Handle GET, PUT and DELETE /api/v1/series/{id}. GET includes the volumes and next_unread
+	mux.HandleFunc("GET /api/v1/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetSeries(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateSeries(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/series/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteSeries(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/series/{id}", problem.MethodNotAllowed("GET, PUT, DELETE"))
+
+	// NOTE:
+	// Handle PUT (add or move) and DELETE /api/v1/series/{id}/books/{book_id}.
+	mux.HandleFunc("PUT /api/v1/series/{id}/books/{book_id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.SetVolume(w, r, r.PathValue("id"), r.PathValue("book_id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/series/{id}/books/{book_id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.RemoveVolume(w, r, r.PathValue("id"), r.PathValue("book_id"))
+	})
+	mux.HandleFunc("/api/v1/series/{id}/books/{book_id}", problem.MethodNotAllowed("PUT, DELETE"))
+}
@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupShelvesRoutes(mux *http.ServeMux, handler *handlers.ShelfHandler) {
+	// NOTE:
+	// Handle POST and GET /api/v1/shelves.
+	mux.HandleFunc("POST /api/v1/shelves", handler.CreateShelf)
+	mux.HandleFunc("GET /api/v1/shelves", handler.ListShelves)
+	mux.HandleFunc("/api/v1/shelves", problem.MethodNotAllowed("GET, POST"))
+
+	// NOTE:
+	// Handle GET, PUT and DELETE /api/v1/shelves/{id}.
+	mux.HandleFunc("GET /api/v1/shelves/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.GetShelf(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("PUT /api/v1/shelves/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.UpdateShelf(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/shelves/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteShelf(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/shelves/{id}", problem.MethodNotAllowed("GET, PUT, DELETE"))
+
+	// NOTE:
+	// Handle GET and POST /api/v1/shelves/{id}/books. GET supports ?limit= and ?offset= like the book list
+	mux.HandleFunc("GET /api/v1/shelves/{id}/books", func(w http.ResponseWriter, r *http.Request) {
+		handler.ListShelfBooks(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("POST /api/v1/shelves/{id}/books", func(w http.ResponseWriter, r *http.Request) {
+		handler.AddBook(w, r, r.PathValue("id"))
+	})
+	mux.HandleFunc("/api/v1/shelves/{id}/books", problem.MethodNotAllowed("GET, POST"))
+
+	// NOTE:
+	// Handle PUT (move) and DELETE /api/v1/shelves/{id}/books/{book_id}.
+	mux.HandleFunc("PUT /api/v1/shelves/{id}/books/{book_id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.MoveBook(w, r, r.PathValue("id"), r.PathValue("book_id"))
+	})
+	mux.HandleFunc("DELETE /api/v1/shelves/{id}/books/{book_id}", func(w http.ResponseWriter, r *http.Request) {
+		handler.RemoveBook(w, r, r.PathValue("id"), r.PathValue("book_id"))
+	})
+	mux.HandleFunc("/api/v1/shelves/{id}/books/{book_id}", problem.MethodNotAllowed("PUT, DELETE"))
+}
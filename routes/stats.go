@@ -4,16 +4,27 @@ import (
 	"net/http"
 
 	"book-tracker/handlers"
+	"book-tracker/problem"
 )
 
 func SetupStatsRoutes(mux *http.ServeMux, handler *handlers.StatsHandler) {
 	// Note:
-	// Handle GET /api/v1/stats.
-	mux.HandleFunc("/api/v1/stats", func(w http.ResponseWriter, r *http.Request) {
-		if r.Method != "GET" { // This endpoint is only related to GET i.e. GET all stats
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-		handler.GetStats(w, r)
-	})
+	// Handle GET /api/v1/stats. This endpoint is only related to GET i.e. GET all stats
+	mux.HandleFunc("GET /api/v1/stats", handler.GetStats)
+	mux.HandleFunc("/api/v1/stats", problem.MethodNotAllowed("GET"))
+
+	// Note:
+	// Handle GET /api/v1/stats/timeline?granularity=week|month|year&from=&to=
+	mux.HandleFunc("GET /api/v1/stats/timeline", handler.GetTimeline)
+	mux.HandleFunc("/api/v1/stats/timeline", problem.MethodNotAllowed("GET"))
+
+	// Note:
+	// Handle GET /api/v1/stats/activity?tz=Europe/Stockholm i.e. streaks and the heatmap for the last year
+	mux.HandleFunc("GET /api/v1/stats/activity", handler.GetActivity)
+	mux.HandleFunc("/api/v1/stats/activity", problem.MethodNotAllowed("GET"))
+
+	// Note:
+	// Handle GET /api/v1/stats/authors?limit=&offset= i.e. the author ranking with counts per status
+	mux.HandleFunc("GET /api/v1/stats/authors", handler.GetAuthorStats)
+	mux.HandleFunc("/api/v1/stats/authors", problem.MethodNotAllowed("GET"))
 }
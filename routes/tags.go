@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"book-tracker/handlers"
+	"book-tracker/problem"
+	"net/http"
+)
+
+func SetupTagsRoutes(mux *http.ServeMux, handler *handlers.TagHandler) {
+	// NOTE:
+	// Handle GET /api/v1/tags. Tags are created through the books, so there is no POST
+	mux.HandleFunc("GET /api/v1/tags", handler.ListTags)
+	mux.HandleFunc("/api/v1/tags", problem.MethodNotAllowed("GET"))
+
+	// NOTE:
+	// Handle PUT (rename) and DELETE /api/v1/tags/{name}.
+	mux.HandleFunc("PUT /api/v1/tags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		handler.RenameTag(w, r, r.PathValue("name"))
+	})
+	mux.HandleFunc("DELETE /api/v1/tags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		handler.DeleteTag(w, r, r.PathValue("name"))
+	})
+	mux.HandleFunc("/api/v1/tags/{name}", problem.MethodNotAllowed("PUT, DELETE"))
+
+	// NOTE:
+	// Handle POST /api/v1/tags/{name}/merge.
+	mux.HandleFunc("POST /api/v1/tags/{name}/merge", func(w http.ResponseWriter, r *http.Request) {
+		handler.MergeTags(w, r, r.PathValue("name"))
+	})
+	mux.HandleFunc("/api/v1/tags/{name}/merge", problem.MethodNotAllowed("POST"))
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ArchiveService interface {
+	// Export writes the whole library to w as a models.Archive, row by row instead of building it in memory first
+	Export(ctx context.Context, w io.Writer) error
+	// Import validates the archive and writes it in one go, nothing is written when anything in it is invalid
+	Import(ctx context.Context, archive *models.Archive, opts models.ImportOptions) (*models.ImportResult, error)
+}
+
+type archiveService struct {
+	store        store.ArchiveStore
+	invalidators []Invalidator
+	now          func() time.Time
+}
+
+// NOTE: invalidators are told about every import, i.e. the stats cache
+func NewArchiveService(store store.ArchiveStore, invalidators ...Invalidator) ArchiveService {
+	return &archiveService{store: store, invalidators: invalidators, now: time.Now}
+}
+
+func (s *archiveService) Export(ctx context.Context, w io.Writer) (err error) {
+	ctx, span := tracer.Start(ctx, "ArchiveService.Export")
+	defer func() { tracing.End(span, err) }()
+
+	aw := newArchiveWriter(w)
+	if err := aw.begin(s.now().UTC()); err != nil {
+		return err
+	}
+	if err := s.store.Export(ctx, aw); err != nil {
+		return err
+	}
+	return aw.end()
+}
+
+func (s *archiveService) Import(ctx context.Context, archive *models.Archive, opts models.ImportOptions) (_ *models.ImportResult, err error) {
+	ctx, span := tracer.Start(ctx, "ArchiveService.Import", trace.WithAttributes(
+		attribute.String("import.mode", string(opts.Mode)),
+		attribute.String("import.ids", string(opts.IDs)),
+		attribute.Int("import.books", len(archive.Books)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := archive.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.IDs == models.ImportNewIDs {
+		if err := archive.RemapIDs(); err != nil {
+			return nil, err
+		}
+	}
+	now := s.now().UTC()
+	archive.AddMissingEvents(now)
+	for i := range archive.Goals {
+		if archive.Goals[i].CreatedAt.IsZero() {
+			archive.Goals[i].CreatedAt = now
+		}
+	}
+	for i := range archive.Notes {
+		note := &archive.Notes[i]
+		if note.CreatedAt.IsZero() {
+			note.CreatedAt = now
+		}
+		if note.UpdatedAt.IsZero() {
+			note.UpdatedAt = note.CreatedAt
+		}
+	}
+	for i := range archive.Shelves {
+		shelf := &archive.Shelves[i]
+		if shelf.CreatedAt.IsZero() {
+			shelf.CreatedAt = now
+		}
+		for j := range shelf.Entries {
+			if shelf.Entries[j].AddedAt.IsZero() {
+				shelf.Entries[j].AddedAt = now
+			}
+		}
+	}
+	for i := range archive.Series {
+		if archive.Series[i].CreatedAt.IsZero() {
+			archive.Series[i].CreatedAt = now
+		}
+	}
+	for i := range archive.Reviews {
+		review := &archive.Reviews[i]
+		if review.CreatedAt.IsZero() {
+			review.CreatedAt = now
+		}
+		if review.UpdatedAt.IsZero() {
+			review.UpdatedAt = review.CreatedAt
+		}
+	}
+
+	defer s.invalidate()
+	if err := s.store.Import(ctx, archive, opts.Mode == models.ImportReplace); err != nil {
+		return nil, err
+	}
+	return &models.ImportResult{
+		ImportOptions: opts,
+		Books:         len(archive.Books),
+		Events:        len(archive.Events),
+		Goals:         len(archive.Goals),
+		Notes:         len(archive.Notes),
+		Shelves:       len(archive.Shelves),
+		Series:        len(archive.Series),
+		Reviews:       len(archive.Reviews),
+		Schedules:     len(archive.Schedules),
+	}, nil
+}
+
+func (s *archiveService) invalidate() {
+	for _, inv := range s.invalidators {
+		inv.Invalidate()
+	}
+}
+
+// archiveWriter writes a models.Archive as JSON one element at a time. The sections are opened in the
+// order the store hands them over, a section without rows is still written as an empty list
+type archiveWriter struct {
+	w        *bufio.Writer
+	sections []string
+	current  int // Index into sections, -1 before the first one
+	count    int // Elements written in the current section
+}
+
+func newArchiveWriter(w io.Writer) *archiveWriter {
+	return &archiveWriter{w: bufio.NewWriter(w), sections: []string{"books", "events", "goals", "notes", "shelves", "series", "reviews", "schedules"}, current: -1}
+}
+
+func (a *archiveWriter) begin(exportedAt time.Time) error {
+	header, err := json.Marshal(struct {
+		Format     string    `json:"format"`
+		Version    int       `json:"version"`
+		ExportedAt time.Time `json:"exported_at"`
+	}{models.ArchiveFormat, models.ArchiveVersion, exportedAt})
+	if err != nil {
+		return err
+	}
+	// NOTE: Drop the closing } of the header, the sections go after it
+	_, err = a.w.Write(header[:len(header)-1])
+	return err
+}
+
+func (a *archiveWriter) Book(book *models.Book) error              { return a.write("books", book) }
+func (a *archiveWriter) Event(event *models.BookEvent) error       { return a.write("events", event) }
+func (a *archiveWriter) Goal(goal *models.Goal) error              { return a.write("goals", goal) }
+func (a *archiveWriter) Note(note *models.Note) error              { return a.write("notes", note) }
+func (a *archiveWriter) Shelf(shelf *models.ArchiveShelf) error    { return a.write("shelves", shelf) }
+func (a *archiveWriter) Series(series *models.ArchiveSeries) error { return a.write("series", series) }
+func (a *archiveWriter) Review(review *models.Review) error        { return a.write("reviews", review) }
+func (a *archiveWriter) Schedule(schedule *models.HighlightSchedule) error {
+	return a.write("schedules", schedule)
+}
+
+func (a *archiveWriter) write(section string, v any) error {
+	for a.current < 0 || a.sections[a.current] != section {
+		if a.current == len(a.sections)-1 {
+			return fmt.Errorf("archive section %s out of order", section)
+		}
+		a.next()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if a.count > 0 {
+		a.w.WriteByte(',')
+	}
+	a.count++
+	_, err = a.w.Write(data)
+	return err
+}
+
+// next closes the current section and opens the one after it
+func (a *archiveWriter) next() {
+	if a.current >= 0 {
+		a.w.WriteByte(']')
+	}
+	a.current++
+	a.count = 0
+	fmt.Fprintf(a.w, `,%q:[`, a.sections[a.current])
+}
+
+func (a *archiveWriter) end() error {
+	for a.current < len(a.sections)-1 {
+		a.next()
+	}
+	a.w.WriteString("]}\n")
+	return a.w.Flush()
+}
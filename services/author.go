@@ -0,0 +1,90 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type AuthorService interface {
+	CreateAuthor(ctx context.Context, author *models.Author) error
+	GetAuthor(ctx context.Context, id int64) (*models.Author, error)
+	ListAuthors(ctx context.Context) ([]*models.Author, error)
+	UpdateAuthor(ctx context.Context, author *models.Author) error
+	DeleteAuthor(ctx context.Context, id int64) error
+	MergeAuthors(ctx context.Context, from int64, merge *models.AuthorMerge) error
+}
+
+type authorService struct {
+	store        store.AuthorStore
+	invalidators []Invalidator
+}
+
+// NOTE: invalidators are told about renames and merges, the top authors of the cached stats are by name.
+// Creating or deleting an author changes nothing there, only authors without books can be deleted
+func NewAuthorService(store store.AuthorStore, invalidators ...Invalidator) AuthorService {
+	return &authorService{store: store, invalidators: invalidators}
+}
+
+func (s *authorService) invalidate() {
+	for _, inv := range s.invalidators {
+		inv.Invalidate()
+	}
+}
+
+func (s *authorService) CreateAuthor(ctx context.Context, author *models.Author) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.CreateAuthor")
+	defer func() { tracing.End(span, err) }()
+
+	if err := author.Validate(); err != nil {
+		return err
+	}
+	return s.store.CreateAuthor(ctx, author)
+}
+
+func (s *authorService) GetAuthor(ctx context.Context, id int64) (_ *models.Author, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.GetAuthor", trace.WithAttributes(attribute.Int64("author.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.GetAuthor(ctx, id)
+}
+
+func (s *authorService) ListAuthors(ctx context.Context) (_ []*models.Author, err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.ListAuthors")
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListAuthors(ctx)
+}
+
+func (s *authorService) UpdateAuthor(ctx context.Context, author *models.Author) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.UpdateAuthor", trace.WithAttributes(attribute.Int64("author.id", author.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := author.Validate(); err != nil {
+		return err
+	}
+	defer s.invalidate()
+	return s.store.UpdateAuthor(ctx, author)
+}
+
+func (s *authorService) DeleteAuthor(ctx context.Context, id int64) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.DeleteAuthor", trace.WithAttributes(attribute.Int64("author.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DeleteAuthor(ctx, id)
+}
+
+func (s *authorService) MergeAuthors(ctx context.Context, from int64, merge *models.AuthorMerge) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthorService.MergeAuthors", trace.WithAttributes(attribute.Int64("author.id", from)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := merge.Validate(from); err != nil {
+		return err
+	}
+	defer s.invalidate()
+	return s.store.MergeAuthors(ctx, from, merge.Into)
+}
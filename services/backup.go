@@ -0,0 +1,167 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type BackupService interface {
+	// CreateBackup takes a snapshot and then removes the oldest ones beyond BackupConfig.Keep
+	CreateBackup(ctx context.Context) (*models.Backup, error)
+	// ListBackups returns the snapshots in the backup directory, newest first
+	ListBackups(ctx context.Context) ([]models.Backup, error)
+}
+
+type BackupConfig struct {
+	Dir      string
+	Keep     int           // Number of snapshots kept, older ones are removed after each backup. 0 keeps all of them
+	Interval time.Duration // How often a backup is taken while the server runs. 0 disables scheduled backups
+	Compress bool          // Gzip the snapshots
+}
+
+func DefaultBackupConfig() BackupConfig {
+	return BackupConfig{
+		Dir:      "backups",
+		Keep:     7,
+		Interval: 0,
+		Compress: true,
+	}
+}
+
+// NOTE: The timestamp in the name is what orders the snapshots, so it sorts the same as a string and as a time
+const (
+	backupPrefix = "books-"
+	backupLayout = "20060102T150405.000Z"
+	backupExt    = ".db"
+	gzipExt      = ".gz"
+)
+
+type backupService struct {
+	store store.BackupStore
+	cfg   BackupConfig
+	now   func() time.Time
+	mu    sync.Mutex // NOTE: One backup at a time, a scheduled and a manual one would otherwise rotate each other away
+}
+
+func NewBackupService(store store.BackupStore, cfg BackupConfig) BackupService {
+	return &backupService{store: store, cfg: cfg, now: time.Now}
+}
+
+func (s *backupService) CreateBackup(ctx context.Context) (_ *models.Backup, err error) {
+	ctx, span := tracer.Start(ctx, "BackupService.CreateBackup", trace.WithAttributes(attribute.Bool("backup.compress", s.cfg.Compress)))
+	defer func() { tracing.End(span, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(s.cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create backup dir: %w", err)
+	}
+	// NOTE: Two backups in the same millisecond would get the same name, the later one moves up a millisecond
+	var name string
+	for at := s.now().UTC(); ; at = at.Add(time.Millisecond) {
+		name = backupPrefix + at.Format(backupLayout) + backupExt
+		if s.cfg.Compress {
+			name += gzipExt
+		}
+		if _, err := os.Stat(filepath.Join(s.cfg.Dir, name)); errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if err := s.store.Backup(ctx, filepath.Join(s.cfg.Dir, name), s.cfg.Compress); err != nil {
+		return nil, err
+	}
+
+	backups, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.rotate(backups); err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(backups, func(b models.Backup) bool { return b.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("backup %s disappeared", name)
+	}
+	return &backups[i], nil
+}
+
+func (s *backupService) ListBackups(ctx context.Context) (_ []models.Backup, err error) {
+	_, span := tracer.Start(ctx, "BackupService.ListBackups")
+	defer func() { tracing.End(span, err) }()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+func (s *backupService) list() ([]models.Backup, error) {
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []models.Backup{}, nil // NOTE: No backup taken yet
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read backup dir: %w", err)
+	}
+
+	backups := []models.Backup{}
+	for _, entry := range entries {
+		backup, ok := parseBackupName(entry.Name())
+		if !ok || !entry.Type().IsRegular() {
+			continue // NOTE: Not ours, i.e. a .tmp of a running backup or a file someone put there
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // NOTE: Removed in the meantime
+		}
+		backup.Size = info.Size()
+		backups = append(backups, backup)
+	}
+	slices.SortFunc(backups, func(a, b models.Backup) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return backups, nil
+}
+
+// rotate removes the snapshots beyond Keep, backups has to be sorted newest first
+func (s *backupService) rotate(backups []models.Backup) error {
+	if s.cfg.Keep <= 0 || len(backups) <= s.cfg.Keep {
+		return nil
+	}
+	for _, old := range backups[s.cfg.Keep:] {
+		if err := os.Remove(filepath.Join(s.cfg.Dir, old.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove old backup: %w", err)
+		}
+	}
+	return nil
+}
+
+func parseBackupName(name string) (models.Backup, bool) {
+	backup := models.Backup{Name: name}
+	stamp, ok := strings.CutPrefix(name, backupPrefix)
+	if !ok {
+		return backup, false
+	}
+	stamp, backup.Compressed = strings.CutSuffix(stamp, gzipExt)
+	stamp, ok = strings.CutSuffix(stamp, backupExt)
+	if !ok {
+		return backup, false
+	}
+	createdAt, err := time.Parse(backupLayout, stamp)
+	if err != nil {
+		return backup, false
+	}
+	backup.CreatedAt = createdAt
+	return backup, true
+}
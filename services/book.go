@@ -3,53 +3,113 @@ package services
 import (
 	"book-tracker/models"
 	"book-tracker/store"
+	"book-tracker/tracing"
 	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("book-tracker/services")
+
 type BookService interface {
 	CreateBook(ctx context.Context, book *models.Book) error
 	GetBook(ctx context.Context, id string) (*models.Book, error)
-	ListBooks(ctx context.Context, status string, limit, offset int) ([]*models.Book, error)
+	ListBooks(ctx context.Context, filter models.BookFilter, limit, offset int) ([]*models.Book, error)
 	UpdateBook(ctx context.Context, book *models.Book) error
 	DeleteBook(ctx context.Context, id string) error
 }
 
 type bookService struct {
-	store store.BookStore
+	store        store.BookStore
+	invalidators []Invalidator
+}
+
+// NOTE: invalidators are told about every write, i.e. the stats cache
+func NewBookService(store store.BookStore, invalidators ...Invalidator) BookService {
+	return &bookService{store: store, invalidators: invalidators}
 }
 
-func NewBookService(store store.BookStore) BookService {
-	return &bookService{store: store}
+// NOTE: Called after every write attempt, even failed ones. An error can come back after the commit went
+// through (i.e. a cancelled context) and a needless invalidation is cheaper than serving stale stats
+func (s *bookService) invalidate() {
+	for _, inv := range s.invalidators {
+		inv.Invalidate()
+	}
 }
 
-func (s *bookService) CreateBook(ctx context.Context, book *models.Book) error {
+func (s *bookService) CreateBook(ctx context.Context, book *models.Book) (err error) {
+	ctx, span := tracer.Start(ctx, "BookService.CreateBook")
+	defer func() { tracing.End(span, err) }()
+
 	if err := book.GenerateID(); err != nil {
 		return err
 	}
+	span.SetAttributes(attribute.String("book.id", book.ID))
 	if err := book.Validate(); err != nil {
 		return err
 	}
+	defer s.invalidate()
 	return s.store.CreateBook(ctx, book)
 }
 
-func (s *bookService) GetBook(ctx context.Context, id string) (*models.Book, error) {
+func (s *bookService) GetBook(ctx context.Context, id string) (_ *models.Book, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.GetBook", trace.WithAttributes(attribute.String("book.id", id)))
+	defer func() { tracing.End(span, err) }()
+
 	return s.store.GetBook(ctx, id)
 }
 
-func (s *bookService) ListBooks(ctx context.Context, status string, limit, offset int) ([]*models.Book, error) {
+func (s *bookService) ListBooks(ctx context.Context, filter models.BookFilter, limit, offset int) (_ []*models.Book, err error) {
+	ctx, span := tracer.Start(ctx, "BookService.ListBooks", trace.WithAttributes(
+		attribute.String("book.status", string(filter.Status)),
+		attribute.StringSlice("book.tags", filter.Tags),
+		attribute.StringSlice("book.tags_any", filter.AnyTags),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer func() { tracing.End(span, err) }()
+
 	// NOTE:
 	// We can extend functionality later on author and book filtering if we wish
-	// but lets keep these neutral for now and only adapt for pagination or infinite-scroll (lets keep status)
-	return s.store.ListBooks(ctx, status, limit, offset, "", "")
+	// but lets keep these neutral for now and only adapt for pagination or infinite-scroll (status and tags)
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return s.store.ListBooks(ctx, filter, limit, offset)
 }
 
-func (s *bookService) UpdateBook(ctx context.Context, book *models.Book) error {
+func (s *bookService) UpdateBook(ctx context.Context, book *models.Book) (err error) {
+	ctx, span := tracer.Start(ctx, "BookService.UpdateBook", trace.WithAttributes(attribute.String("book.id", book.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: A client that only knows the author string would otherwise drop the translators and the like,
+	// and one from before tags every tag of the book. An empty "tags": [] still removes them
+	if book.Contributors == nil || book.Tags == nil {
+		stored, err := s.store.GetBook(ctx, book.ID)
+		if err != nil && !errors.Is(err, store.ErrBookNotFound) {
+			return err
+		}
+		if book.Contributors == nil {
+			book.Contributors = models.KeepContributors(stored, book.Author)
+		}
+		if book.Tags == nil && stored != nil {
+			book.Tags = stored.Tags
+		}
+	}
 	if err := book.Validate(); err != nil {
 		return err
 	}
+	defer s.invalidate()
 	return s.store.UpdateBook(ctx, book)
 }
 
-func (s *bookService) DeleteBook(ctx context.Context, id string) error {
+func (s *bookService) DeleteBook(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "BookService.DeleteBook", trace.WithAttributes(attribute.String("book.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	defer s.invalidate()
 	return s.store.DeleteBook(ctx, id)
 }
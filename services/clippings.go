@@ -0,0 +1,172 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ClippingsService interface {
+	// ImportClippings reads a Kindle My Clippings.txt and adds its highlights and notes to the matching books.
+	// Importing the same file again only adds what is new in it
+	ImportClippings(ctx context.Context, r io.Reader, opts models.ClippingsOptions) (*models.ClippingsResult, error)
+}
+
+type clippingsService struct {
+	books BookService
+	notes store.NoteStore
+}
+
+// NOTE: Books are looked up and created through the BookService, so a created book is validated and
+// invalidates the stats like any other
+func NewClippingsService(books BookService, notes store.NoteStore) ClippingsService {
+	return &clippingsService{books: books, notes: notes}
+}
+
+func (s *clippingsService) ImportClippings(ctx context.Context, r io.Reader, opts models.ClippingsOptions) (_ *models.ClippingsResult, err error) {
+	ctx, span := tracer.Start(ctx, "ClippingsService.ImportClippings", trace.WithAttributes(attribute.Bool("import.create_books", opts.CreateBooks)))
+	defer func() { tracing.End(span, err) }()
+
+	clippings, errs, err := models.ParseClippings(r)
+	if err != nil {
+		return nil, err
+	}
+	result := &models.ClippingsResult{ClippingsOptions: opts, Unmatched: []models.UnmatchedBook{}, Errors: errs}
+	if result.Errors == nil {
+		result.Errors = []models.ClippingError{}
+	}
+	clippings, result.Duplicates = models.DedupeClippings(clippings)
+	span.SetAttributes(attribute.Int("import.clippings", len(clippings)))
+
+	var notes []*models.Note
+	for _, group := range groupByBook(clippings) {
+		first := group[0]
+		book, err := s.findBook(ctx, first.Title, first.Authors)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case book != nil:
+			result.BooksMatched++
+		case opts.CreateBooks && len(first.Authors) > 0:
+			book = &models.Book{Title: first.Title, Status: models.BookReading}
+			for _, author := range first.Authors {
+				book.Contributors = append(book.Contributors, models.Contributor{Name: author, Role: models.RoleAuthor})
+			}
+			if err := s.books.CreateBook(ctx, book); err != nil {
+				if _, ok := models.AsValidationError(err); !ok {
+					return nil, err
+				}
+				result.Errors = append(result.Errors, models.ClippingError{Line: first.Line, Title: first.Title, Message: err.Error()})
+				continue
+			}
+			result.BooksCreated++
+		default:
+			// NOTE: Without an author there is nothing to create the book with, i.e. a personal document
+			result.Unmatched = append(result.Unmatched, models.UnmatchedBook{
+				Title: first.Title, Author: strings.Join(first.Authors, ", "), Clippings: len(group),
+			})
+			continue
+		}
+
+		for _, clipping := range group {
+			if clipping.Kind == models.ClippingBookmark {
+				result.Bookmarks++
+				continue
+			}
+			note := clipping.Note(book.ID)
+			if err := note.Validate(); err != nil {
+				result.Errors = append(result.Errors, models.ClippingError{Line: clipping.Line, Title: clipping.Title, Message: err.Error()})
+				continue
+			}
+			notes = append(notes, &note)
+		}
+	}
+
+	if len(notes) > 0 {
+		added, err := s.notes.ImportNotes(ctx, notes)
+		if err != nil {
+			return nil, err
+		}
+		result.Imported, result.AlreadyImported = added, len(notes)-added
+	}
+	return result, nil
+}
+
+// groupByBook puts the clippings of each book together, the books in the order they first appear
+func groupByBook(clippings []models.Clipping) [][]models.Clipping {
+	var groups [][]models.Clipping
+	for _, c := range clippings {
+		found := false
+		for i := range groups {
+			if groups[i][0].SameBook(c) {
+				groups[i] = append(groups[i], c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, []models.Clipping{c})
+		}
+	}
+	return groups
+}
+
+var trailingParens = regexp.MustCompile(`\s*\([^()]*\)$`)
+
+// findBook looks for the book of a clipping by its title, ignoring case. Kindle titles often carry more
+// than the library has, so without a match the series in parentheses and then the subtitle are dropped.
+// With authors a book only matches when one of its authors is one of them, without any the title has to
+// be unique. Nil when there is no such book
+func (s *clippingsService) findBook(ctx context.Context, title string, authors []string) (*models.Book, error) {
+	titles := []string{title}
+	if short := trailingParens.ReplaceAllString(title, ""); short != title && short != "" {
+		titles = append(titles, short)
+	}
+	if before, _, ok := strings.Cut(titles[len(titles)-1], ":"); ok && strings.TrimSpace(before) != "" {
+		titles = append(titles, strings.TrimSpace(before))
+	}
+
+	for _, t := range titles {
+		books, err := s.books.ListBooks(ctx, models.BookFilter{Title: t}, 100, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(authors) == 0 {
+			if len(books) == 1 {
+				return books[0], nil
+			}
+			continue
+		}
+		for _, book := range books {
+			if hasAuthor(book, authors) {
+				return book, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func hasAuthor(book *models.Book, authors []string) bool {
+	names := []string{book.Author}
+	for _, c := range book.Contributors {
+		if c.Role == models.RoleAuthor {
+			names = append(names, c.Name)
+		}
+	}
+	for _, name := range names {
+		for _, author := range authors {
+			if strings.EqualFold(name, author) {
+				return true
+			}
+		}
+	}
+	return false
+}
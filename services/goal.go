@@ -0,0 +1,117 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type GoalService interface {
+	CreateGoal(ctx context.Context, goal *models.Goal) (*models.GoalProgress, error)
+	GetGoal(ctx context.Context, id string) (*models.GoalProgress, error)
+	ListGoals(ctx context.Context, activeOnly bool) ([]models.GoalProgress, error)
+	UpdateGoal(ctx context.Context, goal *models.Goal) (*models.GoalProgress, error)
+	DeleteGoal(ctx context.Context, id string) error
+}
+
+type goalService struct {
+	store store.GoalStore
+	stats store.StatsStore
+	now   func() time.Time
+}
+
+// NewGoalService needs the stats store as progress is computed from the completion timestamps of the books
+func NewGoalService(store store.GoalStore, stats store.StatsStore) GoalService {
+	return &goalService{store: store, stats: stats, now: time.Now}
+}
+
+func (s *goalService) CreateGoal(ctx context.Context, goal *models.Goal) (_ *models.GoalProgress, err error) {
+	ctx, span := tracer.Start(ctx, "GoalService.CreateGoal")
+	defer func() { tracing.End(span, err) }()
+
+	if err := goal.GenerateID(); err != nil {
+		return nil, err
+	}
+	if err := goal.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.CreateGoal(ctx, goal); err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, goal)
+}
+
+func (s *goalService) GetGoal(ctx context.Context, id string) (_ *models.GoalProgress, err error) {
+	ctx, span := tracer.Start(ctx, "GoalService.GetGoal", trace.WithAttributes(attribute.String("goal.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	goal, err := s.store.GetGoal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, goal)
+}
+
+func (s *goalService) ListGoals(ctx context.Context, activeOnly bool) (_ []models.GoalProgress, err error) {
+	ctx, span := tracer.Start(ctx, "GoalService.ListGoals", trace.WithAttributes(attribute.Bool("active_only", activeOnly)))
+	defer func() { tracing.End(span, err) }()
+
+	var activeOn *time.Time
+	if activeOnly {
+		now := s.now()
+		activeOn = &now
+	}
+	goals, err := s.store.ListGoals(ctx, activeOn)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]models.GoalProgress, 0, len(goals))
+	for _, goal := range goals {
+		p, err := s.progress(ctx, goal)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, *p)
+	}
+	return progress, nil
+}
+
+func (s *goalService) UpdateGoal(ctx context.Context, goal *models.Goal) (_ *models.GoalProgress, err error) {
+	ctx, span := tracer.Start(ctx, "GoalService.UpdateGoal", trace.WithAttributes(attribute.String("goal.id", goal.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := goal.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.store.UpdateGoal(ctx, goal); err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, goal)
+}
+
+func (s *goalService) DeleteGoal(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "GoalService.DeleteGoal", trace.WithAttributes(attribute.String("goal.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DeleteGoal(ctx, id)
+}
+
+func (s *goalService) progress(ctx context.Context, goal *models.Goal) (*models.GoalProgress, error) {
+	from, to := goal.Period()
+	books, pages, err := s.stats.Completions(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	current := books
+	if goal.Metric == models.GoalPages {
+		current = pages
+	}
+	p := goal.Progress(current, s.now())
+	return &p, nil
+}
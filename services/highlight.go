@@ -0,0 +1,47 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type HighlightService interface {
+	// DailyHighlights is what is left of today's review queue, see models.DailyHighlights
+	DailyHighlights(ctx context.Context) (*models.DailyHighlights, error)
+	// ReviewHighlight records keep, discard or favorite for a highlight or quote and schedules its next review
+	ReviewHighlight(ctx context.Context, noteID, feedback string) (*models.HighlightSchedule, error)
+}
+
+type highlightService struct {
+	store  store.HighlightStore
+	perDay int
+}
+
+// NOTE: The day of the queue comes from the clock of the store, so tests move it there
+func NewHighlightService(store store.HighlightStore, perDay int) HighlightService {
+	return &highlightService{store: store, perDay: perDay}
+}
+
+func (s *highlightService) DailyHighlights(ctx context.Context) (_ *models.DailyHighlights, err error) {
+	ctx, span := tracer.Start(ctx, "HighlightService.DailyHighlights", trace.WithAttributes(attribute.Int("highlights.per_day", s.perDay)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DailyHighlights(ctx, s.perDay)
+}
+
+func (s *highlightService) ReviewHighlight(ctx context.Context, noteID, feedback string) (_ *models.HighlightSchedule, err error) {
+	ctx, span := tracer.Start(ctx, "HighlightService.ReviewHighlight", trace.WithAttributes(attribute.String("note.id", noteID)))
+	defer func() { tracing.End(span, err) }()
+
+	f, err := models.ParseHighlightFeedback(feedback)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("highlight.feedback", string(f)))
+	return s.store.ReviewHighlight(ctx, noteID, f)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type NoteService interface {
+	CreateNote(ctx context.Context, note *models.Note) error
+	GetNote(ctx context.Context, id string) (*models.Note, error)
+	ListNotes(ctx context.Context, query models.NoteQuery, limit, offset int) ([]*models.Note, error)
+	// UpdateNote replaces type, body, page and location. The note stays with its book
+	UpdateNote(ctx context.Context, note *models.Note) error
+	DeleteNote(ctx context.Context, id string) error
+}
+
+type noteService struct {
+	store store.NoteStore
+}
+
+func NewNoteService(store store.NoteStore) NoteService {
+	return &noteService{store: store}
+}
+
+func (s *noteService) CreateNote(ctx context.Context, note *models.Note) (err error) {
+	ctx, span := tracer.Start(ctx, "NoteService.CreateNote", trace.WithAttributes(attribute.String("book.id", note.BookID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := note.GenerateID(); err != nil {
+		return err
+	}
+	if err := note.Validate(); err != nil {
+		return err
+	}
+	return s.store.CreateNote(ctx, note)
+}
+
+func (s *noteService) GetNote(ctx context.Context, id string) (_ *models.Note, err error) {
+	ctx, span := tracer.Start(ctx, "NoteService.GetNote", trace.WithAttributes(attribute.String("note.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.GetNote(ctx, id)
+}
+
+func (s *noteService) ListNotes(ctx context.Context, query models.NoteQuery, limit, offset int) (_ []*models.Note, err error) {
+	ctx, span := tracer.Start(ctx, "NoteService.ListNotes", trace.WithAttributes(
+		attribute.String("book.id", query.BookID),
+		attribute.String("note.type", string(query.Type)),
+		attribute.Int("search.terms", len(query.Terms)),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListNotes(ctx, query, limit, offset)
+}
+
+func (s *noteService) UpdateNote(ctx context.Context, note *models.Note) (err error) {
+	ctx, span := tracer.Start(ctx, "NoteService.UpdateNote", trace.WithAttributes(attribute.String("note.id", note.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: Whatever book_id was sent is ignored, the stored one is what gets validated and kept
+	stored, err := s.store.GetNote(ctx, note.ID)
+	if err != nil {
+		return err
+	}
+	note.BookID = stored.BookID
+	if err := note.Validate(); err != nil {
+		return err
+	}
+	return s.store.UpdateNote(ctx, note)
+}
+
+func (s *noteService) DeleteNote(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "NoteService.DeleteNote", trace.WithAttributes(attribute.String("note.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DeleteNote(ctx, id)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ReviewService works on the reviews of owner, the user of the request (see middleware.UserFrom)
+type ReviewService interface {
+	SetReview(ctx context.Context, owner string, review *models.Review) error
+	GetReview(ctx context.Context, owner, bookID string) (*models.Review, error)
+	ListReviews(ctx context.Context, owner string, limit, offset int) ([]*models.Review, error)
+	DeleteReview(ctx context.Context, owner, bookID string) error
+}
+
+type reviewService struct {
+	store        store.ReviewStore
+	invalidators []Invalidator
+}
+
+// NOTE: invalidators are told about every write, the ratings are part of the cached stats
+func NewReviewService(store store.ReviewStore, invalidators ...Invalidator) ReviewService {
+	return &reviewService{store: store, invalidators: invalidators}
+}
+
+func (s *reviewService) invalidate() {
+	for _, inv := range s.invalidators {
+		inv.Invalidate()
+	}
+}
+
+func (s *reviewService) SetReview(ctx context.Context, owner string, review *models.Review) (err error) {
+	ctx, span := tracer.Start(ctx, "ReviewService.SetReview", trace.WithAttributes(attribute.String("book.id", review.BookID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := review.Validate(); err != nil {
+		return err
+	}
+	defer s.invalidate()
+	return s.store.SetReview(ctx, owner, review)
+}
+
+func (s *reviewService) GetReview(ctx context.Context, owner, bookID string) (_ *models.Review, err error) {
+	ctx, span := tracer.Start(ctx, "ReviewService.GetReview", trace.WithAttributes(attribute.String("book.id", bookID)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.GetReview(ctx, owner, bookID)
+}
+
+func (s *reviewService) ListReviews(ctx context.Context, owner string, limit, offset int) (_ []*models.Review, err error) {
+	ctx, span := tracer.Start(ctx, "ReviewService.ListReviews", trace.WithAttributes(
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListReviews(ctx, owner, limit, offset)
+}
+
+func (s *reviewService) DeleteReview(ctx context.Context, owner, bookID string) (err error) {
+	ctx, span := tracer.Start(ctx, "ReviewService.DeleteReview", trace.WithAttributes(attribute.String("book.id", bookID)))
+	defer func() { tracing.End(span, err) }()
+
+	defer s.invalidate()
+	return s.store.DeleteReview(ctx, owner, bookID)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SearchService works on the saved searches of owner, the user of the request (see middleware.UserFrom)
+type SearchService interface {
+	CreateSearch(ctx context.Context, owner string, search *models.SavedSearch) error
+	GetSearch(ctx context.Context, owner, id string) (*models.SavedSearch, error)
+	ListSearches(ctx context.Context, owner string) ([]*models.SavedSearch, error)
+	UpdateSearch(ctx context.Context, owner string, search *models.SavedSearch) error
+	DeleteSearch(ctx context.Context, owner, id string) error
+	// ListSearchBooks runs the saved search, the books are whatever matches its filter right now
+	ListSearchBooks(ctx context.Context, owner, id string, limit, offset int) ([]*models.Book, error)
+}
+
+type searchService struct {
+	store store.SearchStore
+	books store.BookStore
+}
+
+// NewSearchService needs the book store as searches are evaluated through BookStore.ListBooks when read
+func NewSearchService(store store.SearchStore, books store.BookStore) SearchService {
+	return &searchService{store: store, books: books}
+}
+
+func (s *searchService) CreateSearch(ctx context.Context, owner string, search *models.SavedSearch) (err error) {
+	ctx, span := tracer.Start(ctx, "SearchService.CreateSearch")
+	defer func() { tracing.End(span, err) }()
+
+	if err := search.GenerateID(); err != nil {
+		return err
+	}
+	if err := search.Validate(); err != nil {
+		return err
+	}
+	if err := s.store.CreateSearch(ctx, owner, search); err != nil {
+		return err
+	}
+	return s.count(ctx, search)
+}
+
+func (s *searchService) GetSearch(ctx context.Context, owner, id string) (_ *models.SavedSearch, err error) {
+	ctx, span := tracer.Start(ctx, "SearchService.GetSearch", trace.WithAttributes(attribute.String("search.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	search, err := s.store.GetSearch(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.count(ctx, search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *searchService) ListSearches(ctx context.Context, owner string) (_ []*models.SavedSearch, err error) {
+	ctx, span := tracer.Start(ctx, "SearchService.ListSearches")
+	defer func() { tracing.End(span, err) }()
+
+	searches, err := s.store.ListSearches(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	for _, search := range searches {
+		if err := s.count(ctx, search); err != nil {
+			return nil, err
+		}
+	}
+	return searches, nil
+}
+
+func (s *searchService) UpdateSearch(ctx context.Context, owner string, search *models.SavedSearch) (err error) {
+	ctx, span := tracer.Start(ctx, "SearchService.UpdateSearch", trace.WithAttributes(attribute.String("search.id", search.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := search.Validate(); err != nil {
+		return err
+	}
+	if err := s.store.UpdateSearch(ctx, owner, search); err != nil {
+		return err
+	}
+	return s.count(ctx, search)
+}
+
+func (s *searchService) DeleteSearch(ctx context.Context, owner, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "SearchService.DeleteSearch", trace.WithAttributes(attribute.String("search.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DeleteSearch(ctx, owner, id)
+}
+
+func (s *searchService) ListSearchBooks(ctx context.Context, owner, id string, limit, offset int) (_ []*models.Book, err error) {
+	ctx, span := tracer.Start(ctx, "SearchService.ListSearchBooks", trace.WithAttributes(
+		attribute.String("search.id", id),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	search, err := s.store.GetSearch(ctx, owner, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.books.ListBooks(ctx, search.Filter, limit, offset)
+}
+
+// count fills in the number of books the search matches right now
+func (s *searchService) count(ctx context.Context, search *models.SavedSearch) error {
+	books, err := s.books.CountMatching(ctx, search.Filter)
+	if err != nil {
+		return err
+	}
+	search.Books = books
+	return nil
+}
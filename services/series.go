@@ -0,0 +1,108 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type SeriesService interface {
+	CreateSeries(ctx context.Context, series *models.Series) error
+	// GetSeries returns the series with its volumes in order and the one to read next
+	GetSeries(ctx context.Context, id string) (*models.SeriesDetail, error)
+	ListSeries(ctx context.Context) ([]*models.Series, error)
+	UpdateSeries(ctx context.Context, series *models.Series) error
+	DeleteSeries(ctx context.Context, id string) error
+
+	SetVolume(ctx context.Context, seriesID string, volume *models.SeriesVolume) error
+	RemoveVolume(ctx context.Context, seriesID, bookID string) error
+}
+
+type seriesService struct {
+	store store.SeriesStore
+}
+
+func NewSeriesService(store store.SeriesStore) SeriesService {
+	return &seriesService{store: store}
+}
+
+func (s *seriesService) CreateSeries(ctx context.Context, series *models.Series) (err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.CreateSeries")
+	defer func() { tracing.End(span, err) }()
+
+	if err := series.GenerateID(); err != nil {
+		return err
+	}
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	return s.store.CreateSeries(ctx, series)
+}
+
+func (s *seriesService) GetSeries(ctx context.Context, id string) (_ *models.SeriesDetail, err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.GetSeries", trace.WithAttributes(attribute.String("series.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	series, err := s.store.GetSeries(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := s.store.ListVolumes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// NOTE: The count comes from the volumes so the two always agree, even with a write in between
+	series.Books = len(volumes)
+	return &models.SeriesDetail{Series: *series, Volumes: volumes, NextUnread: models.NextUnread(volumes)}, nil
+}
+
+func (s *seriesService) ListSeries(ctx context.Context) (_ []*models.Series, err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.ListSeries")
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListSeries(ctx)
+}
+
+func (s *seriesService) UpdateSeries(ctx context.Context, series *models.Series) (err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.UpdateSeries", trace.WithAttributes(attribute.String("series.id", series.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	return s.store.UpdateSeries(ctx, series)
+}
+
+func (s *seriesService) DeleteSeries(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.DeleteSeries", trace.WithAttributes(attribute.String("series.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DeleteSeries(ctx, id)
+}
+
+func (s *seriesService) SetVolume(ctx context.Context, seriesID string, volume *models.SeriesVolume) (err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.SetVolume", trace.WithAttributes(
+		attribute.String("series.id", seriesID),
+		attribute.String("book.id", volume.BookID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := volume.Validate(); err != nil {
+		return err
+	}
+	return s.store.SetVolume(ctx, seriesID, volume.BookID, *volume.Position)
+}
+
+func (s *seriesService) RemoveVolume(ctx context.Context, seriesID, bookID string) (err error) {
+	ctx, span := tracer.Start(ctx, "SeriesService.RemoveVolume", trace.WithAttributes(
+		attribute.String("series.id", seriesID),
+		attribute.String("book.id", bookID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.RemoveVolume(ctx, seriesID, bookID)
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type ShelfService interface {
+	CreateShelf(ctx context.Context, shelf *models.Shelf) error
+	GetShelf(ctx context.Context, id string) (*models.Shelf, error)
+	ListShelves(ctx context.Context) ([]*models.Shelf, error)
+	UpdateShelf(ctx context.Context, shelf *models.Shelf) error
+	DeleteShelf(ctx context.Context, id string) error
+
+	AddBook(ctx context.Context, shelfID string, placement *models.ShelfPlacement) error
+	// MoveBook moves a book already on the shelf, placement.Position is required here
+	MoveBook(ctx context.Context, shelfID string, placement *models.ShelfPlacement) error
+	RemoveBook(ctx context.Context, shelfID, bookID string) error
+	ListShelfBooks(ctx context.Context, shelfID string, limit, offset int) ([]*models.Book, error)
+}
+
+type shelfService struct {
+	store store.ShelfStore
+}
+
+func NewShelfService(store store.ShelfStore) ShelfService {
+	return &shelfService{store: store}
+}
+
+func (s *shelfService) CreateShelf(ctx context.Context, shelf *models.Shelf) (err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.CreateShelf")
+	defer func() { tracing.End(span, err) }()
+
+	if err := shelf.GenerateID(); err != nil {
+		return err
+	}
+	if err := shelf.Validate(); err != nil {
+		return err
+	}
+	return s.store.CreateShelf(ctx, shelf)
+}
+
+func (s *shelfService) GetShelf(ctx context.Context, id string) (_ *models.Shelf, err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.GetShelf", trace.WithAttributes(attribute.String("shelf.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.GetShelf(ctx, id)
+}
+
+func (s *shelfService) ListShelves(ctx context.Context) (_ []*models.Shelf, err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.ListShelves")
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListShelves(ctx)
+}
+
+func (s *shelfService) UpdateShelf(ctx context.Context, shelf *models.Shelf) (err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.UpdateShelf", trace.WithAttributes(attribute.String("shelf.id", shelf.ID)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := shelf.Validate(); err != nil {
+		return err
+	}
+	return s.store.UpdateShelf(ctx, shelf)
+}
+
+func (s *shelfService) DeleteShelf(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.DeleteShelf", trace.WithAttributes(attribute.String("shelf.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.DeleteShelf(ctx, id)
+}
+
+func (s *shelfService) AddBook(ctx context.Context, shelfID string, placement *models.ShelfPlacement) (err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.AddBook", trace.WithAttributes(
+		attribute.String("shelf.id", shelfID),
+		attribute.String("book.id", placement.BookID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := placement.Validate(false); err != nil {
+		return err
+	}
+	return s.store.AddBook(ctx, shelfID, placement.BookID, placement.Position)
+}
+
+func (s *shelfService) MoveBook(ctx context.Context, shelfID string, placement *models.ShelfPlacement) (err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.MoveBook", trace.WithAttributes(
+		attribute.String("shelf.id", shelfID),
+		attribute.String("book.id", placement.BookID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if err := placement.Validate(true); err != nil {
+		return err
+	}
+	return s.store.MoveBook(ctx, shelfID, placement.BookID, *placement.Position)
+}
+
+func (s *shelfService) RemoveBook(ctx context.Context, shelfID, bookID string) (err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.RemoveBook", trace.WithAttributes(
+		attribute.String("shelf.id", shelfID),
+		attribute.String("book.id", bookID),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.RemoveBook(ctx, shelfID, bookID)
+}
+
+func (s *shelfService) ListShelfBooks(ctx context.Context, shelfID string, limit, offset int) (_ []*models.Book, err error) {
+	ctx, span := tracer.Start(ctx, "ShelfService.ListShelfBooks", trace.WithAttributes(
+		attribute.String("shelf.id", shelfID),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListShelfBooks(ctx, shelfID, limit, offset)
+}
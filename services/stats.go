@@ -3,33 +3,187 @@ package services
 import (
 	"book-tracker/models"
 	"book-tracker/store"
+	"book-tracker/tracing"
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type StatsService interface {
 	GetStats(ctx context.Context) (models.Stats, error)
+	// Timeline takes the raw granularity, from and to values, see models.ParseTimelineQuery for the defaults
+	Timeline(ctx context.Context, granularity, from, to string) (*models.Timeline, error)
+	// Activity returns the reading streaks and the per day activity for the last year, with days in timezone tz (IANA, empty is UTC)
+	Activity(ctx context.Context, tz string) (*models.Activity, error)
+	AuthorStats(ctx context.Context, limit, offset int) (*models.AuthorStatsPage, error)
+	// CacheStats reports how often GetStats was served from the cache, for the /metrics endpoint
+	CacheStats() (hits, misses uint64)
+	Invalidator
+}
+
+// Invalidator is implemented by services that cache data derived from the books. BookService and
+// ReviewService call Invalidate after every write
+type Invalidator interface {
+	Invalidate()
 }
 
 type statsService struct {
 	store store.StatsStore
+	goals GoalService
+	now   func() time.Time
+	cache statsCache
+}
+
+// NOTE: goals is optional, without it the stats are returned without any goals.
+// cacheTTL <= 0 disables the cache
+func NewStatsService(store store.StatsStore, goals GoalService, cacheTTL time.Duration) StatsService {
+	return &statsService{store: store, goals: goals, now: time.Now, cache: statsCache{ttl: cacheTTL}}
+}
+
+// statsCache holds the book derived part of the stats. Goals are left out on purpose, their progress
+// depends on the clock and on goal writes which BookService doesnt know about, and they are cheap anyway.
+// NOTE: The TTL is only a safety net for writes that dont go through BookService
+type statsCache struct {
+	ttl time.Duration
+
+	mu         sync.Mutex
+	stats      *models.Stats
+	expires    time.Time
+	generation uint64 // Bumped on every invalidation so a query that raced with a write is not cached
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func (c *statsCache) get(now time.Time) (models.Stats, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stats != nil && now.Before(c.expires) {
+		c.hits.Add(1)
+		return *c.stats, c.generation, true
+	}
+	c.misses.Add(1)
+	return models.Stats{}, c.generation, false
+}
+
+func (c *statsCache) put(stats models.Stats, generation uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 || generation != c.generation {
+		return
+	}
+	c.stats = &stats
+	c.expires = now.Add(c.ttl)
+}
+
+func (c *statsCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = nil
+	c.generation++
 }
 
-func NewStatsService(store store.StatsStore) StatsService {
-	return &statsService{store: store}
+func (s *statsService) Invalidate() {
+	s.cache.invalidate()
 }
 
-func (s *statsService) GetStats(ctx context.Context) (models.Stats, error) {
-	// NOTE: Could already be handled at store level - lets see if we clean-up
-	totalRead, readingProgress, popularAuthor, err := s.store.GetStats(ctx)
+func (s *statsService) CacheStats() (hits, misses uint64) {
+	return s.cache.hits.Load(), s.cache.misses.Load()
+}
+
+func (s *statsService) GetStats(ctx context.Context) (_ models.Stats, err error) {
+	ctx, span := tracer.Start(ctx, "StatsService.GetStats")
+	defer func() { tracing.End(span, err) }()
+
+	stats, generation, ok := s.cache.get(s.now())
+	span.SetAttributes(attribute.Bool("cache.hit", ok))
+	if !ok {
+		if stats, err = s.bookStats(ctx); err != nil {
+			return models.Stats{}, err
+		}
+		s.cache.put(stats, generation, s.now())
+	}
+
+	goals := []models.GoalProgress{}
+	if s.goals != nil {
+		goals, err = s.goals.ListGoals(ctx, true)
+		if err != nil {
+			return models.Stats{}, fmt.Errorf("get active goals: %w", err)
+		}
+	}
+
+	stats.Goals = goals
+	return stats, nil
+}
 
+// bookStats is the part of the stats that only changes when books do, i.e. what gets cached
+func (s *statsService) bookStats(ctx context.Context) (models.Stats, error) {
+	stats, err := s.store.BookStats(ctx, models.TopAuthorsRank)
 	if err != nil {
 		return models.Stats{}, fmt.Errorf("get stats: %w", err)
 	}
+	if len(stats.TopAuthors) == 0 {
+		stats.TopAuthors = nil // NOTE: null in the JSON tells the client there is nothing to show yet
+	}
+	return stats, nil
+}
+
+func (s *statsService) Timeline(ctx context.Context, granularity, from, to string) (_ *models.Timeline, err error) {
+	ctx, span := tracer.Start(ctx, "StatsService.Timeline", trace.WithAttributes(
+		attribute.String("granularity", granularity),
+		attribute.String("from", from),
+		attribute.String("to", to),
+	))
+	defer func() { tracing.End(span, err) }()
 
-	return models.Stats{
-		TotalRead:       totalRead,
-		ReadingProgress: readingProgress,
-		PopularAuthor:   popularAuthor,
+	q, err := models.ParseTimelineQuery(granularity, from, to, s.now())
+	if err != nil {
+		return nil, err
+	}
+	buckets, err := s.store.Timeline(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("get timeline: %w", err)
+	}
+	return &models.Timeline{
+		Granularity: q.Granularity,
+		From:        q.From.Format(models.DateLayout),
+		To:          q.To.Format(models.DateLayout),
+		Buckets:     buckets,
 	}, nil
 }
+
+func (s *statsService) Activity(ctx context.Context, tz string) (_ *models.Activity, err error) {
+	ctx, span := tracer.Start(ctx, "StatsService.Activity", trace.WithAttributes(attribute.String("tz", tz)))
+	defer func() { tracing.End(span, err) }()
+
+	loc, err := models.ParseTimezone(tz)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	events, err := s.store.EventTimes(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("get activity: %w", err)
+	}
+	activity := models.NewActivity(events, loc, now)
+	return &activity, nil
+}
+
+func (s *statsService) AuthorStats(ctx context.Context, limit, offset int) (_ *models.AuthorStatsPage, err error) {
+	ctx, span := tracer.Start(ctx, "StatsService.AuthorStats", trace.WithAttributes(
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	authors, total, err := s.store.AuthorStats(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("get author stats: %w", err)
+	}
+	return &models.AuthorStatsPage{Authors: authors, Total: total, Limit: limit, Offset: offset}, nil
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"book-tracker/models"
+	"book-tracker/store"
+	"book-tracker/tracing"
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TagService interface {
+	ListTags(ctx context.Context) ([]models.TagCount, error)
+	RenameTag(ctx context.Context, from, to string) error
+	MergeTags(ctx context.Context, from, into string) error
+	DeleteTag(ctx context.Context, name string) error
+}
+
+type tagService struct {
+	store store.TagStore
+}
+
+func NewTagService(store store.TagStore) TagService {
+	return &tagService{store: store}
+}
+
+func (s *tagService) ListTags(ctx context.Context) (_ []models.TagCount, err error) {
+	ctx, span := tracer.Start(ctx, "TagService.ListTags")
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.ListTags(ctx)
+}
+
+func (s *tagService) RenameTag(ctx context.Context, from, to string) (err error) {
+	ctx, span := tracer.Start(ctx, "TagService.RenameTag", trace.WithAttributes(attribute.String("tag.name", from)))
+	defer func() { tracing.End(span, err) }()
+
+	if from, err = models.ParseTag("tag", from); err != nil {
+		return err
+	}
+	if to, err = models.ParseTag("name", to); err != nil {
+		return err
+	}
+	return s.store.RenameTag(ctx, from, to)
+}
+
+func (s *tagService) MergeTags(ctx context.Context, from, into string) (err error) {
+	ctx, span := tracer.Start(ctx, "TagService.MergeTags", trace.WithAttributes(attribute.String("tag.name", from)))
+	defer func() { tracing.End(span, err) }()
+
+	from, into, err = models.ParseTagMerge(from, into)
+	if err != nil {
+		return err
+	}
+	return s.store.MergeTags(ctx, from, into)
+}
+
+func (s *tagService) DeleteTag(ctx context.Context, name string) (err error) {
+	ctx, span := tracer.Start(ctx, "TagService.DeleteTag", trace.WithAttributes(attribute.String("tag.name", name)))
+	defer func() { tracing.End(span, err) }()
+
+	if name, err = models.ParseTag("tag", name); err != nil {
+		return err
+	}
+	return s.store.DeleteTag(ctx, name)
+}
@@ -0,0 +1,445 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ArchiveWriter receives the library row by row during an export. All books come first, then all
+// events, then all goals, then all notes, then all shelves, then all series, then all reviews and then the
+// schedules of the highlights that were reviewed
+type ArchiveWriter interface {
+	Book(book *models.Book) error
+	Event(event *models.BookEvent) error
+	Goal(goal *models.Goal) error
+	Note(note *models.Note) error
+	Shelf(shelf *models.ArchiveShelf) error
+	Series(series *models.ArchiveSeries) error
+	Review(review *models.Review) error
+	Schedule(schedule *models.HighlightSchedule) error
+}
+
+type ArchiveStore interface {
+	// Export hands the whole library to w. Everything is read in one transaction so the archive is a
+	// consistent snapshot even while books are being written
+	Export(ctx context.Context, w ArchiveWriter) error
+	// Import writes a validated archive in one transaction. With replace the library is emptied first,
+	// otherwise books, goals, notes, shelves, series and reviews with the same id are overwritten, the history, tags
+	// and contributors of such a book and the books on such a shelf or in such a series included. An overwritten book
+	// stays on the shelves that are not in the archive, a volume in the archive moves its book out of any other
+	// series. An overwritten book keeps the reviews of the users without one in the archive, an overwritten highlight
+	// without a schedule keeps its schedule
+	Import(ctx context.Context, archive *models.Archive, replace bool) error
+}
+
+type archiveStore struct {
+	db      *DB
+	dialect dialect
+}
+
+func NewArchiveStore(db *DB) ArchiveStore {
+	return &archiveStore{db: db, dialect: sqliteDialect}
+}
+
+// NOTE: SQLite transactions always read from one snapshot, Postgres needs REPEATABLE READ for that
+var snapshotTx = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+const (
+	selectAllBooks     = `SELECT ` + bookColumns + ` FROM books ORDER BY id`
+	selectAllEvents    = `SELECT book_id, from_status, to_status, pages, occurred_at FROM book_events ORDER BY id`
+	selectAllGoals     = `SELECT ` + goalColumns + ` FROM goals ORDER BY created_at, id`
+	selectAllNotes     = `SELECT ` + noteColumns + ` FROM notes ORDER BY created_at, id`
+	selectAllShelves   = selectShelves + ` ORDER BY created_at, id`
+	selectAllSeries    = selectSeries + ` ORDER BY created_at, id`
+	selectAllReviews   = selectReviews + ` ORDER BY book_id, owner`
+	selectAllSchedules = `SELECT note_id, ` + scheduleColumns + ` FROM highlight_schedules ORDER BY note_id`
+	// NOTE: Ordered so the same library always exports the same way
+	selectAllBookTags    = `SELECT book_tags.book_id, tags.name FROM book_tags JOIN tags ON tags.id = book_tags.tag_id ORDER BY book_tags.book_id, tags.name`
+	selectAllBookAuthors = `
+		SELECT book_authors.book_id, authors.id, authors.name, book_authors.role
+		FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+		ORDER BY book_authors.book_id, book_authors.position`
+	selectAllShelfBooks  = `SELECT shelf_id, book_id, added_at FROM shelf_books ORDER BY shelf_id, position, added_at, book_id`
+	selectAllSeriesBooks = `SELECT series_id, book_id, position FROM series_books ORDER BY series_id, position, book_id`
+)
+
+func (s *archiveStore) Export(ctx context.Context, w ArchiveWriter) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "archiveStore.Export", "")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := s.db.Read.BeginTx(ctx, snapshotTx)
+	if err != nil {
+		return fmt.Errorf("begin export: %w", err)
+	}
+	defer tx.Rollback() // Read only so nothing to commit
+
+	// NOTE: Postgres cannot run a query while the books are still streaming, so the tags, contributors and
+	// the books on the shelves and in the series are read up front
+	tags := map[string][]string{}
+	err = eachRow(ctx, tx, selectAllBookTags, func(rows *sql.Rows) error {
+		var bookID, tag string
+		if err := rows.Scan(&bookID, &tag); err != nil {
+			return err
+		}
+		tags[bookID] = append(tags[bookID], tag)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("export tags: %w", err)
+	}
+	contributors := map[string][]models.Contributor{}
+	err = eachRow(ctx, tx, selectAllBookAuthors, func(rows *sql.Rows) error {
+		var bookID string
+		var c models.Contributor
+		if err := rows.Scan(&bookID, &c.AuthorID, &c.Name, &c.Role); err != nil {
+			return err
+		}
+		contributors[bookID] = append(contributors[bookID], c)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("export contributors: %w", err)
+	}
+	entries := map[string][]models.ShelfEntry{}
+	err = eachRow(ctx, tx, selectAllShelfBooks, func(rows *sql.Rows) error {
+		var shelfID string
+		var entry models.ShelfEntry
+		if err := rows.Scan(&shelfID, &entry.BookID, &entry.AddedAt); err != nil {
+			return err
+		}
+		entry.AddedAt = entry.AddedAt.UTC()
+		entries[shelfID] = append(entries[shelfID], entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("export shelf books: %w", err)
+	}
+	volumes := map[string][]models.ArchiveVolume{}
+	err = eachRow(ctx, tx, selectAllSeriesBooks, func(rows *sql.Rows) error {
+		var seriesID string
+		var volume models.ArchiveVolume
+		if err := rows.Scan(&seriesID, &volume.BookID, &volume.Position); err != nil {
+			return err
+		}
+		volumes[seriesID] = append(volumes[seriesID], volume)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("export series books: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllBooks, func(rows *sql.Rows) error {
+		book, err := scanBook(rows)
+		if err != nil {
+			return err
+		}
+		book.Tags = tags[book.ID]
+		book.Contributors = contributors[book.ID]
+		return w.Book(book)
+	})
+	if err != nil {
+		return fmt.Errorf("export books: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllEvents, func(rows *sql.Rows) error {
+		var event models.BookEvent
+		var from sql.NullString
+		if err := rows.Scan(&event.BookID, &from, &event.To, &event.Pages, &event.OccurredAt); err != nil {
+			return err
+		}
+		event.From = models.BookStatus(from.String)
+		event.OccurredAt = event.OccurredAt.UTC()
+		return w.Event(&event)
+	})
+	if err != nil {
+		return fmt.Errorf("export events: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllGoals, func(rows *sql.Rows) error {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return err
+		}
+		return w.Goal(goal)
+	})
+	if err != nil {
+		return fmt.Errorf("export goals: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllNotes, func(rows *sql.Rows) error {
+		note, err := scanNote(rows)
+		if err != nil {
+			return err
+		}
+		return w.Note(note)
+	})
+	if err != nil {
+		return fmt.Errorf("export notes: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllShelves, func(rows *sql.Rows) error {
+		shelf, err := scanShelf(rows)
+		if err != nil {
+			return err
+		}
+		return w.Shelf(&models.ArchiveShelf{Shelf: *shelf, Entries: entries[shelf.ID]})
+	})
+	if err != nil {
+		return fmt.Errorf("export shelves: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllSeries, func(rows *sql.Rows) error {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return err
+		}
+		return w.Series(&models.ArchiveSeries{Series: *series, Volumes: volumes[series.ID]})
+	})
+	if err != nil {
+		return fmt.Errorf("export series: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllReviews, func(rows *sql.Rows) error {
+		review, err := scanReview(rows)
+		if err != nil {
+			return err
+		}
+		return w.Review(review)
+	})
+	if err != nil {
+		return fmt.Errorf("export reviews: %w", err)
+	}
+
+	err = eachRow(ctx, tx, selectAllSchedules, func(rows *sql.Rows) error {
+		var schedule models.HighlightSchedule
+		err := rows.Scan(&schedule.NoteID, &schedule.Repetitions, &schedule.IntervalDays, &schedule.Ease, &schedule.DueOn,
+			&schedule.Favorite, &schedule.Discarded, &schedule.ReviewedOn)
+		if err != nil {
+			return err
+		}
+		return w.Schedule(&schedule)
+	})
+	if err != nil {
+		return fmt.Errorf("export highlight schedules: %w", err)
+	}
+	return nil
+}
+
+// eachRow runs query and calls fn for every row, stopping at the first error
+func eachRow(ctx context.Context, tx *sql.Tx, query string, fn func(rows *sql.Rows) error) error {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const (
+	deleteBookEvents = `DELETE FROM book_events WHERE book_id = ?`
+	deleteBookTags   = `DELETE FROM book_tags WHERE book_id = ?`
+	overwriteBook    = `UPDATE books SET title = ?, author = ?, status = ?, pages = ?, completed_at = ? WHERE id = ?`
+	deleteGoalByID   = `DELETE FROM goals WHERE id = ?`
+	overwriteNote    = `UPDATE notes SET book_id = ?, type = ?, body = ?, page = ?, location = ?, created_at = ?, updated_at = ? WHERE id = ?`
+	insertShelfBook  = `INSERT INTO shelf_books (shelf_id, book_id, position, added_at) VALUES (?, ?, ?, ?)`
+	deleteBookVolume = `DELETE FROM series_books WHERE book_id = ?`
+	insertVolume     = `INSERT INTO series_books (book_id, series_id, position) VALUES (?, ?, ?)`
+	insertReview     = `INSERT INTO reviews (` + reviewColumns + `) VALUES (?, ?, ?, ?, ?, ?)`
+)
+
+func (s *archiveStore) Import(ctx context.Context, archive *models.Archive, replace bool) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "archiveStore.Import", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		if replace {
+			for _, table := range []string{"book_events", "books", "goals", "tags", "authors", "shelves", "series"} {
+				if _, err := tx.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+					return fmt.Errorf("empty %s: %w", table, err)
+				}
+			}
+		}
+
+		// NOTE: An update that falls back to an insert instead of an upsert, that works the same on every
+		// database. Books are not deleted and inserted again as that would take them off their shelves too.
+		// With replace there is nothing to overwrite, no harm done
+		stmts, err := prepareAll(ctx, tx, s.dialect, deleteBookEvents, deleteBookTags, overwriteBook, insertBook, insertBookEvent, deleteGoalByID, insertGoal, insertTag, insertBookTag, overwriteNote, insertNote, deleteShelf, insertShelf, insertShelfBook,
+			deleteSeries, insertSeries, deleteBookVolume, insertVolume, deleteReview, insertReview, upsertSchedule)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			for _, stmt := range stmts {
+				stmt.Close()
+			}
+		}()
+		delEvents, delTags, updBook, insBook, insEvent, delGoal, insGoal, insTag, insBookTag := stmts[0], stmts[1], stmts[2], stmts[3], stmts[4], stmts[5], stmts[6], stmts[7], stmts[8]
+		updNote, insNote, delShelf, insShelf, insShelfBook := stmts[9], stmts[10], stmts[11], stmts[12], stmts[13]
+		delSeries, insSeries, delVolume, insVolume := stmts[14], stmts[15], stmts[16], stmts[17]
+		delReview, insReview, upsSchedule := stmts[18], stmts[19], stmts[20]
+
+		for _, book := range archive.Books {
+			if _, err := delEvents.ExecContext(ctx, book.ID); err != nil {
+				return fmt.Errorf("import book %s: %w", book.ID, err)
+			}
+			if _, err := delTags.ExecContext(ctx, book.ID); err != nil {
+				return fmt.Errorf("import book %s: %w", book.ID, err)
+			}
+			// NOTE: The author ids are the ones of the exporting instance, the names are what carries over
+			book.Contributors = slices.Clone(book.Contributors)
+			for i := range book.Contributors {
+				if book.Contributors[i].Name != "" {
+					book.Contributors[i].AuthorID = 0
+				}
+			}
+			if err := resolveContributors(ctx, s.dialect, tx, &book); err != nil {
+				return fmt.Errorf("import contributors of book %s: %w", book.ID, err)
+			}
+			var completedAt *time.Time
+			if book.CompletedAt != nil {
+				at := book.CompletedAt.UTC()
+				completedAt = &at
+			}
+			result, err := updBook.ExecContext(ctx, book.Title, book.Author, book.Status, book.Pages, completedAt, book.ID)
+			if err != nil {
+				return fmt.Errorf("import book %s: %w", book.ID, err)
+			}
+			if n, err := result.RowsAffected(); err != nil {
+				return fmt.Errorf("import book %s: %w", book.ID, err)
+			} else if n == 0 {
+				if _, err := insBook.ExecContext(ctx, book.ID, book.Title, book.Author, book.Status, book.Pages, completedAt); err != nil {
+					return fmt.Errorf("import book %s: %w", book.ID, err)
+				}
+			}
+			for _, tag := range book.Tags {
+				if _, err := insTag.ExecContext(ctx, tag); err != nil {
+					return fmt.Errorf("import tags of book %s: %w", book.ID, err)
+				}
+				if _, err := insBookTag.ExecContext(ctx, book.ID, tag); err != nil {
+					return fmt.Errorf("import tags of book %s: %w", book.ID, err)
+				}
+			}
+			if err := setContributors(ctx, s.dialect, tx, book.ID, book.Contributors); err != nil {
+				return fmt.Errorf("import contributors of book %s: %w", book.ID, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, pruneTags); err != nil {
+			return fmt.Errorf("prune tags: %w", err)
+		}
+
+		for _, event := range archive.Events {
+			var from sql.NullString
+			if event.From != "" {
+				from = sql.NullString{String: string(event.From), Valid: true}
+			}
+			if _, err := insEvent.ExecContext(ctx, event.BookID, from, event.To, event.Pages, event.OccurredAt.UTC()); err != nil {
+				return fmt.Errorf("import event of book %s: %w", event.BookID, err)
+			}
+		}
+
+		for _, goal := range archive.Goals {
+			if _, err := delGoal.ExecContext(ctx, goal.ID); err != nil {
+				return fmt.Errorf("import goal %s: %w", goal.ID, err)
+			}
+			_, err := insGoal.ExecContext(ctx, goal.ID, goal.Title, goal.Metric, goal.Target, goal.StartDate, goal.EndDate, goal.CreatedAt.UTC())
+			if err != nil {
+				return fmt.Errorf("import goal %s: %w", goal.ID, err)
+			}
+		}
+
+		// NOTE: The notes of the books go with them when the library is emptied, see the foreign key. Like the
+		// books they are updated in place, deleting them would take the review schedules of the highlights along
+		for _, note := range archive.Notes {
+			result, err := updNote.ExecContext(ctx, note.BookID, note.Type, note.Body, note.Page, note.Location, note.CreatedAt.UTC(), note.UpdatedAt.UTC(), note.ID)
+			if err != nil {
+				return fmt.Errorf("import note %s: %w", note.ID, err)
+			}
+			if n, err := result.RowsAffected(); err != nil {
+				return fmt.Errorf("import note %s: %w", note.ID, err)
+			} else if n == 0 {
+				_, err := insNote.ExecContext(ctx, note.ID, note.BookID, note.Type, note.Body, note.Page, note.Location, note.CreatedAt.UTC(), note.UpdatedAt.UTC())
+				if err != nil {
+					return fmt.Errorf("import note %s: %w", note.ID, err)
+				}
+			}
+		}
+
+		// NOTE: Deleting the shelf takes its books off it, the shelf comes back in the order of the archive
+		for _, shelf := range archive.Shelves {
+			if _, err := delShelf.ExecContext(ctx, shelf.ID); err != nil {
+				return fmt.Errorf("import shelf %s: %w", shelf.ID, err)
+			}
+			if _, err := insShelf.ExecContext(ctx, shelf.ID, shelf.Name, shelf.Description, shelf.CreatedAt.UTC()); err != nil {
+				return fmt.Errorf("import shelf %s: %w", shelf.ID, err)
+			}
+			for i, entry := range shelf.Entries {
+				if _, err := insShelfBook.ExecContext(ctx, shelf.ID, entry.BookID, i, entry.AddedAt.UTC()); err != nil {
+					return fmt.Errorf("import shelf %s: %w", shelf.ID, err)
+				}
+			}
+		}
+
+		for _, series := range archive.Series {
+			if _, err := delSeries.ExecContext(ctx, series.ID); err != nil {
+				return fmt.Errorf("import series %s: %w", series.ID, err)
+			}
+			if _, err := insSeries.ExecContext(ctx, series.ID, series.Name, series.Description, series.CreatedAt.UTC()); err != nil {
+				return fmt.Errorf("import series %s: %w", series.ID, err)
+			}
+			for _, volume := range series.Volumes {
+				if _, err := delVolume.ExecContext(ctx, volume.BookID); err != nil {
+					return fmt.Errorf("import series %s: %w", series.ID, err)
+				}
+				if _, err := insVolume.ExecContext(ctx, volume.BookID, series.ID, volume.Position); err != nil {
+					return fmt.Errorf("import series %s: %w", series.ID, err)
+				}
+			}
+		}
+
+		// NOTE: The reviews of the books go with them when the library is emptied, see the foreign key
+		for _, review := range archive.Reviews {
+			if _, err := delReview.ExecContext(ctx, review.BookID, review.Owner); err != nil {
+				return fmt.Errorf("import review of book %s: %w", review.BookID, err)
+			}
+			_, err := insReview.ExecContext(ctx, review.BookID, review.Owner, review.Rating, review.Body, review.CreatedAt.UTC(), review.UpdatedAt.UTC())
+			if err != nil {
+				return fmt.Errorf("import review of book %s: %w", review.BookID, err)
+			}
+		}
+
+		for _, schedule := range archive.Schedules {
+			_, err := upsSchedule.ExecContext(ctx, schedule.NoteID, schedule.Repetitions, schedule.IntervalDays, schedule.Ease,
+				schedule.DueOn, schedule.Favorite, schedule.Discarded, schedule.ReviewedOn)
+			if err != nil {
+				return fmt.Errorf("import schedule of highlight %s: %w", schedule.NoteID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// prepareAll prepares the queries on tx, they are closed again if one of them fails
+func prepareAll(ctx context.Context, tx *sql.Tx, d dialect, queries ...string) ([]*sql.Stmt, error) {
+	stmts := make([]*sql.Stmt, 0, len(queries))
+	for _, query := range queries {
+		stmt, err := tx.PrepareContext(ctx, d.bind(query))
+		if err != nil {
+			for _, prepared := range stmts {
+				prepared.Close()
+			}
+			return nil, fmt.Errorf("prepare import: %w", err)
+		}
+		stmts = append(stmts, stmt)
+	}
+	return stmts, nil
+}
@@ -0,0 +1,414 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+// archiveCollector keeps what Export hands over, in order
+type archiveCollector struct {
+	models.Archive
+	order []string
+}
+
+func (c *archiveCollector) Book(book *models.Book) error {
+	c.order = append(c.order, "book")
+	c.Books = append(c.Books, *book)
+	return nil
+}
+
+func (c *archiveCollector) Event(event *models.BookEvent) error {
+	c.order = append(c.order, "event")
+	c.Events = append(c.Events, *event)
+	return nil
+}
+
+func (c *archiveCollector) Goal(goal *models.Goal) error {
+	c.order = append(c.order, "goal")
+	c.Goals = append(c.Goals, *goal)
+	return nil
+}
+
+func (c *archiveCollector) Note(note *models.Note) error {
+	c.order = append(c.order, "note")
+	c.Notes = append(c.Notes, *note)
+	return nil
+}
+
+func (c *archiveCollector) Shelf(shelf *models.ArchiveShelf) error {
+	c.order = append(c.order, "shelf")
+	c.Shelves = append(c.Shelves, *shelf)
+	return nil
+}
+
+// NOTE: The method hides the Series of the archive, that one is c.Archive.Series
+func (c *archiveCollector) Series(series *models.ArchiveSeries) error {
+	c.order = append(c.order, "series")
+	c.Archive.Series = append(c.Archive.Series, *series)
+	return nil
+}
+
+func (c *archiveCollector) Review(review *models.Review) error {
+	c.order = append(c.order, "review")
+	c.Reviews = append(c.Reviews, *review)
+	return nil
+}
+
+func (c *archiveCollector) Schedule(schedule *models.HighlightSchedule) error {
+	c.order = append(c.order, "schedule")
+	c.Schedules = append(c.Schedules, *schedule)
+	return nil
+}
+
+// archiveBackend is the stores of one database that an archive reads and writes
+type archiveBackend struct {
+	books      BookStore
+	goals      GoalStore
+	archives   ArchiveStore
+	shelves    ShelfStore
+	series     SeriesStore
+	notes      NoteStore
+	reviews    ReviewStore
+	highlights HighlightStore
+}
+
+func TestArchiveStore(t *testing.T) {
+	archiveBackends := map[string]func(t *testing.T) archiveBackend{
+		"sqlite": func(t *testing.T) archiveBackend {
+			db, cleanup := setupDB(t)
+			t.Cleanup(cleanup)
+			return archiveBackend{books: NewBookStore(db), goals: NewGoalStore(db), archives: NewArchiveStore(db), shelves: NewShelfStore(db),
+				series: NewSeriesStore(db), notes: NewNoteStore(db), reviews: NewReviewStore(db),
+				highlights: NewHighlightStore(db)}
+		},
+		"postgres": func(t *testing.T) archiveBackend {
+			db := setupPostgres(t)
+			return archiveBackend{books: NewPostgresBookStore(db), goals: NewPostgresGoalStore(db), archives: NewPostgresArchiveStore(db),
+				shelves: NewPostgresShelfStore(db), series: NewPostgresSeriesStore(db), notes: NewPostgresNoteStore(db), reviews: NewPostgresReviewStore(db),
+				highlights: NewPostgresHighlightStore(db)}
+		},
+	}
+
+	for name, open := range archiveBackends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			t.Run("ExportImportRoundTrip", func(t *testing.T) {
+				b := open(t)
+				book := newBook("Emma", "Jane Austen", models.BookReading, 474)
+				book.Tags = []string{"classic", "romance"}
+				book.Contributors = []models.Contributor{{Name: "Jane Austen", Role: models.RoleAuthor}, {Name: "Juliet Stevenson", Role: models.RoleNarrator}}
+				mustCreateBooks(t, b.books, book)
+				book.Status = models.BookComplete
+				if err := b.books.UpdateBook(ctx, book); err != nil {
+					t.Fatalf("UpdateBook failed: %v", err)
+				}
+				goal := &models.Goal{ID: uuid.NewString(), Metric: models.GoalBooks, Target: 10, StartDate: "2026-01-01", EndDate: "2026-12-31"}
+				if err := b.goals.CreateGoal(ctx, goal); err != nil {
+					t.Fatalf("CreateGoal failed: %v", err)
+				}
+				note := &models.Note{ID: uuid.NewString(), BookID: book.ID, Type: models.NoteQuote, Body: "Badly done, Emma!", Page: 368, Location: "5612"}
+				if err := b.notes.CreateNote(ctx, note); err != nil {
+					t.Fatalf("CreateNote failed: %v", err)
+				}
+
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if want := []string{"book", "event", "event", "goal", "note"}; !slices.Equal(exported.order, want) {
+					t.Fatalf("Expected %v in that order, got %v", want, exported.order)
+				}
+				if exported.Events[0].From != "" || exported.Events[1].From != models.BookReading || exported.Events[1].To != models.BookComplete {
+					t.Errorf("Unexpected events: %+v", exported.Events)
+				}
+				if exported.Books[0].CompletedAt == nil {
+					t.Fatal("Expected completed_at to be exported")
+				}
+
+				// NOTE: Replace into the same library, it has to come back exactly as it was
+				if err := b.archives.Import(ctx, &exported.Archive, true); err != nil {
+					t.Fatalf("Import failed: %v", err)
+				}
+				var again archiveCollector
+				if err := b.archives.Export(ctx, &again); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if len(again.Books) != 1 || len(again.Events) != 2 || len(again.Goals) != 1 {
+					t.Fatalf("Expected 1 book, 2 events and 1 goal, got %+v", again.Archive)
+				}
+				if !again.Books[0].CompletedAt.Equal(*exported.Books[0].CompletedAt) || !again.Events[1].OccurredAt.Equal(exported.Events[1].OccurredAt) {
+					t.Errorf("Expected the timestamps to survive the round trip, got %+v", again.Archive)
+				}
+				if !slices.Equal(again.Books[0].Tags, []string{"classic", "romance"}) {
+					t.Errorf("Expected the tags to survive the round trip, got %v", again.Books[0].Tags)
+				}
+				if c := again.Books[0].Contributors; len(c) != 2 || c[1].Name != "Juliet Stevenson" || c[1].Role != models.RoleNarrator || again.Books[0].Author != "Jane Austen" {
+					t.Errorf("Expected the contributors to survive the round trip, got %q %+v", again.Books[0].Author, c)
+				}
+				if !again.Goals[0].CreatedAt.Equal(exported.Goals[0].CreatedAt) {
+					t.Errorf("Expected created_at %v, got %v", exported.Goals[0].CreatedAt, again.Goals[0].CreatedAt)
+				}
+				if len(again.Notes) != 1 || again.Notes[0] != exported.Notes[0] || again.Notes[0].Location != "5612" {
+					t.Errorf("Expected the note to survive the round trip, got %+v", again.Notes)
+				}
+				if found, err := b.notes.ListNotes(ctx, models.NoteQuery{Terms: []string{"badly"}}, 10, 0); err != nil || len(found) != 1 {
+					t.Errorf("Expected the imported note to be searchable, got %+v (%v)", found, err)
+				}
+			})
+
+			t.Run("ShelvesRoundTrip", func(t *testing.T) {
+				b := open(t)
+				emma := newBook("Emma", "Jane Austen", models.BookComplete, 474)
+				persuasion := newBook("Persuasion", "Jane Austen", models.BookUnread, 249)
+				mustCreateBooks(t, b.books, emma, persuasion)
+				shelf := &models.Shelf{ID: uuid.NewString(), Name: "Book club", Description: "Every other Tuesday"}
+				if err := b.shelves.CreateShelf(ctx, shelf); err != nil {
+					t.Fatalf("CreateShelf failed: %v", err)
+				}
+				top := 0
+				if err := b.shelves.AddBook(ctx, shelf.ID, emma.ID, nil); err != nil {
+					t.Fatalf("AddBook failed: %v", err)
+				}
+				if err := b.shelves.AddBook(ctx, shelf.ID, persuasion.ID, &top); err != nil {
+					t.Fatalf("AddBook failed: %v", err)
+				}
+
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if n := len(exported.order); n == 0 || exported.order[n-1] != "shelf" {
+					t.Fatalf("Expected the shelves last, got %v", exported.order)
+				}
+				if len(exported.Shelves) != 1 || len(exported.Shelves[0].Entries) != 2 || exported.Shelves[0].Entries[0].BookID != persuasion.ID {
+					t.Fatalf("Expected the shelf with Persuasion on top, got %+v", exported.Shelves)
+				}
+
+				if err := b.archives.Import(ctx, &exported.Archive, true); err != nil {
+					t.Fatalf("Import failed: %v", err)
+				}
+				got, err := b.shelves.GetShelf(ctx, shelf.ID)
+				if err != nil || got.Name != "Book club" || got.Description != "Every other Tuesday" || got.Books != 2 || !got.CreatedAt.Equal(shelf.CreatedAt) {
+					t.Errorf("Expected the shelf to survive the round trip, got %+v (%v)", got, err)
+				}
+				onShelf, err := b.shelves.ListShelfBooks(ctx, shelf.ID, 10, 0)
+				if err != nil || !slices.Equal(titles(onShelf), []string{"Persuasion", "Emma"}) {
+					t.Errorf("Expected the books in shelf order, got %v (%v)", titles(onShelf), err)
+				}
+				var again archiveCollector
+				if err := b.archives.Export(ctx, &again); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if len(again.Shelves) != 1 || !slices.Equal(again.Shelves[0].Entries, exported.Shelves[0].Entries) {
+					t.Errorf("Expected the shelf entries to survive the round trip, got %+v", again.Shelves)
+				}
+			})
+
+			t.Run("SeriesRoundTrip", func(t *testing.T) {
+				b := open(t)
+				first := newBook("Leviathan Wakes", "James S. A. Corey", models.BookComplete, 561)
+				novella := newBook("Gods of Risk", "James S. A. Corey", models.BookUnread, 80)
+				second := newBook("Caliban's War", "James S. A. Corey", models.BookReading, 595)
+				mustCreateBooks(t, b.books, first, novella, second)
+				series := &models.Series{ID: uuid.NewString(), Name: "The Expanse", Description: "Nine novels"}
+				if err := b.series.CreateSeries(ctx, series); err != nil {
+					t.Fatalf("CreateSeries failed: %v", err)
+				}
+				for book, position := range map[*models.Book]float64{first: 1, novella: 2.5, second: 2} {
+					if err := b.series.SetVolume(ctx, series.ID, book.ID, position); err != nil {
+						t.Fatalf("SetVolume failed: %v", err)
+					}
+				}
+
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if n := len(exported.order); n == 0 || exported.order[n-1] != "series" {
+					t.Fatalf("Expected the series last, got %v", exported.order)
+				}
+				want := []models.ArchiveVolume{{BookID: first.ID, Position: 1}, {BookID: second.ID, Position: 2}, {BookID: novella.ID, Position: 2.5}}
+				if len(exported.Archive.Series) != 1 || !slices.Equal(exported.Archive.Series[0].Volumes, want) {
+					t.Fatalf("Expected the volumes in series order, got %+v", exported.Archive.Series)
+				}
+
+				if err := b.archives.Import(ctx, &exported.Archive, true); err != nil {
+					t.Fatalf("Import failed: %v", err)
+				}
+				got, err := b.series.GetSeries(ctx, series.ID)
+				if err != nil || got.Name != "The Expanse" || got.Description != "Nine novels" || got.Books != 3 || !got.CreatedAt.Equal(series.CreatedAt) {
+					t.Errorf("Expected the series to survive the round trip, got %+v (%v)", got, err)
+				}
+				book, err := b.books.GetBook(ctx, novella.ID)
+				if err != nil || book.Series == nil || book.Series.ID != series.ID || book.Series.Position != 2.5 {
+					t.Errorf("Expected the novella to be volume 2.5 again, got %+v (%v)", book, err)
+				}
+			})
+
+			t.Run("ReviewsRoundTrip", func(t *testing.T) {
+				b := open(t)
+				emma := newBook("Emma", "Jane Austen", models.BookComplete, 474)
+				persuasion := newBook("Persuasion", "Jane Austen", models.BookComplete, 249)
+				mustCreateBooks(t, b.books, emma, persuasion)
+				// NOTE: Emma has a review of the anonymous user and one of alice, the archive keeps both
+				for _, review := range []*models.Review{{BookID: emma.ID, Rating: 4.5, Body: "Badly done, *Emma*!"}, {BookID: emma.ID, Owner: "alice", Rating: 3}, {BookID: persuasion.ID, Owner: "alice", Rating: 5}} {
+					if err := b.reviews.SetReview(ctx, review.Owner, review); err != nil {
+						t.Fatalf("SetReview failed: %v", err)
+					}
+				}
+				// NOTE: Read again, the review stays and has to come back with the book
+				persuasion.Status = models.BookReading
+				if err := b.books.UpdateBook(ctx, persuasion); err != nil {
+					t.Fatalf("UpdateBook failed: %v", err)
+				}
+
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if n := len(exported.order); n == 0 || exported.order[n-1] != "review" {
+					t.Fatalf("Expected the reviews last, got %v", exported.order)
+				}
+				if len(exported.Reviews) != 3 {
+					t.Fatalf("Expected 3 reviews, got %+v", exported.Reviews)
+				}
+
+				if err := b.archives.Import(ctx, &exported.Archive, true); err != nil {
+					t.Fatalf("Import failed: %v", err)
+				}
+				for _, want := range exported.Reviews {
+					got, err := b.reviews.GetReview(ctx, want.Owner, want.BookID)
+					if err != nil || got.Rating != want.Rating || got.Body != want.Body || !got.CreatedAt.Equal(want.CreatedAt) || !got.UpdatedAt.Equal(want.UpdatedAt) {
+						t.Errorf("Expected the review %+v to survive the round trip, got %+v (%v)", want, got, err)
+					}
+				}
+			})
+
+			t.Run("SchedulesRoundTrip", func(t *testing.T) {
+				b := open(t)
+				book := newBook("Emma", "Jane Austen", models.BookComplete, 474)
+				mustCreateBooks(t, b.books, book)
+				note := &models.Note{ID: uuid.NewString(), BookID: book.ID, Type: models.NoteHighlight, Body: "Badly done, Emma!"}
+				if err := b.notes.CreateNote(ctx, note); err != nil {
+					t.Fatalf("CreateNote failed: %v", err)
+				}
+				for _, feedback := range []models.HighlightFeedback{models.FeedbackKeep, models.FeedbackFavorite} {
+					if _, err := b.highlights.ReviewHighlight(ctx, note.ID, feedback); err != nil {
+						t.Fatalf("ReviewHighlight failed: %v", err)
+					}
+				}
+
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if n := len(exported.order); n == 0 || exported.order[n-1] != "schedule" {
+					t.Fatalf("Expected the schedules last, got %v", exported.order)
+				}
+				if len(exported.Schedules) != 1 || !exported.Schedules[0].Favorite || exported.Schedules[0].Repetitions != 2 {
+					t.Fatalf("Expected the favorite reviewed twice, got %+v", exported.Schedules)
+				}
+
+				// NOTE: Merge overwrites the note in place, replace brings it back from the archive. Either way the
+				// schedule has to survive
+				for _, replace := range []bool{false, true} {
+					if err := b.archives.Import(ctx, &exported.Archive, replace); err != nil {
+						t.Fatalf("Import (replace %v) failed: %v", replace, err)
+					}
+					var again archiveCollector
+					if err := b.archives.Export(ctx, &again); err != nil {
+						t.Fatalf("Export failed: %v", err)
+					}
+					if !slices.Equal(again.Schedules, exported.Schedules) {
+						t.Errorf("Expected the schedules to survive an import (replace %v), got %+v", replace, again.Schedules)
+					}
+				}
+				if found, err := b.notes.ListNotes(ctx, models.NoteQuery{Terms: []string{"badly"}}, 10, 0); err != nil || len(found) != 1 {
+					t.Errorf("Expected the note to stay searchable, got %+v (%v)", found, err)
+				}
+			})
+
+			t.Run("MergeOverwritesSameID", func(t *testing.T) {
+				b := open(t)
+				kept := newBook("Kept", "Author", models.BookUnread, 0)
+				overwritten := newBook("Old Title", "Author", models.BookReading, 0)
+				mustCreateBooks(t, b.books, kept, overwritten)
+				shelf := &models.Shelf{ID: uuid.NewString(), Name: "Book club"}
+				if err := b.shelves.CreateShelf(ctx, shelf); err != nil {
+					t.Fatalf("CreateShelf failed: %v", err)
+				}
+				if err := b.shelves.AddBook(ctx, shelf.ID, overwritten.ID, nil); err != nil {
+					t.Fatalf("AddBook failed: %v", err)
+				}
+
+				at := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+				archive := &models.Archive{
+					Books:  []models.Book{{ID: overwritten.ID, Title: "New Title", Author: "Author", Status: models.BookComplete, CompletedAt: &at}},
+					Events: []models.BookEvent{{BookID: overwritten.ID, To: models.BookComplete, OccurredAt: at}},
+				}
+				if err := b.archives.Import(ctx, archive, false); err != nil {
+					t.Fatalf("Import failed: %v", err)
+				}
+
+				got, err := b.books.GetBook(ctx, overwritten.ID)
+				if err != nil || got.Title != "New Title" || got.Status != models.BookComplete || !got.CompletedAt.Equal(at) {
+					t.Errorf("Expected the book to be overwritten, got %+v (%v)", got, err)
+				}
+				if _, err := b.books.GetBook(ctx, kept.ID); err != nil {
+					t.Errorf("Expected the other book to be kept, got %v", err)
+				}
+				if onShelf, err := b.shelves.ListShelfBooks(ctx, shelf.ID, 10, 0); err != nil || len(onShelf) != 1 || onShelf[0].Title != "New Title" {
+					t.Errorf("Expected the overwritten book to stay on its shelf, got %v (%v)", titles(onShelf), err)
+				}
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if len(exported.Events) != 2 {
+					t.Errorf("Expected the overwritten book's history to be replaced (2 events in total), got %+v", exported.Events)
+				}
+			})
+
+			t.Run("ReplaceEmptiesLibrary", func(t *testing.T) {
+				b := open(t)
+				mustCreateBooks(t, b.books, newBook("Existing", "Author", models.BookUnread, 0))
+				goal := &models.Goal{ID: uuid.NewString(), Metric: models.GoalPages, Target: 100, StartDate: "2026-01-01", EndDate: "2026-01-31"}
+				if err := b.goals.CreateGoal(ctx, goal); err != nil {
+					t.Fatalf("CreateGoal failed: %v", err)
+				}
+
+				book := models.Book{ID: uuid.NewString(), Title: "Imported", Author: "Author", Status: models.BookUnread}
+				archive := &models.Archive{
+					Books:  []models.Book{book},
+					Events: []models.BookEvent{{BookID: book.ID, To: models.BookUnread, OccurredAt: time.Now()}},
+				}
+				if err := b.archives.Import(ctx, archive, true); err != nil {
+					t.Fatalf("Import failed: %v", err)
+				}
+				var exported archiveCollector
+				if err := b.archives.Export(ctx, &exported); err != nil {
+					t.Fatalf("Export failed: %v", err)
+				}
+				if len(exported.Books) != 1 || exported.Books[0].Title != "Imported" || len(exported.Events) != 1 || len(exported.Goals) != 0 {
+					t.Errorf("Expected only the imported book, got %+v", exported.Archive)
+				}
+			})
+		})
+	}
+}
+
+func mustCreateBooks(t *testing.T, store BookStore, books ...*models.Book) {
+	t.Helper()
+	for _, book := range books {
+		if err := store.CreateBook(context.Background(), book); err != nil {
+			t.Fatalf("CreateBook(%s) failed: %v", book.Title, err)
+		}
+	}
+}
@@ -0,0 +1,353 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrAuthorNotFound = errors.New("author not found")
+	ErrAuthorExists   = errors.New("an author with that name already exists")
+	ErrAuthorInUse    = errors.New("author still has books, merge it into another author instead")
+)
+
+type AuthorStore interface {
+	CreateAuthor(ctx context.Context, author *models.Author) error
+	GetAuthor(ctx context.Context, id int64) (*models.Author, error)
+	// ListAuthors returns every author by sort name, also the ones without books
+	ListAuthors(ctx context.Context) ([]*models.Author, error)
+	// UpdateAuthor renames the author, the author line of its books follows
+	UpdateAuthor(ctx context.Context, author *models.Author) error
+	// DeleteAuthor only removes an author without books, see ErrAuthorInUse
+	DeleteAuthor(ctx context.Context, id int64) error
+	// MergeAuthors moves every book of from to into and removes from, i.e. "JK Rowling" into "J.K. Rowling"
+	MergeAuthors(ctx context.Context, from, into int64) error
+}
+
+type authorStore struct {
+	db      *DB
+	dialect dialect
+}
+
+func NewAuthorStore(db *DB) AuthorStore {
+	return &authorStore{db: db, dialect: sqliteDialect}
+}
+
+// NOTE: Books counts every book the author is on in any role
+const selectAuthors = `
+        SELECT id, name, sort_name, (SELECT COUNT(DISTINCT book_id) FROM book_authors WHERE book_authors.author_id = authors.id)
+        FROM authors`
+
+func scanAuthor(row rowScanner) (*models.Author, error) {
+	var author models.Author
+	if err := row.Scan(&author.ID, &author.Name, &author.SortName, &author.Books); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+// authorByName returns the id and stored spelling of the author called name, ignoring case
+func authorByName(ctx context.Context, d dialect, tx *sql.Tx, name string) (int64, string, error) {
+	var id int64
+	var stored string
+	err := tx.QueryRowContext(ctx, d.bind("SELECT id, name FROM authors WHERE "+fmt.Sprintf(d.equalFold, "name")), name).Scan(&id, &stored)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", ErrAuthorNotFound
+		}
+		return 0, "", fmt.Errorf("get author: %w", err)
+	}
+	return id, stored, nil
+}
+
+// resolveContributors looks up the author of every contributor of book, creating the ones only known by a
+// name, and derives book.Author from them. The names come back as stored so the author line uses one
+// spelling. Two contributors that turn out to be the same author in the same role are folded into one.
+// A book without contributors (not validated, i.e. straight from a test) has its author string as the one author
+func resolveContributors(ctx context.Context, d dialect, tx *sql.Tx, book *models.Book) error {
+	contributors := book.Contributors
+	if len(contributors) == 0 {
+		contributors = []models.Contributor{{Name: book.Author, Role: models.RoleAuthor}}
+	}
+	type key struct {
+		id   int64
+		role models.ContributorRole
+	}
+	seen := map[key]bool{}
+	resolved := make([]models.Contributor, 0, len(contributors))
+	for _, c := range contributors {
+		if c.AuthorID != 0 {
+			err := tx.QueryRowContext(ctx, d.bind("SELECT name FROM authors WHERE id = ?"), c.AuthorID).Scan(&c.Name)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					return ErrAuthorNotFound
+				}
+				return fmt.Errorf("get author: %w", err)
+			}
+		} else if id, name, err := authorByName(ctx, d, tx, c.Name); err == nil {
+			c.AuthorID, c.Name = id, name
+		} else if !errors.Is(err, ErrAuthorNotFound) {
+			return err
+		} else {
+			// NOTE: Looked up first, the NOCASE index alone would let "élodie" in next to "Élodie"
+			err := tx.QueryRowContext(ctx, d.bind("INSERT INTO authors (name, sort_name) VALUES (?, ?) RETURNING id"), c.Name, models.DefaultSortName(c.Name)).Scan(&c.AuthorID)
+			if err != nil {
+				return fmt.Errorf("create author %s: %w", c.Name, err)
+			}
+		}
+		if seen[key{c.AuthorID, c.Role}] {
+			continue
+		}
+		seen[key{c.AuthorID, c.Role}] = true
+		resolved = append(resolved, c)
+	}
+	book.Contributors, book.Author = resolved, models.AuthorLine(resolved)
+	return nil
+}
+
+// setContributors replaces the contributors of a book, they have to be resolved already
+func setContributors(ctx context.Context, d dialect, tx *sql.Tx, bookID string, contributors []models.Contributor) error {
+	if _, err := tx.ExecContext(ctx, d.bind("DELETE FROM book_authors WHERE book_id = ?"), bookID); err != nil {
+		return fmt.Errorf("clear contributors: %w", err)
+	}
+	for i, c := range contributors {
+		_, err := tx.ExecContext(ctx, d.bind("INSERT INTO book_authors (book_id, author_id, role, position) VALUES (?, ?, ?, ?)"), bookID, c.AuthorID, c.Role, i)
+		if err != nil {
+			return fmt.Errorf("add contributor: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadContributors fills in the contributors of books with a single query
+func loadContributors(ctx context.Context, d dialect, q queryer, books []*models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Book, len(books))
+	args := make([]any, len(books))
+	for i, book := range books {
+		byID[book.ID] = book
+		args[i] = book.ID
+	}
+
+	query := `
+		SELECT book_authors.book_id, authors.id, authors.name, book_authors.role
+		FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+		WHERE book_authors.book_id IN (` + placeholders(len(books)) + `)
+		ORDER BY book_authors.position`
+	rows, err := q.QueryContext(ctx, d.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("query contributors: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bookID string
+		var c models.Contributor
+		if err := rows.Scan(&bookID, &c.AuthorID, &c.Name, &c.Role); err != nil {
+			return fmt.Errorf("scan contributor: %w", err)
+		}
+		byID[bookID].Contributors = append(byID[bookID].Contributors, c)
+	}
+	return rows.Err()
+}
+
+// loadRelated fills in everything of books that lives outside the books table
+func loadRelated(ctx context.Context, d dialect, q queryer, books []*models.Book) error {
+	if err := loadTags(ctx, d, q, books); err != nil {
+		return err
+	}
+	if err := loadContributors(ctx, d, q, books); err != nil {
+		return err
+	}
+	return loadSeries(ctx, d, q, books)
+}
+
+// refreshAuthorLines writes the author line of every book authorID is an author of again, after a rename or merge
+func refreshAuthorLines(ctx context.Context, d dialect, tx *sql.Tx, authorID int64) error {
+	rows, err := tx.QueryContext(ctx, d.bind(`
+		SELECT book_authors.book_id, authors.name
+		FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+		WHERE book_authors.role = 'author'
+		  AND book_authors.book_id IN (SELECT book_id FROM book_authors WHERE author_id = ? AND role = 'author')
+		ORDER BY book_authors.book_id, book_authors.position`), authorID)
+	if err != nil {
+		return fmt.Errorf("query author lines: %w", err)
+	}
+	defer rows.Close()
+	contributors := map[string][]models.Contributor{}
+	for rows.Next() {
+		var bookID string
+		c := models.Contributor{Role: models.RoleAuthor}
+		if err := rows.Scan(&bookID, &c.Name); err != nil {
+			return fmt.Errorf("scan author line: %w", err)
+		}
+		contributors[bookID] = append(contributors[bookID], c)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close() // NOTE: Before the updates, Postgres cant run a second query while the rows are open
+
+	for bookID, authors := range contributors {
+		if _, err := tx.ExecContext(ctx, d.bind("UPDATE books SET author = ? WHERE id = ?"), models.AuthorLine(authors), bookID); err != nil {
+			return fmt.Errorf("update author line: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *authorStore) CreateAuthor(ctx context.Context, author *models.Author) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "authorStore.CreateAuthor", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		if _, _, err := authorByName(ctx, s.dialect, tx, author.Name); err == nil {
+			return ErrAuthorExists
+		} else if !errors.Is(err, ErrAuthorNotFound) {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, s.dialect.bind("INSERT INTO authors (name, sort_name) VALUES (?, ?) RETURNING id"), author.Name, author.SortName).Scan(&author.ID)
+		if err != nil {
+			return fmt.Errorf("create author: %w", err)
+		}
+		author.Books = 0
+		return nil
+	})
+}
+
+func (s *authorStore) GetAuthor(ctx context.Context, id int64) (_ *models.Author, err error) {
+	query := selectAuthors + " WHERE id = ?"
+	ctx, span := s.dialect.startSpan(ctx, "authorStore.GetAuthor", query)
+	defer func() { tracing.End(span, err) }()
+
+	author, err := scanAuthor(s.db.Read.QueryRowContext(ctx, s.dialect.bind(query), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAuthorNotFound
+		}
+		return nil, fmt.Errorf("get author: %w", err)
+	}
+	return author, nil
+}
+
+func (s *authorStore) ListAuthors(ctx context.Context) (_ []*models.Author, err error) {
+	query := selectAuthors + " ORDER BY " + fmt.Sprintf(s.dialect.byteOrder, "sort_name") + ", id"
+	ctx, span := s.dialect.startSpan(ctx, "authorStore.ListAuthors", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query authors: %w", err)
+	}
+	defer rows.Close()
+
+	authors := []*models.Author{}
+	for rows.Next() {
+		author, err := scanAuthor(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan author: %w", err)
+		}
+		authors = append(authors, author)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return authors, nil
+}
+
+func (s *authorStore) UpdateAuthor(ctx context.Context, author *models.Author) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "authorStore.UpdateAuthor", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		// NOTE: The same name is fine, that is how a spelling is fixed that only differs in case
+		if id, _, err := authorByName(ctx, s.dialect, tx, author.Name); err == nil && id != author.ID {
+			return ErrAuthorExists
+		} else if err != nil && !errors.Is(err, ErrAuthorNotFound) {
+			return err
+		}
+		result, err := tx.ExecContext(ctx, s.dialect.bind("UPDATE authors SET name = ?, sort_name = ? WHERE id = ?"), author.Name, author.SortName, author.ID)
+		if err != nil {
+			return fmt.Errorf("update author: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("check rows affected: %w", err)
+		} else if n == 0 {
+			return ErrAuthorNotFound
+		}
+		if err := refreshAuthorLines(ctx, s.dialect, tx, author.ID); err != nil {
+			return err
+		}
+		err = tx.QueryRowContext(ctx, s.dialect.bind("SELECT COUNT(DISTINCT book_id) FROM book_authors WHERE author_id = ?"), author.ID).Scan(&author.Books)
+		if err != nil {
+			return fmt.Errorf("count author books: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *authorStore) DeleteAuthor(ctx context.Context, id int64) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "authorStore.DeleteAuthor", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		var inUse bool
+		err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT EXISTS (SELECT 1 FROM book_authors WHERE author_id = ?)"), id).Scan(&inUse)
+		if err != nil {
+			return fmt.Errorf("check author books: %w", err)
+		}
+		if inUse {
+			return ErrAuthorInUse
+		}
+		result, err := tx.ExecContext(ctx, s.dialect.bind("DELETE FROM authors WHERE id = ?"), id)
+		if err != nil {
+			return fmt.Errorf("delete author: %w", err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("check rows affected: %w", err)
+		} else if n == 0 {
+			return ErrAuthorNotFound
+		}
+		return nil
+	})
+}
+
+func (s *authorStore) MergeAuthors(ctx context.Context, from, into int64) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "authorStore.MergeAuthors", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		for _, id := range []int64{from, into} {
+			var exists int64
+			if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM authors WHERE id = ?"+s.dialect.lockRow), id).Scan(&exists); err != nil {
+				if err == sql.ErrNoRows {
+					return ErrAuthorNotFound
+				}
+				return fmt.Errorf("get author: %w", err)
+			}
+		}
+		// NOTE: A book that already has into in the same role keeps that one, the rest of from moves over
+		// and keeps its position so co-authors stay in their order
+		_, err := tx.ExecContext(ctx, s.dialect.bind(`
+			DELETE FROM book_authors
+			WHERE author_id = ? AND EXISTS (
+				SELECT 1 FROM book_authors AS other
+				WHERE other.book_id = book_authors.book_id AND other.role = book_authors.role AND other.author_id = ?
+			)`), from, into)
+		if err != nil {
+			return fmt.Errorf("merge authors: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.bind("UPDATE book_authors SET author_id = ? WHERE author_id = ?"), into, from); err != nil {
+			return fmt.Errorf("merge authors: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.bind("DELETE FROM authors WHERE id = ?"), from); err != nil {
+			return fmt.Errorf("delete merged author: %w", err)
+		}
+		return refreshAuthorLines(ctx, s.dialect, tx, into)
+	})
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"book-tracker/tracing"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidBackup = errors.New("invalid backup")
+
+type BackupStore interface {
+	// Backup writes a consistent copy of the database to path, gzipped when compress is set.
+	// path must not exist yet
+	Backup(ctx context.Context, path string, compress bool) error
+}
+
+type backupStore struct {
+	db *DB
+}
+
+func NewBackupStore(db *DB) BackupStore {
+	return &backupStore{db: db}
+}
+
+// NOTE:
+// VACUUM INTO copies the database from inside a read transaction, so the server keeps running and
+// the copy is consistent even while books are written. It has to run on the write connection as the read
+// pool is query only, so writes queue up behind it for the few milliseconds a library this size takes.
+// The copy is written next to path first and renamed once complete so a half written backup never
+// shows up under the final name.
+// Documentation: https://www.sqlite.org/lang_vacuum.html#vacuuminto
+func (s *backupStore) Backup(ctx context.Context, path string, compress bool) (err error) {
+	ctx, span := startSpan(ctx, "backupStore.Backup", "VACUUM INTO ?")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup %s: %w", path, os.ErrExist)
+	}
+	tmp := path + ".tmp"
+	os.Remove(tmp) // NOTE: Left over from a backup that crashed, VACUUM INTO refuses to overwrite files
+	defer os.Remove(tmp)
+
+	if _, err := s.db.ExecContext(ctx, "VACUUM INTO ?", tmp); err != nil {
+		return fmt.Errorf("vacuum into %s: %w", tmp, err)
+	}
+	if compress {
+		if err := gzipFile(tmp, tmp+".gz"); err != nil {
+			os.Remove(tmp + ".gz")
+			return err
+		}
+		tmp += ".gz"
+		defer os.Remove(tmp)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename backup: %w", err)
+	}
+	return nil
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	defer out.Close()
+
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		return fmt.Errorf("compress backup: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compress backup: %w", err)
+	}
+	return out.Sync() // NOTE: Make sure the backup is on disk before it is renamed into place
+}
+
+// RestoreSQLite replaces the database at dbPath with backup, which can be gzipped or not. The backup is
+// unpacked next to dbPath and checked (integrity and schema version) before it is swapped in, a broken
+// or too new backup leaves the database untouched.
+// NOTE: The server must not be running, it would keep writing to the file that was replaced
+func RestoreSQLite(backup, dbPath string) error {
+	tmp := dbPath + ".restore"
+	if err := unpackBackup(backup, tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := validateBackup(tmp); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// NOTE: A WAL left behind by the old database would be replayed into the restored one
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tmp)
+			return fmt.Errorf("remove %s: %w", dbPath+suffix, err)
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("swap in backup: %w", err)
+	}
+	return nil
+}
+
+func unpackBackup(backup, dst string) error {
+	in, err := os.Open(backup)
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer in.Close()
+
+	var src io.Reader = bufio.NewReader(in)
+	// NOTE: Gzip is detected by its magic bytes instead of the extension, renamed files still work
+	if magic, _ := src.(*bufio.Reader).Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidBackup, err)
+		}
+		defer zr.Close()
+		src = zr
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(dst), err)
+	}
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	defer out.Close()
+	if _, err := io.Copy(out, src); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBackup, err)
+	}
+	return out.Sync()
+}
+
+// validateBackup checks that path is an intact book-tracker database this version can run on.
+// Older schema versions are fine as the migrations bring them up to date on the next start
+func validateBackup(path string) error {
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("open backup: %w", err)
+	}
+	defer db.Close()
+
+	// NOTE: The full check, a restore is rare and the server is stopped for it anyway
+	if err := integrityCheck(db, integrityChecks["full"]); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBackup, err)
+	}
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBackup, err)
+	}
+	if version < 1 {
+		return fmt.Errorf("%w: not a book-tracker database", ErrInvalidBackup)
+	}
+	if version > SchemaVersion() {
+		return fmt.Errorf("%w: schema version %d is newer than %d, restore it with a newer book-tracker", ErrInvalidBackup, version, SchemaVersion())
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+
+	seed := func(t *testing.T, books BookStore, n int) {
+		t.Helper()
+		for i := range n {
+			book := &models.Book{ID: uuid.NewString(), Title: fmt.Sprintf("Book %d", i), Author: "Author", Status: models.BookUnread}
+			if err := books.CreateBook(ctx, book); err != nil {
+				t.Fatalf("CreateBook failed: %v", err)
+			}
+		}
+	}
+	count := func(t *testing.T, path string) int {
+		t.Helper()
+		db := setupFileDB(t, DefaultSQLiteConfig(path))
+		total, _, err := NewBookStore(db).CountBooks(ctx)
+		if err != nil {
+			t.Fatalf("CountBooks failed: %v", err)
+		}
+		db.Close()
+		return total
+	}
+
+	for _, compress := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Compress=%t", compress), func(t *testing.T) {
+			dir := t.TempDir()
+			dbPath := filepath.Join(dir, "books.db")
+			db := setupFileDB(t, DefaultSQLiteConfig(dbPath))
+			books := NewBookStore(db)
+			seed(t, books, 10)
+
+			// NOTE: Writes carry on during the backup, it has to contain a consistent state of at least the seeded books
+			var wg sync.WaitGroup
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				seed(t, books, 20)
+			}()
+			backup := filepath.Join(dir, "backup.db")
+			if err := NewBackupStore(db).Backup(ctx, backup, compress); err != nil {
+				t.Fatalf("Backup failed: %v", err)
+			}
+			wg.Wait()
+			if _, err := os.Stat(backup + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("Expected the temporary file to be removed, got %v", err)
+			}
+			if err := NewBackupStore(db).Backup(ctx, backup, compress); !errors.Is(err, os.ErrExist) {
+				t.Errorf("Expected an existing backup not to be overwritten, got %v", err)
+			}
+			db.Close()
+
+			restored := filepath.Join(dir, "restored.db")
+			if err := RestoreSQLite(backup, restored); err != nil {
+				t.Fatalf("RestoreSQLite failed: %v", err)
+			}
+			if n := count(t, restored); n < 10 || n > 30 {
+				t.Errorf("Expected between 10 and 30 books in the restored database, got %d", n)
+			}
+		})
+	}
+
+	t.Run("RejectsInvalidBackups", func(t *testing.T) {
+		dir := t.TempDir()
+		dbPath := filepath.Join(dir, "books.db")
+		db := setupFileDB(t, DefaultSQLiteConfig(dbPath))
+		seed(t, NewBookStore(db), 3)
+		db.Close()
+
+		garbage := filepath.Join(dir, "garbage.db")
+		if err := os.WriteFile(garbage, []byte("this is not a database, not even close to one, it is just text"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		empty := filepath.Join(dir, "empty.db")
+		emptyDB := setupFileDB(t, SQLiteConfig{Path: empty, JournalMode: "DELETE", Synchronous: "FULL", ReadConns: 1})
+		if _, err := emptyDB.Exec("PRAGMA user_version = 0"); err != nil {
+			t.Fatal(err)
+		}
+		emptyDB.Close()
+		newer := filepath.Join(dir, "newer.db")
+		newerDB := setupFileDB(t, SQLiteConfig{Path: newer, JournalMode: "DELETE", Synchronous: "FULL", ReadConns: 1})
+		if _, err := newerDB.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion()+1)); err != nil {
+			t.Fatal(err)
+		}
+		newerDB.Close()
+
+		for name, backup := range map[string]string{"Garbage": garbage, "NoSchema": empty, "NewerSchema": newer} {
+			err := RestoreSQLite(backup, dbPath)
+			if !errors.Is(err, ErrInvalidBackup) {
+				t.Errorf("%s: expected ErrInvalidBackup, got %v", name, err)
+			}
+		}
+		if n := count(t, dbPath); n != 3 {
+			t.Errorf("Expected a failed restore to leave the database alone, got %d books", n)
+		}
+		if _, err := os.Stat(dbPath + ".restore"); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Expected the unpacked backup to be removed, got %v", err)
+		}
+	})
+}
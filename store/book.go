@@ -2,11 +2,15 @@ package store
 
 import (
 	"book-tracker/models"
+	"book-tracker/tracing"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -16,45 +20,115 @@ var (
 type BookStore interface {
 	CreateBook(ctx context.Context, book *models.Book) error
 	GetBook(ctx context.Context, id string) (*models.Book, error)
-	ListBooks(ctx context.Context, status string, limit, offset int, title, author string) ([]*models.Book, error)
+	ListBooks(ctx context.Context, filter models.BookFilter, limit, offset int) ([]*models.Book, error)
 	UpdateBook(ctx context.Context, book *models.Book) error
 	DeleteBook(ctx context.Context, id string) error
 	CountBooks(ctx context.Context) (total int, byStatus map[string]int, err error)
+	// CountMatching counts the books ListBooks would return for filter without a limit
+	CountMatching(ctx context.Context, filter models.BookFilter) (int, error)
 }
 
 type bookStore struct {
-	db *sql.DB
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewBookStore(db *DB) BookStore {
+	return &bookStore{db: db, dialect: sqliteDialect, now: time.Now}
 }
 
-func NewBookStore(db *sql.DB) BookStore {
-	return &bookStore{db: db}
+const bookColumns = "id, title, author, status, pages, completed_at"
+
+// NOTE: Small helper so the column list and the Scan order only live in one place
+type rowScanner interface {
+	Scan(dest ...any) error
 }
 
-func (s *bookStore) CreateBook(ctx context.Context, book *models.Book) error {
-	// NOTE: Documentation: https://pkg.go.dev/database/sql#Conn.ExecContext
-	_, err := s.db.ExecContext(ctx, `
-        INSERT INTO books (id, title, author, status) VALUES (?, ?, ?, ?)`,
-		book.ID, book.Title, book.Author, book.Status)
+func scanBook(row rowScanner) (*models.Book, error) {
+	var book models.Book
+	var completedAt sql.NullTime
+	if err := row.Scan(&book.ID, &book.Title, &book.Author, &book.Status, &book.Pages, &completedAt); err != nil {
+		return nil, err
+	}
+	if completedAt.Valid {
+		t := completedAt.Time.UTC() // NOTE: Postgres hands back the session timezone
+		book.CompletedAt = &t
+	}
+	return &book, nil // Lets stress test Garbage Collector =)
+}
+
+const insertBook = `
+        INSERT INTO books (id, title, author, status, pages, completed_at) VALUES (?, ?, ?, ?, ?, ?)`
+
+func (s *bookStore) CreateBook(ctx context.Context, book *models.Book) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.CreateBook", insertBook)
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	book.CompletedAt = nil
+	if book.Status == models.BookComplete {
+		book.CompletedAt = &now
+	}
+	book.Series = nil // NOTE: A new book is in no series yet, see SeriesStore.SetVolume
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		if err := resolveContributors(ctx, s.dialect, tx, book); err != nil {
+			return err
+		}
+
+		// NOTE: Documentation: https://pkg.go.dev/database/sql#Conn.ExecContext
+		_, err := tx.ExecContext(ctx, s.dialect.bind(insertBook), book.ID, book.Title, book.Author, book.Status, book.Pages, book.CompletedAt)
+		if err != nil {
+			return fmt.Errorf("create book: %w", err)
+		}
+		if err := setTags(ctx, s.dialect, tx, book.ID, book.Tags); err != nil {
+			return err
+		}
+		if err := setContributors(ctx, s.dialect, tx, book.ID, book.Contributors); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.dialect, tx, book, "", now)
+	})
+}
+
+const insertBookEvent = `
+        INSERT INTO book_events (book_id, from_status, to_status, pages, occurred_at) VALUES (?, ?, ?, ?, ?)`
+
+// recordEvent stores a status change or the new page count of book. from is empty for a newly created book
+// and the same as the status of book for a page count
+func recordEvent(ctx context.Context, d dialect, tx *sql.Tx, book *models.Book, from models.BookStatus, at time.Time) error {
+	var fromStatus sql.NullString
+	if from != "" {
+		fromStatus = sql.NullString{String: string(from), Valid: true}
+	}
+	_, err := tx.ExecContext(ctx, d.bind(insertBookEvent), book.ID, fromStatus, book.Status, book.Pages, at)
 	if err != nil {
-		return fmt.Errorf("create book: %w", err)
+		return fmt.Errorf("record book event: %w", err)
 	}
 	return nil
 }
 
-func (s *bookStore) GetBook(ctx context.Context, id string) (*models.Book, error) {
-	// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.QueryRowContext
-	var book models.Book
-	err := s.db.QueryRowContext(ctx, `
-        SELECT id, title, author, status
+const selectBook = `
+        SELECT ` + bookColumns + `
         FROM books
-        WHERE id = ?`, id).Scan(&book.ID, &book.Title, &book.Author, &book.Status)
+        WHERE id = ?`
+
+func (s *bookStore) GetBook(ctx context.Context, id string) (_ *models.Book, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.GetBook", selectBook)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.QueryRowContext
+	book, err := scanBook(s.db.Read.QueryRowContext(ctx, s.dialect.bind(selectBook), id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, ErrBookNotFound
 		}
 		return nil, fmt.Errorf("get book: %w", err)
 	}
-	return &book, nil // Lets stress test Garbage Collector =)
+	if err := loadRelated(ctx, s.dialect, s.db.Read, []*models.Book{book}); err != nil {
+		return nil, fmt.Errorf("get book: %w", err)
+	}
+	return book, nil
 }
 
 // IMPORTANT:
@@ -62,30 +136,18 @@ func (s *bookStore) GetBook(ctx context.Context, id string) (*models.Book, error
 // all validations in the service layer so nothing dangerous will be injected into here
 // Also, in this case, i am careful not to bring in too many external libraries but this could be simplified
 // alot with a ORM like Prisma (or GORM of go in this case). But that also adds overhead
-func (s *bookStore) ListBooks(ctx context.Context, status string, limit, offset int, title, author string) ([]*models.Book, error) {
+func (s *bookStore) ListBooks(ctx context.Context, filter models.BookFilter, limit, offset int) (_ []*models.Book, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.ListBooks", "")
+	defer func() { tracing.End(span, err) }()
+
 	// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.QueryContext
-	query := "SELECT id, title, author, status FROM books"
-	args := []any{}
-	conditions := []string{}
-	if status != "" {
-		conditions = append(conditions, "status = ?")
-		args = append(args, status)
-	}
-	if title != "" {
-		conditions = append(conditions, "title = ?")
-		args = append(args, title)
-	}
-	if author != "" {
-		conditions = append(conditions, "author = ?")
-		args = append(args, author)
-	}
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
-	query += " ORDER BY title ASC LIMIT ? OFFSET ?"
+	where, args := s.where(filter)
+	query := "SELECT " + bookColumns + " FROM books" + where + " ORDER BY " + s.orderBy(filter.Sort) + " LIMIT ? OFFSET ?"
 	args = append(args, limit, offset)
+	query = s.dialect.bind(query)
+	span.SetAttributes(attribute.String("db.query.text", query))
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.Read.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query books: %w", err)
 	}
@@ -93,66 +155,209 @@ func (s *bookStore) ListBooks(ctx context.Context, status string, limit, offset
 
 	books := []*models.Book{}
 	for rows.Next() {
-		var book models.Book
-		if err := rows.Scan(&book.ID, &book.Title, &book.Author, &book.Status); err != nil {
+		book, err := scanBook(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan book: %w", err)
 		}
-		books = append(books, &book) // Lets stress test Garbage Collector =)
+		books = append(books, book)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
+	rows.Close() // NOTE: Before loadRelated, Postgres cant run a second query while the rows are open
+	if err := loadRelated(ctx, s.dialect, s.db.Read, books); err != nil {
+		return nil, fmt.Errorf("list books: %w", err)
+	}
 	return books, nil
 }
 
-func (s *bookStore) UpdateBook(ctx context.Context, book *models.Book) error {
-	// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.ExecContext
-	result, err := s.db.ExecContext(ctx, `
-        UPDATE books
-        SET title = ?, author = ?, status = ?
-        WHERE id = ?
-    `, book.Title, book.Author, book.Status, book.ID)
-	if err != nil {
-		return fmt.Errorf("update book: %w", err)
+// where builds the WHERE clause (with its leading space) of filter, empty when nothing is filtered
+func (s *bookStore) where(filter models.BookFilter) (string, []any) {
+	args := []any{}
+	conditions := []string{}
+	if filter.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, filter.Status)
 	}
-	// NOTE: Documentation: https://pkg.go.dev/database/sql#Result
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("check rows affected: %w", err)
+	// NOTE: Title and author match the whole value but ignore case. The author is any one of the authors
+	// of a book, so a co-written book shows up for each of them
+	if filter.Title != "" {
+		conditions = append(conditions, fmt.Sprintf(s.dialect.equalFold, "title"))
+		args = append(args, filter.Title)
 	}
-	if rowsAffected == 0 {
-		return ErrBookNotFound
+	if filter.Author != "" {
+		conditions = append(conditions, `id IN (
+			SELECT book_authors.book_id FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+			WHERE book_authors.role = 'author' AND `+fmt.Sprintf(s.dialect.equalFold, "authors.name")+`)`)
+		args = append(args, filter.Author)
 	}
-	return nil
+	tagConds, tagArgs := tagConditions(filter)
+	conditions = append(conditions, tagConds...)
+	args = append(args, tagArgs...)
+	from, to := filter.CompletedRange()
+	if from != nil {
+		conditions = append(conditions, "completed_at >= ?")
+		args = append(args, *from)
+	}
+	if to != nil {
+		conditions = append(conditions, "completed_at < ?")
+		args = append(args, *to)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
 }
 
-func (s *bookStore) DeleteBook(ctx context.Context, id string) error {
-	// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.ExecContext
-	result, err := s.db.ExecContext(ctx, "DELETE FROM books WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("delete book: %w", err)
+// orderBy is the ORDER BY clause for sort. Books that were never completed (or are in no series) go last either way
+func (s *bookStore) orderBy(sort models.BookSort) string {
+	title := fmt.Sprintf(s.dialect.byteOrder, "title")
+	field, desc := sort.Split()
+	direction := " ASC"
+	if desc {
+		direction = " DESC"
 	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("check rows affected: %w", err)
+	switch field {
+	case models.SortAuthor:
+		return fmt.Sprintf(s.dialect.byteOrder, "author") + direction + ", " + title + " ASC"
+	case models.SortPages:
+		return "pages" + direction + ", " + title + " ASC"
+	case models.SortCompletedAt:
+		// NOTE: SQLite puts NULLs first and Postgres last, sorting on IS NULL first makes them agree
+		return "(completed_at IS NULL), completed_at" + direction + ", " + title + " ASC"
+	case models.SortSeries:
+		// NOTE: Subqueries instead of a join so the WHERE of the filter can keep its unqualified columns.
+		// The series id keeps two series with the same name apart
+		name := "(SELECT series.name FROM series_books JOIN series ON series.id = series_books.series_id WHERE series_books.book_id = books.id)"
+		return "(" + name + " IS NULL), " + fmt.Sprintf(s.dialect.byteOrder, name) + direction +
+			", (SELECT series_id FROM series_books WHERE series_books.book_id = books.id)" + direction +
+			", (SELECT position FROM series_books WHERE series_books.book_id = books.id)" + direction + ", " + title + " ASC"
+	default:
+		return title + direction
 	}
-	if rowsAffected == 0 {
-		return ErrBookNotFound
+}
+
+func (s *bookStore) CountMatching(ctx context.Context, filter models.BookFilter) (_ int, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.CountMatching", "")
+	defer func() { tracing.End(span, err) }()
+
+	where, args := s.where(filter)
+	query := s.dialect.bind("SELECT COUNT(*) FROM books" + where)
+	span.SetAttributes(attribute.String("db.query.text", query))
+
+	var count int
+	if err := s.db.Read.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count books: %w", err)
 	}
-	return nil
+	return count, nil
+}
+
+// NOTE: completed_at keeps its original value while the book stays complete and is cleared when it leaves it.
+// RETURNING hands back the stored value and doubles as the not found check (no row = no book)
+const updateBook = `
+        UPDATE books
+        SET title = ?, author = ?, status = ?, pages = ?,
+            completed_at = CASE WHEN ? = 'complete' THEN COALESCE(completed_at, ?) ELSE NULL END
+        WHERE id = ?
+        RETURNING completed_at
+    `
+
+func (s *bookStore) UpdateBook(ctx context.Context, book *models.Book) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.UpdateBook", updateBook)
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		var previous models.BookStatus
+		var previousPages int
+		err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT status, pages FROM books WHERE id = ?"+s.dialect.lockRow), book.ID).Scan(&previous, &previousPages)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrBookNotFound
+			}
+			return fmt.Errorf("get book status: %w", err)
+		}
+
+		if err := resolveContributors(ctx, s.dialect, tx, book); err != nil {
+			return err
+		}
+
+		// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.QueryRowContext
+		var completedAt sql.NullTime
+		err = tx.QueryRowContext(ctx, s.dialect.bind(updateBook), book.Title, book.Author, book.Status, book.Pages, book.Status, now, book.ID).Scan(&completedAt)
+		if err != nil {
+			return fmt.Errorf("update book: %w", err)
+		}
+		book.CompletedAt = nil
+		if completedAt.Valid {
+			t := completedAt.Time.UTC() // NOTE: Postgres hands back the session timezone, same as in scanBook
+			book.CompletedAt = &t
+		}
+
+		if err := setTags(ctx, s.dialect, tx, book.ID, book.Tags); err != nil {
+			return err
+		}
+		if err := setContributors(ctx, s.dialect, tx, book.ID, book.Contributors); err != nil {
+			return err
+		}
+		// NOTE: The series is not part of an update, hand back the one the book is in
+		book.Series = nil
+		if err := loadSeries(ctx, s.dialect, tx, []*models.Book{book}); err != nil {
+			return fmt.Errorf("update book: %w", err)
+		}
+		// NOTE: A new page count is progress even when the status stays, the streaks and the heatmap count it
+		if previous == book.Status && previousPages == book.Pages {
+			return nil
+		}
+		return recordEvent(ctx, s.dialect, tx, book, previous, now)
+	})
+}
+
+const deleteBook = "DELETE FROM books WHERE id = ?"
+
+func (s *bookStore) DeleteBook(ctx context.Context, id string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.DeleteBook", deleteBook)
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.ExecContext
+		result, err := tx.ExecContext(ctx, s.dialect.bind(deleteBook), id)
+		if err != nil {
+			return fmt.Errorf("delete book: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("check rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrBookNotFound
+		}
+		// NOTE: The history goes with the book, stats only ever cover books that still exist
+		if _, err := tx.ExecContext(ctx, s.dialect.bind("DELETE FROM book_events WHERE book_id = ?"), id); err != nil {
+			return fmt.Errorf("delete book events: %w", err)
+		}
+		// NOTE: The book_tags rows go with the cascade, the tags only this book had are pruned here
+		if _, err := tx.ExecContext(ctx, pruneTags); err != nil {
+			return fmt.Errorf("prune tags: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *bookStore) CountBooks(ctx context.Context) (total int, byStatus map[string]int, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "bookStore.CountBooks", "")
+	defer func() { tracing.End(span, err) }()
+
 	// NOTE: Documentation: https://pkg.go.dev/database/sql#DB.QueryContext
 	// NOTE:
 	// var total int <--- Not possible (or already in-scope) as it somehow declares the variables in the function
 	// return declaration as in-scope variables?
-	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books").Scan(&total)
+	err = s.db.Read.QueryRowContext(ctx, "SELECT COUNT(*) FROM books").Scan(&total)
 	if err != nil {
 		return 0, nil, fmt.Errorf("count total books: %w", err)
 	}
 	byStatus = make(map[string]int)
-	rows, err := s.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM books GROUP BY status")
+	rows, err := s.db.Read.QueryContext(ctx, "SELECT status, COUNT(*) FROM books GROUP BY status")
 	if err != nil {
 		return total, nil, fmt.Errorf("query books by status: %w", err)
 	}
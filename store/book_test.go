@@ -2,7 +2,6 @@ package store
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"testing"
 
@@ -11,7 +10,7 @@ import (
 	"github.com/google/uuid"
 )
 
-func setupDB(t *testing.T) (*sql.DB, func()) {
+func setupDB(t *testing.T) (*DB, func()) {
 	db, cleanup, err := NewDB(":memory:")
 	if err != nil {
 		t.Fatalf("Failed to initialize database: %v", err)
@@ -90,7 +89,7 @@ func TestBookStore(t *testing.T) {
 			}
 		}
 
-		got, err := store.ListBooks(ctx, "", 10, 0, "", "")
+		got, err := store.ListBooks(ctx, models.BookFilter{}, 10, 0)
 		if err != nil {
 			t.Errorf("ListBooks failed: %v", err)
 		}
@@ -144,6 +143,50 @@ func TestBookStore(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateBook_CompletedAt", func(t *testing.T) {
+		book := &models.Book{
+			ID:     uuid.NewString(),
+			Title:  "Completed Book",
+			Author: "Completed Author",
+			Status: models.BookReading,
+		}
+		if err := store.CreateBook(ctx, book); err != nil {
+			t.Fatalf("CreateBook failed: %v", err)
+		}
+		if book.CompletedAt != nil {
+			t.Errorf("CompletedAt = %v, want nil for a book being read", book.CompletedAt)
+		}
+
+		book.Status = models.BookComplete
+		if err := store.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		if book.CompletedAt == nil {
+			t.Fatalf("CompletedAt not set when book was completed")
+		}
+		first := *book.CompletedAt
+
+		book.Title = "Completed Book (2nd edition)"
+		if err := store.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		if book.CompletedAt == nil || !book.CompletedAt.Equal(first) {
+			t.Errorf("CompletedAt = %v, want it kept at %v while still complete", book.CompletedAt, first)
+		}
+
+		book.Status = models.BookReading
+		if err := store.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		got, err := store.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("GetBook failed: %v", err)
+		}
+		if got.CompletedAt != nil {
+			t.Errorf("CompletedAt = %v, want nil after leaving complete", got.CompletedAt)
+		}
+	})
+
 	t.Run("DeleteBook", func(t *testing.T) {
 		book := &models.Book{
 			ID:     uuid.NewString(),
@@ -261,7 +304,7 @@ func TestBookStore(t *testing.T) {
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				got, err := store.ListBooks(ctx, "", tt.limit, tt.offset, "", "")
+				got, err := store.ListBooks(ctx, models.BookFilter{}, tt.limit, tt.offset)
 				if err != nil {
 					t.Errorf("ListBooks failed: %v", err)
 				}
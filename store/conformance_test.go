@@ -0,0 +1,1423 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+// backend is one BookStore/StatsStore/TagStore/ShelfStore/SeriesStore/AuthorStore/ReviewStore/NoteStore implementation under test. setNow controls the
+// clock used for completed_at and the book events
+type backend struct {
+	books      BookStore
+	stats      StatsStore
+	tags       TagStore
+	shelves    ShelfStore
+	series     SeriesStore
+	authors    AuthorStore
+	reviews    ReviewStore
+	notes      NoteStore
+	highlights HighlightStore
+	setNow     func(now func() time.Time)
+}
+
+// NOTE: Every new backend gets added here so it has to pass the exact same tests as the others
+var backends = map[string]func(t *testing.T) backend{
+	"sqlite": func(t *testing.T) backend {
+		db, cleanup := setupDB(t)
+		t.Cleanup(cleanup)
+		books := NewBookStore(db).(*bookStore)
+		reviews := NewReviewStore(db).(*reviewStore)
+		notes := NewNoteStore(db).(*noteStore)
+		highlights := NewHighlightStore(db).(*highlightStore)
+		return backend{books: books, stats: NewStatsStore(db), tags: NewTagStore(db), shelves: NewShelfStore(db), series: NewSeriesStore(db), authors: NewAuthorStore(db), reviews: reviews, notes: notes, highlights: highlights,
+			setNow: func(now func() time.Time) { books.now, reviews.now, notes.now, highlights.now = now, now, now, now }}
+	},
+	"memory": func(t *testing.T) backend {
+		mem := NewMemoryStore()
+		return backend{books: mem, stats: mem, tags: mem, shelves: mem, series: mem, authors: mem, reviews: mem, notes: mem, highlights: mem, setNow: func(now func() time.Time) { mem.now = now }}
+	},
+	"postgres": func(t *testing.T) backend {
+		db := setupPostgres(t) // Skips unless a Postgres is configured, see postgres_test.go
+		books := NewPostgresBookStore(db).(*bookStore)
+		reviews := NewPostgresReviewStore(db).(*reviewStore)
+		notes := NewPostgresNoteStore(db).(*noteStore)
+		highlights := NewPostgresHighlightStore(db).(*highlightStore)
+		return backend{books: books, stats: NewPostgresStatsStore(db), tags: NewPostgresTagStore(db), shelves: NewPostgresShelfStore(db), series: NewPostgresSeriesStore(db), authors: NewPostgresAuthorStore(db), reviews: reviews, notes: notes, highlights: highlights,
+			setNow: func(now func() time.Time) { books.now, reviews.now, notes.now, highlights.now = now, now, now, now }}
+	},
+}
+
+func TestConformance(t *testing.T) {
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			for _, tc := range conformanceTests {
+				t.Run(tc.name, func(t *testing.T) {
+					tc.run(t, open(t))
+				})
+			}
+		})
+	}
+}
+
+func newBook(title, author string, status models.BookStatus, pages int) *models.Book {
+	return &models.Book{ID: uuid.NewString(), Title: title, Author: author, Status: status, Pages: pages}
+}
+
+func mustCreate(t *testing.T, b backend, books ...*models.Book) {
+	t.Helper()
+	for _, book := range books {
+		if err := b.books.CreateBook(context.Background(), book); err != nil {
+			t.Fatalf("CreateBook(%s) failed: %v", book.Title, err)
+		}
+	}
+}
+
+func fixedClock(year int, month time.Month, day int) func() time.Time {
+	return func() time.Time { return time.Date(year, month, day, 12, 0, 0, 0, time.UTC) }
+}
+
+func titles(books []*models.Book) []string {
+	out := make([]string, len(books))
+	for i, b := range books {
+		out[i] = b.Title
+	}
+	return out
+}
+
+var conformanceTests = []struct {
+	name string
+	run  func(t *testing.T, b backend)
+}{
+	{"CreateAndGet", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 5, 1))
+		book := newBook("Emma", "Jane Austen", models.BookComplete, 474)
+		mustCreate(t, b, book)
+
+		got, err := b.books.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("GetBook failed: %v", err)
+		}
+		if got.Title != book.Title || got.Author != book.Author || got.Status != book.Status || got.Pages != book.Pages {
+			t.Errorf("GetBook = %+v, want %+v", got, book)
+		}
+		want := fixedClock(2026, 5, 1)()
+		if got.CompletedAt == nil || !got.CompletedAt.Equal(want) {
+			t.Errorf("CompletedAt = %v, want %v", got.CompletedAt, want)
+		}
+	}},
+	{"CreateDuplicateID", func(t *testing.T, b backend) {
+		book := newBook("Emma", "Jane Austen", models.BookUnread, 0)
+		mustCreate(t, b, book)
+		if err := b.books.CreateBook(context.Background(), book); err == nil {
+			t.Error("CreateBook with an existing id succeeded, want an error")
+		}
+	}},
+	{"GetNotFound", func(t *testing.T, b backend) {
+		_, err := b.books.GetBook(context.Background(), uuid.NewString())
+		if !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("GetBook error = %v, want ErrBookNotFound", err)
+		}
+	}},
+	{"ListSortFilterPaginate", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		mustCreate(t, b,
+			newBook("Persuasion", "Jane Austen", models.BookUnread, 0),
+			newBook("Emma", "Jane Austen", models.BookComplete, 0),
+			newBook("Walden", "Henry Thoreau", models.BookReading, 0),
+			newBook("Anna Karenina", "Leo Tolstoy", models.BookComplete, 0),
+			newBook("emma", "Jane Austen", models.BookUnread, 0), // Lower case sorts after upper case
+		)
+
+		tests := []struct {
+			status, title, author string
+			limit, offset         int
+			want                  []string
+		}{
+			{limit: 10, want: []string{"Anna Karenina", "Emma", "Persuasion", "Walden", "emma"}},
+			{limit: 2, offset: 1, want: []string{"Emma", "Persuasion"}},
+			{limit: 10, offset: 10, want: []string{}},
+			{status: "complete", limit: 10, want: []string{"Anna Karenina", "Emma"}},
+			{author: "jane austen", limit: 10, want: []string{"Emma", "Persuasion", "emma"}},
+			{title: "EMMA", limit: 10, want: []string{"Emma", "emma"}},
+			{title: "Emm%", limit: 10, want: []string{}}, // Not a pattern
+			{status: "reading", author: "Jane Austen", limit: 10, want: []string{}},
+		}
+		for _, tt := range tests {
+			filter := models.BookFilter{Status: models.BookStatus(tt.status), Title: tt.title, Author: tt.author}
+			books, err := b.books.ListBooks(ctx, filter, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("ListBooks failed: %v", err)
+			}
+			if got := titles(books); !slices.Equal(got, tt.want) {
+				t.Errorf("ListBooks(status=%q, title=%q, author=%q, limit=%d, offset=%d) = %v, want %v",
+					tt.status, tt.title, tt.author, tt.limit, tt.offset, got, tt.want)
+			}
+		}
+	}},
+	{"ListSortAndCompletedRange", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 3, 10))
+		mustCreate(t, b, newBook("Emma", "Jane Austen", models.BookComplete, 474))
+		b.setNow(fixedClock(2026, 4, 30))
+		mustCreate(t, b, newBook("Walden", "Henry Thoreau", models.BookComplete, 352))
+		b.setNow(fixedClock(2026, 5, 1))
+		mustCreate(t, b,
+			newBook("Dune", "Frank Herbert", models.BookComplete, 612),
+			newBook("Persuasion", "Jane Austen", models.BookUnread, 249),
+			newBook("Anna Karenina", "Leo Tolstoy", models.BookReading, 864),
+		)
+
+		tests := []struct {
+			filter models.BookFilter
+			want   []string
+		}{
+			{models.BookFilter{Sort: "-title"}, []string{"Walden", "Persuasion", "Emma", "Dune", "Anna Karenina"}},
+			{models.BookFilter{Sort: "author"}, []string{"Dune", "Walden", "Emma", "Persuasion", "Anna Karenina"}},
+			{models.BookFilter{Sort: "-pages"}, []string{"Anna Karenina", "Dune", "Emma", "Walden", "Persuasion"}},
+			// Never completed goes last in both directions
+			{models.BookFilter{Sort: "completed_at"}, []string{"Emma", "Walden", "Dune", "Anna Karenina", "Persuasion"}},
+			{models.BookFilter{Sort: "-completed_at"}, []string{"Dune", "Walden", "Emma", "Anna Karenina", "Persuasion"}},
+			{models.BookFilter{CompletedFrom: "2026-04-01", CompletedTo: "2026-04-30"}, []string{"Walden"}},
+			{models.BookFilter{CompletedFrom: "2026-04-30"}, []string{"Dune", "Walden"}},
+			{models.BookFilter{CompletedTo: "2026-03-10", Author: "jane austen"}, []string{"Emma"}},
+		}
+		for _, tt := range tests {
+			books, err := b.books.ListBooks(ctx, tt.filter, 10, 0)
+			if err != nil {
+				t.Fatalf("ListBooks failed: %v", err)
+			}
+			if got := titles(books); !slices.Equal(got, tt.want) {
+				t.Errorf("ListBooks(%+v) = %v, want %v", tt.filter, got, tt.want)
+			}
+			count, err := b.books.CountMatching(ctx, tt.filter)
+			if err != nil || count != len(tt.want) {
+				t.Errorf("CountMatching(%+v) = %d (%v), want %d", tt.filter, count, err, len(tt.want))
+			}
+		}
+	}},
+	{"ListByTags", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		emma := newBook("Emma", "Jane Austen", models.BookUnread, 0)
+		emma.Tags = []string{"classic", "romance"}
+		walden := newBook("Walden", "Henry Thoreau", models.BookUnread, 0)
+		walden.Tags = []string{"classic", "nature"}
+		dune := newBook("Dune", "Frank Herbert", models.BookUnread, 0)
+		dune.Tags = []string{"sci-fi"}
+		mustCreate(t, b, emma, walden, dune, newBook("Untagged", "Nobody", models.BookUnread, 0))
+
+		tests := []struct {
+			tags, anyTags []string
+			want          []string
+		}{
+			{tags: []string{"classic"}, want: []string{"Emma", "Walden"}},
+			{tags: []string{"classic", "nature"}, want: []string{"Walden"}},
+			{tags: []string{"classic", "sci-fi"}, want: []string{}},
+			{anyTags: []string{"nature", "sci-fi"}, want: []string{"Dune", "Walden"}},
+			{tags: []string{"classic"}, anyTags: []string{"romance", "sci-fi"}, want: []string{"Emma"}},
+			{tags: []string{"unknown"}, want: []string{}},
+		}
+		for _, tt := range tests {
+			books, err := b.books.ListBooks(ctx, models.BookFilter{Tags: tt.tags, AnyTags: tt.anyTags}, 10, 0)
+			if err != nil {
+				t.Fatalf("ListBooks failed: %v", err)
+			}
+			if got := titles(books); !slices.Equal(got, tt.want) {
+				t.Errorf("ListBooks(tag=%v, tag_any=%v) = %v, want %v", tt.tags, tt.anyTags, got, tt.want)
+			}
+		}
+
+		got, err := b.books.GetBook(ctx, walden.ID)
+		if err != nil || !slices.Equal(got.Tags, []string{"classic", "nature"}) {
+			t.Errorf("GetBook tags = %v (%v), want [classic nature]", got.Tags, err)
+		}
+		walden.Tags = []string{"essays"}
+		if err := b.books.UpdateBook(ctx, walden); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		if err := b.books.DeleteBook(ctx, dune.ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		// Tags nobody uses anymore are gone
+		tags, err := b.tags.ListTags(ctx)
+		if err != nil {
+			t.Fatalf("ListTags failed: %v", err)
+		}
+		want := []models.TagCount{{Name: "classic", Books: 1}, {Name: "essays", Books: 1}, {Name: "romance", Books: 1}}
+		if !slices.Equal(tags, want) {
+			t.Errorf("ListTags = %v, want %v", tags, want)
+		}
+	}},
+	{"ManageTags", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		emma := newBook("Emma", "Jane Austen", models.BookUnread, 0)
+		emma.Tags = []string{"classic", "classics"}
+		walden := newBook("Walden", "Henry Thoreau", models.BookUnread, 0)
+		walden.Tags = []string{"classics", "nature"}
+		mustCreate(t, b, emma, walden)
+
+		if err := b.tags.MergeTags(ctx, "classics", "classic"); err != nil {
+			t.Fatalf("MergeTags failed: %v", err)
+		}
+		if err := b.tags.RenameTag(ctx, "nature", "classic"); !errors.Is(err, ErrTagExists) {
+			t.Errorf("RenameTag onto an existing tag error = %v, want ErrTagExists", err)
+		}
+		if err := b.tags.RenameTag(ctx, "nature", "outdoors"); err != nil {
+			t.Fatalf("RenameTag failed: %v", err)
+		}
+		for _, tt := range []struct {
+			book *models.Book
+			want []string
+		}{{emma, []string{"classic"}}, {walden, []string{"classic", "outdoors"}}} {
+			got, err := b.books.GetBook(ctx, tt.book.ID)
+			if err != nil || !slices.Equal(got.Tags, tt.want) {
+				t.Errorf("GetBook(%s) tags = %v (%v), want %v", tt.book.Title, got.Tags, err, tt.want)
+			}
+		}
+
+		if err := b.tags.DeleteTag(ctx, "classic"); err != nil {
+			t.Fatalf("DeleteTag failed: %v", err)
+		}
+		tags, err := b.tags.ListTags(ctx)
+		if err != nil || !slices.Equal(tags, []models.TagCount{{Name: "outdoors", Books: 1}}) {
+			t.Errorf("ListTags = %v (%v), want only outdoors", tags, err)
+		}
+		if got, err := b.books.GetBook(ctx, emma.ID); err != nil || len(got.Tags) != 0 {
+			t.Errorf("GetBook tags = %v (%v), want none", got.Tags, err)
+		}
+
+		for name, err := range map[string]error{
+			"rename": b.tags.RenameTag(ctx, "missing", "other"),
+			"merge":  b.tags.MergeTags(ctx, "missing", "outdoors"),
+			"into":   b.tags.MergeTags(ctx, "outdoors", "missing"),
+			"delete": b.tags.DeleteTag(ctx, "classic"),
+		} {
+			if !errors.Is(err, ErrTagNotFound) {
+				t.Errorf("%s error = %v, want ErrTagNotFound", name, err)
+			}
+		}
+	}},
+	{"Shelves", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		first := &models.Shelf{ID: uuid.NewString(), Name: "Book club 2026"}
+		second := &models.Shelf{ID: uuid.NewString(), Name: "Onboarding", Description: "Read these first"}
+		for _, shelf := range []*models.Shelf{first, second} {
+			if err := b.shelves.CreateShelf(ctx, shelf); err != nil {
+				t.Fatalf("CreateShelf failed: %v", err)
+			}
+		}
+		book := newBook("Emma", "Jane Austen", models.BookUnread, 0)
+		mustCreate(t, b, book)
+		// A book can be on several shelves
+		for _, shelf := range []*models.Shelf{first, second} {
+			if err := b.shelves.AddBook(ctx, shelf.ID, book.ID, nil); err != nil {
+				t.Fatalf("AddBook failed: %v", err)
+			}
+		}
+		if err := b.shelves.AddBook(ctx, first.ID, book.ID, nil); !errors.Is(err, ErrBookOnShelf) {
+			t.Errorf("AddBook twice error = %v, want ErrBookOnShelf", err)
+		}
+		if err := b.shelves.AddBook(ctx, first.ID, uuid.NewString(), nil); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("AddBook of a missing book error = %v, want ErrBookNotFound", err)
+		}
+
+		second.Name = "Onboarding reading"
+		if err := b.shelves.UpdateShelf(ctx, second); err != nil {
+			t.Fatalf("UpdateShelf failed: %v", err)
+		}
+		if second.Books != 1 || second.CreatedAt.IsZero() {
+			t.Errorf("UpdateShelf = %+v, want the book count and created_at filled in", second)
+		}
+		shelves, err := b.shelves.ListShelves(ctx)
+		if err != nil {
+			t.Fatalf("ListShelves failed: %v", err)
+		}
+		if len(shelves) != 2 || shelves[1].Name != "Onboarding reading" || shelves[1].Description != "Read these first" || shelves[0].Books != 1 {
+			t.Errorf("ListShelves = %+v", shelves)
+		}
+
+		if err := b.shelves.DeleteShelf(ctx, first.ID); err != nil {
+			t.Fatalf("DeleteShelf failed: %v", err)
+		}
+		if _, err := b.shelves.GetShelf(ctx, first.ID); !errors.Is(err, ErrShelfNotFound) {
+			t.Errorf("GetShelf after delete error = %v, want ErrShelfNotFound", err)
+		}
+		if _, err := b.books.GetBook(ctx, book.ID); err != nil {
+			t.Errorf("GetBook after deleting its shelf error = %v, want the book to stay", err)
+		}
+		// Deleting the book takes it off its shelves
+		if err := b.books.DeleteBook(ctx, book.ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		if got, err := b.shelves.GetShelf(ctx, second.ID); err != nil || got.Books != 0 {
+			t.Errorf("GetShelf = %+v (%v), want no books left", got, err)
+		}
+
+		for name, err := range map[string]error{
+			"update": b.shelves.UpdateShelf(ctx, first),
+			"delete": b.shelves.DeleteShelf(ctx, first.ID),
+			"add":    b.shelves.AddBook(ctx, first.ID, book.ID, nil),
+		} {
+			if !errors.Is(err, ErrShelfNotFound) {
+				t.Errorf("%s error = %v, want ErrShelfNotFound", name, err)
+			}
+		}
+		if _, err := b.shelves.ListShelfBooks(ctx, first.ID, 10, 0); !errors.Is(err, ErrShelfNotFound) {
+			t.Errorf("ListShelfBooks error = %v, want ErrShelfNotFound", err)
+		}
+	}},
+	{"ShelfOrder", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		shelf := &models.Shelf{ID: uuid.NewString(), Name: "Book club"}
+		if err := b.shelves.CreateShelf(ctx, shelf); err != nil {
+			t.Fatalf("CreateShelf failed: %v", err)
+		}
+		books := map[string]*models.Book{}
+		for _, title := range []string{"A", "B", "C", "D", "E"} {
+			books[title] = newBook(title, "Author", models.BookUnread, 0)
+			mustCreate(t, b, books[title])
+		}
+		at := func(position int) *int { return &position }
+
+		expect := func(step string, want ...string) {
+			t.Helper()
+			got, err := b.shelves.ListShelfBooks(ctx, shelf.ID, 10, 0)
+			if err != nil {
+				t.Fatalf("%s: ListShelfBooks failed: %v", step, err)
+			}
+			if !slices.Equal(titles(got), want) {
+				t.Errorf("%s: shelf = %v, want %v", step, titles(got), want)
+			}
+		}
+
+		for _, add := range []struct {
+			title    string
+			position *int
+		}{{"A", nil}, {"B", nil}, {"C", at(0)}, {"D", at(1)}, {"E", at(99)}} {
+			if err := b.shelves.AddBook(ctx, shelf.ID, books[add.title].ID, add.position); err != nil {
+				t.Fatalf("AddBook(%s) failed: %v", add.title, err)
+			}
+		}
+		expect("add", "C", "D", "A", "B", "E")
+
+		if err := b.shelves.MoveBook(ctx, shelf.ID, books["C"].ID, 3); err != nil {
+			t.Fatalf("MoveBook failed: %v", err)
+		}
+		expect("move down", "D", "A", "B", "C", "E")
+		if err := b.shelves.MoveBook(ctx, shelf.ID, books["E"].ID, 0); err != nil {
+			t.Fatalf("MoveBook failed: %v", err)
+		}
+		expect("move up", "E", "D", "A", "B", "C")
+
+		if err := b.shelves.RemoveBook(ctx, shelf.ID, books["D"].ID); err != nil {
+			t.Fatalf("RemoveBook failed: %v", err)
+		}
+		// A deleted book leaves a gap in the stored positions, the order has to survive that
+		if err := b.books.DeleteBook(ctx, books["A"].ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		expect("remove", "E", "B", "C")
+		if err := b.shelves.MoveBook(ctx, shelf.ID, books["C"].ID, 1); err != nil {
+			t.Fatalf("MoveBook failed: %v", err)
+		}
+		expect("move after a gap", "E", "C", "B")
+
+		got, err := b.shelves.ListShelfBooks(ctx, shelf.ID, 1, 1)
+		if err != nil || !slices.Equal(titles(got), []string{"C"}) {
+			t.Errorf("ListShelfBooks(limit=1, offset=1) = %v (%v), want [C]", titles(got), err)
+		}
+		if got, err := b.shelves.ListShelfBooks(ctx, shelf.ID, 10, 10); err != nil || len(got) != 0 {
+			t.Errorf("ListShelfBooks past the end = %v (%v), want none", titles(got), err)
+		}
+
+		for name, err := range map[string]error{
+			"move":   b.shelves.MoveBook(ctx, shelf.ID, books["D"].ID, 0),
+			"remove": b.shelves.RemoveBook(ctx, shelf.ID, books["D"].ID),
+		} {
+			if !errors.Is(err, ErrBookNotOnShelf) {
+				t.Errorf("%s error = %v, want ErrBookNotOnShelf", name, err)
+			}
+		}
+	}},
+	{"Series", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		expanse := &models.Series{ID: uuid.NewString(), Name: "The Expanse"}
+		other := &models.Series{ID: uuid.NewString(), Name: "Discworld"}
+		for _, series := range []*models.Series{expanse, other} {
+			if err := b.series.CreateSeries(ctx, series); err != nil {
+				t.Fatalf("CreateSeries failed: %v", err)
+			}
+		}
+		books := map[string]*models.Book{}
+		for _, title := range []string{"Leviathan Wakes", "Caliban's War", "Gods of Risk", "Abaddon's Gate"} {
+			books[title] = newBook(title, "James S. A. Corey", models.BookUnread, 0)
+			mustCreate(t, b, books[title])
+		}
+		for title, position := range map[string]float64{"Leviathan Wakes": 1, "Caliban's War": 2, "Gods of Risk": 2.5, "Abaddon's Gate": 30} {
+			if err := b.series.SetVolume(ctx, expanse.ID, books[title].ID, position); err != nil {
+				t.Fatalf("SetVolume(%s) failed: %v", title, err)
+			}
+		}
+		// Setting it again moves the volume
+		if err := b.series.SetVolume(ctx, expanse.ID, books["Abaddon's Gate"].ID, 3); err != nil {
+			t.Fatalf("SetVolume move failed: %v", err)
+		}
+		volumes, err := b.series.ListVolumes(ctx, expanse.ID)
+		if err != nil {
+			t.Fatalf("ListVolumes failed: %v", err)
+		}
+		if want := []string{"Leviathan Wakes", "Caliban's War", "Gods of Risk", "Abaddon's Gate"}; !slices.Equal(titles(volumes), want) {
+			t.Errorf("ListVolumes = %v, want %v", titles(volumes), want)
+		}
+		if s := volumes[2].Series; s == nil || s.ID != expanse.ID || s.Name != "The Expanse" || s.Position != 2.5 {
+			t.Errorf("ListVolumes series = %+v, want The Expanse at 2.5", s)
+		}
+
+		if err := b.series.SetVolume(ctx, other.ID, books["Gods of Risk"].ID, 1); !errors.Is(err, ErrBookInOtherSeries) {
+			t.Errorf("SetVolume into a second series error = %v, want ErrBookInOtherSeries", err)
+		}
+		if err := b.series.SetVolume(ctx, expanse.ID, uuid.NewString(), 1); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("SetVolume of a missing book error = %v, want ErrBookNotFound", err)
+		}
+
+		// The series shows up on the book and survives an update of it, renaming the series follows through
+		expanse.Name = "Expanse"
+		if err := b.series.UpdateSeries(ctx, expanse); err != nil {
+			t.Fatalf("UpdateSeries failed: %v", err)
+		}
+		if expanse.Books != 4 || expanse.CreatedAt.IsZero() {
+			t.Errorf("UpdateSeries = %+v, want the book count and created_at filled in", expanse)
+		}
+		book := books["Caliban's War"]
+		book.Series = nil
+		book.Status = models.BookReading
+		if err := b.books.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		got, err := b.books.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("GetBook failed: %v", err)
+		}
+		for name, series := range map[string]*models.BookSeries{"UpdateBook": book.Series, "GetBook": got.Series} {
+			if series == nil || series.Name != "Expanse" || series.Position != 2 {
+				t.Errorf("%s series = %+v, want Expanse at 2", name, series)
+			}
+		}
+
+		list, err := b.series.ListSeries(ctx)
+		if err != nil {
+			t.Fatalf("ListSeries failed: %v", err)
+		}
+		if len(list) != 2 || list[0].Name != "Discworld" || list[0].Books != 0 || list[1].Books != 4 {
+			t.Errorf("ListSeries = %+v, want Discworld (0) then Expanse (4)", list)
+		}
+
+		if err := b.series.RemoveVolume(ctx, expanse.ID, books["Gods of Risk"].ID); err != nil {
+			t.Fatalf("RemoveVolume failed: %v", err)
+		}
+		if err := b.series.RemoveVolume(ctx, expanse.ID, books["Gods of Risk"].ID); !errors.Is(err, ErrBookNotInSeries) {
+			t.Errorf("RemoveVolume twice error = %v, want ErrBookNotInSeries", err)
+		}
+		if err := b.books.DeleteBook(ctx, books["Leviathan Wakes"].ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		if got, err := b.series.GetSeries(ctx, expanse.ID); err != nil || got.Books != 2 {
+			t.Errorf("GetSeries = %+v (%v), want 2 books left", got, err)
+		}
+
+		// Deleting the series keeps its books
+		if err := b.series.DeleteSeries(ctx, expanse.ID); err != nil {
+			t.Fatalf("DeleteSeries failed: %v", err)
+		}
+		if got, err := b.books.GetBook(ctx, book.ID); err != nil || got.Series != nil {
+			t.Errorf("GetBook after deleting its series = %+v (%v), want the book without a series", got, err)
+		}
+		for name, err := range map[string]error{
+			"update": b.series.UpdateSeries(ctx, expanse),
+			"delete": b.series.DeleteSeries(ctx, expanse.ID),
+			"set":    b.series.SetVolume(ctx, expanse.ID, book.ID, 1),
+			"remove": b.series.RemoveVolume(ctx, expanse.ID, book.ID),
+		} {
+			if !errors.Is(err, ErrSeriesNotFound) {
+				t.Errorf("%s error = %v, want ErrSeriesNotFound", name, err)
+			}
+		}
+		if _, err := b.series.ListVolumes(ctx, expanse.ID); !errors.Is(err, ErrSeriesNotFound) {
+			t.Errorf("ListVolumes error = %v, want ErrSeriesNotFound", err)
+		}
+	}},
+	{"ListSortBySeries", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		discworld := &models.Series{ID: uuid.NewString(), Name: "Discworld"}
+		expanse := &models.Series{ID: uuid.NewString(), Name: "The Expanse"}
+		for _, series := range []*models.Series{discworld, expanse} {
+			if err := b.series.CreateSeries(ctx, series); err != nil {
+				t.Fatalf("CreateSeries failed: %v", err)
+			}
+		}
+		volumes := []struct {
+			title    string
+			series   *models.Series
+			position float64
+		}{
+			{"Mort", discworld, 4},
+			{"The Colour of Magic", discworld, 1},
+			{"Caliban's War", expanse, 2},
+			{"Leviathan Wakes", expanse, 1},
+			{"Gods of Risk", expanse, 2.5},
+		}
+		for _, v := range volumes {
+			book := newBook(v.title, "Author", models.BookUnread, 0)
+			mustCreate(t, b, book)
+			if err := b.series.SetVolume(ctx, v.series.ID, book.ID, v.position); err != nil {
+				t.Fatalf("SetVolume(%s) failed: %v", v.title, err)
+			}
+		}
+		mustCreate(t, b, newBook("Emma", "Jane Austen", models.BookUnread, 0), newBook("Dracula", "Bram Stoker", models.BookUnread, 0))
+
+		for _, tc := range []struct {
+			sort models.BookSort
+			want []string
+		}{
+			{models.SortSeries, []string{"The Colour of Magic", "Mort", "Leviathan Wakes", "Caliban's War", "Gods of Risk", "Dracula", "Emma"}},
+			{"-series", []string{"Gods of Risk", "Caliban's War", "Leviathan Wakes", "Mort", "The Colour of Magic", "Dracula", "Emma"}},
+		} {
+			got, err := b.books.ListBooks(ctx, models.BookFilter{Sort: tc.sort}, 10, 0)
+			if err != nil {
+				t.Fatalf("ListBooks(%s) failed: %v", tc.sort, err)
+			}
+			if !slices.Equal(titles(got), tc.want) {
+				t.Errorf("ListBooks(%s) = %v, want %v", tc.sort, titles(got), tc.want)
+			}
+		}
+	}},
+	{"UpdateCompletedAt", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		book := newBook("Emma", "Jane Austen", models.BookReading, 0)
+		mustCreate(t, b, book)
+
+		b.setNow(fixedClock(2026, 5, 1))
+		book.Status = models.BookComplete
+		if err := b.books.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		first := fixedClock(2026, 5, 1)()
+
+		// Still complete so the original completion time is kept
+		b.setNow(fixedClock(2026, 6, 1))
+		book.Title = "Emma (annotated)"
+		if err := b.books.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		got, err := b.books.GetBook(ctx, book.ID)
+		if err != nil {
+			t.Fatalf("GetBook failed: %v", err)
+		}
+		if got.Title != "Emma (annotated)" || got.CompletedAt == nil || !got.CompletedAt.Equal(first) {
+			t.Errorf("GetBook = %+v, want the new title and CompletedAt %v", got, first)
+		}
+
+		book.Status = models.BookReading
+		if err := b.books.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		if book.CompletedAt != nil {
+			t.Errorf("CompletedAt = %v after leaving complete, want nil", book.CompletedAt)
+		}
+	}},
+	{"UpdateNotFound", func(t *testing.T, b backend) {
+		err := b.books.UpdateBook(context.Background(), newBook("Emma", "Jane Austen", models.BookUnread, 0))
+		if !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("UpdateBook error = %v, want ErrBookNotFound", err)
+		}
+	}},
+	{"Delete", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		book := newBook("Emma", "Jane Austen", models.BookComplete, 100)
+		mustCreate(t, b, book)
+
+		if err := b.books.DeleteBook(ctx, book.ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		if _, err := b.books.GetBook(ctx, book.ID); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("GetBook after delete error = %v, want ErrBookNotFound", err)
+		}
+		if err := b.books.DeleteBook(ctx, book.ID); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("DeleteBook twice error = %v, want ErrBookNotFound", err)
+		}
+		// The history goes with the book
+		times, err := b.stats.EventTimes(ctx, time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("EventTimes failed: %v", err)
+		}
+		if len(times) != 0 {
+			t.Errorf("EventTimes after delete = %v, want none", times)
+		}
+	}},
+	{"CountAndStats", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		mustCreate(t, b,
+			newBook("Emma", "Jane Austen", models.BookComplete, 0),
+			newBook("Persuasion", "Jane Austen", models.BookReading, 0),
+			newBook("Walden", "Henry Thoreau", models.BookUnread, 0),
+		)
+
+		total, byStatus, err := b.books.CountBooks(ctx)
+		if err != nil {
+			t.Fatalf("CountBooks failed: %v", err)
+		}
+		if total != 3 || byStatus["complete"] != 1 || byStatus["reading"] != 1 || byStatus["unread"] != 1 {
+			t.Errorf("CountBooks = %d, %v", total, byStatus)
+		}
+
+		totalRead, readingProgress, err := b.stats.GetStats(ctx)
+		if err != nil {
+			t.Fatalf("GetStats failed: %v", err)
+		}
+		if totalRead != 1 || readingProgress != 33 {
+			t.Errorf("GetStats = %d, %d, want 1, 33", totalRead, readingProgress)
+		}
+	}},
+	{"Authors", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		mustCreate(t, b,
+			newBook("Emma", "Jane Austen", models.BookComplete, 0),
+			newBook("Persuasion", "Jane Austen", models.BookUnread, 0),
+			newBook("Walden", "Henry Thoreau", models.BookReading, 0),
+			newBook("Civil Disobedience", "Henry Thoreau", models.BookReading, 0),
+			newBook("War and Peace", "Leo Tolstoy", models.BookUnread, 0),
+		)
+
+		authors, total, err := b.stats.AuthorStats(ctx, 10, 0)
+		if err != nil {
+			t.Fatalf("AuthorStats failed: %v", err)
+		}
+		want := []models.AuthorStats{
+			{Author: "Henry Thoreau", Rank: 1, Total: 2, Reading: 2},
+			{Author: "Jane Austen", Rank: 1, Total: 2, ToRead: 1, Complete: 1, CompletionRate: 0.5},
+			{Author: "Leo Tolstoy", Rank: 3, Total: 1, ToRead: 1},
+		}
+		if total != 3 || len(authors) != len(want) {
+			t.Fatalf("AuthorStats = %+v (total %d), want %+v", authors, total, want)
+		}
+		for i := range want {
+			if authors[i] != want[i] {
+				t.Errorf("author %d = %+v, want %+v", i, authors[i], want[i])
+			}
+		}
+
+		page, total, err := b.stats.AuthorStats(ctx, 1, 2)
+		if err != nil || total != 3 || len(page) != 1 || page[0].Author != "Leo Tolstoy" {
+			t.Errorf("AuthorStats(1, 2) = %+v (total %d, err %v), want only Leo Tolstoy", page, total, err)
+		}
+
+		top, err := b.stats.TopAuthors(ctx, 2)
+		if err != nil || len(top) != 2 {
+			t.Errorf("TopAuthors(2) = %+v (err %v), want the two authors tied at rank 1", top, err)
+		}
+	}},
+	{"CompletionsTimelineAndEvents", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 1, 15))
+		book := newBook("Emma", "Jane Austen", models.BookReading, 300)
+		mustCreate(t, b, book)
+		b.setNow(fixedClock(2026, 3, 2))
+		book.Status = models.BookComplete
+		if err := b.books.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		if book.CompletedAt == nil || book.CompletedAt.Location() != time.UTC {
+			t.Errorf("CompletedAt = %v, want it in UTC", book.CompletedAt)
+		}
+
+		books, pages, err := b.stats.Completions(ctx, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil || books != 1 || pages != 300 {
+			t.Errorf("Completions in March = %d, %d (err %v), want 1, 300", books, pages, err)
+		}
+		books, _, err = b.stats.Completions(ctx, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil || books != 0 {
+			t.Errorf("Completions before March = %d (err %v), want 0", books, err)
+		}
+
+		buckets, err := b.stats.Timeline(ctx, models.TimelineQuery{
+			Granularity: models.GranularityMonth,
+			From:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:          time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			t.Fatalf("Timeline failed: %v", err)
+		}
+		want := []models.TimelineBucket{
+			{Start: "2026-01-01", Started: 1},
+			{Start: "2026-02-01"},
+			{Start: "2026-03-01", Finished: 1, PagesRead: 300},
+		}
+		if len(buckets) != len(want) {
+			t.Fatalf("Timeline = %+v, want %+v", buckets, want)
+		}
+		for i := range want {
+			if buckets[i] != want[i] {
+				t.Errorf("bucket %d = %+v, want %+v", i, buckets[i], want[i])
+			}
+		}
+
+		times, err := b.stats.EventTimes(ctx, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("EventTimes failed: %v", err)
+		}
+		if len(times) != 2 || !times[0].Equal(fixedClock(2026, 1, 15)()) || !times[1].Equal(fixedClock(2026, 3, 2)()) {
+			t.Errorf("EventTimes = %v, want 2026-01-15 and 2026-03-02", times)
+		}
+	}},
+	{"PageUpdatesKeepStreak", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 10, 17))
+		book := newBook("Emma", "Jane Austen", models.BookReading, 10)
+		mustCreate(t, b, book)
+		for day, pages := range map[int]int{18: 40, 19: 90} {
+			b.setNow(fixedClock(2026, 10, day))
+			book.Pages = pages
+			if err := b.books.UpdateBook(ctx, book); err != nil {
+				t.Fatalf("UpdateBook failed: %v", err)
+			}
+		}
+		// NOTE: Nothing changed, nothing to record
+		b.setNow(fixedClock(2026, 10, 20))
+		if err := b.books.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+
+		times, err := b.stats.EventTimes(ctx, time.Date(2026, 10, 21, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("EventTimes failed: %v", err)
+		}
+		activity := models.NewActivity(times, time.UTC, fixedClock(2026, 10, 19)())
+		if len(times) != 3 || activity.CurrentStreak != 3 || activity.LongestStreak != 3 {
+			t.Errorf("EventTimes = %v, activity %+v, want a 3 day streak from the page updates", times, activity)
+		}
+
+		buckets, err := b.stats.Timeline(ctx, models.TimelineQuery{
+			Granularity: models.GranularityMonth,
+			From:        time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			To:          time.Date(2026, 10, 31, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil || len(buckets) != 1 || buckets[0] != (models.TimelineBucket{Start: "2026-10-01", Started: 1}) {
+			t.Errorf("Timeline = %+v (err %v), want the book started once, the page updates are no status changes", buckets, err)
+		}
+	}},
+	{"Concurrent", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				book := newBook(fmt.Sprintf("Book %d", i), "Jane Austen", models.BookReading, i)
+				if err := b.books.CreateBook(ctx, book); err != nil {
+					t.Errorf("CreateBook failed: %v", err)
+					return
+				}
+				book.Status = models.BookComplete
+				if err := b.books.UpdateBook(ctx, book); err != nil {
+					t.Errorf("UpdateBook failed: %v", err)
+				}
+				if _, err := b.books.ListBooks(ctx, models.BookFilter{}, 100, 0); err != nil {
+					t.Errorf("ListBooks failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		totalRead, _, err := b.stats.GetStats(ctx)
+		if err != nil || totalRead != 8 {
+			t.Errorf("GetStats total read = %d (err %v), want 8", totalRead, err)
+		}
+	}},
+	{"CoAuthors", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		omens := newBook("Good Omens", "", models.BookComplete, 0)
+		omens.Contributors = []models.Contributor{
+			{Name: "Terry Pratchett", Role: models.RoleAuthor},
+			{Name: "Neil Gaiman", Role: models.RoleAuthor},
+			{Name: "Stephen Briggs", Role: models.RoleNarrator},
+		}
+		// The same author again, only in lower case
+		mort := newBook("Mort", "terry pratchett", models.BookUnread, 0)
+		mustCreate(t, b, omens, mort)
+
+		if omens.Author != "Terry Pratchett, Neil Gaiman" || mort.Author != "Terry Pratchett" {
+			t.Errorf("Author lines = %q and %q, want the authors in order with the stored spelling", omens.Author, mort.Author)
+		}
+		got, err := b.books.GetBook(ctx, omens.ID)
+		if err != nil {
+			t.Fatalf("GetBook failed: %v", err)
+		}
+		if len(got.Contributors) != 3 || got.Contributors[1].Name != "Neil Gaiman" || got.Contributors[2].Role != models.RoleNarrator || got.Contributors[0].AuthorID == 0 {
+			t.Errorf("GetBook contributors = %+v, want both authors and the narrator in order", got.Contributors)
+		}
+
+		for author, want := range map[string][]string{"neil gaiman": {"Good Omens"}, "Terry Pratchett": {"Good Omens", "Mort"}, "Stephen Briggs": {}} {
+			books, err := b.books.ListBooks(ctx, models.BookFilter{Author: author}, -1, 0)
+			if err != nil {
+				t.Fatalf("ListBooks failed: %v", err)
+			}
+			if !slices.Equal(titles(books), want) {
+				t.Errorf("ListBooks(author %q) = %v, want %v", author, titles(books), want)
+			}
+		}
+
+		authors, err := b.stats.TopAuthors(ctx, 1)
+		if err != nil {
+			t.Fatalf("TopAuthors failed: %v", err)
+		}
+		if len(authors) != 1 || authors[0].Author != "Terry Pratchett" || authors[0].Total != 2 || authors[0].Complete != 1 {
+			t.Errorf("TopAuthors(1) = %+v, want Terry Pratchett with 2 books", authors)
+		}
+
+		// An update with only the author string replaces the authors
+		got.Contributors = nil
+		got.Author = "Neil Gaiman"
+		if err := b.books.UpdateBook(ctx, got); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		if got.Author != "Neil Gaiman" || len(got.Contributors) != 1 {
+			t.Errorf("UpdateBook = %q %+v, want Neil Gaiman alone", got.Author, got.Contributors)
+		}
+	}},
+	{"Authors", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		rowling := &models.Author{Name: "J.K. Rowling", SortName: "Rowling, J.K."}
+		if err := b.authors.CreateAuthor(ctx, rowling); err != nil {
+			t.Fatalf("CreateAuthor failed: %v", err)
+		}
+		if rowling.ID == 0 {
+			t.Fatalf("CreateAuthor did not set the id")
+		}
+		if err := b.authors.CreateAuthor(ctx, &models.Author{Name: "j.k. rowling", SortName: "x"}); !errors.Is(err, ErrAuthorExists) {
+			t.Errorf("CreateAuthor of an existing name error = %v, want ErrAuthorExists", err)
+		}
+
+		stone := newBook("Philosopher's Stone", "", models.BookComplete, 0)
+		stone.Contributors = []models.Contributor{{AuthorID: rowling.ID, Role: models.RoleAuthor}}
+		chamber := newBook("Chamber of Secrets", "JK Rowling", models.BookReading, 0)
+		mustCreate(t, b, stone, chamber)
+		if stone.Author != "J.K. Rowling" {
+			t.Errorf("Author line by id = %q, want J.K. Rowling", stone.Author)
+		}
+
+		authors, err := b.authors.ListAuthors(ctx)
+		if err != nil {
+			t.Fatalf("ListAuthors failed: %v", err)
+		}
+		// "JK Rowling" sorts as "Rowling, JK" after "Rowling, J.K."
+		if len(authors) != 2 || authors[0].ID != rowling.ID || authors[0].Books != 1 || authors[1].Name != "JK Rowling" || authors[1].SortName != "Rowling, JK" {
+			t.Fatalf("ListAuthors = %+v, want J.K. Rowling then JK Rowling", authors)
+		}
+		duplicate := authors[1]
+
+		if err := b.authors.DeleteAuthor(ctx, duplicate.ID); !errors.Is(err, ErrAuthorInUse) {
+			t.Errorf("DeleteAuthor with books error = %v, want ErrAuthorInUse", err)
+		}
+		if err := b.authors.MergeAuthors(ctx, duplicate.ID, rowling.ID); err != nil {
+			t.Fatalf("MergeAuthors failed: %v", err)
+		}
+		if _, err := b.authors.GetAuthor(ctx, duplicate.ID); !errors.Is(err, ErrAuthorNotFound) {
+			t.Errorf("GetAuthor of the merged author error = %v, want ErrAuthorNotFound", err)
+		}
+		got, err := b.books.GetBook(ctx, chamber.ID)
+		if err != nil {
+			t.Fatalf("GetBook failed: %v", err)
+		}
+		if got.Author != "J.K. Rowling" || len(got.Contributors) != 1 || got.Contributors[0].AuthorID != rowling.ID {
+			t.Errorf("Merged book = %q %+v, want J.K. Rowling", got.Author, got.Contributors)
+		}
+		stats, err := b.stats.TopAuthors(ctx, 1)
+		if err != nil || len(stats) != 1 || stats[0].Total != 2 {
+			t.Errorf("TopAuthors after merge = %+v (err %v), want one author with 2 books", stats, err)
+		}
+
+		// Renaming follows through to the author line
+		rowling.Name, rowling.SortName = "Joanne Rowling", "Rowling, Joanne"
+		if err := b.authors.UpdateAuthor(ctx, rowling); err != nil {
+			t.Fatalf("UpdateAuthor failed: %v", err)
+		}
+		if rowling.Books != 2 {
+			t.Errorf("UpdateAuthor books = %d, want 2", rowling.Books)
+		}
+		books, err := b.books.ListBooks(ctx, models.BookFilter{Author: "joanne rowling"}, -1, 0)
+		if err != nil || len(books) != 2 || books[0].Author != "Joanne Rowling" {
+			t.Errorf("ListBooks after rename = %+v (err %v), want both books by Joanne Rowling", books, err)
+		}
+
+		if err := b.authors.MergeAuthors(ctx, rowling.ID, 999999); !errors.Is(err, ErrAuthorNotFound) {
+			t.Errorf("MergeAuthors into a missing author error = %v, want ErrAuthorNotFound", err)
+		}
+		if err := b.authors.UpdateAuthor(ctx, &models.Author{ID: 999999, Name: "Nobody"}); !errors.Is(err, ErrAuthorNotFound) {
+			t.Errorf("UpdateAuthor of a missing author error = %v, want ErrAuthorNotFound", err)
+		}
+		unused := &models.Author{Name: "Robert Galbraith", SortName: "Galbraith, Robert"}
+		if err := b.authors.CreateAuthor(ctx, unused); err != nil {
+			t.Fatalf("CreateAuthor failed: %v", err)
+		}
+		if err := b.authors.DeleteAuthor(ctx, unused.ID); err != nil {
+			t.Errorf("DeleteAuthor without books failed: %v", err)
+		}
+		if err := b.authors.DeleteAuthor(ctx, unused.ID); !errors.Is(err, ErrAuthorNotFound) {
+			t.Errorf("DeleteAuthor twice error = %v, want ErrAuthorNotFound", err)
+		}
+	}},
+	{"Reviews", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 5, 1))
+		emma := newBook("Emma", "Jane Austen", models.BookComplete, 0)
+		walden := newBook("Walden", "Henry David Thoreau", models.BookComplete, 0)
+		dune := newBook("Dune", "Frank Herbert", models.BookReading, 0)
+		mustCreate(t, b, emma, walden, dune)
+
+		if err := b.reviews.SetReview(ctx, "", &models.Review{BookID: dune.ID, Rating: 4}); !errors.Is(err, ErrBookNotComplete) {
+			t.Errorf("SetReview of a book being read error = %v, want ErrBookNotComplete", err)
+		}
+		if err := b.reviews.SetReview(ctx, "", &models.Review{BookID: uuid.NewString(), Rating: 4}); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("SetReview of a missing book error = %v, want ErrBookNotFound", err)
+		}
+
+		review := &models.Review{BookID: emma.ID, Rating: 3, Body: "Meddling"}
+		if err := b.reviews.SetReview(ctx, "", review); err != nil {
+			t.Fatalf("SetReview failed: %v", err)
+		}
+		if err := b.reviews.SetReview(ctx, "", &models.Review{BookID: walden.ID, Rating: 5}); err != nil {
+			t.Fatalf("SetReview failed: %v", err)
+		}
+
+		// Replacing keeps created_at and moves the review to the front
+		b.setNow(fixedClock(2026, 5, 3))
+		review = &models.Review{BookID: emma.ID, Rating: 4.5, Body: "Better the second time"}
+		if err := b.reviews.SetReview(ctx, "", review); err != nil {
+			t.Fatalf("SetReview again failed: %v", err)
+		}
+		if want := fixedClock(2026, 5, 1)(); !review.CreatedAt.Equal(want) || !review.UpdatedAt.Equal(fixedClock(2026, 5, 3)()) {
+			t.Errorf("SetReview again = %+v, want created_at kept and updated_at now", review)
+		}
+		got, err := b.reviews.GetReview(ctx, "", emma.ID)
+		if err != nil {
+			t.Fatalf("GetReview failed: %v", err)
+		}
+		if got.Rating != 4.5 || got.Body != "Better the second time" || !got.CreatedAt.Equal(review.CreatedAt) {
+			t.Errorf("GetReview = %+v, want %+v", got, review)
+		}
+		list, err := b.reviews.ListReviews(ctx, "", 10, 0)
+		if err != nil {
+			t.Fatalf("ListReviews failed: %v", err)
+		}
+		if len(list) != 2 || list[0].BookID != emma.ID || list[1].BookID != walden.ID {
+			t.Errorf("ListReviews = %+v, want Emma then Walden", list)
+		}
+		if list, err := b.reviews.ListReviews(ctx, "", 10, 2); err != nil || len(list) != 0 {
+			t.Errorf("ListReviews past the end = %+v (err %v), want none", list, err)
+		}
+
+		// Every user has their own review, an abandoned book can be rated too
+		dune.Status = models.BookAbandoned
+		if err := b.books.UpdateBook(ctx, dune); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		for _, review := range []*models.Review{{BookID: emma.ID, Rating: 1}, {BookID: dune.ID, Rating: 2}} {
+			if err := b.reviews.SetReview(ctx, "alice", review); err != nil || review.Owner != "alice" {
+				t.Fatalf("SetReview of alice = %+v (err %v), want it saved for alice", review, err)
+			}
+		}
+		if got, err := b.reviews.GetReview(ctx, "", emma.ID); err != nil || got.Rating != 4.5 {
+			t.Errorf("GetReview = %+v (err %v), want the review of alice kept apart", got, err)
+		}
+		if _, err := b.reviews.GetReview(ctx, "", dune.ID); !errors.Is(err, ErrReviewNotFound) {
+			t.Errorf("GetReview of the review of alice error = %v, want ErrReviewNotFound", err)
+		}
+		if list, err := b.reviews.ListReviews(ctx, "alice", 10, 0); err != nil || len(list) != 2 || list[0].Owner != "alice" {
+			t.Errorf("ListReviews of alice = %+v (err %v), want her 2 reviews", list, err)
+		}
+		if err := b.reviews.DeleteReview(ctx, "", dune.ID); !errors.Is(err, ErrReviewNotFound) {
+			t.Errorf("DeleteReview of the review of alice error = %v, want ErrReviewNotFound", err)
+		}
+		if err := b.reviews.DeleteReview(ctx, "alice", dune.ID); err != nil {
+			t.Fatalf("DeleteReview failed: %v", err)
+		}
+		if err := b.reviews.DeleteReview(ctx, "alice", emma.ID); err != nil {
+			t.Fatalf("DeleteReview failed: %v", err)
+		}
+
+		counts, err := b.stats.RatingCounts(ctx)
+		if err != nil {
+			t.Fatalf("RatingCounts failed: %v", err)
+		}
+		if len(counts) != 2 || counts[4.5] != 1 || counts[5] != 1 {
+			t.Errorf("RatingCounts = %v, want one 4.5 and one 5", counts)
+		}
+
+		// Deleting the book takes the review with it
+		if err := b.books.DeleteBook(ctx, walden.ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		if _, err := b.reviews.GetReview(ctx, "", walden.ID); !errors.Is(err, ErrReviewNotFound) {
+			t.Errorf("GetReview of a deleted book error = %v, want ErrReviewNotFound", err)
+		}
+		if err := b.reviews.DeleteReview(ctx, "", emma.ID); err != nil {
+			t.Fatalf("DeleteReview failed: %v", err)
+		}
+		if err := b.reviews.DeleteReview(ctx, "", emma.ID); !errors.Is(err, ErrReviewNotFound) {
+			t.Errorf("DeleteReview twice error = %v, want ErrReviewNotFound", err)
+		}
+		if counts, err := b.stats.RatingCounts(ctx); err != nil || len(counts) != 0 {
+			t.Errorf("RatingCounts without reviews = %v (err %v), want none", counts, err)
+		}
+	}},
+	{"Notes", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		emma := newBook("Emma", "Jane Austen", models.BookReading, 0)
+		walden := newBook("Walden", "Henry David Thoreau", models.BookComplete, 0)
+		mustCreate(t, b, emma, walden)
+
+		create := func(day int, note *models.Note) *models.Note {
+			t.Helper()
+			b.setNow(fixedClock(2026, 5, day))
+			note.ID = uuid.NewString()
+			if err := b.notes.CreateNote(ctx, note); err != nil {
+				t.Fatalf("CreateNote failed: %v", err)
+			}
+			return note
+		}
+		bodies := func(notes []*models.Note) []string {
+			out := make([]string, len(notes))
+			for i, n := range notes {
+				out[i] = n.Body
+			}
+			return out
+		}
+
+		unpaged := create(1, &models.Note{BookID: emma.ID, Type: models.NoteText, Body: "Reread the **Box Hill** chapter"})
+		create(2, &models.Note{BookID: emma.ID, Type: models.NoteQuote, Body: "Badly done, Emma!", Page: 368})
+		create(3, &models.Note{BookID: emma.ID, Type: models.NoteHighlight, Body: "Silly things do cease to be silly", Page: 212, Location: "1234-1240"})
+		create(4, &models.Note{BookID: walden.ID, Type: models.NoteQuote, Body: "I went to the woods because I wished to live deliberately"})
+		if err := b.notes.CreateNote(ctx, &models.Note{ID: uuid.NewString(), BookID: uuid.NewString(), Type: models.NoteText, Body: "Lost"}); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("CreateNote for a missing book error = %v, want ErrBookNotFound", err)
+		}
+
+		// A book lists its notes by page, the ones without a page last
+		list, err := b.notes.ListNotes(ctx, models.NoteQuery{BookID: emma.ID}, 10, 0)
+		if err != nil {
+			t.Fatalf("ListNotes failed: %v", err)
+		}
+		want := []string{"Silly things do cease to be silly", "Badly done, Emma!", "Reread the **Box Hill** chapter"}
+		if !slices.Equal(bodies(list), want) || list[0].Location != "1234-1240" || list[0].Page != 212 {
+			t.Errorf("ListNotes of Emma = %+v, want %v", list, want)
+		}
+		if _, err := b.notes.ListNotes(ctx, models.NoteQuery{BookID: uuid.NewString()}, 10, 0); !errors.Is(err, ErrBookNotFound) {
+			t.Errorf("ListNotes of a missing book error = %v, want ErrBookNotFound", err)
+		}
+
+		// The search matches word prefixes regardless of case, every term has to match
+		search := func(query models.NoteQuery) []string {
+			t.Helper()
+			notes, err := b.notes.ListNotes(ctx, query, 10, 0)
+			if err != nil {
+				t.Fatalf("ListNotes(%+v) failed: %v", query, err)
+			}
+			return bodies(notes)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"silly"}}); !slices.Equal(got, []string{"Silly things do cease to be silly"}) {
+			t.Errorf("Search silly = %v", got)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"wood", "deliber"}}); len(got) != 1 || !strings.HasPrefix(got[0], "I went") {
+			t.Errorf("Search wood deliber = %v, want the Walden quote", got)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"box", "woods"}}); len(got) != 0 {
+			t.Errorf("Search box woods = %v, want nothing", got)
+		}
+		if got := search(models.NoteQuery{Type: models.NoteQuote}); !slices.Equal(got, []string{"I went to the woods because I wished to live deliberately", "Badly done, Emma!"}) {
+			t.Errorf("Quotes = %v, want the last written first", got)
+		}
+		if got := search(models.NoteQuery{BookID: walden.ID, Terms: []string{"emma"}}); len(got) != 0 {
+			t.Errorf("Search emma in Walden = %v, want nothing", got)
+		}
+		if got, err := b.notes.ListNotes(ctx, models.NoteQuery{}, 2, 1); err != nil || len(got) != 2 || got[0].Body != "Silly things do cease to be silly" {
+			t.Errorf("ListNotes page = %+v (err %v), want the 2nd and 3rd newest", got, err)
+		}
+
+		// Updating keeps the book and created_at, and the search follows the new body
+		b.setNow(fixedClock(2026, 5, 10))
+		update := &models.Note{ID: unpaged.ID, Type: models.NoteHighlight, Body: "Mr. Knightley at Donwell Abbey", Page: 400}
+		if err := b.notes.UpdateNote(ctx, update); err != nil {
+			t.Fatalf("UpdateNote failed: %v", err)
+		}
+		if update.BookID != emma.ID || !update.CreatedAt.Equal(unpaged.CreatedAt) || !update.UpdatedAt.Equal(fixedClock(2026, 5, 10)()) {
+			t.Errorf("UpdateNote = %+v, want book and created_at kept", update)
+		}
+		got, err := b.notes.GetNote(ctx, unpaged.ID)
+		if err != nil {
+			t.Fatalf("GetNote failed: %v", err)
+		}
+		if got.Body != update.Body || got.Type != models.NoteHighlight || got.Page != 400 || got.BookID != emma.ID {
+			t.Errorf("GetNote = %+v, want %+v", got, update)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"box"}}); len(got) != 0 {
+			t.Errorf("Search for the old body = %v, want nothing", got)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"knight"}}); len(got) != 1 {
+			t.Errorf("Search for the new body = %v, want the updated note", got)
+		}
+		if err := b.notes.UpdateNote(ctx, &models.Note{ID: uuid.NewString(), Type: models.NoteText, Body: "Nope"}); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("UpdateNote of a missing note error = %v, want ErrNoteNotFound", err)
+		}
+
+		// Deleting the book takes its notes with it, also out of the search
+		if err := b.books.DeleteBook(ctx, walden.ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"woods"}}); len(got) != 0 {
+			t.Errorf("Search after deleting the book = %v, want nothing", got)
+		}
+		if err := b.notes.DeleteNote(ctx, unpaged.ID); err != nil {
+			t.Fatalf("DeleteNote failed: %v", err)
+		}
+		if _, err := b.notes.GetNote(ctx, unpaged.ID); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("GetNote after delete error = %v, want ErrNoteNotFound", err)
+		}
+		if err := b.notes.DeleteNote(ctx, unpaged.ID); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("DeleteNote twice error = %v, want ErrNoteNotFound", err)
+		}
+		if got := search(models.NoteQuery{Terms: []string{"knightley"}}); len(got) != 0 {
+			t.Errorf("Search after delete = %v, want nothing", got)
+		}
+	}},
+	{"ImportNotes", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 5, 1))
+		dune := newBook("Dune", "Frank Herbert", models.BookReading, 0)
+		mustCreate(t, b, dune)
+
+		added := time.Date(2025, 3, 3, 22, 12, 45, 0, time.UTC)
+		notes := []*models.Note{
+			{ID: uuid.NewString(), BookID: dune.ID, Type: models.NoteHighlight, Body: "I must not fear.", Location: "112-114", CreatedAt: added, UpdatedAt: added},
+			{ID: uuid.NewString(), BookID: dune.ID, Type: models.NoteText, Body: "The litany"},
+		}
+		n, err := b.notes.ImportNotes(ctx, notes)
+		if err != nil || n != 2 {
+			t.Fatalf("ImportNotes = %d (err %v), want 2", n, err)
+		}
+		got, err := b.notes.GetNote(ctx, notes[0].ID)
+		if err != nil || !got.CreatedAt.Equal(added) || !got.UpdatedAt.Equal(added) || got.Location != "112-114" {
+			t.Errorf("GetNote = %+v (err %v), want the imported created_at kept", got, err)
+		}
+		if got, err := b.notes.GetNote(ctx, notes[1].ID); err != nil || !got.CreatedAt.Equal(fixedClock(2026, 5, 1)()) {
+			t.Errorf("GetNote = %+v (err %v), want created_at now without one", got, err)
+		}
+
+		// The same notes again are skipped, an edit made in between is kept
+		edit := &models.Note{ID: notes[1].ID, Type: models.NoteText, Body: "The litany against fear"}
+		if err := b.notes.UpdateNote(ctx, edit); err != nil {
+			t.Fatalf("UpdateNote failed: %v", err)
+		}
+		again := []*models.Note{
+			{ID: notes[0].ID, BookID: dune.ID, Type: models.NoteHighlight, Body: "I must not fear."},
+			{ID: notes[1].ID, BookID: dune.ID, Type: models.NoteText, Body: "The litany"},
+			{ID: uuid.NewString(), BookID: dune.ID, Type: models.NoteHighlight, Body: "Fear is the mind-killer."},
+		}
+		if n, err := b.notes.ImportNotes(ctx, again); err != nil || n != 1 {
+			t.Fatalf("ImportNotes again = %d (err %v), want only the new one", n, err)
+		}
+		if got, err := b.notes.GetNote(ctx, notes[1].ID); err != nil || got.Body != "The litany against fear" {
+			t.Errorf("GetNote = %+v (err %v), want the edit kept", got, err)
+		}
+		if list, err := b.notes.ListNotes(ctx, models.NoteQuery{Terms: []string{"fear"}}, 10, 0); err != nil || len(list) != 3 {
+			t.Errorf("Search fear = %+v (err %v), want the 3 imported notes", list, err)
+		}
+	}},
+	{"DailyHighlights", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		b.setNow(fixedClock(2026, 5, 1))
+		dune := newBook("Dune", "Frank Herbert", models.BookReading, 0)
+		mustCreate(t, b, dune)
+
+		written := func(day int) time.Time { return time.Date(2026, 4, day, 9, 0, 0, 0, time.UTC) }
+		highlight := func(noteType models.NoteType, created time.Time) *models.Note {
+			return &models.Note{ID: uuid.NewString(), BookID: dune.ID, Type: noteType, Body: "I must not fear.", CreatedAt: created}
+		}
+		first, quote, later, text := highlight(models.NoteHighlight, written(1)), highlight(models.NoteQuote, written(2)),
+			highlight(models.NoteHighlight, written(3)), highlight(models.NoteText, written(1))
+		future := highlight(models.NoteHighlight, time.Date(2026, 5, 2, 9, 0, 0, 0, time.UTC))
+		if _, err := b.notes.ImportNotes(ctx, []*models.Note{first, quote, later, text, future}); err != nil {
+			t.Fatalf("ImportNotes failed: %v", err)
+		}
+		ids := func(daily *models.DailyHighlights) []string {
+			out := []string{}
+			for _, h := range daily.Highlights {
+				out = append(out, h.ID)
+			}
+			return out
+		}
+
+		// Never reviewed highlights are due from the day they were written, plain notes never are
+		daily, err := b.highlights.DailyHighlights(ctx, 2)
+		if err != nil {
+			t.Fatalf("DailyHighlights failed: %v", err)
+		}
+		if daily.Date != "2026-05-01" || daily.PerDay != 2 || daily.Reviewed != 0 || !slices.Equal(ids(daily), []string{first.ID, quote.ID}) {
+			t.Fatalf("DailyHighlights = %+v, want the 2 longest due", daily)
+		}
+		if h := daily.Highlights[0]; h.BookTitle != "Dune" || h.Schedule != models.NewHighlightSchedule(first.ID, written(1)) {
+			t.Errorf("DailyHighlights[0] = %+v, want the book title and a new schedule", h)
+		}
+
+		schedule, err := b.highlights.ReviewHighlight(ctx, first.ID, models.FeedbackKeep)
+		if err != nil {
+			t.Fatalf("ReviewHighlight failed: %v", err)
+		}
+		want := models.HighlightSchedule{NoteID: first.ID, Repetitions: 1, IntervalDays: 1, Ease: 2.5, DueOn: "2026-05-02", ReviewedOn: "2026-05-01"}
+		if *schedule != want {
+			t.Errorf("ReviewHighlight = %+v, want %+v", schedule, want)
+		}
+		// Reviews count against the day, the queue only gets shorter
+		if daily, err := b.highlights.DailyHighlights(ctx, 2); err != nil || daily.Reviewed != 1 || !slices.Equal(ids(daily), []string{quote.ID}) {
+			t.Errorf("DailyHighlights after a review = %+v (err %v), want the quote left", daily, err)
+		}
+		if _, err := b.highlights.ReviewHighlight(ctx, quote.ID, models.FeedbackDiscard); err != nil {
+			t.Fatalf("ReviewHighlight discard failed: %v", err)
+		}
+		if daily, err := b.highlights.DailyHighlights(ctx, 2); err != nil || daily.Reviewed != 2 || len(daily.Highlights) != 0 {
+			t.Errorf("DailyHighlights after the day = %+v (err %v), want nothing left", daily, err)
+		}
+
+		if _, err := b.highlights.ReviewHighlight(ctx, text.ID, models.FeedbackKeep); !errors.Is(err, ErrNotHighlight) {
+			t.Errorf("ReviewHighlight(note) = %v, want ErrNotHighlight", err)
+		}
+		if _, err := b.highlights.ReviewHighlight(ctx, uuid.NewString(), models.FeedbackKeep); !errors.Is(err, ErrNoteNotFound) {
+			t.Errorf("ReviewHighlight(unknown) = %v, want ErrNoteNotFound", err)
+		}
+
+		// The next day the discarded quote stays out, the kept highlight is due again
+		b.setNow(fixedClock(2026, 5, 2))
+		daily, err = b.highlights.DailyHighlights(ctx, 5)
+		if err != nil {
+			t.Fatalf("DailyHighlights failed: %v", err)
+		}
+		got := ids(daily)
+		if daily.Reviewed != 0 || len(got) != 3 || got[0] != later.ID || !slices.Contains(got, first.ID) || !slices.Contains(got, future.ID) {
+			t.Errorf("DailyHighlights next day = %v, want %s first then %s and %s", got, later.ID, first.ID, future.ID)
+		}
+
+		// A favorite is graded hard so the ease drops and it comes back sooner than a kept one
+		if schedule, err = b.highlights.ReviewHighlight(ctx, first.ID, models.FeedbackFavorite); err != nil {
+			t.Fatalf("ReviewHighlight favorite failed: %v", err)
+		}
+		want = models.HighlightSchedule{NoteID: first.ID, Repetitions: 2, IntervalDays: 6, Ease: 2.36, DueOn: "2026-05-08", Favorite: true, ReviewedOn: "2026-05-02"}
+		if *schedule != want {
+			t.Errorf("ReviewHighlight favorite = %+v, want %+v", schedule, want)
+		}
+		b.setNow(fixedClock(2026, 5, 8))
+		if schedule, err = b.highlights.ReviewHighlight(ctx, first.ID, models.FeedbackKeep); err != nil {
+			t.Fatalf("ReviewHighlight failed: %v", err)
+		}
+		want = models.HighlightSchedule{NoteID: first.ID, Repetitions: 3, IntervalDays: 14, Ease: 2.36, DueOn: "2026-05-22", Favorite: true, ReviewedOn: "2026-05-08"}
+		if *schedule != want {
+			t.Errorf("ReviewHighlight third = %+v, want %+v", schedule, want)
+		}
+
+		// The schedules go with the book
+		if err := b.books.DeleteBook(ctx, dune.ID); err != nil {
+			t.Fatalf("DeleteBook failed: %v", err)
+		}
+		if daily, err := b.highlights.DailyHighlights(ctx, 5); err != nil || daily.Reviewed != 0 || len(daily.Highlights) != 0 {
+			t.Errorf("DailyHighlights after DeleteBook = %+v (err %v), want nothing", daily, err)
+		}
+	}},
+	{"BookStats", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		emma := newBook("Emma", "Jane Austen", models.BookComplete, 0)
+		mustCreate(t, b, emma, newBook("Persuasion", "Jane Austen", models.BookReading, 0), newBook("Walden", "Henry Thoreau", models.BookUnread, 0),
+			newBook("Sanditon", "Jane Austen", models.BookAbandoned, 0))
+		if err := b.reviews.SetReview(ctx, "", &models.Review{BookID: emma.ID, Rating: 4.5}); err != nil {
+			t.Fatalf("SetReview failed: %v", err)
+		}
+
+		stats, err := b.stats.BookStats(ctx, 1)
+		if err != nil {
+			t.Fatalf("BookStats failed: %v", err)
+		}
+		if stats.TotalRead != 1 || stats.TotalAbandoned != 1 || stats.ReadingProgress != 25 || len(stats.TopAuthors) != 1 ||
+			stats.TopAuthors[0].Author != "Jane Austen" || stats.TopAuthors[0].Abandoned != 1 || stats.Ratings.Count != 1 || *stats.Ratings.Average != 4.5 {
+			t.Errorf("BookStats = %+v, want 1 read, 1 abandoned, 25%%, Jane Austen on top and one 4.5 rating", stats)
+		}
+		filter := models.BookFilter{Status: " Abandoned "}
+		if err := filter.Validate(); err != nil {
+			t.Fatalf("Validate failed: %v", err)
+		}
+		if books, err := b.books.ListBooks(ctx, filter, 10, 0); err != nil || !slices.Equal(titles(books), []string{"Sanditon"}) {
+			t.Errorf("ListBooks(status=abandoned) = %v (err %v), want Sanditon", titles(books), err)
+		}
+
+		// Every book written here is complete and by a writer of its own, ranked 2 with Henry Thoreau behind Jane
+		// Austen. So a consistent snapshot always has one more top author than books read
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := range 30 {
+				if err := b.books.CreateBook(ctx, newBook(fmt.Sprintf("Book %d", i), fmt.Sprintf("Writer %d", i), models.BookComplete, 0)); err != nil {
+					t.Errorf("CreateBook failed: %v", err)
+					return
+				}
+			}
+		}()
+		for running := true; running; {
+			select {
+			case <-done:
+				running = false
+			default:
+			}
+			stats, err := b.stats.BookStats(ctx, 2)
+			if err != nil {
+				t.Fatalf("BookStats failed: %v", err)
+			}
+			if len(stats.TopAuthors) != stats.TotalRead+1 {
+				t.Fatalf("BookStats = %d read with %d top authors, not from one snapshot", stats.TotalRead, len(stats.TopAuthors))
+			}
+		}
+		if stats, err := b.stats.BookStats(ctx, 2); err != nil || stats.TotalRead != 31 {
+			t.Errorf("BookStats = %+v (err %v), want all 31 read", stats, err)
+		}
+	}},
+	{"FoldNonASCII", func(t *testing.T, b backend) {
+		ctx := context.Background()
+		mustCreate(t, b,
+			newBook("Éloge de l'ombre", "Élodie Durand", models.BookUnread, 0),
+			newBook("Ökologie", "élodie durand", models.BookUnread, 0), // The same author, NOCASE alone would not see that
+			newBook("Eloge", "Elodie Durand", models.BookUnread, 0),    // Not folded to ASCII, a different author
+		)
+
+		for _, filter := range []models.BookFilter{{Title: "ÉLOGE DE L'OMBRE"}, {Title: "éloge de l'ombre"}} {
+			if books, err := b.books.ListBooks(ctx, filter, 10, 0); err != nil || !slices.Equal(titles(books), []string{"Éloge de l'ombre"}) {
+				t.Errorf("ListBooks(title=%q) = %v (err %v), want the non ASCII title to ignore case", filter.Title, titles(books), err)
+			}
+		}
+		books, err := b.books.ListBooks(ctx, models.BookFilter{Author: "élodie durand"}, 10, 0)
+		if err != nil || !slices.Equal(titles(books), []string{"Éloge de l'ombre", "Ökologie"}) {
+			t.Errorf("ListBooks(author=élodie durand) = %v (err %v), want both books of Élodie Durand", titles(books), err)
+		}
+		for _, book := range books {
+			if book.Author != "Élodie Durand" {
+				t.Errorf("Author = %q, want the stored spelling Élodie Durand", book.Author)
+			}
+		}
+		if authors, err := b.authors.ListAuthors(ctx); err != nil || len(authors) != 2 {
+			t.Errorf("ListAuthors = %+v (err %v), want Élodie Durand and Elodie Durand", authors, err)
+		}
+	}},
+}
@@ -1,12 +1,31 @@
 package store
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"net/url"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
+// sqliteDriver is go-sqlite3 with the functions our queries need registered on every connection
+const sqliteDriver = "sqlite3_book_tracker"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("fold", foldCase, true)
+		},
+	})
+}
+
 const createBooksTable = `
 CREATE TABLE IF NOT EXISTS books (
     id TEXT PRIMARY KEY,
@@ -22,27 +41,511 @@ const createStatusIndex = `
 CREATE INDEX IF NOT EXISTS idx_status ON books (status)
 `
 
-func NewDB(dbPath string) (*sql.DB, func(), error) {
-	// Documentation: https://duckdb.org/docs/stable/clients/go.html
-	db, err := sql.Open("sqlite3", dbPath)
+// NOTE: completed_at is set by the store when a book moves into the complete status
+const addCompletedAt = `
+ALTER TABLE books ADD COLUMN completed_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_completed_at ON books (completed_at)
+`
 
-	if err != nil {
-		return nil, nil, fmt.Errorf("open duckdb: %w", err)
+// NOTE: A goal is a target number of books or pages to complete between two dates (both inclusive)
+const addGoals = `
+ALTER TABLE books ADD COLUMN pages INTEGER NOT NULL DEFAULT 0;
+CREATE TABLE IF NOT EXISTS goals (
+    id TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    metric TEXT NOT NULL,
+    target INTEGER NOT NULL,
+    start_date TEXT NOT NULL,
+    end_date TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_goals_period ON goals (start_date, end_date)
+`
+
+// NOTE: Every status change of a book is recorded so stats can be computed over time.
+// from_status is NULL for the event created with the book. Existing completions are backfilled
+const addBookEvents = `
+CREATE TABLE IF NOT EXISTS book_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    book_id TEXT NOT NULL,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    pages INTEGER NOT NULL DEFAULT 0,
+    occurred_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_book_events_occurred_at ON book_events (occurred_at);
+CREATE INDEX IF NOT EXISTS idx_book_events_book_id ON book_events (book_id);
+INSERT INTO book_events (book_id, from_status, to_status, pages, occurred_at)
+    SELECT id, NULL, status, pages, completed_at FROM books WHERE completed_at IS NOT NULL
+`
+
+// NOTE: Tags are many-to-many with books. Names are stored normalized (see models.NormalizeTag) so a plain
+// UNIQUE is enough. book_tags goes with its book or tag through the cascades, foreign_keys is always on (see SQLiteConfig.dsn)
+const addTags = `
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE IF NOT EXISTS book_tags (
+    book_id TEXT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (book_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_book_tags_tag_id ON book_tags (tag_id)
+`
+
+// NOTE: A shelf keeps its books in the order of position. Removing a book (or the book itself going away)
+// can leave gaps, positions are only ever compared so that does no harm. They are renumbered on the next change
+const addShelves = `
+CREATE TABLE IF NOT EXISTS shelves (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS shelf_books (
+    shelf_id TEXT NOT NULL REFERENCES shelves (id) ON DELETE CASCADE,
+    book_id TEXT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (shelf_id, book_id)
+);
+CREATE INDEX IF NOT EXISTS idx_shelf_books_position ON shelf_books (shelf_id, position);
+CREATE INDEX IF NOT EXISTS idx_shelf_books_book_id ON shelf_books (book_id)
+`
+
+// NOTE: The filter of a saved search is kept as the JSON of models.BookFilter. It is only ever read whole
+// and handed to the book query builder, so there is nothing to gain from a column per field
+const addSavedSearches = `
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    filter TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+)
+`
+
+// NOTE: A book is in at most one series, hence book_id as the key. Positions are REAL so a novella can sit
+// at 2.5 between the second and third volume, they dont have to be unique (i.e. an omnibus and its parts)
+const addSeries = `
+CREATE TABLE IF NOT EXISTS series (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL
+);
+CREATE TABLE IF NOT EXISTS series_books (
+    book_id TEXT PRIMARY KEY REFERENCES books (id) ON DELETE CASCADE,
+    series_id TEXT NOT NULL REFERENCES series (id) ON DELETE CASCADE,
+    position REAL NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_series_books_series_id ON series_books (series_id, position)
+`
+
+// NOTE: books.author stays as the derived author line (see models.AuthorLine) so old readers of the column
+// keep working, book_authors is the source of truth. The existing author strings become one author each,
+// spellings that only differ in case are the same author. Their sort name starts out as the name, there
+// is no sane way to split names in SQL. An author is only ever removed explicitly, hence no cascade
+const addAuthors = `
+CREATE TABLE IF NOT EXISTS authors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    sort_name TEXT NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_authors_name ON authors (name COLLATE NOCASE);
+CREATE TABLE IF NOT EXISTS book_authors (
+    book_id TEXT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES authors (id),
+    role TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (book_id, author_id, role)
+);
+CREATE INDEX IF NOT EXISTS idx_book_authors_author_id ON book_authors (author_id);
+INSERT INTO authors (name, sort_name)
+    SELECT MIN(author), MIN(author) FROM books GROUP BY author COLLATE NOCASE;
+INSERT INTO book_authors (book_id, author_id, role, position)
+    SELECT books.id, authors.id, 'author', 0 FROM books JOIN authors ON authors.name = books.author COLLATE NOCASE
+`
+
+// NOTE: One review per book, hence book_id as the key. The rating is REAL for the half stars
+const addReviews = `
+CREATE TABLE IF NOT EXISTS reviews (
+    book_id TEXT PRIMARY KEY REFERENCES books (id) ON DELETE CASCADE,
+    rating REAL NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_reviews_updated_at ON reviews (updated_at)
+`
+
+// NOTE:
+// The FTS4 index follows the notes through triggers, keyed by docid. seq is there so that key never changes,
+// the implicit rowid of a table with a TEXT primary key may be renumbered by a VACUUM.
+// FTS5 would be nicer but is not compiled into go-sqlite3 by default
+const addNotes = `
+CREATE TABLE IF NOT EXISTS notes (
+    seq INTEGER PRIMARY KEY,
+    id TEXT NOT NULL UNIQUE,
+    book_id TEXT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    body TEXT NOT NULL,
+    page INTEGER NOT NULL DEFAULT 0,
+    location TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_notes_book_id ON notes (book_id, page);
+CREATE INDEX IF NOT EXISTS idx_notes_created_at ON notes (created_at);
+CREATE VIRTUAL TABLE IF NOT EXISTS notes_fts USING fts4 (body, tokenize=unicode61 "remove_diacritics=0");
+CREATE TRIGGER IF NOT EXISTS notes_fts_insert AFTER INSERT ON notes BEGIN
+    INSERT INTO notes_fts (docid, body) VALUES (new.seq, new.body);
+END;
+CREATE TRIGGER IF NOT EXISTS notes_fts_update AFTER UPDATE OF body ON notes BEGIN
+    UPDATE notes_fts SET body = new.body WHERE docid = new.seq;
+END;
+CREATE TRIGGER IF NOT EXISTS notes_fts_delete AFTER DELETE ON notes BEGIN
+    DELETE FROM notes_fts WHERE docid = old.seq;
+END
+`
+
+// NOTE: Only reviewed highlights have a schedule, the others are due from the day they were written.
+// The days are UTC days as YYYY-MM-DD, the same as goals keep them
+const addHighlightSchedules = `
+CREATE TABLE IF NOT EXISTS highlight_schedules (
+    note_id TEXT PRIMARY KEY REFERENCES notes (id) ON DELETE CASCADE,
+    repetitions INTEGER NOT NULL,
+    interval_days INTEGER NOT NULL,
+    ease REAL NOT NULL,
+    due_on TEXT NOT NULL,
+    favorite BOOLEAN NOT NULL DEFAULT FALSE,
+    discarded BOOLEAN NOT NULL DEFAULT FALSE,
+    reviewed_on TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_highlight_schedules_due_on ON highlight_schedules (due_on);
+CREATE INDEX IF NOT EXISTS idx_highlight_schedules_reviewed_on ON highlight_schedules (reviewed_on)
+`
+
+// NOTE: Saved searches belong to the user that made them, see middleware.User. The ones saved before were
+// shared by everyone, they go to the anonymous user with an empty owner, who everyone is without a user header
+const addSearchOwners = `
+ALTER TABLE saved_searches ADD COLUMN owner TEXT NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_saved_searches_owner ON saved_searches (owner, created_at)
+`
+
+// NOTE: addAuthors grouped the author names with NOCASE, which only folds ASCII, and the books could then
+// create "élodie durand" next to "Élodie Durand". The duplicates go into the author with the lowest id, under
+// the name it has. Only needed once, every write of an author looks it up with fold now
+const mergeFoldedAuthors = `
+CREATE TEMP TABLE author_merges AS
+    SELECT authors.id, authors.name, kept.id AS kept_id, kept.name AS kept_name
+    FROM authors JOIN authors AS kept ON kept.id = (SELECT MIN(id) FROM authors AS a WHERE fold(a.name) = fold(authors.name))
+    WHERE kept.id <> authors.id;
+UPDATE books SET author = (
+    SELECT REPLACE(books.author, author_merges.name, author_merges.kept_name)
+    FROM book_authors JOIN author_merges ON author_merges.id = book_authors.author_id
+    WHERE book_authors.book_id = books.id AND book_authors.role = 'author' LIMIT 1
+) WHERE id IN (
+    SELECT book_id FROM book_authors JOIN author_merges ON author_merges.id = book_authors.author_id WHERE role = 'author'
+);
+INSERT OR IGNORE INTO book_authors (book_id, author_id, role, position)
+    SELECT book_id, kept_id, role, position FROM book_authors JOIN author_merges ON author_merges.id = book_authors.author_id;
+DELETE FROM book_authors WHERE author_id IN (SELECT id FROM author_merges);
+DELETE FROM authors WHERE id IN (SELECT id FROM author_merges);
+DROP TABLE author_merges
+`
+
+// NOTE: Reviews belong to the user that wrote them, one per book and user, the same as the saved searches
+// (see addSearchOwners). SQLite cant change a primary key so the table is copied. The ratings in the stats
+// still count the reviews of every user
+const addReviewOwners = `
+CREATE TABLE reviews_by_owner (
+    book_id TEXT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+    owner TEXT NOT NULL DEFAULT '',
+    rating REAL NOT NULL,
+    body TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (book_id, owner)
+);
+INSERT INTO reviews_by_owner (book_id, rating, body, created_at, updated_at)
+    SELECT book_id, rating, body, created_at, updated_at FROM reviews;
+DROP TABLE reviews;
+ALTER TABLE reviews_by_owner RENAME TO reviews;
+CREATE INDEX IF NOT EXISTS idx_reviews_owner ON reviews (owner, updated_at)
+`
+
+// NOTE:
+// Each entry is one schema version and is applied once, in order. The current version is kept in
+// SQLite's own user_version pragma so we dont need a separate bookkeeping table.
+// IMPORTANT: Never edit an entry that has shipped, append a new one instead
+var migrations = []string{
+	createBooksTable + ";" + createStatusIndex,
+	addCompletedAt,
+	addGoals,
+	addBookEvents,
+	addTags,
+	addShelves,
+	addSavedSearches,
+	addSeries,
+	addAuthors,
+	addReviews,
+	addNotes,
+	addHighlightSchedules,
+	addSearchOwners,
+	mergeFoldedAuthors,
+	addReviewOwners,
+}
+
+var ErrCorruptDatabase = errors.New("database failed the integrity check")
+
+// SchemaVersion is the version a database has once all migrations are applied
+func SchemaVersion() int {
+	return len(migrations)
+}
+
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
 	}
 
-	_, err = db.Exec(createBooksTable)
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("apply migration %d: %w", i+1, err)
+		}
+		// NOTE: PRAGMA does not support placeholders but the value is our own int so this is safe
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("set schema version %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
 
+// withTx runs fn in a transaction which is committed when fn returns nil and rolled back otherwise
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		db.Close()
-		return nil, nil, fmt.Errorf("create table: %w", err)
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// DB is the database the stores work on. The embedded *sql.DB is used for writes and Read for everything
+// else. With SQLite these are two pools, see OpenSQLite, with Postgres both are the same pool
+type DB struct {
+	*sql.DB
+	Read *sql.DB
+}
+
+func (db *DB) Close() error {
+	if db.Read != db.DB {
+		db.Read.Close()
+	}
+	return db.DB.Close()
+}
+
+// Stats adds up both pools so /metrics shows every connection
+func (db *DB) Stats() sql.DBStats {
+	stats := db.DB.Stats()
+	if db.Read == db.DB {
+		return stats
+	}
+	read := db.Read.Stats()
+	stats.MaxOpenConnections += read.MaxOpenConnections
+	stats.OpenConnections += read.OpenConnections
+	stats.InUse += read.InUse
+	stats.Idle += read.Idle
+	stats.WaitCount += read.WaitCount
+	stats.WaitDuration += read.WaitDuration
+	stats.MaxIdleClosed += read.MaxIdleClosed
+	stats.MaxIdleTimeClosed += read.MaxIdleTimeClosed
+	stats.MaxLifetimeClosed += read.MaxLifetimeClosed
+	return stats
+}
+
+// SQLiteConfig holds the pragmas every connection is opened with and how the pools are sized
+type SQLiteConfig struct {
+	Path           string
+	JournalMode    string        // WAL lets readers carry on while a write is going on
+	BusyTimeout    time.Duration // How long a connection waits for a lock before failing with "database is locked"
+	Synchronous    string        // NORMAL is safe with WAL and a lot faster than FULL
+	ReadConns      int           // Size of the read pool. Writes always go through a single connection
+	IntegrityCheck string        // quick, full or off. Checked before migrating, a damaged file refuses to start
+}
+
+func DefaultSQLiteConfig(path string) SQLiteConfig {
+	return SQLiteConfig{
+		Path:           path,
+		JournalMode:    "WAL",
+		BusyTimeout:    5 * time.Second,
+		Synchronous:    "NORMAL",
+		ReadConns:      max(4, runtime.NumCPU()),
+		IntegrityCheck: "quick",
+	}
+}
+
+// NOTE: integrity_check reads every page and matches every index against its table, on a big database that
+// takes a while and holds up the writer. quick_check skips the index matching and is a lot faster
+var integrityChecks = map[string]string{
+	"quick": "PRAGMA quick_check",
+	"full":  "PRAGMA integrity_check",
+	"off":   "",
+	"":      "", // NOTE: The zero value of SQLiteConfig, the same as off
+}
+
+var (
+	journalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+	syncModes    = []string{"OFF", "NORMAL", "FULL", "EXTRA"}
+)
+
+func (cfg SQLiteConfig) validate() error {
+	if !slices.Contains(journalModes, strings.ToUpper(cfg.JournalMode)) {
+		return fmt.Errorf("invalid journal mode %q: must be one of %v", cfg.JournalMode, journalModes)
+	}
+	if !slices.Contains(syncModes, strings.ToUpper(cfg.Synchronous)) {
+		return fmt.Errorf("invalid synchronous %q: must be one of %v", cfg.Synchronous, syncModes)
+	}
+	if _, ok := integrityChecks[strings.ToLower(cfg.IntegrityCheck)]; !ok {
+		return fmt.Errorf("invalid integrity check %q: must be quick, full or off", cfg.IntegrityCheck)
+	}
+	if cfg.BusyTimeout < 0 || cfg.ReadConns < 1 {
+		return fmt.Errorf("busy timeout must not be negative and there must be at least one read connection")
+	}
+	return nil
+}
+
+// dsn builds the go-sqlite3 connection string. The driver runs the pragmas on every new connection
+// which matters as most of them are per connection and not per database.
+// Documentation: https://github.com/mattn/go-sqlite3#connection-string
+func (cfg SQLiteConfig) dsn(readOnly bool) string {
+	params := url.Values{}
+	params.Set("_journal_mode", strings.ToUpper(cfg.JournalMode))
+	params.Set("_busy_timeout", strconv.FormatInt(cfg.BusyTimeout.Milliseconds(), 10))
+	params.Set("_synchronous", strings.ToUpper(cfg.Synchronous))
+	// NOTE: Always on, not an option. Deleting a book, tag, shelf or note relies on ON DELETE CASCADE to
+	// clean up after it and would leave orphan rows behind without
+	params.Set("_foreign_keys", "true")
+	if readOnly {
+		params.Set("_query_only", "true")
+	} else {
+		// NOTE: BEGIN IMMEDIATE takes the write lock up front instead of failing halfway through a transaction
+		params.Set("_txlock", "immediate")
+	}
+
+	sep := "?"
+	if strings.Contains(cfg.Path, "?") {
+		sep = "&"
+	}
+	return "file:" + strings.TrimPrefix(cfg.Path, "file:") + sep + params.Encode()
+}
+
+func NewDB(dbPath string) (*DB, func(), error) {
+	return OpenSQLite(DefaultSQLiteConfig(dbPath))
+}
+
+// OpenSQLite opens the database with one connection for writes and a pool of query only connections for reads.
+// NOTE: SQLite only allows one writer at a time anyway. With a single write connection writers queue up in
+// database/sql instead of fighting over the lock and failing with "database is locked"
+func OpenSQLite(cfg SQLiteConfig) (*DB, func(), error) {
+	if err := cfg.validate(); err != nil {
+		return nil, nil, err
 	}
 
-	_, err = db.Exec(createStatusIndex)
+	// NOTE: Every connection to ":memory:" gets its own empty database so there can only be one connection,
+	// shared by reads and writes. WAL doesnt apply to memory databases either
+	if cfg.Path == ":memory:" {
+		cfg.JournalMode = "MEMORY"
+		db, err := openSQLitePool(cfg.dsn(false), 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		return finishOpen(&DB{DB: db, Read: db}, cfg)
+	}
 
+	// Documentation: https://duckdb.org/docs/stable/clients/go.html
+	write, err := openSQLitePool(cfg.dsn(false), 1)
 	if err != nil {
-		db.Close()
-		return nil, nil, fmt.Errorf("create index: %w", err)
+		return nil, nil, err
+	}
+	// NOTE: The writer has to go first, it is the one that switches the file to WAL
+	if err := write.Ping(); err != nil {
+		write.Close()
+		return nil, nil, fmt.Errorf("open duckdb: %w", err)
+	}
+	read, err := openSQLitePool(cfg.dsn(true), cfg.ReadConns)
+	if err != nil {
+		write.Close()
+		return nil, nil, err
+	}
+	return finishOpen(&DB{DB: write, Read: read}, cfg)
+}
+
+func openSQLitePool(dsn string, conns int) (*sql.DB, error) {
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open duckdb: %w", err)
 	}
+	db.SetMaxOpenConns(conns)
+	db.SetMaxIdleConns(conns)
+	db.SetConnMaxIdleTime(0) // NOTE: Keep them, reopening means running all the pragmas again
+	return db, nil
+}
 
+func finishOpen(db *DB, cfg SQLiteConfig) (*DB, func(), error) {
+	if pragma := integrityChecks[strings.ToLower(cfg.IntegrityCheck)]; pragma != "" {
+		if err := integrityCheck(db.DB, pragma); err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+	}
+	if err := migrate(db.DB); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("migrate: %w", err)
+	}
 	return db, func() { db.Close() }, nil // return db.Close() in a wrapper to defer closing the db conenction
 }
+
+// integrityCheck fails when SQLite finds anything wrong with the file, pragma is one of integrityChecks. Both
+// report up to 100 problems, one per row, and a single "ok" row when there are none
+func integrityCheck(db *sql.DB, pragma string) error {
+	rows, err := db.Query(pragma)
+	if err != nil {
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	problems := []string{}
+	for rows.Next() {
+		var result string
+		if err := rows.Scan(&result); err != nil {
+			return fmt.Errorf("integrity check: %w", err)
+		}
+		if result != "ok" {
+			problems = append(problems, result)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrCorruptDatabase, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+func setupFileDB(t *testing.T, cfg SQLiteConfig) *DB {
+	t.Helper()
+	db, cleanup, err := OpenSQLite(cfg)
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", cfg.Path, err)
+	}
+	t.Cleanup(cleanup)
+	return db
+}
+
+func TestOpenSQLite(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Pragmas", func(t *testing.T) {
+		cfg := DefaultSQLiteConfig(filepath.Join(t.TempDir(), "books.db"))
+		cfg.BusyTimeout = 1234 * time.Millisecond
+		db := setupFileDB(t, cfg)
+
+		pragmas := map[string]string{
+			"journal_mode": "wal",
+			"busy_timeout": "1234",
+			"synchronous":  "1", // NORMAL
+			"foreign_keys": "1",
+		}
+		for pragma, want := range pragmas {
+			var writer, reader string
+			if err := db.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(&writer); err != nil {
+				t.Fatalf("PRAGMA %s on the writer: %v", pragma, err)
+			}
+			if err := db.Read.QueryRowContext(ctx, "PRAGMA "+pragma).Scan(&reader); err != nil {
+				t.Fatalf("PRAGMA %s on the reader: %v", pragma, err)
+			}
+			if writer != want || reader != want {
+				t.Errorf("PRAGMA %s = %q (writer) %q (reader), want %q", pragma, writer, reader, want)
+			}
+		}
+	})
+
+	t.Run("ReaderIsQueryOnly", func(t *testing.T) {
+		db := setupFileDB(t, DefaultSQLiteConfig(filepath.Join(t.TempDir(), "books.db")))
+
+		_, err := db.Read.ExecContext(ctx, "DELETE FROM books")
+		if err == nil {
+			t.Fatal("Expected the read pool to refuse writes")
+		}
+		if db.Stats().MaxOpenConnections != 1+DefaultSQLiteConfig("").ReadConns {
+			t.Errorf("MaxOpenConnections = %d, want the writer plus the read pool", db.Stats().MaxOpenConnections)
+		}
+	})
+
+	t.Run("ConcurrentWrites", func(t *testing.T) {
+		cfg := DefaultSQLiteConfig(filepath.Join(t.TempDir(), "books.db"))
+		cfg.BusyTimeout = 0 // NOTE: Any lock contention would fail straight away with "database is locked"
+		store := NewBookStore(setupFileDB(t, cfg))
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 50)
+		for i := range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				book := &models.Book{ID: uuid.NewString(), Title: "Book", Author: "Author", Status: models.BookUnread}
+				if err := store.CreateBook(ctx, book); err != nil {
+					errs <- err
+					return
+				}
+				if i%2 == 0 {
+					book.Status = models.BookReading
+					if err := store.UpdateBook(ctx, book); err != nil {
+						errs <- err
+						return
+					}
+				}
+				if _, err := store.ListBooks(ctx, models.BookFilter{}, 10, 0); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Errorf("Concurrent access failed: %v", err)
+		}
+
+		total, _, err := store.CountBooks(ctx)
+		if err != nil {
+			t.Fatalf("CountBooks failed: %v", err)
+		}
+		if total != 50 {
+			t.Errorf("Expected 50 books, got %d", total)
+		}
+	})
+
+	t.Run("NotADatabase", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "books.db")
+		if err := os.WriteFile(path, []byte("definitely not an SQLite file, just some text that is long enough"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := OpenSQLite(DefaultSQLiteConfig(path)); err == nil {
+			t.Fatal("Expected opening a file that is not a database to fail")
+		}
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "books.db")
+		db, cleanup, err := OpenSQLite(DefaultSQLiteConfig(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// NOTE: writable_schema lets us point the index at the wrong table, integrity_check then finds rows missing from it
+		for _, stmt := range []string{
+			"INSERT INTO books (id, title, author, status) VALUES ('1', 'A', 'B', 'unread')",
+			"PRAGMA writable_schema = ON",
+			"UPDATE sqlite_master SET sql = 'CREATE INDEX idx_status ON books (title)' WHERE name = 'idx_status'",
+		} {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				t.Fatalf("%s: %v", stmt, err)
+			}
+		}
+		cleanup()
+
+		cfg := DefaultSQLiteConfig(path)
+		cfg.IntegrityCheck = "full"
+		_, _, err = OpenSQLite(cfg)
+		if !errors.Is(err, ErrCorruptDatabase) {
+			t.Fatalf("Expected ErrCorruptDatabase, got %v", err)
+		}
+
+		// NOTE: The default quick check does not match the indexes against their tables
+		setupFileDB(t, DefaultSQLiteConfig(path))
+		cfg.IntegrityCheck = "off"
+		setupFileDB(t, cfg)
+	})
+
+	t.Run("MergeFoldedAuthors", func(t *testing.T) {
+		db := setupFileDB(t, DefaultSQLiteConfig(filepath.Join(t.TempDir(), "books.db")))
+		// NOTE: The duplicates NOCASE let in before, then the migration again
+		for _, stmt := range []string{
+			"INSERT INTO books (id, title, author, status) VALUES ('1', 'Éloge', 'Élodie Durand', 'unread'), ('2', 'Ökologie', 'élodie durand, Anna Berg', 'unread')",
+			"INSERT INTO authors (id, name, sort_name) VALUES (1, 'Élodie Durand', 'Durand, Élodie'), (2, 'élodie durand', 'durand, élodie'), (3, 'Anna Berg', 'Berg, Anna')",
+			"INSERT INTO book_authors (book_id, author_id, role, position) VALUES ('1', 1, 'author', 0), ('2', 2, 'author', 0), ('2', 3, 'author', 1)",
+			mergeFoldedAuthors,
+		} {
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				t.Fatalf("%s: %v", stmt, err)
+			}
+		}
+
+		authors, err := NewAuthorStore(db).ListAuthors(ctx)
+		if err != nil {
+			t.Fatalf("ListAuthors failed: %v", err)
+		}
+		if len(authors) != 2 || authors[1].Name != "Élodie Durand" || authors[1].Books != 2 {
+			t.Errorf("ListAuthors = %+v, want Anna Berg and Élodie Durand with both books", authors)
+		}
+		book, err := NewBookStore(db).GetBook(ctx, "2")
+		if err != nil || book.Author != "Élodie Durand, Anna Berg" {
+			t.Errorf("GetBook = %+v (err %v), want the author line with the kept spelling", book, err)
+		}
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		for _, mutate := range []func(*SQLiteConfig){
+			func(c *SQLiteConfig) { c.JournalMode = "fast" },
+			func(c *SQLiteConfig) { c.Synchronous = "sometimes" },
+			func(c *SQLiteConfig) { c.ReadConns = 0 },
+			func(c *SQLiteConfig) { c.BusyTimeout = -time.Second },
+			func(c *SQLiteConfig) { c.IntegrityCheck = "sometimes" },
+		} {
+			cfg := DefaultSQLiteConfig(filepath.Join(t.TempDir(), "books.db"))
+			mutate(&cfg)
+			if _, _, err := OpenSQLite(cfg); err == nil {
+				t.Errorf("Expected %+v to be rejected", cfg)
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// dialect holds the few things that differ between the SQL databases the stores run on. Queries are
+// written with ? placeholders like SQLite wants them and go through bind before they are executed
+type dialect struct {
+	system    string                      // db.system.name on the spans
+	bind      func(query string) string   // Rewrites the ? placeholders for the database
+	equalFold string                      // Case insensitive match of the %s column against the next placeholder, see foldCase
+	byteOrder string                      // Sorts the %s column by byte order, i.e. the same everywhere regardless of locale
+	lockRow   string                      // Appended to a SELECT to lock the row until the transaction ends
+	utcDate   string                      // The UTC day (YYYY-MM-DD) of the %s timestamp column
+	dbSize    string                      // Query for the size of the database in bytes
+	matchNote string                      // Full text match of the notes row against the next placeholder
+	matchArg  func(terms []string) string // Builds the argument of matchNote, every term has to match a word prefix
+}
+
+var sqliteDialect = dialect{
+	system:    "sqlite",
+	bind:      func(query string) string { return query },
+	equalFold: "fold(%s) = fold(?)",
+	byteOrder: "%s", // BINARY is the default collation
+	lockRow:   "",   // NOTE: SQLite locks the whole database on write so there is nothing to do
+	utcDate:   "date(%s)",
+	dbSize:    "SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()",
+	matchNote: "notes.seq IN (SELECT docid FROM notes_fts WHERE notes_fts MATCH ?)",
+	matchArg:  func(terms []string) string { return matchQuery(terms, "*", " ") },
+}
+
+var postgresDialect = dialect{
+	system:    "postgresql",
+	bind:      bindNumbered,
+	equalFold: "lower(%s) = lower(?)",
+	byteOrder: `%s COLLATE "C"`,
+	lockRow:   " FOR UPDATE",
+	utcDate:   `to_char(%s AT TIME ZONE 'UTC', 'YYYY-MM-DD')`,
+	dbSize:    "SELECT pg_database_size(current_database())",
+	matchNote: "to_tsvector('simple', notes.body) @@ to_tsquery('simple', ?)",
+	matchArg:  func(terms []string) string { return matchQuery(terms, ":*", " & ") },
+}
+
+func (d dialect) startSpan(ctx context.Context, name, statement string) (context.Context, trace.Span) {
+	return startDBSpan(ctx, d.system, name, statement)
+}
+
+// bindNumbered turns ? placeholders into $1, $2, ... for Postgres.
+// NOTE: None of our queries have a ? inside a string literal so there is no need to parse the SQL
+func bindNumbered(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// foldCase is how names and titles are compared ignoring case: both sides lower cased, all of Unicode and
+// not just ASCII. Postgres does the same with lower(), SQLite with the fold function (see sqliteDriver)
+// and the memory store directly.
+// NOTE: SQLite's NOCASE only folds ASCII, "élodie" would not find "Élodie". The unique index on the author
+// names is still NOCASE, so every write of an author looks the name up with authorByName first
+func foldCase(s string) string {
+	return strings.ToLower(s)
+}
+
+// matchQuery joins the search terms into a full text query. The terms are only letters and digits (see
+// models.NoteSearchTerms) so none of them can be mistaken for an operator of either query syntax.
+// NOTE: FTS4 only knows AND, OR and NOT in upper case and the terms are lower case
+func matchQuery(terms []string, prefix, and string) string {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = term + prefix
+	}
+	return strings.Join(parts, and)
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrGoalNotFound = errors.New("goal not found")
+)
+
+type GoalStore interface {
+	CreateGoal(ctx context.Context, goal *models.Goal) error
+	GetGoal(ctx context.Context, id string) (*models.Goal, error)
+	// ListGoals returns all goals, or only the ones whose period contains activeOn when it is set
+	ListGoals(ctx context.Context, activeOn *time.Time) ([]*models.Goal, error)
+	UpdateGoal(ctx context.Context, goal *models.Goal) error
+	DeleteGoal(ctx context.Context, id string) error
+}
+
+type goalStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewGoalStore(db *DB) GoalStore {
+	return &goalStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const goalColumns = "id, title, metric, target, start_date, end_date, created_at"
+
+func scanGoal(row rowScanner) (*models.Goal, error) {
+	var goal models.Goal
+	if err := row.Scan(&goal.ID, &goal.Title, &goal.Metric, &goal.Target, &goal.StartDate, &goal.EndDate, &goal.CreatedAt); err != nil {
+		return nil, err
+	}
+	goal.CreatedAt = goal.CreatedAt.UTC() // NOTE: Postgres hands back the session timezone
+	return &goal, nil
+}
+
+const insertGoal = `
+        INSERT INTO goals (` + goalColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+func (s *goalStore) CreateGoal(ctx context.Context, goal *models.Goal) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "goalStore.CreateGoal", insertGoal)
+	defer func() { tracing.End(span, err) }()
+
+	goal.CreatedAt = s.now().UTC()
+	_, err = s.db.ExecContext(ctx, s.dialect.bind(insertGoal),
+		goal.ID, goal.Title, goal.Metric, goal.Target, goal.StartDate, goal.EndDate, goal.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("create goal: %w", err)
+	}
+	return nil
+}
+
+const selectGoal = "SELECT " + goalColumns + " FROM goals WHERE id = ?"
+
+func (s *goalStore) GetGoal(ctx context.Context, id string) (_ *models.Goal, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "goalStore.GetGoal", selectGoal)
+	defer func() { tracing.End(span, err) }()
+
+	goal, err := scanGoal(s.db.Read.QueryRowContext(ctx, s.dialect.bind(selectGoal), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrGoalNotFound
+		}
+		return nil, fmt.Errorf("get goal: %w", err)
+	}
+	return goal, nil
+}
+
+func (s *goalStore) ListGoals(ctx context.Context, activeOn *time.Time) (_ []*models.Goal, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "goalStore.ListGoals", "")
+	defer func() { tracing.End(span, err) }()
+
+	query := "SELECT " + goalColumns + " FROM goals"
+	args := []any{}
+	if activeOn != nil {
+		// NOTE: YYYY-MM-DD compares correctly as text so no date functions needed
+		day := activeOn.UTC().Format(models.DateLayout)
+		query += " WHERE start_date <= ? AND end_date >= ?"
+		args = append(args, day, day)
+	}
+	query += " ORDER BY end_date ASC, created_at ASC"
+
+	rows, err := s.db.Read.QueryContext(ctx, s.dialect.bind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query goals: %w", err)
+	}
+	defer rows.Close()
+
+	goals := []*models.Goal{}
+	for rows.Next() {
+		goal, err := scanGoal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan goal: %w", err)
+		}
+		goals = append(goals, goal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return goals, nil
+}
+
+const updateGoal = `
+        UPDATE goals
+        SET title = ?, metric = ?, target = ?, start_date = ?, end_date = ?
+        WHERE id = ?
+        RETURNING created_at`
+
+func (s *goalStore) UpdateGoal(ctx context.Context, goal *models.Goal) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "goalStore.UpdateGoal", updateGoal)
+	defer func() { tracing.End(span, err) }()
+
+	err = s.db.QueryRowContext(ctx, s.dialect.bind(updateGoal),
+		goal.Title, goal.Metric, goal.Target, goal.StartDate, goal.EndDate, goal.ID).Scan(&goal.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrGoalNotFound
+		}
+		return fmt.Errorf("update goal: %w", err)
+	}
+	goal.CreatedAt = goal.CreatedAt.UTC()
+	return nil
+}
+
+const deleteGoal = "DELETE FROM goals WHERE id = ?"
+
+func (s *goalStore) DeleteGoal(ctx context.Context, id string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "goalStore.DeleteGoal", deleteGoal)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, s.dialect.bind(deleteGoal), id)
+	if err != nil {
+		return fmt.Errorf("delete goal: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrGoalNotFound
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+func TestGoalStore(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+
+	store := NewGoalStore(db)
+	ctx := context.Background()
+
+	yearly := &models.Goal{ID: uuid.NewString(), Metric: models.GoalBooks, Target: 24, StartDate: "2026-01-01", EndDate: "2026-12-31"}
+	summer := &models.Goal{ID: uuid.NewString(), Metric: models.GoalPages, Target: 3000, StartDate: "2026-06-01", EndDate: "2026-08-31"}
+
+	t.Run("CreateAndGetGoal", func(t *testing.T) {
+		for _, g := range []*models.Goal{yearly, summer} {
+			if err := store.CreateGoal(ctx, g); err != nil {
+				t.Fatalf("CreateGoal failed: %v", err)
+			}
+		}
+		got, err := store.GetGoal(ctx, summer.ID)
+		if err != nil {
+			t.Fatalf("GetGoal failed: %v", err)
+		}
+		if got.Metric != summer.Metric || got.Target != summer.Target || got.StartDate != summer.StartDate || got.EndDate != summer.EndDate {
+			t.Errorf("GetGoal = %+v, want %+v", got, summer)
+		}
+
+		_, err = store.GetGoal(ctx, "nonexistent")
+		if !errors.Is(err, ErrGoalNotFound) {
+			t.Errorf("GetGoal error = %v, want %v", err, ErrGoalNotFound)
+		}
+	})
+
+	t.Run("ListGoals", func(t *testing.T) {
+		all, err := store.ListGoals(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListGoals failed: %v", err)
+		}
+		if len(all) != 2 {
+			t.Errorf("ListGoals returned %d goals, want 2", len(all))
+		}
+
+		march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		active, err := store.ListGoals(ctx, &march)
+		if err != nil {
+			t.Fatalf("ListGoals failed: %v", err)
+		}
+		if len(active) != 1 || active[0].ID != yearly.ID {
+			t.Errorf("ListGoals active in March = %+v, want only the yearly goal", active)
+		}
+	})
+
+	t.Run("UpdateGoal", func(t *testing.T) {
+		summer.Target = 4000
+		if err := store.UpdateGoal(ctx, summer); err != nil {
+			t.Fatalf("UpdateGoal failed: %v", err)
+		}
+		got, err := store.GetGoal(ctx, summer.ID)
+		if err != nil {
+			t.Fatalf("GetGoal failed: %v", err)
+		}
+		if got.Target != 4000 {
+			t.Errorf("Target = %d, want 4000", got.Target)
+		}
+
+		err = store.UpdateGoal(ctx, &models.Goal{ID: "nonexistent", Metric: models.GoalBooks, Target: 1})
+		if !errors.Is(err, ErrGoalNotFound) {
+			t.Errorf("UpdateGoal error = %v, want %v", err, ErrGoalNotFound)
+		}
+	})
+
+	t.Run("DeleteGoal", func(t *testing.T) {
+		if err := store.DeleteGoal(ctx, summer.ID); err != nil {
+			t.Fatalf("DeleteGoal failed: %v", err)
+		}
+		if err := store.DeleteGoal(ctx, summer.ID); !errors.Is(err, ErrGoalNotFound) {
+			t.Errorf("DeleteGoal error = %v, want %v", err, ErrGoalNotFound)
+		}
+	})
+}
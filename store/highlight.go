@@ -0,0 +1,138 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNotHighlight = errors.New("note is not a highlight or quote")
+
+type HighlightStore interface {
+	// DailyHighlights is the review queue of today (UTC): the highlights and quotes that are due, the longest
+	// due first, at most perDay of them less the ones reviewed today. See models.DailyHighlights
+	DailyHighlights(ctx context.Context, perDay int) (*models.DailyHighlights, error)
+	// ReviewHighlight records the feedback given today and returns the next schedule of the highlight.
+	// Only highlights and quotes are reviewed, a plain note is ErrNotHighlight
+	ReviewHighlight(ctx context.Context, noteID string, feedback models.HighlightFeedback) (*models.HighlightSchedule, error)
+}
+
+type highlightStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewHighlightStore(db *DB) HighlightStore {
+	return &highlightStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const scheduleColumns = "repetitions, interval_days, ease, due_on, favorite, discarded, reviewed_on"
+
+// dueHighlights selects the queue, %[1]s is the day a never reviewed highlight is due from (its created day)
+const dueHighlights = `
+        SELECT notes.id, notes.book_id, notes.type, notes.body, notes.page, notes.location, notes.created_at, notes.updated_at, books.title,
+               COALESCE(s.repetitions, 0), COALESCE(s.interval_days, 0), COALESCE(s.ease, ?), COALESCE(s.due_on, %[1]s),
+               COALESCE(s.favorite, FALSE), COALESCE(s.reviewed_on, '')
+        FROM notes
+        JOIN books ON books.id = notes.book_id
+        LEFT JOIN highlight_schedules s ON s.note_id = notes.id
+        WHERE notes.type IN ('highlight', 'quote') AND (s.note_id IS NULL OR NOT s.discarded) AND COALESCE(s.due_on, %[1]s) <= ?
+        ORDER BY COALESCE(s.due_on, %[1]s), notes.id
+        LIMIT ?`
+
+func (s *highlightStore) DailyHighlights(ctx context.Context, perDay int) (_ *models.DailyHighlights, err error) {
+	query := fmt.Sprintf(dueHighlights, fmt.Sprintf(s.dialect.utcDate, "notes.created_at"))
+	ctx, span := s.dialect.startSpan(ctx, "highlightStore.DailyHighlights", query)
+	defer func() { tracing.End(span, err) }()
+
+	today := s.now().UTC().Format(time.DateOnly)
+	daily := &models.DailyHighlights{Date: today, PerDay: perDay, Highlights: []models.DailyHighlight{}}
+
+	// NOTE: One read transaction so a review in between cant be counted and served at the same time
+	tx, err := s.db.Read.BeginTx(ctx, snapshotTx)
+	if err != nil {
+		return nil, fmt.Errorf("begin daily highlights: %w", err)
+	}
+	defer tx.Rollback() // Read only so nothing to commit
+
+	if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT COUNT(*) FROM highlight_schedules WHERE reviewed_on = ?"), today).Scan(&daily.Reviewed); err != nil {
+		return nil, fmt.Errorf("count reviewed highlights: %w", err)
+	}
+	if daily.Reviewed >= perDay {
+		return daily, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, s.dialect.bind(query), models.DefaultHighlightEase, today, perDay-daily.Reviewed)
+	if err != nil {
+		return nil, fmt.Errorf("query daily highlights: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h models.DailyHighlight
+		sched := &h.Schedule
+		if err := rows.Scan(&h.ID, &h.BookID, &h.Type, &h.Body, &h.Page, &h.Location, &h.CreatedAt, &h.UpdatedAt, &h.BookTitle,
+			&sched.Repetitions, &sched.IntervalDays, &sched.Ease, &sched.DueOn, &sched.Favorite, &sched.ReviewedOn); err != nil {
+			return nil, fmt.Errorf("scan daily highlight: %w", err)
+		}
+		h.CreatedAt, h.UpdatedAt = h.CreatedAt.UTC(), h.UpdatedAt.UTC()
+		sched.NoteID = h.ID
+		daily.Highlights = append(daily.Highlights, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return daily, nil
+}
+
+const upsertSchedule = `
+        INSERT INTO highlight_schedules (note_id, ` + scheduleColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT (note_id) DO UPDATE SET repetitions = excluded.repetitions, interval_days = excluded.interval_days,
+            ease = excluded.ease, due_on = excluded.due_on, favorite = excluded.favorite, discarded = excluded.discarded,
+            reviewed_on = excluded.reviewed_on`
+
+func (s *highlightStore) ReviewHighlight(ctx context.Context, noteID string, feedback models.HighlightFeedback) (_ *models.HighlightSchedule, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "highlightStore.ReviewHighlight", upsertSchedule)
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	var schedule models.HighlightSchedule
+	err = withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		var noteType models.NoteType
+		var createdAt time.Time
+		err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT type, created_at FROM notes WHERE id = ?"+s.dialect.lockRow), noteID).Scan(&noteType, &createdAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrNoteNotFound
+			}
+			return fmt.Errorf("get note: %w", err)
+		}
+		if noteType != models.NoteHighlight && noteType != models.NoteQuote {
+			return ErrNotHighlight
+		}
+
+		schedule = models.NewHighlightSchedule(noteID, createdAt)
+		err = tx.QueryRowContext(ctx, s.dialect.bind("SELECT "+scheduleColumns+" FROM highlight_schedules WHERE note_id = ?"), noteID).Scan(
+			&schedule.Repetitions, &schedule.IntervalDays, &schedule.Ease, &schedule.DueOn, &schedule.Favorite, &schedule.Discarded, &schedule.ReviewedOn)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("get highlight schedule: %w", err)
+		}
+
+		schedule.Review(feedback, now)
+		_, err = tx.ExecContext(ctx, s.dialect.bind(upsertSchedule), schedule.NoteID, schedule.Repetitions, schedule.IntervalDays,
+			schedule.Ease, schedule.DueOn, schedule.Favorite, schedule.Discarded, schedule.ReviewedOn)
+		if err != nil {
+			return fmt.Errorf("save highlight schedule: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,592 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type memoryBook struct {
+	book models.Book
+	seq  int // Insertion order, keeps the order of books with the same title stable like SQLite does
+}
+
+type memoryEvent struct {
+	bookID     string
+	fromStatus models.BookStatus // Empty for the event that added the book
+	toStatus   models.BookStatus
+	pages      int
+	occurredAt time.Time
+}
+
+// MemoryStore keeps the books and their status events in maps. It implements BookStore, StatsStore,
+// MetricsStore, TagStore, ShelfStore (see memory_shelf.go), SeriesStore (see memory_series.go), AuthorStore
+// (see memory_author.go), ReviewStore (see memory_review.go), NoteStore (see memory_note.go) and HighlightStore
+// (see memory_highlight.go) with the same semantics as the SQLite stores, the conformance tests make sure of that.
+// NOTE: Everything is gone on restart, its meant for demos, tests and trying out the API
+type MemoryStore struct {
+	mu        sync.RWMutex
+	books     map[string]*memoryBook
+	events    []memoryEvent
+	shelves   map[string]*memoryShelf
+	series    map[string]*models.Series // NOTE: The volumes are the books whose Series points here
+	authors   map[int64]*models.Author  // NOTE: The books keep the contributors with the author names, see renameContributor
+	reviews   map[reviewKey]*models.Review
+	notes     map[string]*models.Note
+	schedules map[string]*models.HighlightSchedule // By note id, only reviewed highlights have one
+	seq       int
+	now       func() time.Time
+
+	authorSeq int64
+}
+
+var (
+	_ BookStore      = (*MemoryStore)(nil)
+	_ StatsStore     = (*MemoryStore)(nil)
+	_ MetricsStore   = (*MemoryStore)(nil)
+	_ TagStore       = (*MemoryStore)(nil)
+	_ ShelfStore     = (*MemoryStore)(nil)
+	_ SeriesStore    = (*MemoryStore)(nil)
+	_ AuthorStore    = (*MemoryStore)(nil)
+	_ ReviewStore    = (*MemoryStore)(nil)
+	_ NoteStore      = (*MemoryStore)(nil)
+	_ HighlightStore = (*MemoryStore)(nil)
+)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{books: map[string]*memoryBook{}, shelves: map[string]*memoryShelf{}, series: map[string]*models.Series{},
+		authors: map[int64]*models.Author{}, reviews: map[reviewKey]*models.Review{}, notes: map[string]*models.Note{},
+		schedules: map[string]*models.HighlightSchedule{}, now: time.Now}
+}
+
+// NOTE: Books are copied in and out so callers can never change what is stored without the lock
+func (s *MemoryStore) CreateBook(ctx context.Context, book *models.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[book.ID]; ok {
+		return fmt.Errorf("create book: id %s already exists", book.ID)
+	}
+	if err := s.resolveContributors(book); err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	book.CompletedAt = nil
+	if book.Status == models.BookComplete {
+		book.CompletedAt = &now
+	}
+	book.Series = nil
+	s.seq++
+	s.books[book.ID] = &memoryBook{book: copyBook(book), seq: s.seq}
+	s.recordEvent(book, "", now)
+	return nil
+}
+
+func (s *MemoryStore) recordEvent(book *models.Book, from models.BookStatus, at time.Time) {
+	s.events = append(s.events, memoryEvent{bookID: book.ID, fromStatus: from, toStatus: book.Status, pages: book.Pages, occurredAt: at})
+}
+
+func (s *MemoryStore) GetBook(ctx context.Context, id string) (*models.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	b, ok := s.books[id]
+	if !ok {
+		return nil, ErrBookNotFound
+	}
+	book := copyBook(&b.book)
+	return &book, nil
+}
+
+func (s *MemoryStore) ListBooks(ctx context.Context, filter models.BookFilter, limit, offset int) ([]*models.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	matches := s.matching(filter)
+	books := []*models.Book{}
+	if offset >= len(matches) {
+		return books, nil
+	}
+	matches = matches[offset:]
+	if limit >= 0 && limit < len(matches) { // NOTE: A negative LIMIT means no limit in SQLite
+		matches = matches[:limit]
+	}
+	for _, b := range matches {
+		book := copyBook(&b.book)
+		books = append(books, &book)
+	}
+	return books, nil
+}
+
+func (s *MemoryStore) CountMatching(ctx context.Context, filter models.BookFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.matching(filter)), nil
+}
+
+// matching returns the books that pass filter in the order of filter.Sort, the caller holds the lock
+func (s *MemoryStore) matching(filter models.BookFilter) []*memoryBook {
+	from, to := filter.CompletedRange()
+	matches := []*memoryBook{}
+	for _, b := range s.books {
+		// NOTE: Title and author ignore case like in the SQL stores, see foldCase
+		if (filter.Status == "" || b.book.Status == filter.Status) &&
+			(filter.Title == "" || foldCase(b.book.Title) == foldCase(filter.Title)) &&
+			(filter.Author == "" || hasAuthor(b.book.Contributors, filter.Author)) &&
+			hasAllTags(b.book.Tags, filter.Tags) && hasAnyTag(b.book.Tags, filter.AnyTags) &&
+			completedWithin(b.book.CompletedAt, from, to) {
+			matches = append(matches, b)
+		}
+	}
+
+	// NOTE: Plain string comparison is byte order, the same as SQLite's default BINARY collation
+	field, desc := filter.Sort.Split()
+	slices.SortFunc(matches, func(a, b *memoryBook) int {
+		var c int
+		switch field {
+		case models.SortAuthor:
+			c = cmp.Compare(a.book.Author, b.book.Author)
+		case models.SortPages:
+			c = cmp.Compare(a.book.Pages, b.book.Pages)
+		case models.SortCompletedAt:
+			if a.book.CompletedAt == nil || b.book.CompletedAt == nil {
+				// Never completed goes last, also when descending
+				return cmp.Or(cmp.Compare(boolInt(a.book.CompletedAt == nil), boolInt(b.book.CompletedAt == nil)), tieBreak(a, b))
+			}
+			c = a.book.CompletedAt.Compare(*b.book.CompletedAt)
+		case models.SortSeries:
+			if a.book.Series == nil || b.book.Series == nil {
+				// Outside a series goes last, also when descending
+				return cmp.Or(cmp.Compare(boolInt(a.book.Series == nil), boolInt(b.book.Series == nil)), tieBreak(a, b))
+			}
+			c = cmp.Or(cmp.Compare(a.book.Series.Name, b.book.Series.Name), cmp.Compare(a.book.Series.ID, b.book.Series.ID),
+				cmp.Compare(a.book.Series.Position, b.book.Series.Position))
+		default:
+			c = cmp.Compare(a.book.Title, b.book.Title)
+		}
+		if desc {
+			c = -c
+		}
+		return cmp.Or(c, tieBreak(a, b))
+	})
+	return matches
+}
+
+// hasAuthor reports if name is one of the authors, translators and the like dont count
+func hasAuthor(contributors []models.Contributor, name string) bool {
+	return slices.ContainsFunc(contributors, func(c models.Contributor) bool {
+		return c.Role == models.RoleAuthor && foldCase(c.Name) == foldCase(name)
+	})
+}
+
+// tieBreak orders books that sort the same by title and then by insertion
+func tieBreak(a, b *memoryBook) int {
+	return cmp.Or(cmp.Compare(a.book.Title, b.book.Title), cmp.Compare(a.seq, b.seq))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func completedWithin(completedAt, from, to *time.Time) bool {
+	if from == nil && to == nil {
+		return true
+	}
+	return completedAt != nil && (from == nil || !completedAt.Before(*from)) && (to == nil || completedAt.Before(*to))
+}
+
+func (s *MemoryStore) UpdateBook(ctx context.Context, book *models.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[book.ID]
+	if !ok {
+		return ErrBookNotFound
+	}
+	if err := s.resolveContributors(book); err != nil {
+		return err
+	}
+	now := s.now().UTC()
+	previous, previousPages := b.book.Status, b.book.Pages
+
+	// NOTE: Same rules as the SQL update, completed_at is kept while the book stays complete
+	switch {
+	case book.Status != models.BookComplete:
+		book.CompletedAt = nil
+	case b.book.CompletedAt != nil:
+		completedAt := *b.book.CompletedAt
+		book.CompletedAt = &completedAt
+	default:
+		book.CompletedAt = &now
+	}
+	// NOTE: The series is not part of an update, same as in the SQL store
+	book.Series = copyBook(&b.book).Series
+	b.book = copyBook(book)
+
+	// NOTE: Same as the SQL update, a new page count is recorded too
+	if previous != book.Status || previousPages != book.Pages {
+		s.recordEvent(book, previous, now)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteBook(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[id]; !ok {
+		return ErrBookNotFound
+	}
+	delete(s.books, id)
+	maps.DeleteFunc(s.reviews, func(key reviewKey, _ *models.Review) bool { return key.bookID == id })
+	maps.DeleteFunc(s.notes, func(_ string, note *models.Note) bool { return note.BookID == id })
+	maps.DeleteFunc(s.schedules, func(noteID string, _ *models.HighlightSchedule) bool { _, ok := s.notes[noteID]; return !ok })
+	s.events = slices.DeleteFunc(s.events, func(e memoryEvent) bool { return e.bookID == id })
+	for _, shelf := range s.shelves {
+		shelf.books = slices.DeleteFunc(shelf.books, func(bookID string) bool { return bookID == id })
+	}
+	return nil
+}
+
+func (s *MemoryStore) CountBooks(ctx context.Context) (total int, byStatus map[string]int, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byStatus = map[string]int{}
+	for _, b := range s.books {
+		byStatus[string(b.book.Status)]++
+	}
+	return len(s.books), byStatus, nil
+}
+
+func (s *MemoryStore) GetStats(ctx context.Context) (totalRead, readingProgress int, err error) {
+	total, byStatus, _ := s.CountBooks(ctx)
+	if total > 0 {
+		readingProgress = byStatus[string(models.BookReading)] * 100 / total
+	}
+	return byStatus[string(models.BookComplete)], readingProgress, nil
+}
+
+func (s *MemoryStore) Completions(ctx context.Context, from, to time.Time) (books, pages int, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, b := range s.books {
+		if b.book.Status != models.BookComplete || b.book.CompletedAt == nil {
+			continue
+		}
+		if at := *b.book.CompletedAt; !at.Before(from) && at.Before(to) {
+			books++
+			pages += b.book.Pages
+		}
+	}
+	return books, pages, nil
+}
+
+func (s *MemoryStore) Timeline(ctx context.Context, q models.TimelineQuery) ([]models.TimelineBucket, error) {
+	if _, ok := bucketModifiers[q.Granularity]; !ok {
+		return nil, models.ErrInvalidGranularity
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	buckets := []models.TimelineBucket{}
+	index := map[string]int{}
+	for start := q.Granularity.BucketStart(q.From); !start.After(q.To); start = q.Granularity.Next(start) {
+		key := start.Format(models.DateLayout)
+		index[key] = len(buckets)
+		buckets = append(buckets, models.TimelineBucket{Start: key})
+	}
+
+	from, end := q.From.UTC(), q.To.UTC().AddDate(0, 0, 1)
+	for _, e := range s.events {
+		if e.occurredAt.Before(from) || !e.occurredAt.Before(end) || e.fromStatus == e.toStatus {
+			continue
+		}
+		i, ok := index[q.Granularity.BucketStart(e.occurredAt).Format(models.DateLayout)]
+		if !ok {
+			continue
+		}
+		switch e.toStatus {
+		case models.BookReading:
+			buckets[i].Started++
+		case models.BookComplete:
+			buckets[i].Finished++
+			buckets[i].PagesRead += e.pages
+		}
+	}
+	return buckets, nil
+}
+
+func (s *MemoryStore) EventTimes(ctx context.Context, before time.Time) ([]time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	times := []time.Time{}
+	for _, e := range s.events {
+		if e.occurredAt.Before(before) {
+			times = append(times, e.occurredAt)
+		}
+	}
+	slices.SortFunc(times, func(a, b time.Time) int { return a.Compare(b) })
+	return times, nil
+}
+
+func (s *MemoryStore) AuthorStats(ctx context.Context, limit, offset int) ([]models.AuthorStats, int, error) {
+	s.mu.RLock()
+	authors := s.rankAuthors()
+	s.mu.RUnlock()
+	total := len(authors)
+	if offset >= total {
+		return []models.AuthorStats{}, total, nil
+	}
+	authors = authors[offset:]
+	if limit >= 0 && limit < len(authors) {
+		authors = authors[:limit]
+	}
+	return authors, total, nil
+}
+
+func (s *MemoryStore) TopAuthors(ctx context.Context, maxRank int) ([]models.AuthorStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.topAuthors(maxRank), nil
+}
+
+// NOTE: Under one read lock, the same as the snapshot of the SQL stores
+func (s *MemoryStore) BookStats(ctx context.Context, maxRank int) (models.Stats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats models.Stats
+	reading := 0
+	for _, b := range s.books {
+		switch b.book.Status {
+		case models.BookComplete:
+			stats.TotalRead++
+		case models.BookAbandoned:
+			stats.TotalAbandoned++
+		case models.BookReading:
+			reading++
+		}
+	}
+	if len(s.books) > 0 {
+		stats.ReadingProgress = reading * 100 / len(s.books)
+	}
+	stats.TopAuthors = s.topAuthors(maxRank)
+	stats.Ratings = models.NewRatingStats(s.ratingCounts())
+	return stats, nil
+}
+
+// topAuthors needs the read lock
+func (s *MemoryStore) topAuthors(maxRank int) []models.AuthorStats {
+	top := []models.AuthorStats{}
+	for _, a := range s.rankAuthors() {
+		if a.Rank > maxRank {
+			break
+		}
+		top = append(top, a)
+	}
+	return top
+}
+
+// rankAuthors is the in memory version of RANK() OVER (ORDER BY COUNT(*) DESC), see selectAuthorStats.
+// Needs the read lock
+func (s *MemoryStore) rankAuthors() []models.AuthorStats {
+	byAuthor := map[int64]*models.AuthorStats{}
+	for _, b := range s.books {
+		for _, c := range b.book.Contributors {
+			if c.Role != models.RoleAuthor {
+				continue
+			}
+			a, ok := byAuthor[c.AuthorID]
+			if !ok {
+				a = &models.AuthorStats{Author: c.Name}
+				byAuthor[c.AuthorID] = a
+			}
+			a.Total++
+			switch b.book.Status {
+			case models.BookUnread:
+				a.ToRead++
+			case models.BookReading:
+				a.Reading++
+			case models.BookComplete:
+				a.Complete++
+			case models.BookAbandoned:
+				a.Abandoned++
+			}
+		}
+	}
+
+	authors := make([]models.AuthorStats, 0, len(byAuthor))
+	for _, a := range byAuthor {
+		a.CompletionRate = float64(a.Complete) / float64(a.Total)
+		authors = append(authors, *a)
+	}
+	slices.SortFunc(authors, func(a, b models.AuthorStats) int {
+		return cmp.Or(cmp.Compare(b.Total, a.Total), cmp.Compare(a.Author, b.Author))
+	})
+	for i := range authors {
+		if i > 0 && authors[i].Total == authors[i-1].Total {
+			authors[i].Rank = authors[i-1].Rank
+		} else {
+			authors[i].Rank = i + 1
+		}
+	}
+	return authors
+}
+
+func (s *MemoryStore) LibrarySnapshot(ctx context.Context, topAuthors int, since time.Time) (*LibrarySnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot := &LibrarySnapshot{
+		BooksByStatus:    map[string]int{},
+		TopAuthors:       []AuthorCount{},
+		CompletionsByDay: map[string]int{},
+	}
+	counts := map[string]int{}
+	for _, b := range s.books {
+		snapshot.BooksByStatus[string(b.book.Status)]++
+		for _, c := range b.book.Contributors {
+			if c.Role == models.RoleAuthor {
+				counts[c.Name]++ // NOTE: Names are unique ignoring case, so the name is as good as the id
+			}
+		}
+		if b.book.CompletedAt != nil && !b.book.CompletedAt.Before(since) {
+			snapshot.CompletionsByDay[b.book.CompletedAt.UTC().Format(models.DateLayout)]++
+		}
+	}
+	for author, count := range counts {
+		snapshot.TopAuthors = append(snapshot.TopAuthors, AuthorCount{Author: author, Count: count})
+	}
+	slices.SortFunc(snapshot.TopAuthors, func(a, b AuthorCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Author, b.Author))
+	})
+	snapshot.TopAuthors = snapshot.TopAuthors[:min(topAuthors, len(snapshot.TopAuthors))]
+	return snapshot, nil
+}
+
+func copyBook(b *models.Book) models.Book {
+	book := *b
+	if b.CompletedAt != nil {
+		completedAt := *b.CompletedAt
+		book.CompletedAt = &completedAt
+	}
+	book.Tags = slices.Clone(b.Tags)
+	book.Contributors = slices.Clone(b.Contributors)
+	if b.Series != nil {
+		series := *b.Series
+		book.Series = &series
+	}
+	return book
+}
+
+func hasAllTags(tags, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasAnyTag(tags, want []string) bool {
+	return len(want) == 0 || slices.ContainsFunc(want, func(tag string) bool { return slices.Contains(tags, tag) })
+}
+
+func (s *MemoryStore) ListTags(ctx context.Context) ([]models.TagCount, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	counts := map[string]int{}
+	for _, b := range s.books {
+		for _, tag := range b.book.Tags {
+			counts[tag]++
+		}
+	}
+	tags := []models.TagCount{}
+	for name, books := range counts {
+		tags = append(tags, models.TagCount{Name: name, Books: books})
+	}
+	slices.SortFunc(tags, func(a, b models.TagCount) int {
+		return cmp.Or(cmp.Compare(b.Books, a.Books), cmp.Compare(a.Name, b.Name))
+	})
+	return tags, nil
+}
+
+// retag calls fn with the tags of every book that has tag and stores what it returns, sorted and deduplicated.
+// It reports whether any book had the tag
+func (s *MemoryStore) retag(tag string, fn func(tags []string) []string) bool {
+	found := false
+	for _, b := range s.books {
+		if !slices.Contains(b.book.Tags, tag) {
+			continue
+		}
+		found = true
+		tags := fn(slices.DeleteFunc(b.book.Tags, func(t string) bool { return t == tag }))
+		slices.Sort(tags)
+		b.book.Tags = slices.Compact(tags)
+		if len(b.book.Tags) == 0 {
+			b.book.Tags = nil
+		}
+	}
+	return found
+}
+
+func (s *MemoryStore) inUse(tag string) bool {
+	for _, b := range s.books {
+		if slices.Contains(b.book.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *MemoryStore) RenameTag(ctx context.Context, from, to string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.inUse(from) {
+		return ErrTagNotFound
+	}
+	if from == to {
+		return nil
+	}
+	if s.inUse(to) {
+		return ErrTagExists
+	}
+	s.retag(from, func(tags []string) []string { return append(tags, to) })
+	return nil
+}
+
+func (s *MemoryStore) MergeTags(ctx context.Context, from, into string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.inUse(from) || !s.inUse(into) {
+		return ErrTagNotFound
+	}
+	s.retag(from, func(tags []string) []string { return append(tags, into) })
+	return nil
+}
+
+func (s *MemoryStore) DeleteTag(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.retag(name, func(tags []string) []string { return tags }) {
+		return ErrTagNotFound
+	}
+	return nil
+}
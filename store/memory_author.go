@@ -0,0 +1,190 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"slices"
+)
+
+// authorByName returns the author called name ignoring case, nil without one. The caller holds the lock
+func (s *MemoryStore) authorByName(name string) *models.Author {
+	for _, author := range s.authors {
+		if foldCase(author.Name) == foldCase(name) {
+			return author
+		}
+	}
+	return nil
+}
+
+// resolveContributors is the in memory version of resolveContributors in author.go, the caller holds the lock
+func (s *MemoryStore) resolveContributors(book *models.Book) error {
+	contributors := book.Contributors
+	if len(contributors) == 0 {
+		contributors = []models.Contributor{{Name: book.Author, Role: models.RoleAuthor}}
+	}
+	type key struct {
+		id   int64
+		role models.ContributorRole
+	}
+	seen := map[key]bool{}
+	resolved := make([]models.Contributor, 0, len(contributors))
+	for _, c := range contributors {
+		if c.AuthorID != 0 {
+			author, ok := s.authors[c.AuthorID]
+			if !ok {
+				return ErrAuthorNotFound
+			}
+			c.Name = author.Name
+		} else {
+			author := s.authorByName(c.Name)
+			if author == nil {
+				s.authorSeq++
+				author = &models.Author{ID: s.authorSeq, Name: c.Name, SortName: models.DefaultSortName(c.Name)}
+				s.authors[author.ID] = author
+			}
+			c.AuthorID, c.Name = author.ID, author.Name
+		}
+		if seen[key{c.AuthorID, c.Role}] {
+			continue
+		}
+		seen[key{c.AuthorID, c.Role}] = true
+		resolved = append(resolved, c)
+	}
+	book.Contributors, book.Author = resolved, models.AuthorLine(resolved)
+	return nil
+}
+
+// authorBooks counts the books author is on in any role, the caller holds the lock
+func (s *MemoryStore) authorBooks(id int64) int {
+	books := 0
+	for _, b := range s.books {
+		if slices.ContainsFunc(b.book.Contributors, func(c models.Contributor) bool { return c.AuthorID == id }) {
+			books++
+		}
+	}
+	return books
+}
+
+// authorCopy returns a copy of the stored author with its book count, the caller holds the lock
+func (s *MemoryStore) authorCopy(stored *models.Author) *models.Author {
+	author := *stored
+	author.Books = s.authorBooks(author.ID)
+	return &author
+}
+
+// renameContributor writes the stored name of author into every book it is on, the caller holds the lock
+func (s *MemoryStore) renameContributor(author *models.Author) {
+	for _, b := range s.books {
+		for i := range b.book.Contributors {
+			if b.book.Contributors[i].AuthorID == author.ID {
+				b.book.Contributors[i].Name = author.Name
+				b.book.Author = models.AuthorLine(b.book.Contributors)
+			}
+		}
+	}
+}
+
+func (s *MemoryStore) CreateAuthor(ctx context.Context, author *models.Author) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.authorByName(author.Name) != nil {
+		return ErrAuthorExists
+	}
+	s.authorSeq++
+	author.ID = s.authorSeq
+	author.Books = 0
+	stored := *author
+	s.authors[author.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) GetAuthor(ctx context.Context, id int64) (*models.Author, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	author, ok := s.authors[id]
+	if !ok {
+		return nil, ErrAuthorNotFound
+	}
+	return s.authorCopy(author), nil
+}
+
+func (s *MemoryStore) ListAuthors(ctx context.Context) ([]*models.Author, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	authors := make([]*models.Author, 0, len(s.authors))
+	for _, author := range s.authors {
+		authors = append(authors, s.authorCopy(author))
+	}
+	slices.SortFunc(authors, func(a, b *models.Author) int {
+		return cmp.Or(cmp.Compare(a.SortName, b.SortName), cmp.Compare(a.ID, b.ID))
+	})
+	return authors, nil
+}
+
+func (s *MemoryStore) UpdateAuthor(ctx context.Context, author *models.Author) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.authors[author.ID]
+	if !ok {
+		return ErrAuthorNotFound
+	}
+	if other := s.authorByName(author.Name); other != nil && other.ID != author.ID {
+		return ErrAuthorExists
+	}
+	stored.Name, stored.SortName = author.Name, author.SortName
+	s.renameContributor(stored)
+	author.Books = s.authorBooks(author.ID)
+	return nil
+}
+
+func (s *MemoryStore) DeleteAuthor(ctx context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.authorBooks(id) > 0 {
+		return ErrAuthorInUse
+	}
+	if _, ok := s.authors[id]; !ok {
+		return ErrAuthorNotFound
+	}
+	delete(s.authors, id)
+	return nil
+}
+
+func (s *MemoryStore) MergeAuthors(ctx context.Context, from, into int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.authors[into]
+	if !ok {
+		return ErrAuthorNotFound
+	}
+	if _, ok := s.authors[from]; !ok {
+		return ErrAuthorNotFound
+	}
+	// NOTE: Same as the SQL merge, a book that already has into in the same role keeps that one
+	for _, b := range s.books {
+		roles := map[models.ContributorRole]bool{}
+		for _, c := range b.book.Contributors {
+			if c.AuthorID == into {
+				roles[c.Role] = true
+			}
+		}
+		b.book.Contributors = slices.DeleteFunc(b.book.Contributors, func(c models.Contributor) bool {
+			return c.AuthorID == from && roles[c.Role]
+		})
+		for i := range b.book.Contributors {
+			if b.book.Contributors[i].AuthorID == from {
+				b.book.Contributors[i].AuthorID = into
+			}
+		}
+	}
+	delete(s.authors, from)
+	s.renameContributor(target)
+	return nil
+}
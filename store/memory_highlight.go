@@ -0,0 +1,68 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"slices"
+	"time"
+)
+
+// schedule is the stored schedule of a highlight or the one it starts with. Needs the lock
+func (s *MemoryStore) schedule(note *models.Note) models.HighlightSchedule {
+	if stored, ok := s.schedules[note.ID]; ok {
+		return *stored
+	}
+	return models.NewHighlightSchedule(note.ID, note.CreatedAt)
+}
+
+func (s *MemoryStore) DailyHighlights(ctx context.Context, perDay int) (*models.DailyHighlights, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	today := s.now().UTC().Format(time.DateOnly)
+	daily := &models.DailyHighlights{Date: today, PerDay: perDay, Highlights: []models.DailyHighlight{}}
+	for _, schedule := range s.schedules {
+		if schedule.ReviewedOn == today {
+			daily.Reviewed++
+		}
+	}
+	if daily.Reviewed >= perDay {
+		return daily, nil
+	}
+
+	for _, note := range s.notes {
+		if note.Type != models.NoteHighlight && note.Type != models.NoteQuote {
+			continue
+		}
+		schedule := s.schedule(note)
+		if schedule.Discarded || schedule.DueOn > today {
+			continue
+		}
+		daily.Highlights = append(daily.Highlights, models.DailyHighlight{Note: *note, BookTitle: s.books[note.BookID].book.Title, Schedule: schedule})
+	}
+	// NOTE: Same as dueHighlights, the longest due first
+	slices.SortFunc(daily.Highlights, func(a, b models.DailyHighlight) int {
+		return cmp.Or(cmp.Compare(a.Schedule.DueOn, b.Schedule.DueOn), cmp.Compare(a.ID, b.ID))
+	})
+	daily.Highlights = daily.Highlights[:min(len(daily.Highlights), perDay-daily.Reviewed)]
+	return daily, nil
+}
+
+func (s *MemoryStore) ReviewHighlight(ctx context.Context, noteID string, feedback models.HighlightFeedback) (*models.HighlightSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	note, ok := s.notes[noteID]
+	if !ok {
+		return nil, ErrNoteNotFound
+	}
+	if note.Type != models.NoteHighlight && note.Type != models.NoteQuote {
+		return nil, ErrNotHighlight
+	}
+	schedule := s.schedule(note)
+	schedule.Review(feedback, s.now())
+	stored := schedule
+	s.schedules[noteID] = &stored
+	return &schedule, nil
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+)
+
+func (s *MemoryStore) CreateNote(ctx context.Context, note *models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.books[note.BookID]; !ok {
+		return ErrBookNotFound
+	}
+	now := s.now().UTC()
+	note.CreatedAt, note.UpdatedAt = now, now
+	stored := *note
+	s.notes[note.ID] = &stored
+	return nil
+}
+
+func (s *MemoryStore) GetNote(ctx context.Context, id string) (*models.Note, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.notes[id]
+	if !ok {
+		return nil, ErrNoteNotFound
+	}
+	note := *stored
+	return &note, nil
+}
+
+// matchesTerms is the full text search of the SQL stores, every term has to be the start of some word
+func matchesTerms(body string, terms []string) bool {
+	words := models.NoteSearchTerms(body)
+	for _, term := range terms {
+		if !slices.ContainsFunc(words, func(word string) bool { return strings.HasPrefix(word, term) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *MemoryStore) ListNotes(ctx context.Context, query models.NoteQuery, limit, offset int) ([]*models.Note, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.books[query.BookID]; query.BookID != "" && !ok {
+		return nil, ErrBookNotFound
+	}
+	notes := []*models.Note{}
+	for _, stored := range s.notes {
+		if query.BookID != "" && stored.BookID != query.BookID {
+			continue
+		}
+		if query.Type != "" && stored.Type != query.Type {
+			continue
+		}
+		if !matchesTerms(stored.Body, query.Terms) {
+			continue
+		}
+		note := *stored
+		notes = append(notes, &note)
+	}
+	if query.BookID != "" {
+		// NOTE: Same as noteBookOrder, the notes without a page go last
+		unpaged := func(n *models.Note) int {
+			if n.Page == 0 {
+				return 1
+			}
+			return 0
+		}
+		slices.SortFunc(notes, func(a, b *models.Note) int {
+			return cmp.Or(cmp.Compare(unpaged(a), unpaged(b)), cmp.Compare(a.Page, b.Page),
+				a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+		})
+	} else {
+		slices.SortFunc(notes, func(a, b *models.Note) int {
+			return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(a.ID, b.ID))
+		})
+	}
+	if offset >= len(notes) {
+		return []*models.Note{}, nil
+	}
+	notes = notes[offset:]
+	if limit >= 0 && limit < len(notes) {
+		notes = notes[:limit]
+	}
+	return notes, nil
+}
+
+func (s *MemoryStore) UpdateNote(ctx context.Context, note *models.Note) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.notes[note.ID]
+	if !ok {
+		return ErrNoteNotFound
+	}
+	note.BookID, note.CreatedAt, note.UpdatedAt = stored.BookID, stored.CreatedAt, s.now().UTC()
+	updated := *note
+	s.notes[note.ID] = &updated
+	return nil
+}
+
+func (s *MemoryStore) DeleteNote(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.notes[id]; !ok {
+		return ErrNoteNotFound
+	}
+	delete(s.notes, id)
+	delete(s.schedules, id)
+	return nil
+}
+
+func (s *MemoryStore) ImportNotes(ctx context.Context, notes []*models.Note) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// NOTE: Checked up front, like the rollback of the SQL stores nothing is written when a book is missing
+	for _, note := range notes {
+		if _, ok := s.books[note.BookID]; !ok {
+			return 0, ErrBookNotFound
+		}
+	}
+	now := s.now().UTC()
+	added := 0
+	for _, note := range notes {
+		if _, ok := s.notes[note.ID]; ok {
+			continue
+		}
+		note.CreatedAt, note.UpdatedAt = importedTimes(note, now)
+		stored := *note
+		s.notes[note.ID] = &stored
+		added++
+	}
+	return added, nil
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"slices"
+)
+
+// reviewKey is the primary key of the reviews table, one review per book and user
+type reviewKey struct {
+	bookID, owner string
+}
+
+func (s *MemoryStore) SetReview(ctx context.Context, owner string, review *models.Review) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[review.BookID]
+	if !ok {
+		return ErrBookNotFound
+	}
+	if !b.book.Status.Finished() {
+		return ErrBookNotComplete
+	}
+	now := s.now().UTC()
+	review.Owner = owner
+	review.CreatedAt, review.UpdatedAt = now, now
+	key := reviewKey{review.BookID, owner}
+	if stored, ok := s.reviews[key]; ok {
+		review.CreatedAt = stored.CreatedAt
+	}
+	stored := *review
+	s.reviews[key] = &stored
+	return nil
+}
+
+func (s *MemoryStore) GetReview(ctx context.Context, owner, bookID string) (*models.Review, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.reviews[reviewKey{bookID, owner}]
+	if !ok {
+		return nil, ErrReviewNotFound
+	}
+	review := *stored
+	return &review, nil
+}
+
+func (s *MemoryStore) ListReviews(ctx context.Context, owner string, limit, offset int) ([]*models.Review, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reviews := []*models.Review{}
+	for key, stored := range s.reviews {
+		if key.owner != owner {
+			continue
+		}
+		review := *stored
+		reviews = append(reviews, &review)
+	}
+	slices.SortFunc(reviews, func(a, b *models.Review) int {
+		return cmp.Or(b.UpdatedAt.Compare(a.UpdatedAt), cmp.Compare(a.BookID, b.BookID))
+	})
+	if offset >= len(reviews) {
+		return []*models.Review{}, nil
+	}
+	reviews = reviews[offset:]
+	if limit >= 0 && limit < len(reviews) {
+		reviews = reviews[:limit]
+	}
+	return reviews, nil
+}
+
+func (s *MemoryStore) DeleteReview(ctx context.Context, owner, bookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := reviewKey{bookID, owner}
+	if _, ok := s.reviews[key]; !ok {
+		return ErrReviewNotFound
+	}
+	delete(s.reviews, key)
+	return nil
+}
+
+func (s *MemoryStore) RatingCounts(ctx context.Context) (map[float64]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.ratingCounts(), nil
+}
+
+// ratingCounts needs the read lock
+func (s *MemoryStore) ratingCounts() map[float64]int {
+	counts := map[float64]int{}
+	for _, review := range s.reviews {
+		counts[review.Rating]++
+	}
+	return counts
+}
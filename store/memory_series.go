@@ -0,0 +1,150 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+func (s *MemoryStore) CreateSeries(ctx context.Context, series *models.Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.series[series.ID]; ok {
+		return fmt.Errorf("create series: id %s already exists", series.ID)
+	}
+	series.CreatedAt = s.now().UTC()
+	series.Books = 0
+	stored := *series
+	s.series[series.ID] = &stored
+	return nil
+}
+
+// seriesCopy returns a copy of the stored series with its book count, the caller holds the lock
+func (s *MemoryStore) seriesCopy(stored *models.Series) *models.Series {
+	series := *stored
+	series.Books = len(s.volumes(series.ID))
+	return &series
+}
+
+// volumes returns the books of a series by position, the caller holds the lock
+func (s *MemoryStore) volumes(seriesID string) []*memoryBook {
+	volumes := []*memoryBook{}
+	for _, b := range s.books {
+		if b.book.Series != nil && b.book.Series.ID == seriesID {
+			volumes = append(volumes, b)
+		}
+	}
+	slices.SortFunc(volumes, func(a, b *memoryBook) int {
+		return cmp.Or(cmp.Compare(a.book.Series.Position, b.book.Series.Position), cmp.Compare(a.book.Title, b.book.Title), cmp.Compare(a.book.ID, b.book.ID))
+	})
+	return volumes
+}
+
+func (s *MemoryStore) GetSeries(ctx context.Context, id string) (*models.Series, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series, ok := s.series[id]
+	if !ok {
+		return nil, ErrSeriesNotFound
+	}
+	return s.seriesCopy(series), nil
+}
+
+func (s *MemoryStore) ListSeries(ctx context.Context) ([]*models.Series, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := []*models.Series{}
+	for _, series := range s.series {
+		list = append(list, s.seriesCopy(series))
+	}
+	slices.SortFunc(list, func(a, b *models.Series) int {
+		return cmp.Or(cmp.Compare(a.Name, b.Name), cmp.Compare(a.ID, b.ID))
+	})
+	return list, nil
+}
+
+func (s *MemoryStore) UpdateSeries(ctx context.Context, series *models.Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.series[series.ID]
+	if !ok {
+		return ErrSeriesNotFound
+	}
+	stored.Name = series.Name
+	stored.Description = series.Description
+	// NOTE: The books carry the name of their series, the SQL stores join it in on every read
+	for _, b := range s.volumes(series.ID) {
+		b.book.Series.Name = series.Name
+	}
+	*series = *s.seriesCopy(stored)
+	return nil
+}
+
+func (s *MemoryStore) DeleteSeries(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.series[id]; !ok {
+		return ErrSeriesNotFound
+	}
+	for _, b := range s.volumes(id) {
+		b.book.Series = nil
+	}
+	delete(s.series, id)
+	return nil
+}
+
+func (s *MemoryStore) SetVolume(ctx context.Context, seriesID, bookID string, position float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	series, ok := s.series[seriesID]
+	if !ok {
+		return ErrSeriesNotFound
+	}
+	b, ok := s.books[bookID]
+	if !ok {
+		return ErrBookNotFound
+	}
+	if b.book.Series != nil && b.book.Series.ID != seriesID {
+		return ErrBookInOtherSeries
+	}
+	b.book.Series = &models.BookSeries{ID: seriesID, Name: series.Name, Position: position}
+	return nil
+}
+
+func (s *MemoryStore) RemoveVolume(ctx context.Context, seriesID, bookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.series[seriesID]; !ok {
+		return ErrSeriesNotFound
+	}
+	b, ok := s.books[bookID]
+	if !ok || b.book.Series == nil || b.book.Series.ID != seriesID {
+		return ErrBookNotInSeries
+	}
+	b.book.Series = nil
+	return nil
+}
+
+func (s *MemoryStore) ListVolumes(ctx context.Context, seriesID string) ([]*models.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.series[seriesID]; !ok {
+		return nil, ErrSeriesNotFound
+	}
+	books := []*models.Book{}
+	for _, b := range s.volumes(seriesID) {
+		book := copyBook(&b.book)
+		books = append(books, &book)
+	}
+	return books, nil
+}
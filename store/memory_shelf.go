@@ -0,0 +1,150 @@
+package store
+
+import (
+	"book-tracker/models"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// memoryShelf keeps the ids of its books from top to bottom, the index is the position
+type memoryShelf struct {
+	shelf models.Shelf
+	books []string
+}
+
+func (s *MemoryStore) CreateShelf(ctx context.Context, shelf *models.Shelf) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.shelves[shelf.ID]; ok {
+		return fmt.Errorf("create shelf: id %s already exists", shelf.ID)
+	}
+	shelf.CreatedAt = s.now().UTC()
+	shelf.Books = 0
+	s.shelves[shelf.ID] = &memoryShelf{shelf: *shelf}
+	return nil
+}
+
+func (s *MemoryStore) GetShelf(ctx context.Context, id string) (*models.Shelf, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shelf, ok := s.shelves[id]
+	if !ok {
+		return nil, ErrShelfNotFound
+	}
+	return shelf.copy(), nil
+}
+
+func (m *memoryShelf) copy() *models.Shelf {
+	shelf := m.shelf
+	shelf.Books = len(m.books)
+	return &shelf
+}
+
+func (s *MemoryStore) ListShelves(ctx context.Context) ([]*models.Shelf, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shelves := []*models.Shelf{}
+	for _, shelf := range s.shelves {
+		shelves = append(shelves, shelf.copy())
+	}
+	slices.SortFunc(shelves, func(a, b *models.Shelf) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	return shelves, nil
+}
+
+func (s *MemoryStore) UpdateShelf(ctx context.Context, shelf *models.Shelf) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.shelves[shelf.ID]
+	if !ok {
+		return ErrShelfNotFound
+	}
+	stored.shelf.Name = shelf.Name
+	stored.shelf.Description = shelf.Description
+	*shelf = *stored.copy()
+	return nil
+}
+
+func (s *MemoryStore) DeleteShelf(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.shelves[id]; !ok {
+		return ErrShelfNotFound
+	}
+	delete(s.shelves, id)
+	return nil
+}
+
+func (s *MemoryStore) AddBook(ctx context.Context, shelfID, bookID string, position *int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shelf, ok := s.shelves[shelfID]
+	if !ok {
+		return ErrShelfNotFound
+	}
+	if slices.Contains(shelf.books, bookID) {
+		return ErrBookOnShelf
+	}
+	if _, ok := s.books[bookID]; !ok {
+		return ErrBookNotFound
+	}
+	shelf.books = placeAt(shelf.books, bookID, position)
+	return nil
+}
+
+func (s *MemoryStore) MoveBook(ctx context.Context, shelfID, bookID string, position int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shelf, ok := s.shelves[shelfID]
+	if !ok {
+		return ErrShelfNotFound
+	}
+	i := slices.Index(shelf.books, bookID)
+	if i < 0 {
+		return ErrBookNotOnShelf
+	}
+	shelf.books = placeAt(slices.Delete(shelf.books, i, i+1), bookID, &position)
+	return nil
+}
+
+func (s *MemoryStore) RemoveBook(ctx context.Context, shelfID, bookID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	shelf, ok := s.shelves[shelfID]
+	if !ok {
+		return ErrShelfNotFound
+	}
+	i := slices.Index(shelf.books, bookID)
+	if i < 0 {
+		return ErrBookNotOnShelf
+	}
+	shelf.books = slices.Delete(shelf.books, i, i+1)
+	return nil
+}
+
+func (s *MemoryStore) ListShelfBooks(ctx context.Context, shelfID string, limit, offset int) ([]*models.Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	shelf, ok := s.shelves[shelfID]
+	if !ok {
+		return nil, ErrShelfNotFound
+	}
+	books := []*models.Book{}
+	for _, bookID := range shelf.books[min(offset, len(shelf.books)):min(offset+limit, len(shelf.books))] {
+		book := copyBook(&s.books[bookID].book)
+		books = append(books, &book)
+	}
+	return books, nil
+}
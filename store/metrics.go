@@ -0,0 +1,123 @@
+package store
+
+import (
+	"book-tracker/tracing"
+	"context"
+	"fmt"
+	"time"
+)
+
+type AuthorCount struct {
+	Author string
+	Count  int
+}
+
+// LibrarySnapshot is what the /metrics endpoint exports about the library itself
+type LibrarySnapshot struct {
+	BooksByStatus    map[string]int
+	TopAuthors       []AuthorCount
+	CompletionsByDay map[string]int // Keyed by UTC date (YYYY-MM-DD)
+	DBSizeBytes      int64
+}
+
+type MetricsStore interface {
+	LibrarySnapshot(ctx context.Context, topAuthors int, since time.Time) (*LibrarySnapshot, error)
+}
+
+type metricsStore struct {
+	db      *DB
+	dialect dialect
+}
+
+func NewMetricsStore(db *DB) MetricsStore {
+	return &metricsStore{db: db, dialect: sqliteDialect}
+}
+
+// NOTE: All queries run in one transaction so the numbers in a single scrape agree with each other
+func (s *metricsStore) LibrarySnapshot(ctx context.Context, topAuthors int, since time.Time) (_ *LibrarySnapshot, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "metricsStore.LibrarySnapshot", "")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := s.db.Read.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin snapshot: %w", err)
+	}
+	defer tx.Rollback() // Read only so nothing to commit
+
+	snapshot := &LibrarySnapshot{
+		BooksByStatus:    map[string]int{},
+		TopAuthors:       []AuthorCount{},
+		CompletionsByDay: map[string]int{},
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT status, COUNT(*) FROM books GROUP BY status")
+	if err != nil {
+		return nil, fmt.Errorf("query books by status: %w", err)
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan status count: %w", err)
+		}
+		snapshot.BooksByStatus[status] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	rows, err = tx.QueryContext(ctx, s.dialect.bind(`
+		SELECT authors.name, COUNT(*)
+		FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+		WHERE book_authors.role = 'author'
+		GROUP BY authors.id, authors.name
+		ORDER BY COUNT(*) DESC, `+fmt.Sprintf(s.dialect.byteOrder, "authors.name")+` ASC
+		LIMIT ?`), topAuthors)
+	if err != nil {
+		return nil, fmt.Errorf("query top authors: %w", err)
+	}
+	for rows.Next() {
+		var ac AuthorCount
+		if err := rows.Scan(&ac.Author, &ac.Count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan author count: %w", err)
+		}
+		snapshot.TopAuthors = append(snapshot.TopAuthors, ac)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	day := fmt.Sprintf(s.dialect.utcDate, "completed_at")
+	rows, err = tx.QueryContext(ctx, s.dialect.bind(`
+		SELECT `+day+`, COUNT(*)
+		FROM books
+		WHERE completed_at >= ?
+		GROUP BY `+day), since.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query completions: %w", err)
+	}
+	for rows.Next() {
+		var day string
+		var count int
+		if err := rows.Scan(&day, &count); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan completions: %w", err)
+		}
+		snapshot.CompletionsByDay[day] = count
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	err = tx.QueryRowContext(ctx, s.dialect.dbSize).Scan(&snapshot.DBSizeBytes)
+	if err != nil {
+		return nil, fmt.Errorf("query db size: %w", err)
+	}
+
+	return snapshot, nil
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+func TestMetricsStore(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+
+	bookStore := NewBookStore(db).(*bookStore)
+	store := NewMetricsStore(db)
+	ctx := context.Background()
+
+	completedAt := time.Date(2026, 3, 14, 15, 9, 26, 535897932, time.UTC)
+	bookStore.now = func() time.Time { return completedAt }
+
+	books := []models.Book{
+		{ID: uuid.NewString(), Title: "Book 1", Author: "Author A", Status: models.BookComplete},
+		{ID: uuid.NewString(), Title: "Book 2", Author: "Author A", Status: models.BookReading},
+		{ID: uuid.NewString(), Title: "Book 3", Author: "Author B", Status: models.BookUnread},
+		{ID: uuid.NewString(), Title: "Book 4", Author: "Author C", Status: models.BookComplete},
+	}
+	for _, b := range books {
+		if err := bookStore.CreateBook(ctx, &b); err != nil {
+			t.Fatalf("CreateBook failed: %v", err)
+		}
+	}
+
+	snapshot, err := store.LibrarySnapshot(ctx, 2, completedAt.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("LibrarySnapshot failed: %v", err)
+	}
+
+	if snapshot.BooksByStatus["complete"] != 2 || snapshot.BooksByStatus["reading"] != 1 || snapshot.BooksByStatus["unread"] != 1 {
+		t.Errorf("BooksByStatus = %+v, want complete:2, reading:1, unread:1", snapshot.BooksByStatus)
+	}
+	if len(snapshot.TopAuthors) != 2 {
+		t.Fatalf("TopAuthors has %d entries, want 2", len(snapshot.TopAuthors))
+	}
+	if snapshot.TopAuthors[0] != (AuthorCount{Author: "Author A", Count: 2}) || snapshot.TopAuthors[1].Author != "Author B" {
+		t.Errorf("TopAuthors = %+v, want Author A (2) then Author B", snapshot.TopAuthors)
+	}
+	if snapshot.CompletionsByDay["2026-03-14"] != 2 {
+		t.Errorf("CompletionsByDay = %+v, want 2026-03-14:2", snapshot.CompletionsByDay)
+	}
+	if snapshot.DBSizeBytes <= 0 {
+		t.Errorf("DBSizeBytes = %d, want > 0", snapshot.DBSizeBytes)
+	}
+
+	snapshot, err = store.LibrarySnapshot(ctx, 2, completedAt.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("LibrarySnapshot failed: %v", err)
+	}
+	if len(snapshot.CompletionsByDay) != 0 {
+		t.Errorf("CompletionsByDay = %+v, want none after since", snapshot.CompletionsByDay)
+	}
+}
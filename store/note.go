@@ -0,0 +1,258 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrNoteNotFound = errors.New("note not found")
+
+type NoteStore interface {
+	CreateNote(ctx context.Context, note *models.Note) error
+	GetNote(ctx context.Context, id string) (*models.Note, error)
+	// ListNotes returns a page of the notes matching query, see models.NoteQuery for the order. With a
+	// query.BookID the book has to exist, a book without notes is an empty list
+	ListNotes(ctx context.Context, query models.NoteQuery, limit, offset int) ([]*models.Note, error)
+	// UpdateNote writes type, body, page and location. A note stays with its book, note.BookID and
+	// note.CreatedAt are filled from the stored note
+	UpdateNote(ctx context.Context, note *models.Note) error
+	DeleteNote(ctx context.Context, id string) error
+	// ImportNotes adds notes made elsewhere in one transaction, keeping their created_at and updated_at when set.
+	// A note whose id exists already is left as it is, added is how many were new. Every book has to exist
+	ImportNotes(ctx context.Context, notes []*models.Note) (added int, err error)
+}
+
+type noteStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewNoteStore(db *DB) NoteStore {
+	return &noteStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const (
+	noteColumns = "id, book_id, type, body, page, location, created_at, updated_at"
+	selectNotes = "SELECT " + noteColumns + " FROM notes"
+	// NOTE: Pages first in reading order, notes without a page after them
+	noteBookOrder = " ORDER BY page = 0, page, created_at, id"
+	noteAllOrder  = " ORDER BY created_at DESC, id"
+)
+
+func scanNote(row rowScanner) (*models.Note, error) {
+	var note models.Note
+	if err := row.Scan(&note.ID, &note.BookID, &note.Type, &note.Body, &note.Page, &note.Location, &note.CreatedAt, &note.UpdatedAt); err != nil {
+		return nil, err
+	}
+	note.CreatedAt = note.CreatedAt.UTC()
+	note.UpdatedAt = note.UpdatedAt.UTC()
+	return &note, nil
+}
+
+const insertNote = `INSERT INTO notes (` + noteColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+func (s *noteStore) CreateNote(ctx context.Context, note *models.Note) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "noteStore.CreateNote", insertNote)
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		var exists int
+		if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT 1 FROM books WHERE id = ?"), note.BookID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrBookNotFound
+			}
+			return fmt.Errorf("get book: %w", err)
+		}
+
+		_, err := tx.ExecContext(ctx, s.dialect.bind(insertNote),
+			note.ID, note.BookID, note.Type, note.Body, note.Page, note.Location, now, now)
+		if err != nil {
+			return fmt.Errorf("insert note: %w", err)
+		}
+		note.CreatedAt, note.UpdatedAt = now, now
+		return nil
+	})
+}
+
+func (s *noteStore) GetNote(ctx context.Context, id string) (_ *models.Note, err error) {
+	query := selectNotes + " WHERE id = ?"
+	ctx, span := s.dialect.startSpan(ctx, "noteStore.GetNote", query)
+	defer func() { tracing.End(span, err) }()
+
+	note, err := scanNote(s.db.Read.QueryRowContext(ctx, s.dialect.bind(query), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrNoteNotFound
+		}
+		return nil, fmt.Errorf("get note: %w", err)
+	}
+	return note, nil
+}
+
+// selectMatchingNotes builds the query for ListNotes, the arguments are in the order of the placeholders
+func (s *noteStore) selectMatchingNotes(query models.NoteQuery, limit, offset int) (string, []any) {
+	var where []string
+	var args []any
+	if query.BookID != "" {
+		where = append(where, "book_id = ?")
+		args = append(args, query.BookID)
+	}
+	if query.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, query.Type)
+	}
+	if len(query.Terms) > 0 {
+		where = append(where, s.dialect.matchNote)
+		args = append(args, s.dialect.matchArg(query.Terms))
+	}
+
+	stmt := selectNotes
+	if len(where) > 0 {
+		stmt += " WHERE " + strings.Join(where, " AND ")
+	}
+	if query.BookID != "" {
+		stmt += noteBookOrder
+	} else {
+		stmt += noteAllOrder
+	}
+	return stmt + " LIMIT ? OFFSET ?", append(args, limit, offset)
+}
+
+func (s *noteStore) ListNotes(ctx context.Context, query models.NoteQuery, limit, offset int) (_ []*models.Note, err error) {
+	stmt, args := s.selectMatchingNotes(query, limit, offset)
+	ctx, span := s.dialect.startSpan(ctx, "noteStore.ListNotes", stmt)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: One read transaction so the book cant disappear between the check and the notes
+	tx, err := s.db.Read.BeginTx(ctx, snapshotTx)
+	if err != nil {
+		return nil, fmt.Errorf("begin list notes: %w", err)
+	}
+	defer tx.Rollback() // Read only so nothing to commit
+
+	if query.BookID != "" {
+		var id string
+		if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM books WHERE id = ?"), query.BookID).Scan(&id); err != nil {
+			if err == sql.ErrNoRows {
+				return nil, ErrBookNotFound
+			}
+			return nil, fmt.Errorf("get book: %w", err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, s.dialect.bind(stmt), args...)
+	if err != nil {
+		return nil, fmt.Errorf("query notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []*models.Note{}
+	for rows.Next() {
+		note, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return notes, nil
+}
+
+const updateNote = `
+        UPDATE notes SET type = ?, body = ?, page = ?, location = ?, updated_at = ?
+        WHERE id = ?
+        RETURNING book_id, created_at`
+
+func (s *noteStore) UpdateNote(ctx context.Context, note *models.Note) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "noteStore.UpdateNote", updateNote)
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	err = s.db.QueryRowContext(ctx, s.dialect.bind(updateNote),
+		note.Type, note.Body, note.Page, note.Location, now, note.ID).Scan(&note.BookID, &note.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNoteNotFound
+		}
+		return fmt.Errorf("update note: %w", err)
+	}
+	note.CreatedAt = note.CreatedAt.UTC()
+	note.UpdatedAt = now
+	return nil
+}
+
+const deleteNote = "DELETE FROM notes WHERE id = ?"
+
+func (s *noteStore) DeleteNote(ctx context.Context, id string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "noteStore.DeleteNote", deleteNote)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, s.dialect.bind(deleteNote), id)
+	if err != nil {
+		return fmt.Errorf("delete note: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrNoteNotFound
+	}
+	return nil
+}
+
+const importNote = insertNote + " ON CONFLICT (id) DO NOTHING"
+
+func (s *noteStore) ImportNotes(ctx context.Context, notes []*models.Note) (added int, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "noteStore.ImportNotes", importNote)
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	err = withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, s.dialect.bind(importNote))
+		if err != nil {
+			return fmt.Errorf("prepare import notes: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, note := range notes {
+			note.CreatedAt, note.UpdatedAt = importedTimes(note, now)
+			result, err := stmt.ExecContext(ctx, note.ID, note.BookID, note.Type, note.Body, note.Page, note.Location, note.CreatedAt, note.UpdatedAt)
+			if err != nil {
+				return fmt.Errorf("import note %s: %w", note.ID, err)
+			}
+			n, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("check rows affected: %w", err)
+			}
+			added += int(n)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// importedTimes are the timestamps an imported note is stored with, now for the ones it does not have
+func importedTimes(note *models.Note, now time.Time) (created, updated time.Time) {
+	created, updated = note.CreatedAt.UTC(), note.UpdatedAt.UTC()
+	if note.CreatedAt.IsZero() {
+		created = now
+	}
+	if note.UpdatedAt.IsZero() {
+		updated = created
+	}
+	return created, updated
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrReviewNotFound  = errors.New("review not found")
+	ErrBookNotComplete = errors.New("only complete or abandoned books can be rated")
+)
+
+// ReviewStore keeps one review of a book per user (see middleware.User). Every call is scoped to owner, the
+// review another user wrote is not found
+type ReviewStore interface {
+	// SetReview creates the review of a book or replaces it, the book has to be complete or abandoned (see
+	// ErrBookNotComplete). A replaced review keeps its created_at
+	SetReview(ctx context.Context, owner string, review *models.Review) error
+	GetReview(ctx context.Context, owner, bookID string) (*models.Review, error)
+	// ListReviews returns a page of the reviews of owner, the last written first
+	ListReviews(ctx context.Context, owner string, limit, offset int) ([]*models.Review, error)
+	DeleteReview(ctx context.Context, owner, bookID string) error
+}
+
+type reviewStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewReviewStore(db *DB) ReviewStore {
+	return &reviewStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const (
+	reviewColumns = "book_id, owner, rating, body, created_at, updated_at"
+	selectReviews = "SELECT " + reviewColumns + " FROM reviews"
+)
+
+func scanReview(row rowScanner) (*models.Review, error) {
+	var review models.Review
+	if err := row.Scan(&review.BookID, &review.Owner, &review.Rating, &review.Body, &review.CreatedAt, &review.UpdatedAt); err != nil {
+		return nil, err
+	}
+	review.CreatedAt = review.CreatedAt.UTC()
+	review.UpdatedAt = review.UpdatedAt.UTC()
+	return &review, nil
+}
+
+const upsertReview = `
+        INSERT INTO reviews (` + reviewColumns + `) VALUES (?, ?, ?, ?, ?, ?)
+        ON CONFLICT (book_id, owner) DO UPDATE SET rating = excluded.rating, body = excluded.body, updated_at = excluded.updated_at
+        RETURNING created_at`
+
+func (s *reviewStore) SetReview(ctx context.Context, owner string, review *models.Review) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "reviewStore.SetReview", upsertReview)
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		// NOTE: Locked so the book cant go back to reading between the check and the write
+		var status models.BookStatus
+		err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT status FROM books WHERE id = ?"+s.dialect.lockRow), review.BookID).Scan(&status)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrBookNotFound
+			}
+			return fmt.Errorf("get book: %w", err)
+		}
+		if !status.Finished() {
+			return ErrBookNotComplete
+		}
+
+		now := s.now().UTC()
+		err = tx.QueryRowContext(ctx, s.dialect.bind(upsertReview), review.BookID, owner, review.Rating, review.Body, now, now).Scan(&review.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("set review: %w", err)
+		}
+		review.Owner = owner
+		review.CreatedAt = review.CreatedAt.UTC()
+		review.UpdatedAt = now
+		return nil
+	})
+}
+
+func (s *reviewStore) GetReview(ctx context.Context, owner, bookID string) (_ *models.Review, err error) {
+	query := selectReviews + " WHERE book_id = ? AND owner = ?"
+	ctx, span := s.dialect.startSpan(ctx, "reviewStore.GetReview", query)
+	defer func() { tracing.End(span, err) }()
+
+	review, err := scanReview(s.db.Read.QueryRowContext(ctx, s.dialect.bind(query), bookID, owner))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrReviewNotFound
+		}
+		return nil, fmt.Errorf("get review: %w", err)
+	}
+	return review, nil
+}
+
+func (s *reviewStore) ListReviews(ctx context.Context, owner string, limit, offset int) (_ []*models.Review, err error) {
+	query := selectReviews + " WHERE owner = ? ORDER BY updated_at DESC, book_id LIMIT ? OFFSET ?"
+	ctx, span := s.dialect.startSpan(ctx, "reviewStore.ListReviews", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, s.dialect.bind(query), owner, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := []*models.Review{}
+	for rows.Next() {
+		review, err := scanReview(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return reviews, nil
+}
+
+const deleteReview = "DELETE FROM reviews WHERE book_id = ? AND owner = ?"
+
+func (s *reviewStore) DeleteReview(ctx context.Context, owner, bookID string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "reviewStore.DeleteReview", deleteReview)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, s.dialect.bind(deleteReview), bookID, owner)
+	if err != nil {
+		return fmt.Errorf("delete review: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrReviewNotFound
+	}
+	return nil
+}
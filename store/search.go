@@ -0,0 +1,153 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSearchNotFound = errors.New("saved search not found")
+)
+
+// SearchStore keeps the saved searches. Evaluating one is up to BookStore.ListBooks and CountMatching.
+// Every call is scoped to owner, the search of another user is not found
+type SearchStore interface {
+	CreateSearch(ctx context.Context, owner string, search *models.SavedSearch) error
+	GetSearch(ctx context.Context, owner, id string) (*models.SavedSearch, error)
+	ListSearches(ctx context.Context, owner string) ([]*models.SavedSearch, error)
+	UpdateSearch(ctx context.Context, owner string, search *models.SavedSearch) error
+	DeleteSearch(ctx context.Context, owner, id string) error
+}
+
+type searchStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewSearchStore(db *DB) SearchStore {
+	return &searchStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const searchColumns = "id, owner, name, filter, created_at"
+
+func scanSearch(row rowScanner) (*models.SavedSearch, error) {
+	var search models.SavedSearch
+	var filter string
+	if err := row.Scan(&search.ID, &search.Owner, &search.Name, &filter, &search.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(filter), &search.Filter); err != nil {
+		return nil, fmt.Errorf("decode filter of saved search %s: %w", search.ID, err)
+	}
+	search.CreatedAt = search.CreatedAt.UTC()
+	return &search, nil
+}
+
+const insertSearch = `INSERT INTO saved_searches (` + searchColumns + `) VALUES (?, ?, ?, ?, ?)`
+
+func (s *searchStore) CreateSearch(ctx context.Context, owner string, search *models.SavedSearch) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "searchStore.CreateSearch", insertSearch)
+	defer func() { tracing.End(span, err) }()
+
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		return fmt.Errorf("encode filter: %w", err)
+	}
+	search.Owner = owner
+	search.CreatedAt = s.now().UTC()
+	if _, err := s.db.ExecContext(ctx, s.dialect.bind(insertSearch), search.ID, owner, search.Name, string(filter), search.CreatedAt); err != nil {
+		return fmt.Errorf("create saved search: %w", err)
+	}
+	return nil
+}
+
+const selectSearch = "SELECT " + searchColumns + " FROM saved_searches WHERE id = ? AND owner = ?"
+
+func (s *searchStore) GetSearch(ctx context.Context, owner, id string) (_ *models.SavedSearch, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "searchStore.GetSearch", selectSearch)
+	defer func() { tracing.End(span, err) }()
+
+	search, err := scanSearch(s.db.Read.QueryRowContext(ctx, s.dialect.bind(selectSearch), id, owner))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSearchNotFound
+		}
+		return nil, fmt.Errorf("get saved search: %w", err)
+	}
+	return search, nil
+}
+
+const selectSearches = "SELECT " + searchColumns + " FROM saved_searches WHERE owner = ? ORDER BY created_at, id"
+
+func (s *searchStore) ListSearches(ctx context.Context, owner string) (_ []*models.SavedSearch, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "searchStore.ListSearches", selectSearches)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, s.dialect.bind(selectSearches), owner)
+	if err != nil {
+		return nil, fmt.Errorf("query saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []*models.SavedSearch{}
+	for rows.Next() {
+		search, err := scanSearch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan saved search: %w", err)
+		}
+		searches = append(searches, search)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return searches, nil
+}
+
+const updateSearch = `UPDATE saved_searches SET name = ?, filter = ? WHERE id = ? AND owner = ? RETURNING created_at`
+
+func (s *searchStore) UpdateSearch(ctx context.Context, owner string, search *models.SavedSearch) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "searchStore.UpdateSearch", updateSearch)
+	defer func() { tracing.End(span, err) }()
+
+	filter, err := json.Marshal(search.Filter)
+	if err != nil {
+		return fmt.Errorf("encode filter: %w", err)
+	}
+	err = s.db.QueryRowContext(ctx, s.dialect.bind(updateSearch), search.Name, string(filter), search.ID, owner).Scan(&search.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrSearchNotFound
+		}
+		return fmt.Errorf("update saved search: %w", err)
+	}
+	search.Owner = owner
+	search.CreatedAt = search.CreatedAt.UTC()
+	return nil
+}
+
+const deleteSearch = "DELETE FROM saved_searches WHERE id = ? AND owner = ?"
+
+func (s *searchStore) DeleteSearch(ctx context.Context, owner, id string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "searchStore.DeleteSearch", deleteSearch)
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, s.dialect.bind(deleteSearch), id, owner)
+	if err != nil {
+		return fmt.Errorf("delete saved search: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSearchNotFound
+	}
+	return nil
+}
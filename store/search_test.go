@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"book-tracker/models"
+
+	"github.com/google/uuid"
+)
+
+func TestSearchStore(t *testing.T) {
+	db, cleanup := setupDB(t)
+	defer cleanup()
+
+	store := NewSearchStore(db)
+	ctx := context.Background()
+	const owner = "alice"
+
+	orwell := &models.SavedSearch{ID: uuid.NewString(), Name: "Orwell classics", Filter: models.BookFilter{
+		Status: models.BookUnread, Author: "George Orwell", Tags: []string{"classics"}, Sort: "-pages",
+	}}
+	recent := &models.SavedSearch{ID: uuid.NewString(), Name: "Read this spring", Filter: models.BookFilter{
+		CompletedFrom: "2026-03-01", CompletedTo: "2026-05-31", Sort: "-completed_at",
+	}}
+
+	t.Run("CreateAndGetSearch", func(t *testing.T) {
+		for _, s := range []*models.SavedSearch{orwell, recent} {
+			if err := store.CreateSearch(ctx, owner, s); err != nil {
+				t.Fatalf("CreateSearch failed: %v", err)
+			}
+		}
+		got, err := store.GetSearch(ctx, owner, orwell.ID)
+		if err != nil {
+			t.Fatalf("GetSearch failed: %v", err)
+		}
+		// The filter goes through JSON, it has to come back exactly as it was
+		if got.Name != orwell.Name || got.Owner != owner || !reflect.DeepEqual(got.Filter, orwell.Filter) || !got.CreatedAt.Equal(orwell.CreatedAt) {
+			t.Errorf("GetSearch = %+v, want %+v", got, orwell)
+		}
+
+		_, err = store.GetSearch(ctx, owner, "nonexistent")
+		if !errors.Is(err, ErrSearchNotFound) {
+			t.Errorf("GetSearch error = %v, want %v", err, ErrSearchNotFound)
+		}
+	})
+
+	t.Run("ListSearches", func(t *testing.T) {
+		all, err := store.ListSearches(ctx, owner)
+		if err != nil {
+			t.Fatalf("ListSearches failed: %v", err)
+		}
+		if len(all) != 2 || all[0].ID != orwell.ID || all[1].Filter.CompletedFrom != "2026-03-01" {
+			t.Errorf("ListSearches = %+v, want both searches in creation order", all)
+		}
+	})
+
+	t.Run("OtherOwner", func(t *testing.T) {
+		const other = "bob"
+		if all, err := store.ListSearches(ctx, other); err != nil || len(all) != 0 {
+			t.Errorf("ListSearches(%s) = %+v (%v), want none", other, all, err)
+		}
+		if _, err := store.GetSearch(ctx, other, orwell.ID); !errors.Is(err, ErrSearchNotFound) {
+			t.Errorf("GetSearch error = %v, want %v", err, ErrSearchNotFound)
+		}
+		if err := store.UpdateSearch(ctx, other, &models.SavedSearch{ID: orwell.ID, Name: "Taken over"}); !errors.Is(err, ErrSearchNotFound) {
+			t.Errorf("UpdateSearch error = %v, want %v", err, ErrSearchNotFound)
+		}
+		if err := store.DeleteSearch(ctx, other, orwell.ID); !errors.Is(err, ErrSearchNotFound) {
+			t.Errorf("DeleteSearch error = %v, want %v", err, ErrSearchNotFound)
+		}
+
+		// NOTE: Same id space, the search of bob does not show up for alice either
+		mine := &models.SavedSearch{ID: uuid.NewString(), Name: "Mine", Filter: models.BookFilter{Status: models.BookReading}}
+		if err := store.CreateSearch(ctx, other, mine); err != nil {
+			t.Fatalf("CreateSearch failed: %v", err)
+		}
+		if got, err := store.GetSearch(ctx, owner, orwell.ID); err != nil || got.Name != orwell.Name {
+			t.Errorf("GetSearch = %+v (%v), want the search untouched", got, err)
+		}
+		if all, err := store.ListSearches(ctx, owner); err != nil || len(all) != 2 {
+			t.Errorf("ListSearches(%s) = %+v (%v), want only the 2 of %s", owner, all, err, owner)
+		}
+	})
+
+	t.Run("UpdateSearch", func(t *testing.T) {
+		update := &models.SavedSearch{ID: recent.ID, Name: "Read this summer", Filter: models.BookFilter{CompletedFrom: "2026-06-01"}}
+		if err := store.UpdateSearch(ctx, owner, update); err != nil {
+			t.Fatalf("UpdateSearch failed: %v", err)
+		}
+		if !update.CreatedAt.Equal(recent.CreatedAt) {
+			t.Errorf("UpdateSearch created_at = %v, want %v", update.CreatedAt, recent.CreatedAt)
+		}
+		got, err := store.GetSearch(ctx, owner, recent.ID)
+		if err != nil || got.Name != "Read this summer" || got.Filter.CompletedTo != "" || got.Filter.Sort != "" {
+			t.Errorf("GetSearch after update = %+v (%v), want the filter replaced", got, err)
+		}
+
+		err = store.UpdateSearch(ctx, owner, &models.SavedSearch{ID: uuid.NewString(), Name: "Missing"})
+		if !errors.Is(err, ErrSearchNotFound) {
+			t.Errorf("UpdateSearch error = %v, want %v", err, ErrSearchNotFound)
+		}
+	})
+
+	t.Run("DeleteSearch", func(t *testing.T) {
+		if err := store.DeleteSearch(ctx, owner, orwell.ID); err != nil {
+			t.Fatalf("DeleteSearch failed: %v", err)
+		}
+		if err := store.DeleteSearch(ctx, owner, orwell.ID); !errors.Is(err, ErrSearchNotFound) {
+			t.Errorf("DeleteSearch twice error = %v, want %v", err, ErrSearchNotFound)
+		}
+	})
+}
@@ -0,0 +1,310 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSeriesNotFound    = errors.New("series not found")
+	ErrBookInOtherSeries = errors.New("book is already in another series")
+	ErrBookNotInSeries   = errors.New("book is not in the series")
+)
+
+type SeriesStore interface {
+	CreateSeries(ctx context.Context, series *models.Series) error
+	GetSeries(ctx context.Context, id string) (*models.Series, error)
+	ListSeries(ctx context.Context) ([]*models.Series, error)
+	UpdateSeries(ctx context.Context, series *models.Series) error
+	DeleteSeries(ctx context.Context, id string) error
+
+	// SetVolume puts a book into the series at position, or moves it there when it already is in it
+	SetVolume(ctx context.Context, seriesID, bookID string, position float64) error
+	RemoveVolume(ctx context.Context, seriesID, bookID string) error
+	// ListVolumes returns every book of the series by position
+	ListVolumes(ctx context.Context, seriesID string) ([]*models.Book, error)
+}
+
+type seriesStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewSeriesStore(db *DB) SeriesStore {
+	return &seriesStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const (
+	seriesColumns = "id, name, description, created_at"
+	selectSeries  = `
+        SELECT ` + seriesColumns + `, (SELECT COUNT(*) FROM series_books WHERE series_books.series_id = series.id)
+        FROM series`
+)
+
+func scanSeries(row rowScanner) (*models.Series, error) {
+	var series models.Series
+	if err := row.Scan(&series.ID, &series.Name, &series.Description, &series.CreatedAt, &series.Books); err != nil {
+		return nil, err
+	}
+	series.CreatedAt = series.CreatedAt.UTC()
+	return &series, nil
+}
+
+// loadSeries fills in the series of books with a single query, books outside a series are left alone
+func loadSeries(ctx context.Context, d dialect, q queryer, books []*models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Book, len(books))
+	args := make([]any, len(books))
+	for i, book := range books {
+		byID[book.ID] = book
+		args[i] = book.ID
+	}
+
+	query := `
+		SELECT series_books.book_id, series.id, series.name, series_books.position
+		FROM series_books JOIN series ON series.id = series_books.series_id
+		WHERE series_books.book_id IN (` + placeholders(len(books)) + `)`
+	rows, err := q.QueryContext(ctx, d.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("query series: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bookID string
+		var series models.BookSeries
+		if err := rows.Scan(&bookID, &series.ID, &series.Name, &series.Position); err != nil {
+			return fmt.Errorf("scan series: %w", err)
+		}
+		byID[bookID].Series = &series
+	}
+	return rows.Err()
+}
+
+const insertSeries = `INSERT INTO series (` + seriesColumns + `) VALUES (?, ?, ?, ?)`
+
+func (s *seriesStore) CreateSeries(ctx context.Context, series *models.Series) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.CreateSeries", insertSeries)
+	defer func() { tracing.End(span, err) }()
+
+	series.CreatedAt = s.now().UTC()
+	series.Books = 0
+	if _, err := s.db.ExecContext(ctx, s.dialect.bind(insertSeries), series.ID, series.Name, series.Description, series.CreatedAt); err != nil {
+		return fmt.Errorf("create series: %w", err)
+	}
+	return nil
+}
+
+func (s *seriesStore) GetSeries(ctx context.Context, id string) (_ *models.Series, err error) {
+	query := selectSeries + " WHERE id = ?"
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.GetSeries", query)
+	defer func() { tracing.End(span, err) }()
+
+	series, err := scanSeries(s.db.Read.QueryRowContext(ctx, s.dialect.bind(query), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, fmt.Errorf("get series: %w", err)
+	}
+	return series, nil
+}
+
+func (s *seriesStore) ListSeries(ctx context.Context) (_ []*models.Series, err error) {
+	query := selectSeries + " ORDER BY " + fmt.Sprintf(s.dialect.byteOrder, "name") + ", id"
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.ListSeries", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query series: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*models.Series{}
+	for rows.Next() {
+		series, err := scanSeries(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan series: %w", err)
+		}
+		list = append(list, series)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return list, nil
+}
+
+const updateSeries = `UPDATE series SET name = ?, description = ? WHERE id = ? RETURNING created_at`
+
+func (s *seriesStore) UpdateSeries(ctx context.Context, series *models.Series) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.UpdateSeries", updateSeries)
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, s.dialect.bind(updateSeries), series.Name, series.Description, series.ID).Scan(&series.CreatedAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSeriesNotFound
+			}
+			return fmt.Errorf("update series: %w", err)
+		}
+		series.CreatedAt = series.CreatedAt.UTC()
+		err = tx.QueryRowContext(ctx, s.dialect.bind("SELECT COUNT(*) FROM series_books WHERE series_id = ?"), series.ID).Scan(&series.Books)
+		if err != nil {
+			return fmt.Errorf("count series books: %w", err)
+		}
+		return nil
+	})
+}
+
+const deleteSeries = "DELETE FROM series WHERE id = ?"
+
+func (s *seriesStore) DeleteSeries(ctx context.Context, id string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.DeleteSeries", deleteSeries)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: The books stay and are simply no longer in a series, series_books goes with the cascade
+	result, err := s.db.ExecContext(ctx, s.dialect.bind(deleteSeries), id)
+	if err != nil {
+		return fmt.Errorf("delete series: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrSeriesNotFound
+	}
+	return nil
+}
+
+// NOTE: The WHERE on the conflict makes a book that is in another series a no-op instead of a silent move,
+// no affected rows tells SetVolume about it
+const upsertVolume = `
+        INSERT INTO series_books (book_id, series_id, position) VALUES (?, ?, ?)
+        ON CONFLICT (book_id) DO UPDATE SET position = excluded.position
+        WHERE series_books.series_id = excluded.series_id`
+
+func (s *seriesStore) SetVolume(ctx context.Context, seriesID, bookID string, position float64) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.SetVolume", upsertVolume)
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		var id string
+		if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM series WHERE id = ?"), seriesID).Scan(&id); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSeriesNotFound
+			}
+			return fmt.Errorf("get series: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM books WHERE id = ?"), bookID).Scan(&id); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrBookNotFound
+			}
+			return fmt.Errorf("get book: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx, s.dialect.bind(upsertVolume), bookID, seriesID, position)
+		if err != nil {
+			return fmt.Errorf("set volume: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("check rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrBookInOtherSeries
+		}
+		return nil
+	})
+}
+
+const deleteVolume = "DELETE FROM series_books WHERE series_id = ? AND book_id = ?"
+
+func (s *seriesStore) RemoveVolume(ctx context.Context, seriesID, bookID string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.RemoveVolume", deleteVolume)
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		var id string
+		if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM series WHERE id = ?"), seriesID).Scan(&id); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrSeriesNotFound
+			}
+			return fmt.Errorf("get series: %w", err)
+		}
+		result, err := tx.ExecContext(ctx, s.dialect.bind(deleteVolume), seriesID, bookID)
+		if err != nil {
+			return fmt.Errorf("remove volume: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("check rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return ErrBookNotInSeries
+		}
+		return nil
+	})
+}
+
+// NOTE: Volumes at the same position (an omnibus and its parts) are ordered by title like the book list
+func (s *seriesStore) selectVolumes() string {
+	return `
+        SELECT ` + bookColumns + `
+        FROM series_books JOIN books ON books.id = series_books.book_id
+        WHERE series_books.series_id = ?
+        ORDER BY series_books.position, ` + fmt.Sprintf(s.dialect.byteOrder, "books.title") + `, books.id`
+}
+
+func (s *seriesStore) ListVolumes(ctx context.Context, seriesID string) (_ []*models.Book, err error) {
+	query := s.selectVolumes()
+	ctx, span := s.dialect.startSpan(ctx, "seriesStore.ListVolumes", query)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: One read transaction so the series cant disappear between the check and the books
+	tx, err := s.db.Read.BeginTx(ctx, snapshotTx)
+	if err != nil {
+		return nil, fmt.Errorf("begin list volumes: %w", err)
+	}
+	defer tx.Rollback() // Read only so nothing to commit
+
+	var id string
+	if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM series WHERE id = ?"), seriesID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, fmt.Errorf("get series: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, s.dialect.bind(query), seriesID)
+	if err != nil {
+		return nil, fmt.Errorf("query volumes: %w", err)
+	}
+	defer rows.Close()
+
+	books := []*models.Book{}
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan book: %w", err)
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close() // NOTE: Before loadRelated, Postgres cant run a second query while the rows are open
+	if err := loadRelated(ctx, s.dialect, tx, books); err != nil {
+		return nil, fmt.Errorf("list volumes: %w", err)
+	}
+	return books, nil
+}
@@ -0,0 +1,342 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+var (
+	ErrShelfNotFound  = errors.New("shelf not found")
+	ErrBookOnShelf    = errors.New("book is already on the shelf")
+	ErrBookNotOnShelf = errors.New("book is not on the shelf")
+)
+
+type ShelfStore interface {
+	CreateShelf(ctx context.Context, shelf *models.Shelf) error
+	GetShelf(ctx context.Context, id string) (*models.Shelf, error)
+	ListShelves(ctx context.Context) ([]*models.Shelf, error)
+	UpdateShelf(ctx context.Context, shelf *models.Shelf) error
+	DeleteShelf(ctx context.Context, id string) error
+
+	// AddBook puts a book on the shelf at position, or at the bottom when position is nil.
+	// The books from that position on move down by one
+	AddBook(ctx context.Context, shelfID, bookID string, position *int) error
+	// MoveBook moves a book that is on the shelf to position, the books in between shift up or down
+	MoveBook(ctx context.Context, shelfID, bookID string, position int) error
+	RemoveBook(ctx context.Context, shelfID, bookID string) error
+	// ListShelfBooks returns the books on the shelf from top to bottom
+	ListShelfBooks(ctx context.Context, shelfID string, limit, offset int) ([]*models.Book, error)
+}
+
+type shelfStore struct {
+	db      *DB
+	dialect dialect
+	now     func() time.Time
+}
+
+func NewShelfStore(db *DB) ShelfStore {
+	return &shelfStore{db: db, dialect: sqliteDialect, now: time.Now}
+}
+
+const (
+	shelfColumns = "id, name, description, created_at"
+	// NOTE: The count is a correlated subquery so a shelf without books still shows up with 0
+	selectShelves = `
+        SELECT ` + shelfColumns + `, (SELECT COUNT(*) FROM shelf_books WHERE shelf_books.shelf_id = shelves.id)
+        FROM shelves`
+)
+
+func scanShelf(row rowScanner) (*models.Shelf, error) {
+	var shelf models.Shelf
+	if err := row.Scan(&shelf.ID, &shelf.Name, &shelf.Description, &shelf.CreatedAt, &shelf.Books); err != nil {
+		return nil, err
+	}
+	shelf.CreatedAt = shelf.CreatedAt.UTC()
+	return &shelf, nil
+}
+
+const insertShelf = `INSERT INTO shelves (` + shelfColumns + `) VALUES (?, ?, ?, ?)`
+
+func (s *shelfStore) CreateShelf(ctx context.Context, shelf *models.Shelf) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.CreateShelf", insertShelf)
+	defer func() { tracing.End(span, err) }()
+
+	shelf.CreatedAt = s.now().UTC()
+	shelf.Books = 0
+	if _, err := s.db.ExecContext(ctx, s.dialect.bind(insertShelf), shelf.ID, shelf.Name, shelf.Description, shelf.CreatedAt); err != nil {
+		return fmt.Errorf("create shelf: %w", err)
+	}
+	return nil
+}
+
+func (s *shelfStore) GetShelf(ctx context.Context, id string) (_ *models.Shelf, err error) {
+	query := selectShelves + " WHERE id = ?"
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.GetShelf", query)
+	defer func() { tracing.End(span, err) }()
+
+	shelf, err := scanShelf(s.db.Read.QueryRowContext(ctx, s.dialect.bind(query), id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrShelfNotFound
+		}
+		return nil, fmt.Errorf("get shelf: %w", err)
+	}
+	return shelf, nil
+}
+
+func (s *shelfStore) ListShelves(ctx context.Context) (_ []*models.Shelf, err error) {
+	query := selectShelves + " ORDER BY created_at, id"
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.ListShelves", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query shelves: %w", err)
+	}
+	defer rows.Close()
+
+	shelves := []*models.Shelf{}
+	for rows.Next() {
+		shelf, err := scanShelf(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan shelf: %w", err)
+		}
+		shelves = append(shelves, shelf)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return shelves, nil
+}
+
+const updateShelf = `UPDATE shelves SET name = ?, description = ? WHERE id = ? RETURNING created_at`
+
+func (s *shelfStore) UpdateShelf(ctx context.Context, shelf *models.Shelf) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.UpdateShelf", updateShelf)
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, s.dialect.bind(updateShelf), shelf.Name, shelf.Description, shelf.ID).Scan(&shelf.CreatedAt)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrShelfNotFound
+			}
+			return fmt.Errorf("update shelf: %w", err)
+		}
+		shelf.CreatedAt = shelf.CreatedAt.UTC()
+		err = tx.QueryRowContext(ctx, s.dialect.bind("SELECT COUNT(*) FROM shelf_books WHERE shelf_id = ?"), shelf.ID).Scan(&shelf.Books)
+		if err != nil {
+			return fmt.Errorf("count shelf books: %w", err)
+		}
+		return nil
+	})
+}
+
+const deleteShelf = "DELETE FROM shelves WHERE id = ?"
+
+func (s *shelfStore) DeleteShelf(ctx context.Context, id string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.DeleteShelf", deleteShelf)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: The books themselves stay, only their places on the shelf go with the cascade
+	result, err := s.db.ExecContext(ctx, s.dialect.bind(deleteShelf), id)
+	if err != nil {
+		return fmt.Errorf("delete shelf: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrShelfNotFound
+	}
+	return nil
+}
+
+// shelfOrder is the order of a shelf as it is stored. positions can have gaps, see the addShelves migration
+type shelfOrder struct {
+	books     []string
+	positions map[string]int
+}
+
+// lockShelf locks the shelf for the rest of tx and returns its books from top to bottom. Every change to
+// the order goes through here first so two of them cant renumber the same shelf at the same time
+func (s *shelfStore) lockShelf(ctx context.Context, tx *sql.Tx, shelfID string) (*shelfOrder, error) {
+	var id string
+	err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM shelves WHERE id = ?"+s.dialect.lockRow), shelfID).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrShelfNotFound
+		}
+		return nil, fmt.Errorf("get shelf: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, s.dialect.bind("SELECT book_id, position FROM shelf_books WHERE shelf_id = ? ORDER BY position, added_at, book_id"), shelfID)
+	if err != nil {
+		return nil, fmt.Errorf("query shelf order: %w", err)
+	}
+	defer rows.Close()
+
+	order := &shelfOrder{positions: map[string]int{}}
+	for rows.Next() {
+		var bookID string
+		var position int
+		if err := rows.Scan(&bookID, &position); err != nil {
+			return nil, fmt.Errorf("scan shelf order: %w", err)
+		}
+		order.books = append(order.books, bookID)
+		order.positions[bookID] = position
+	}
+	return order, rows.Err()
+}
+
+// renumber stores books as the new order of the shelf, numbered from 0. Only the books whose position
+// actually changed are written, moving a book one place down touches two rows
+func (s *shelfStore) renumber(ctx context.Context, tx *sql.Tx, shelfID string, order *shelfOrder, books []string) error {
+	for i, bookID := range books {
+		if position, ok := order.positions[bookID]; ok && position == i {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, s.dialect.bind("UPDATE shelf_books SET position = ? WHERE shelf_id = ? AND book_id = ?"), i, shelfID, bookID)
+		if err != nil {
+			return fmt.Errorf("renumber shelf: %w", err)
+		}
+	}
+	return nil
+}
+
+// placeAt inserts bookID into books at position, past the end (or without a position) it goes last
+func placeAt(books []string, bookID string, position *int) []string {
+	if position == nil || *position > len(books) {
+		return append(books, bookID)
+	}
+	return slices.Insert(books, *position, bookID)
+}
+
+func (s *shelfStore) AddBook(ctx context.Context, shelfID, bookID string, position *int) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.AddBook", "")
+	defer func() { tracing.End(span, err) }()
+
+	now := s.now().UTC()
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		order, err := s.lockShelf(ctx, tx, shelfID)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(order.books, bookID) {
+			return ErrBookOnShelf
+		}
+		var exists int
+		if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT 1 FROM books WHERE id = ?"), bookID).Scan(&exists); err != nil {
+			if err == sql.ErrNoRows {
+				return ErrBookNotFound
+			}
+			return fmt.Errorf("get book: %w", err)
+		}
+
+		// NOTE: Goes in at the bottom first, renumber then moves it (and the ones below) into place
+		bottom := len(order.books)
+		_, err = tx.ExecContext(ctx, s.dialect.bind("INSERT INTO shelf_books (shelf_id, book_id, position, added_at) VALUES (?, ?, ?, ?)"),
+			shelfID, bookID, bottom, now)
+		if err != nil {
+			return fmt.Errorf("add book to shelf: %w", err)
+		}
+		order.positions[bookID] = bottom
+		return s.renumber(ctx, tx, shelfID, order, placeAt(slices.Clone(order.books), bookID, position))
+	})
+}
+
+func (s *shelfStore) MoveBook(ctx context.Context, shelfID, bookID string, position int) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.MoveBook", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		order, err := s.lockShelf(ctx, tx, shelfID)
+		if err != nil {
+			return err
+		}
+		i := slices.Index(order.books, bookID)
+		if i < 0 {
+			return ErrBookNotOnShelf
+		}
+		books := slices.Delete(slices.Clone(order.books), i, i+1)
+		return s.renumber(ctx, tx, shelfID, order, placeAt(books, bookID, &position))
+	})
+}
+
+func (s *shelfStore) RemoveBook(ctx context.Context, shelfID, bookID string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.RemoveBook", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		order, err := s.lockShelf(ctx, tx, shelfID)
+		if err != nil {
+			return err
+		}
+		i := slices.Index(order.books, bookID)
+		if i < 0 {
+			return ErrBookNotOnShelf
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.bind("DELETE FROM shelf_books WHERE shelf_id = ? AND book_id = ?"), shelfID, bookID); err != nil {
+			return fmt.Errorf("remove book from shelf: %w", err)
+		}
+		// NOTE: Closes the gap right away, it would be harmless but this keeps the positions readable
+		return s.renumber(ctx, tx, shelfID, order, slices.Delete(slices.Clone(order.books), i, i+1))
+	})
+}
+
+const selectShelfBooks = `
+        SELECT ` + bookColumns + `
+        FROM shelf_books JOIN books ON books.id = shelf_books.book_id
+        WHERE shelf_books.shelf_id = ?
+        ORDER BY shelf_books.position, shelf_books.added_at, shelf_books.book_id
+        LIMIT ? OFFSET ?`
+
+func (s *shelfStore) ListShelfBooks(ctx context.Context, shelfID string, limit, offset int) (_ []*models.Book, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "shelfStore.ListShelfBooks", selectShelfBooks)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE: One read transaction so the shelf cant disappear between the check and the books
+	tx, err := s.db.Read.BeginTx(ctx, snapshotTx)
+	if err != nil {
+		return nil, fmt.Errorf("begin list shelf books: %w", err)
+	}
+	defer tx.Rollback() // Read only so nothing to commit
+
+	var id string
+	if err := tx.QueryRowContext(ctx, s.dialect.bind("SELECT id FROM shelves WHERE id = ?"), shelfID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrShelfNotFound
+		}
+		return nil, fmt.Errorf("get shelf: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, s.dialect.bind(selectShelfBooks), shelfID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("query shelf books: %w", err)
+	}
+	defer rows.Close()
+
+	books := []*models.Book{}
+	for rows.Next() {
+		book, err := scanBook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan book: %w", err)
+		}
+		books = append(books, book)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close() // NOTE: Before loadRelated, Postgres cant run a second query while the rows are open
+	if err := loadRelated(ctx, s.dialect, tx, books); err != nil {
+		return nil, fmt.Errorf("list shelf books: %w", err)
+	}
+	return books, nil
+}
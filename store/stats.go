@@ -1,69 +1,332 @@
 package store
 
 import (
+	"book-tracker/models"
+	"book-tracker/tracing"
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 type StatsStore interface {
-	GetStats(ctx context.Context) (totalRead, readingProgress int, popularAuthor string, err error)
+	// BookStats is the part of models.Stats that comes from the books: the totals, the authors ranked maxRank
+	// or better and the ratings, all read from one snapshot so they always agree with each other
+	BookStats(ctx context.Context, maxRank int) (models.Stats, error)
+	GetStats(ctx context.Context) (totalRead, readingProgress int, err error)
+	// AuthorStats returns a page of the author ranking and the total number of authors
+	AuthorStats(ctx context.Context, limit, offset int) (_ []models.AuthorStats, total int, err error)
+	// TopAuthors returns every author ranked maxRank or better, so ties at the cut-off are all included
+	TopAuthors(ctx context.Context, maxRank int) ([]models.AuthorStats, error)
+	// Completions counts the books, and their pages, completed in [from, to)
+	Completions(ctx context.Context, from, to time.Time) (books, pages int, err error)
+	// Timeline returns one bucket per week/month/year in the query range, including empty ones
+	Timeline(ctx context.Context, q models.TimelineQuery) ([]models.TimelineBucket, error)
+	// EventTimes returns when every recorded book event before the given time happened, oldest first
+	EventTimes(ctx context.Context, before time.Time) ([]time.Time, error)
+	// RatingCounts returns the number of reviewed books per rating, see models.NewRatingStats
+	RatingCounts(ctx context.Context) (map[float64]int, error)
 }
 
 type statsStore struct {
-	db *sql.DB
+	db      *DB
+	dialect dialect
 }
 
-func NewStatsStore(db *sql.DB) StatsStore {
-	return &statsStore{db: db}
+func NewStatsStore(db *DB) StatsStore {
+	return &statsStore{db: db, dialect: sqliteDialect}
 }
 
-func (s *statsStore) GetStats(ctx context.Context) (totalRead, readingProgress int, popularAuthor string, err error) {
-	err = s.db.QueryRowContext(ctx, `
-	SELECT COUNT(*)
+// NOTE: One statement is one consistent snapshot in SQLite, so the numbers always agree with each other
+// even when books are written at the same time. Used to be four separate queries
+const selectStats = `
+	SELECT COUNT(CASE WHEN status = 'complete' THEN 1 END),
+		COUNT(CASE WHEN status = 'abandoned' THEN 1 END),
+		COUNT(CASE WHEN status = 'reading' THEN 1 END),
+		COUNT(*)
 	FROM books
-	WHERE status = 'complete'
-	`).Scan(&totalRead)
+	`
 
+// statsQueryer is what the stats queries need, both *sql.DB and *sql.Tx have it
+type statsQueryer interface {
+	queryer
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// NOTE: The statements are fine on their own, but between them a book can be written. One read transaction
+// gives them all the same snapshot
+func (s *statsStore) BookStats(ctx context.Context, maxRank int) (_ models.Stats, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.BookStats", selectStats)
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := s.db.Read.BeginTx(ctx, snapshotTx)
 	if err != nil {
-		return 0, 0, "", fmt.Errorf("query total completed: %w", err)
+		return models.Stats{}, fmt.Errorf("begin stats: %w", err)
 	}
+	defer tx.Rollback() // Read only so nothing to commit
 
-	var totalBooks float64
-
-	err = s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM books").Scan(&totalBooks)
+	stats, err := s.queryStats(ctx, tx)
 	if err != nil {
-		return 0, 0, "", fmt.Errorf("query total books: %w", err)
+		return models.Stats{}, err
+	}
+	if stats.TopAuthors, _, err = s.queryAuthorStats(ctx, tx, s.topAuthorsQuery(), maxRank); err != nil {
+		return models.Stats{}, err
 	}
+	counts, err := s.queryRatingCounts(ctx, tx)
+	if err != nil {
+		return models.Stats{}, err
+	}
+	stats.Ratings = models.NewRatingStats(counts)
+	return stats, nil
+}
+
+func (s *statsStore) GetStats(ctx context.Context) (totalRead, readingProgress int, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.GetStats", selectStats)
+	defer func() { tracing.End(span, err) }()
 
-	var readingBooks float64
-	err = s.db.QueryRowContext(ctx, `
-		SELECT COUNT(*)
-		FROM books
-		WHERE status = 'reading'
-	`).Scan(&readingBooks)
+	stats, err := s.queryStats(ctx, s.db.Read)
+	return stats.TotalRead, stats.ReadingProgress, err
+}
+
+// queryStats fills in the totals of the stats, the rest is up to the caller
+func (s *statsStore) queryStats(ctx context.Context, q statsQueryer) (models.Stats, error) {
+	var stats models.Stats
+	var readingBooks, totalBooks int
+	err := q.QueryRowContext(ctx, s.dialect.bind(selectStats)).Scan(&stats.TotalRead, &stats.TotalAbandoned, &readingBooks, &totalBooks)
 	if err != nil {
-		return 0, 0, "", fmt.Errorf("query reading books: %w", err)
+		return models.Stats{}, fmt.Errorf("query stats: %w", err)
 	}
 
 	if totalBooks > 0 {
-		readingProgress = int((readingBooks / totalBooks) * 100)
+		stats.ReadingProgress = readingBooks * 100 / totalBooks
+	}
+	return stats, nil
+}
+
+// NOTE: RANK() gives authors with the same number of books the same rank (1, 2, 2, 4), the name is only
+// there to keep the order of tied authors stable between pages. A co-written book counts for each of its
+// authors, translators and the like dont count at all
+const selectAuthorStats = `
+	WITH ranked AS (
+		SELECT authors.name AS author,
+			RANK() OVER (ORDER BY COUNT(*) DESC) AS rank,
+			COUNT(*) AS total,
+			COUNT(CASE WHEN books.status = 'unread' THEN 1 END) AS to_read,
+			COUNT(CASE WHEN books.status = 'reading' THEN 1 END) AS reading,
+			COUNT(CASE WHEN books.status = 'complete' THEN 1 END) AS complete,
+			COUNT(CASE WHEN books.status = 'abandoned' THEN 1 END) AS abandoned
+		FROM book_authors
+		JOIN authors ON authors.id = book_authors.author_id
+		JOIN books ON books.id = book_authors.book_id
+		WHERE book_authors.role = 'author'
+		GROUP BY authors.id, authors.name
+	)
+	SELECT author, rank, total, to_read, reading, complete, abandoned, COUNT(*) OVER ()
+	FROM ranked
+	%[1]s
+	ORDER BY rank, %[2]s
+	`
+
+func (s *statsStore) AuthorStats(ctx context.Context, limit, offset int) (_ []models.AuthorStats, total int, err error) {
+	query := s.dialect.bind(fmt.Sprintf(selectAuthorStats, "", fmt.Sprintf(s.dialect.byteOrder, "author")) + "LIMIT ? OFFSET ?")
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.AuthorStats", query)
+	defer func() { tracing.End(span, err) }()
+
+	authors, total, err := s.queryAuthorStats(ctx, s.db.Read, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
 	}
+	// NOTE: The window count is only on the rows, an offset past the end needs its own count
+	if len(authors) == 0 && offset > 0 {
+		if err := s.db.Read.QueryRowContext(ctx, "SELECT COUNT(DISTINCT author_id) FROM book_authors WHERE role = 'author'").Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("count authors: %w", err)
+		}
+	}
+	return authors, total, nil
+}
+
+func (s *statsStore) topAuthorsQuery() string {
+	return s.dialect.bind(fmt.Sprintf(selectAuthorStats, "WHERE rank <= ?", fmt.Sprintf(s.dialect.byteOrder, "author")))
+}
+
+func (s *statsStore) TopAuthors(ctx context.Context, maxRank int) (_ []models.AuthorStats, err error) {
+	query := s.topAuthorsQuery()
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.TopAuthors", query)
+	defer func() { tracing.End(span, err) }()
 
-	// NOTE: added author ASC to ensure author name ordering when there are several authors that have same book count (debug from unit-tests)
-	err = s.db.QueryRowContext(ctx, `
-		SELECT author 
-		FROM books 
-		GROUP BY author 
-		ORDER BY COUNT(*) DESC, author ASC
-		LIMIT 1
-	`).Scan(&popularAuthor)
-	if err == sql.ErrNoRows {
-		popularAuthor = "N/A"
-	} else if err != nil {
-		return 0, 0, "", fmt.Errorf("query popular author: %w", err)
+	authors, _, err := s.queryAuthorStats(ctx, s.db.Read, query, maxRank)
+	return authors, err
+}
+
+func (s *statsStore) queryAuthorStats(ctx context.Context, q queryer, query string, args ...any) ([]models.AuthorStats, int, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("query author stats: %w", err)
 	}
+	defer rows.Close()
 
-	return totalRead, readingProgress, popularAuthor, nil
+	authors := []models.AuthorStats{}
+	total := 0
+	for rows.Next() {
+		var a models.AuthorStats
+		if err := rows.Scan(&a.Author, &a.Rank, &a.Total, &a.ToRead, &a.Reading, &a.Complete, &a.Abandoned, &total); err != nil {
+			return nil, 0, fmt.Errorf("scan author stats: %w", err)
+		}
+		if a.Total > 0 {
+			a.CompletionRate = float64(a.Complete) / float64(a.Total)
+		}
+		authors = append(authors, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("rows error: %w", err)
+	}
+	return authors, total, nil
+}
+
+const selectRatingCounts = `SELECT rating, COUNT(*) FROM reviews GROUP BY rating`
+
+func (s *statsStore) RatingCounts(ctx context.Context) (_ map[float64]int, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.RatingCounts", selectRatingCounts)
+	defer func() { tracing.End(span, err) }()
 
+	return s.queryRatingCounts(ctx, s.db.Read)
+}
+
+func (s *statsStore) queryRatingCounts(ctx context.Context, q queryer) (map[float64]int, error) {
+	rows, err := q.QueryContext(ctx, selectRatingCounts)
+	if err != nil {
+		return nil, fmt.Errorf("query rating counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := map[float64]int{}
+	for rows.Next() {
+		var rating float64
+		var books int
+		if err := rows.Scan(&rating, &books); err != nil {
+			return nil, fmt.Errorf("scan rating count: %w", err)
+		}
+		counts[rating] = books
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return counts, nil
+}
+
+const selectCompletions = `
+	SELECT COUNT(*), COALESCE(SUM(pages), 0)
+	FROM books
+	WHERE status = 'complete' AND completed_at >= ? AND completed_at < ?
+	`
+
+func (s *statsStore) Completions(ctx context.Context, from, to time.Time) (books, pages int, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.Completions", selectCompletions)
+	defer func() { tracing.End(span, err) }()
+
+	err = s.db.Read.QueryRowContext(ctx, s.dialect.bind(selectCompletions), from.UTC(), to.UTC()).Scan(&books, &pages)
+	if err != nil {
+		return 0, 0, fmt.Errorf("query completions: %w", err)
+	}
+	return books, pages, nil
+}
+
+// NOTE: SQLite date modifiers per granularity. The first one moves a date to the start of its bucket
+// ('weekday 0' jumps forward to Sunday so -6 days lands on the Monday), the second one steps to the next bucket.
+// Only values from this map end up in the query so building it with Sprintf is safe
+var bucketModifiers = map[models.Granularity][2]string{
+	models.GranularityWeek:  {"'weekday 0', '-6 days'", "'+7 days'"},
+	models.GranularityMonth: {"'start of month'", "'+1 month'"},
+	models.GranularityYear:  {"'start of year'", "'+1 year'"},
+}
+
+// NOTE: The recursive CTE generates every bucket in the range so empty ones come back as zeros
+// instead of having to be filled in afterwards. Only status changes count, not the page counts in between
+const selectTimeline = `
+	WITH RECURSIVE buckets(start) AS (
+		SELECT date(?, %[1]s)
+		UNION ALL
+		SELECT date(start, %[2]s) FROM buckets WHERE date(start, %[2]s) <= ?
+	),
+	events AS (
+		SELECT date(occurred_at, %[1]s) AS bucket, to_status, pages
+		FROM book_events
+		WHERE occurred_at >= ? AND occurred_at < ? AND (from_status IS NULL OR from_status <> to_status)
+	)
+	SELECT buckets.start,
+		COUNT(CASE WHEN events.to_status = 'reading' THEN 1 END),
+		COUNT(CASE WHEN events.to_status = 'complete' THEN 1 END),
+		COALESCE(SUM(CASE WHEN events.to_status = 'complete' THEN events.pages END), 0)
+	FROM buckets
+	LEFT JOIN events ON events.bucket = buckets.start
+	GROUP BY buckets.start
+	ORDER BY buckets.start
+	`
+
+func (s *statsStore) Timeline(ctx context.Context, q models.TimelineQuery) (_ []models.TimelineBucket, err error) {
+	modifiers, ok := bucketModifiers[q.Granularity]
+	if !ok {
+		return nil, models.ErrInvalidGranularity
+	}
+	query := fmt.Sprintf(selectTimeline, modifiers[0], modifiers[1])
+
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.Timeline", query)
+	defer func() { tracing.End(span, err) }()
+
+	from := q.From.UTC().Format(models.DateLayout)
+	to := q.To.UTC().Format(models.DateLayout)
+	end := q.To.UTC().AddDate(0, 0, 1) // To is inclusive so events up to the end of that day count
+	rows, err := s.db.Read.QueryContext(ctx, query, from, to, q.From.UTC(), end)
+	if err != nil {
+		return nil, fmt.Errorf("query timeline: %w", err)
+	}
+	defer rows.Close()
+	return scanTimeline(rows)
+}
+
+func scanTimeline(rows *sql.Rows) ([]models.TimelineBucket, error) {
+	buckets := []models.TimelineBucket{}
+	for rows.Next() {
+		var b models.TimelineBucket
+		if err := rows.Scan(&b.Start, &b.Started, &b.Finished, &b.PagesRead); err != nil {
+			return nil, fmt.Errorf("scan timeline bucket: %w", err)
+		}
+		buckets = append(buckets, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return buckets, nil
+}
+
+const selectEventTimes = `
+	SELECT occurred_at
+	FROM book_events
+	WHERE occurred_at < ?
+	ORDER BY occurred_at
+	`
+
+// NOTE: Grouping into days happens in Go as the day boundaries depend on the callers timezone
+func (s *statsStore) EventTimes(ctx context.Context, before time.Time) (_ []time.Time, err error) {
+	ctx, span := s.dialect.startSpan(ctx, "statsStore.EventTimes", selectEventTimes)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, s.dialect.bind(selectEventTimes), before.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("query event times: %w", err)
+	}
+	defer rows.Close()
+
+	times := []time.Time{}
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return nil, fmt.Errorf("scan event time: %w", err)
+		}
+		times = append(times, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	return times, nil
 }
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"testing"
+	"time"
 
 	"book-tracker/models"
 
@@ -23,10 +24,21 @@ func TestStatsStore(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to insert book: %v", err)
 		}
+		// NOTE: The author stats count the join, not the author line
+		_, err = db.ExecContext(ctx, "INSERT INTO authors (name, sort_name) VALUES (?, ?) ON CONFLICT DO NOTHING", author, author)
+		if err != nil {
+			t.Fatalf("Failed to insert author: %v", err)
+		}
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO book_authors (book_id, author_id, role, position)
+			SELECT ?, id, 'author', 0 FROM authors WHERE name = ?`, id, author)
+		if err != nil {
+			t.Fatalf("Failed to insert book author: %v", err)
+		}
 	}
 
 	t.Run("EmptyDatabase", func(t *testing.T) {
-		totalRead, readingProgress, popularAuthor, err := store.GetStats(ctx)
+		totalRead, readingProgress, err := store.GetStats(ctx)
 		if err != nil {
 			t.Errorf("GetStats failed: %v", err)
 		}
@@ -36,8 +48,12 @@ func TestStatsStore(t *testing.T) {
 		if readingProgress != 0 {
 			t.Errorf("readingProgress = %d, want 0", readingProgress)
 		}
-		if popularAuthor != "N/A" {
-			t.Errorf("popularAuthor = %s, want N/A", popularAuthor)
+		authors, err := store.TopAuthors(ctx, models.TopAuthorsRank)
+		if err != nil {
+			t.Errorf("TopAuthors failed: %v", err)
+		}
+		if len(authors) != 0 {
+			t.Errorf("TopAuthors = %+v, want none", authors)
 		}
 	})
 
@@ -52,7 +68,7 @@ func TestStatsStore(t *testing.T) {
 		addBook(uuid.NewString(), "Book 3", "Author B", models.BookReading)
 		addBook(uuid.NewString(), "Book 4", "Author B", models.BookUnread)
 
-		totalRead, readingProgress, popularAuthor, err := store.GetStats(ctx)
+		totalRead, readingProgress, err := store.GetStats(ctx)
 		if err != nil {
 			t.Errorf("GetStats failed: %v", err)
 		}
@@ -66,11 +82,27 @@ func TestStatsStore(t *testing.T) {
 		}
 
 		// NOTE:
-		// So the logic is that if there are several authors with equally many *max* books like here where you have 2
-		// from Author A and 2 from Author B in your collection. Then it will estbalish the
-		// most popular author as the one which name comes first alpabetically
-		if popularAuthor != "Author A" {
-			t.Errorf("popularAuthor = %s, want Author A", popularAuthor)
+		// Author A and Author B both have 2 books so they share rank 1. The name only decides the order
+		authors, err := store.TopAuthors(ctx, 1)
+		if err != nil {
+			t.Errorf("TopAuthors failed: %v", err)
+		}
+		if len(authors) != 2 || authors[0].Author != "Author A" || authors[1].Author != "Author B" || authors[1].Rank != 1 {
+			t.Errorf("TopAuthors(1) = %+v, want Author A and Author B at rank 1", authors)
+		}
+		if a := authors[0]; a.Complete != 2 || a.CompletionRate != 1 {
+			t.Errorf("Author A = %+v, want 2 complete and completion rate 1", a)
+		}
+
+		authors, total, err := store.AuthorStats(ctx, 1, 1)
+		if err != nil {
+			t.Errorf("AuthorStats failed: %v", err)
+		}
+		if total != 2 || len(authors) != 1 || authors[0].Author != "Author B" || authors[0].Reading != 1 || authors[0].ToRead != 1 {
+			t.Errorf("AuthorStats(1, 1) = %+v (total %d), want only Author B of 2", authors, total)
+		}
+		if _, total, err = store.AuthorStats(ctx, 10, 5); err != nil || total != 2 {
+			t.Errorf("AuthorStats past the end = total %d (err %v), want 2", total, err)
 		}
 	})
 
@@ -84,7 +116,7 @@ func TestStatsStore(t *testing.T) {
 		addBook(uuid.NewString(), "Book 1", "Author X", models.BookComplete)
 		addBook(uuid.NewString(), "Book 2", "Author X", models.BookReading)
 
-		totalRead, readingProgress, popularAuthor, err := store.GetStats(ctx)
+		totalRead, readingProgress, err := store.GetStats(ctx)
 		if err != nil {
 			t.Errorf("GetStats failed: %v", err)
 		}
@@ -94,8 +126,125 @@ func TestStatsStore(t *testing.T) {
 		if readingProgress != 50 {
 			t.Errorf("readingProgress = %d, want 50", readingProgress)
 		}
-		if popularAuthor != "Author X" {
-			t.Errorf("popularAuthor = %s, want Author X", popularAuthor)
+		authors, err := store.TopAuthors(ctx, models.TopAuthorsRank)
+		if err != nil {
+			t.Errorf("TopAuthors failed: %v", err)
+		}
+		if len(authors) != 1 || authors[0].Author != "Author X" || authors[0].CompletionRate != 0.5 {
+			t.Errorf("TopAuthors = %+v, want only Author X", authors)
+		}
+	})
+
+	t.Run("Completions", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, "DELETE FROM books")
+		if err != nil {
+			t.Fatalf("Failed to clear database: %v", err)
+		}
+
+		bookStore := NewBookStore(db).(*bookStore)
+		completedAt := time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC)
+		bookStore.now = func() time.Time { return completedAt }
+		books := []models.Book{
+			{ID: uuid.NewString(), Title: "Book 1", Author: "Author A", Status: models.BookComplete, Pages: 300},
+			{ID: uuid.NewString(), Title: "Book 2", Author: "Author A", Status: models.BookComplete, Pages: 200},
+			{ID: uuid.NewString(), Title: "Book 3", Author: "Author B", Status: models.BookReading, Pages: 100},
+		}
+		for _, b := range books {
+			if err := bookStore.CreateBook(ctx, &b); err != nil {
+				t.Fatalf("CreateBook failed: %v", err)
+			}
+		}
+
+		gotBooks, gotPages, err := store.Completions(ctx, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("Completions failed: %v", err)
+		}
+		if gotBooks != 2 || gotPages != 500 {
+			t.Errorf("Completions = %d books, %d pages, want 2 books, 500 pages", gotBooks, gotPages)
+		}
+
+		gotBooks, _, err = store.Completions(ctx, time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("Completions failed: %v", err)
+		}
+		if gotBooks != 0 {
+			t.Errorf("Completions after the completion date = %d, want 0", gotBooks)
+		}
+	})
+
+	t.Run("Timeline", func(t *testing.T) {
+		for _, table := range []string{"books", "book_events"} {
+			if _, err := db.ExecContext(ctx, "DELETE FROM "+table); err != nil {
+				t.Fatalf("Failed to clear %s: %v", table, err)
+			}
+		}
+
+		bookStore := NewBookStore(db).(*bookStore)
+		at := func(month time.Month, day int) {
+			bookStore.now = func() time.Time { return time.Date(2026, month, day, 12, 0, 0, 0, time.UTC) }
+		}
+
+		// Started in January, finished in March. February stays empty
+		book := &models.Book{ID: uuid.NewString(), Title: "Book 1", Author: "Author A", Status: models.BookReading, Pages: 320}
+		at(time.January, 15)
+		if err := bookStore.CreateBook(ctx, book); err != nil {
+			t.Fatalf("CreateBook failed: %v", err)
+		}
+		at(time.March, 2)
+		book.Status = models.BookComplete
+		if err := bookStore.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+		// A title change is not a status change so it should not show up
+		book.Title = "Book 1 (revised)"
+		if err := bookStore.UpdateBook(ctx, book); err != nil {
+			t.Fatalf("UpdateBook failed: %v", err)
+		}
+
+		q := models.TimelineQuery{
+			Granularity: models.GranularityMonth,
+			From:        time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:          time.Date(2026, 4, 30, 0, 0, 0, 0, time.UTC),
+		}
+		buckets, err := store.Timeline(ctx, q)
+		if err != nil {
+			t.Fatalf("Timeline failed: %v", err)
+		}
+		want := []models.TimelineBucket{
+			{Start: "2026-01-01", Started: 1},
+			{Start: "2026-02-01"},
+			{Start: "2026-03-01", Finished: 1, PagesRead: 320},
+			{Start: "2026-04-01"},
+		}
+		if len(buckets) != len(want) {
+			t.Fatalf("Timeline returned %d buckets (%+v), want %d", len(buckets), buckets, len(want))
+		}
+		for i := range want {
+			if buckets[i] != want[i] {
+				t.Errorf("bucket %d = %+v, want %+v", i, buckets[i], want[i])
+			}
+		}
+
+		q.Granularity = models.GranularityWeek
+		q.From = time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) // Monday
+		q.To = time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)
+		buckets, err = store.Timeline(ctx, q)
+		if err != nil {
+			t.Fatalf("Timeline failed: %v", err)
+		}
+		if len(buckets) != 2 || buckets[0].Start != "2026-03-02" || buckets[0].Finished != 1 || buckets[1].Start != "2026-03-09" {
+			t.Errorf("Weekly timeline = %+v, want weeks starting 2026-03-02 (1 finished) and 2026-03-09", buckets)
+		}
+	})
+
+	t.Run("EventTimes", func(t *testing.T) {
+		// Left over from the Timeline test: created on 2026-01-15 and completed on 2026-03-02
+		times, err := store.EventTimes(ctx, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("EventTimes failed: %v", err)
+		}
+		if len(times) != 1 || !times[0].Equal(time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("EventTimes = %v, want only 2026-01-15 12:00 UTC", times)
 		}
 	})
 }
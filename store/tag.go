@@ -0,0 +1,235 @@
+package store
+
+import (
+	"book-tracker/models"
+	"book-tracker/tracing"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists, merge into it instead")
+)
+
+type TagStore interface {
+	// ListTags returns every tag in use with its number of books, most used first
+	ListTags(ctx context.Context) ([]models.TagCount, error)
+	RenameTag(ctx context.Context, from, to string) error
+	// MergeTags moves every book tagged from over to into and removes from
+	MergeTags(ctx context.Context, from, into string) error
+	// DeleteTag removes the tag from every book
+	DeleteTag(ctx context.Context, name string) error
+}
+
+type tagStore struct {
+	db      *DB
+	dialect dialect
+}
+
+func NewTagStore(db *DB) TagStore {
+	return &tagStore{db: db, dialect: sqliteDialect}
+}
+
+// queryer is what loadTags needs, both *sql.DB and *sql.Tx have it
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// placeholders returns "?, ?, ?" for n arguments
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+const (
+	insertTag     = `INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING`
+	insertBookTag = `INSERT INTO book_tags (book_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`
+	// NOTE: Tags only exist through their books, so a tag nobody uses anymore goes away
+	pruneTags = `DELETE FROM tags WHERE NOT EXISTS (SELECT 1 FROM book_tags WHERE book_tags.tag_id = tags.id)`
+)
+
+// setTags replaces the tags of a book, tags has to be normalized already (see models.Book.Validate)
+func setTags(ctx context.Context, d dialect, tx *sql.Tx, bookID string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, d.bind("DELETE FROM book_tags WHERE book_id = ?"), bookID); err != nil {
+		return fmt.Errorf("clear tags: %w", err)
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, d.bind(insertTag), tag); err != nil {
+			return fmt.Errorf("create tag %s: %w", tag, err)
+		}
+		if _, err := tx.ExecContext(ctx, d.bind(insertBookTag), bookID, tag); err != nil {
+			return fmt.Errorf("tag book: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, pruneTags); err != nil {
+		return fmt.Errorf("prune tags: %w", err)
+	}
+	return nil
+}
+
+// loadTags fills in the tags of books with a single query
+func loadTags(ctx context.Context, d dialect, q queryer, books []*models.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+	byID := make(map[string]*models.Book, len(books))
+	args := make([]any, len(books))
+	for i, book := range books {
+		byID[book.ID] = book
+		args[i] = book.ID
+	}
+
+	query := `
+		SELECT book_tags.book_id, tags.name
+		FROM book_tags JOIN tags ON tags.id = book_tags.tag_id
+		WHERE book_tags.book_id IN (` + placeholders(len(books)) + `)
+		ORDER BY ` + fmt.Sprintf(d.byteOrder, "tags.name")
+	rows, err := q.QueryContext(ctx, d.bind(query), args...)
+	if err != nil {
+		return fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var bookID, tag string
+		if err := rows.Scan(&bookID, &tag); err != nil {
+			return fmt.Errorf("scan tag: %w", err)
+		}
+		byID[bookID].Tags = append(byID[bookID].Tags, tag)
+	}
+	return rows.Err()
+}
+
+// tagConditions are the tag= and tag_any= filters of ListBooks, as conditions on books.id
+func tagConditions(filter models.BookFilter) (conditions []string, args []any) {
+	const taggedWith = `SELECT book_tags.book_id FROM book_tags JOIN tags ON tags.id = book_tags.tag_id WHERE tags.name IN (%s)`
+
+	if len(filter.Tags) > 0 {
+		// NOTE: Every tag has to match, the tags are deduplicated so counting them is enough
+		conditions = append(conditions, "id IN ("+fmt.Sprintf(taggedWith, placeholders(len(filter.Tags)))+" GROUP BY book_tags.book_id HAVING COUNT(*) = ?)")
+		for _, tag := range filter.Tags {
+			args = append(args, tag)
+		}
+		args = append(args, len(filter.Tags))
+	}
+	if len(filter.AnyTags) > 0 {
+		conditions = append(conditions, "id IN ("+fmt.Sprintf(taggedWith, placeholders(len(filter.AnyTags)))+")")
+		for _, tag := range filter.AnyTags {
+			args = append(args, tag)
+		}
+	}
+	return conditions, args
+}
+
+const selectTagCounts = `
+	SELECT tags.name, COUNT(*) AS books
+	FROM tags JOIN book_tags ON book_tags.tag_id = tags.id
+	GROUP BY tags.name
+	ORDER BY books DESC, %s`
+
+func (s *tagStore) ListTags(ctx context.Context) (_ []models.TagCount, err error) {
+	query := fmt.Sprintf(selectTagCounts, fmt.Sprintf(s.dialect.byteOrder, "tags.name"))
+	ctx, span := s.dialect.startSpan(ctx, "tagStore.ListTags", query)
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := s.db.Read.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("query tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []models.TagCount{}
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Books); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// tagID looks up a tag inside tx
+func tagID(ctx context.Context, d dialect, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, d.bind("SELECT id FROM tags WHERE name = ?"+d.lockRow), name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrTagNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get tag: %w", err)
+	}
+	return id, nil
+}
+
+func (s *tagStore) RenameTag(ctx context.Context, from, to string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "tagStore.RenameTag", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		id, err := tagID(ctx, s.dialect, tx, from)
+		if err != nil {
+			return err
+		}
+		if from == to {
+			return nil
+		}
+		if _, err := tagID(ctx, s.dialect, tx, to); err == nil {
+			return ErrTagExists
+		} else if !errors.Is(err, ErrTagNotFound) {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.bind("UPDATE tags SET name = ? WHERE id = ?"), to, id); err != nil {
+			return fmt.Errorf("rename tag: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *tagStore) MergeTags(ctx context.Context, from, into string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "tagStore.MergeTags", "")
+	defer func() { tracing.End(span, err) }()
+
+	return withTx(ctx, s.db.DB, func(tx *sql.Tx) error {
+		fromID, err := tagID(ctx, s.dialect, tx, from)
+		if err != nil {
+			return err
+		}
+		intoID, err := tagID(ctx, s.dialect, tx, into)
+		if err != nil {
+			return err
+		}
+		// NOTE: Books that already have both keep a single one, the rest of from goes with the cascade
+		_, err = tx.ExecContext(ctx, s.dialect.bind(`
+			INSERT INTO book_tags (book_id, tag_id)
+			SELECT book_id, ? FROM book_tags WHERE tag_id = ?
+			ON CONFLICT DO NOTHING`), intoID, fromID)
+		if err != nil {
+			return fmt.Errorf("merge tags: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, s.dialect.bind("DELETE FROM tags WHERE id = ?"), fromID); err != nil {
+			return fmt.Errorf("delete merged tag: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *tagStore) DeleteTag(ctx context.Context, name string) (err error) {
+	ctx, span := s.dialect.startSpan(ctx, "tagStore.DeleteTag", "DELETE FROM tags WHERE name = ?")
+	defer func() { tracing.End(span, err) }()
+
+	result, err := s.db.ExecContext(ctx, s.dialect.bind("DELETE FROM tags WHERE name = ?"), name)
+	if err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("check rows affected: %w", err)
+	}
+	if n == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
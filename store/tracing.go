@@ -0,0 +1,28 @@
+package store
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("book-tracker/store")
+
+// startSpan starts a client span around a database call. statement is optional as some
+// methods run more than one query, in that case the span covers all of them
+func startSpan(ctx context.Context, name, statement string) (context.Context, trace.Span) {
+	return startDBSpan(ctx, sqliteDialect.system, name, statement)
+}
+
+func startDBSpan(ctx context.Context, system, name, statement string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system.name", system),
+		attribute.String("db.operation.name", name),
+	}
+	if statement != "" {
+		attrs = append(attrs, attribute.String("db.query.text", statement))
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+const adminToken = "s3cret"
+
+func setupAdmin(t *testing.T, keep int) (*http.ServeMux, string) {
+	dir := t.TempDir()
+	db, closeDB, err := store.NewDB(filepath.Join(dir, "books.db"))
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	t.Cleanup(closeDB)
+
+	cfg := services.BackupConfig{Dir: filepath.Join(dir, "backups"), Keep: keep, Compress: true}
+	mux := http.NewServeMux()
+	routes.SetupAdminRoutes(mux, handlers.NewBackupHandler(services.NewBackupService(store.NewBackupStore(db), cfg)), adminToken)
+	return mux, cfg.Dir
+}
+
+func adminRequest(mux *http.ServeMux, method, token string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, "/api/v1/admin/backups", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAdminRoutes(t *testing.T) {
+	t.Run("Unauthorized", func(t *testing.T) {
+		mux, dir := setupAdmin(t, 0)
+
+		for _, token := range []string{"", "wrong"} {
+			for _, method := range []string{"GET", "POST"} {
+				rr := adminRequest(mux, method, token)
+				if rr.Code != http.StatusUnauthorized {
+					t.Errorf("%s with token %q: expected status 401, got %d", method, token, rr.Code)
+				}
+				if rr.Header().Get("WWW-Authenticate") == "" {
+					t.Errorf("%s with token %q: expected a WWW-Authenticate header", method, token)
+				}
+			}
+		}
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("Expected no backup to be taken, got %v", err)
+		}
+	})
+
+	t.Run("POST_CreateBackup", func(t *testing.T) {
+		mux, dir := setupAdmin(t, 0)
+
+		rr := adminRequest(mux, "POST", adminToken)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var backup models.Backup
+		if err := json.NewDecoder(rr.Body).Decode(&backup); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if !backup.Compressed || backup.Size == 0 || backup.CreatedAt.IsZero() {
+			t.Errorf("Unexpected backup: %+v", backup)
+		}
+		if err := store.RestoreSQLite(filepath.Join(dir, backup.Name), filepath.Join(t.TempDir(), "restored.db")); err != nil {
+			t.Errorf("Expected the backup to restore, got %v", err)
+		}
+	})
+
+	t.Run("GET_ListBackups_Rotated", func(t *testing.T) {
+		mux, _ := setupAdmin(t, 2)
+
+		created := []string{}
+		for range 3 {
+			rr := adminRequest(mux, "POST", adminToken)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+			var backup models.Backup
+			json.NewDecoder(rr.Body).Decode(&backup)
+			created = append(created, backup.Name)
+		}
+
+		rr := adminRequest(mux, "GET", adminToken)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var backups []models.Backup
+		if err := json.NewDecoder(rr.Body).Decode(&backups); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(backups) != 2 || backups[0].Name != created[2] || backups[1].Name != created[1] {
+			t.Errorf("Expected the two newest backups %v newest first, got %+v", created[1:], backups)
+		}
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		mux, _ := setupAdmin(t, 0)
+
+		rr := adminRequest(mux, "DELETE", adminToken)
+		if rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupArchive(t *testing.T) (*http.ServeMux, store.BookStore) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	t.Cleanup(closeDB)
+	statsService := services.NewStatsService(store.NewStatsStore(db), nil, 0)
+	mux := http.NewServeMux()
+	routes.SetupArchiveRoutes(mux, handlers.NewArchiveHandler(services.NewArchiveService(store.NewArchiveStore(db), statsService)))
+	routes.SetupStatsRoutes(mux, handlers.NewStatsHandler(statsService))
+	return mux, store.NewBookStore(db)
+}
+
+func seedBooks(t *testing.T, bookStore store.BookStore, books ...models.Book) {
+	t.Helper()
+	for i := range books {
+		if err := books[i].GenerateID(); err != nil {
+			t.Fatalf("Failed to generate UUID: %v", err)
+		}
+		if err := bookStore.CreateBook(context.Background(), &books[i]); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+}
+
+func importArchive(mux *http.ServeMux, query string, body []byte) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/api/v1/import"+query, bytes.NewReader(body))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestArchiveRoutes(t *testing.T) {
+	t.Run("GET_Export_POST_Import_RoundTrip", func(t *testing.T) {
+		source, bookStore := setupArchive(t)
+		seedBooks(t, bookStore,
+			models.Book{Title: "Emma", Author: "Jane Austen", Status: models.BookComplete, Pages: 474},
+			models.Book{Title: "Persuasion", Author: "Jane Austen", Status: models.BookReading},
+		)
+
+		req, _ := http.NewRequest("GET", "/api/v1/export", nil)
+		rr := httptest.NewRecorder()
+		source.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if !strings.HasPrefix(rr.Header().Get("Content-Disposition"), "attachment") {
+			t.Errorf("Expected a download, got Content-Disposition %q", rr.Header().Get("Content-Disposition"))
+		}
+		exported := rr.Body.Bytes()
+		var archive models.Archive
+		if err := json.Unmarshal(exported, &archive); err != nil {
+			t.Fatalf("Export is not valid JSON: %v\n%s", err, exported)
+		}
+		if archive.Format != models.ArchiveFormat || archive.Version != models.ArchiveVersion || len(archive.Books) != 2 || len(archive.Events) != 2 {
+			t.Fatalf("Unexpected archive: %+v", archive)
+		}
+		if !strings.Contains(string(exported), `"goals":[],"notes":[],"shelves":[],"series":[],"reviews":[]`) || !strings.HasSuffix(string(exported), `"schedules":[]}`+"\n") {
+			t.Errorf("Expected empty goals, notes, shelves, series, reviews and schedules lists, got %s", exported)
+		}
+
+		target, _ := setupArchive(t)
+		rr = importArchive(target, "?mode=replace", exported)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var result models.ImportResult
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if result.Mode != models.ImportReplace || result.IDs != models.ImportKeepIDs || result.Books != 2 || result.Events != 2 {
+			t.Errorf("Unexpected result: %+v", result)
+		}
+
+		req, _ = http.NewRequest("GET", "/api/v1/stats", nil)
+		rr = httptest.NewRecorder()
+		target.ServeHTTP(rr, req)
+		var stats models.Stats
+		json.NewDecoder(rr.Body).Decode(&stats)
+		if stats.TotalRead != 1 || stats.ReadingProgress != 50 {
+			t.Errorf("Expected the imported library in the stats, got %+v", stats)
+		}
+	})
+
+	t.Run("POST_Import_NewIDsTwice", func(t *testing.T) {
+		mux, bookStore := setupArchive(t)
+		body := []byte(`{"format":"book-tracker","version":1,"books":[
+			{"id":"0b9c3a4e-8f0a-4a57-9f4e-3f1f2d6c1a11","title":"Dune","author":"Frank Herbert","status":"unread"}]}`)
+
+		for range 2 {
+			if rr := importArchive(mux, "?ids=new", body); rr.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+		total, _, err := bookStore.CountBooks(context.Background())
+		if err != nil || total != 2 {
+			t.Errorf("Expected the book to be added twice, got %d (%v)", total, err)
+		}
+	})
+
+	t.Run("POST_Import_Invalid", func(t *testing.T) {
+		mux, bookStore := setupArchive(t)
+		seedBooks(t, bookStore, models.Book{Title: "Existing", Author: "Author", Status: models.BookUnread})
+
+		tests := []struct {
+			name   string
+			query  string
+			body   string
+			status int
+			code   string
+		}{
+			{"NotJSON", "", `{"format":`, http.StatusBadRequest, "request.invalid_body"},
+			{"WrongFormat", "", `{"format":"csv","version":1}`, http.StatusUnprocessableEntity, "validation_failed"},
+			{"InvalidBook", "?mode=replace", `{"format":"book-tracker","version":1,"books":[{"id":"nope","title":"","author":"A","status":"unread"}]}`, http.StatusUnprocessableEntity, "validation_failed"},
+			{"InvalidMode", "?mode=append", `{"format":"book-tracker","version":1}`, http.StatusUnprocessableEntity, "validation_failed"},
+		}
+		for _, tt := range tests {
+			rr := importArchive(mux, tt.query, []byte(tt.body))
+			if rr.Code != tt.status || !strings.Contains(rr.Body.String(), `"code":"`+tt.code+`"`) {
+				t.Errorf("%s: expected %d %s, got %d: %s", tt.name, tt.status, tt.code, rr.Code, rr.Body.String())
+			}
+		}
+
+		total, _, err := bookStore.CountBooks(context.Background())
+		if err != nil || total != 1 {
+			t.Errorf("Expected a rejected import to leave the library alone, got %d books (%v)", total, err)
+		}
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		mux, _ := setupArchive(t)
+		for _, path := range []string{"/api/v1/export", "/api/v1/import"} {
+			req, _ := http.NewRequest("DELETE", path, nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != http.StatusMethodNotAllowed {
+				t.Errorf("DELETE %s: expected status 405, got %d", path, rr.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,200 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupAuthors(t *testing.T) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	// NOTE: With a cache so the test also covers that renames and merges invalidate it
+	statsService := services.NewStatsService(store.NewStatsStore(db), nil, time.Hour)
+	mux := http.NewServeMux()
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(store.NewBookStore(db), statsService)))
+	routes.SetupAuthorsRoutes(mux, handlers.NewAuthorHandler(services.NewAuthorService(store.NewAuthorStore(db), statsService)))
+	routes.SetupStatsRoutes(mux, handlers.NewStatsHandler(statsService))
+	return mux, closeDB
+}
+
+func TestAuthorsRoutes(t *testing.T) {
+	listAuthors := func(t *testing.T, mux *http.ServeMux) []models.Author {
+		t.Helper()
+		rr := sendJSON(mux, "GET", "/api/v1/authors", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var authors []models.Author
+		json.NewDecoder(rr.Body).Decode(&authors)
+		return authors
+	}
+	topAuthors := func(t *testing.T, mux *http.ServeMux) []models.AuthorStats {
+		t.Helper()
+		rr := sendJSON(mux, "GET", "/api/v1/stats", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var stats models.Stats
+		json.NewDecoder(rr.Body).Decode(&stats)
+		return stats.TopAuthors
+	}
+
+	t.Run("Contributors_AuthorLineStaysCompatible", func(t *testing.T) {
+		mux, closeDB := setupAuthors(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]any{
+			"title":  "Good Omens",
+			"status": "unread",
+			"contributors": []map[string]string{
+				{"name": "Terry Pratchett"},
+				{"name": "Neil Gaiman", "role": "author"},
+				{"name": "Stephen Briggs", "role": "narrator"},
+			},
+		})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var book models.Book
+		json.NewDecoder(rr.Body).Decode(&book)
+		if book.Author != "Terry Pratchett, Neil Gaiman" || len(book.Contributors) != 3 || book.Contributors[2].AuthorID == 0 {
+			t.Fatalf("Expected the author line and resolved contributors, got %+v", book)
+		}
+
+		// An old client that only sends the author string keeps the narrator
+		rr = sendJSON(mux, "PUT", "/api/v1/books/"+book.ID, map[string]any{"title": "Good Omens", "author": "Neil Gaiman", "status": "reading"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		json.NewDecoder(rr.Body).Decode(&book)
+		if book.Author != "Neil Gaiman" || len(book.Contributors) != 2 || book.Contributors[1].Name != "Stephen Briggs" {
+			t.Errorf("Expected Neil Gaiman with the narrator kept, got %q %+v", book.Author, book.Contributors)
+		}
+
+		rr = sendJSON(mux, "POST", "/api/v1/books", map[string]any{
+			"title": "Good Omens", "status": "unread", "contributors": []map[string]string{{"name": "Stephen Briggs", "role": "reader"}},
+		})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 2 || p.Errors[0].Field != "contributors[0].role" || p.Errors[0].Code != models.CodeContributorRoleInvalid || p.Errors[1].Code != models.CodeBookAuthorMissing {
+			t.Errorf("Expected an invalid role and a missing author, got %+v", p.Errors)
+		}
+	})
+
+	t.Run("Authors_CRUDAndMerge", func(t *testing.T) {
+		mux, closeDB := setupAuthors(t)
+		defer closeDB()
+
+		for _, author := range []string{"J.K. Rowling", "JK Rowling"} {
+			if rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": "Book by " + author, "author": author, "status": "unread"}); rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+		rr := sendJSON(mux, "POST", "/api/v1/authors", map[string]string{"name": " Robert Galbraith "})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var galbraith models.Author
+		json.NewDecoder(rr.Body).Decode(&galbraith)
+		if galbraith.ID == 0 || galbraith.Name != "Robert Galbraith" || galbraith.SortName != "Galbraith, Robert" {
+			t.Fatalf("Expected the created author with a sort name, got %+v", galbraith)
+		}
+		if rr := sendJSON(mux, "POST", "/api/v1/authors", map[string]string{"name": "robert galbraith"}); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for an existing name, got %d", rr.Code)
+		}
+
+		authors := listAuthors(t, mux)
+		if len(authors) != 3 || authors[0].Name != "Robert Galbraith" || authors[1].Name != "J.K. Rowling" || authors[2].Books != 1 {
+			t.Fatalf("Expected the authors by sort name, got %+v", authors)
+		}
+		rowling, duplicate := authors[1], authors[2]
+
+		if rr := sendJSON(mux, "DELETE", fmt.Sprintf("/api/v1/authors/%d", duplicate.ID), nil); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 deleting an author with books, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "POST", fmt.Sprintf("/api/v1/authors/%d/merge", duplicate.ID), map[string]int64{"into": duplicate.ID}); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 merging an author into itself, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "POST", fmt.Sprintf("/api/v1/authors/%d/merge", duplicate.ID), map[string]int64{"into": rowling.ID}); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for merge, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		rr = sendJSON(mux, "GET", fmt.Sprintf("/api/v1/authors/%d", rowling.ID), nil)
+		json.NewDecoder(rr.Body).Decode(&rowling)
+		if rr.Code != http.StatusOK || rowling.Books != 2 {
+			t.Errorf("Expected J.K. Rowling with 2 books, got %d: %+v", rr.Code, rowling)
+		}
+		rr = sendJSON(mux, "GET", "/api/v1/books", nil)
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		if len(books) != 2 {
+			t.Fatalf("Expected 2 books, got %d", len(books))
+		}
+		for _, book := range books {
+			if book.Author != "J.K. Rowling" {
+				t.Errorf("Expected the merged author line, got %q", book.Author)
+			}
+		}
+
+		rr = sendJSON(mux, "PUT", fmt.Sprintf("/api/v1/authors/%d", rowling.ID), map[string]string{"name": "Joanne Rowling"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := sendJSON(mux, "DELETE", fmt.Sprintf("/api/v1/authors/%d", galbraith.ID), nil); rr.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204 deleting an author without books, got %d", rr.Code)
+		}
+		for _, path := range []string{fmt.Sprintf("/api/v1/authors/%d", galbraith.ID), "/api/v1/authors/nope"} {
+			if rr := sendJSON(mux, "GET", path, nil); rr.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404 for %s, got %d", path, rr.Code)
+			}
+		}
+		if rr := sendJSON(mux, "PATCH", "/api/v1/authors", nil); rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+	t.Run("Authors_RenameAndMergeUpdateStats", func(t *testing.T) {
+		mux, closeDB := setupAuthors(t)
+		defer closeDB()
+
+		for _, author := range []string{"J.K. Rowling", "JK Rowling"} {
+			if rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": "Book by " + author, "author": author, "status": "complete"}); rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+		if top := topAuthors(t, mux); len(top) != 2 {
+			t.Fatalf("Expected 2 top authors, got %+v", top)
+		}
+		authors := listAuthors(t, mux)
+		rowling, duplicate := authors[0], authors[1]
+
+		if rr := sendJSON(mux, "PUT", fmt.Sprintf("/api/v1/authors/%d", rowling.ID), map[string]string{"name": "Joanne Rowling"}); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		top := topAuthors(t, mux)
+		if len(top) != 2 || (top[0].Author != "Joanne Rowling" && top[1].Author != "Joanne Rowling") {
+			t.Errorf("Expected the renamed author in the stats, got %+v", top)
+		}
+
+		if rr := sendJSON(mux, "POST", fmt.Sprintf("/api/v1/authors/%d/merge", duplicate.ID), map[string]int64{"into": rowling.ID}); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for merge, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if top := topAuthors(t, mux); len(top) != 1 || top[0].Author != "Joanne Rowling" || top[0].Complete != 2 {
+			t.Errorf("Expected one author with both books in the stats, got %+v", top)
+		}
+	})
+}
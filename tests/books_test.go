@@ -11,9 +11,12 @@ import (
 
 	"book-tracker/handlers"
 	"book-tracker/models"
+	"book-tracker/problem"
 	"book-tracker/routes"
 	"book-tracker/services"
 	"book-tracker/store"
+
+	"github.com/google/uuid"
 )
 
 func setupBooks(t *testing.T) (*http.ServeMux, store.BookStore, func()) {
@@ -176,6 +179,57 @@ func TestBooksRoutes(t *testing.T) {
 		}
 	})
 
+	t.Run("POST_CreateBook_ProblemDetails", func(t *testing.T) {
+		mux, _, closeDB := setupBooks(t)
+		defer closeDB()
+
+		body, _ := json.Marshal(models.Book{Status: models.BookUnread})
+		req, _ := http.NewRequest("POST", "/api/v1/books", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rr.Code)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+			t.Errorf("Content-Type = %q, want %q", ct, problem.ContentType)
+		}
+		var p problem.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if p.Code != "validation_failed" || p.Status != http.StatusUnprocessableEntity || p.Instance != "/api/v1/books" {
+			t.Errorf("Problem = %+v, want code validation_failed, status 422 and instance /api/v1/books", p)
+		}
+		// NOTE: Both missing fields are reported in one response
+		if len(p.Errors) != 2 || p.Errors[0].Code != models.CodeTitleMissing || p.Errors[1].Code != models.CodeAuthorMissing {
+			t.Errorf("Problem errors = %+v, want title and author errors", p.Errors)
+		}
+	})
+
+	t.Run("PUT_UpdateBook_NotFound", func(t *testing.T) {
+		mux, _, closeDB := setupBooks(t)
+		defer closeDB()
+
+		body, _ := json.Marshal(models.Book{Title: "No Book", Author: "No Author", Status: models.BookUnread})
+		req, _ := http.NewRequest("PUT", "/api/v1/books/"+uuid.NewString(), bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+		var p problem.Problem
+		if err := json.NewDecoder(rr.Body).Decode(&p); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if p.Code != "book.not_found" {
+			t.Errorf("Problem code = %q, want book.not_found", p.Code)
+		}
+	})
+
 	t.Run("InvalidMethod_Books", func(t *testing.T) {
 		mux, _, closeDB := setupBooks(t)
 		defer closeDB()
@@ -188,6 +242,9 @@ func TestBooksRoutes(t *testing.T) {
 		if rr.Code != http.StatusMethodNotAllowed {
 			t.Errorf("Expected status 405, got %d", rr.Code)
 		}
+		if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+			t.Errorf("Content-Type = %q, want %q", ct, problem.ContentType)
+		}
 	})
 
 	t.Run("InvalidPath_BookID", func(t *testing.T) {
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupClippings(t *testing.T) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	bookService := services.NewBookService(store.NewBookStore(db))
+	noteStore := store.NewNoteStore(db)
+	mux := http.NewServeMux()
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(bookService))
+	routes.SetupNotesRoutes(mux, handlers.NewNoteHandler(services.NewNoteService(noteStore)))
+	routes.SetupClippingsRoutes(mux, handlers.NewClippingsHandler(services.NewClippingsService(bookService, noteStore)))
+	return mux, closeDB
+}
+
+const kindleClippings = `Dune: Deluxe Edition (Herbert, Frank)
+- Your Highlight on page 8 | Location 112-113 | Added on Monday, March 3, 2025 10:12:45 PM
+
+I must not fear.
+==========
+Dune: Deluxe Edition (Herbert, Frank)
+- Your Highlight on page 8 | Location 112-114 | Added on Monday, March 3, 2025 10:14:00 PM
+
+I must not fear. Fear is the mind-killer.
+==========
+Dune: Deluxe Edition (Herbert, Frank)
+- Your Bookmark on page 9 | Location 130 | Added on Monday, March 3, 2025 10:20:00 PM
+
+
+==========
+Walden (Henry David Thoreau)
+- Your Note on Location 2001 | Added on Tuesday, March 4, 2025 8:00:00 AM
+
+Read this again
+==========
+Meeting notes
+- Your Highlight on Location 5-6 | Added on Tuesday, March 4, 2025 9:00:00 AM
+
+Ship it
+==========
+`
+
+func importClippings(mux *http.ServeMux, query, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/api/v1/import/kindle"+query, strings.NewReader(body))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestClippingsRoutes(t *testing.T) {
+	decode := func(t *testing.T, rr *httptest.ResponseRecorder) models.ClippingsResult {
+		t.Helper()
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var result models.ClippingsResult
+		json.NewDecoder(rr.Body).Decode(&result)
+		return result
+	}
+
+	t.Run("POST_ImportKindle_MatchAndReport", func(t *testing.T) {
+		mux, closeDB := setupClippings(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": "dune", "author": "Frank Herbert", "status": "complete"})
+		var dune models.Book
+		json.NewDecoder(rr.Body).Decode(&dune)
+
+		result := decode(t, importClippings(mux, "", kindleClippings))
+		if result.Imported != 1 || result.Duplicates != 1 || result.Bookmarks != 1 || result.BooksMatched != 1 || result.BooksCreated != 0 {
+			t.Errorf("Expected the extended highlight on Dune, got %+v", result)
+		}
+		if len(result.Unmatched) != 2 || result.Unmatched[0].Title != "Walden" || result.Unmatched[1].Title != "Meeting notes" || result.Unmatched[1].Clippings != 1 {
+			t.Errorf("Expected Walden and the meeting notes unmatched, got %+v", result.Unmatched)
+		}
+
+		rr = sendJSON(mux, "GET", "/api/v1/books/"+dune.ID+"/notes", nil)
+		var notes []models.Note
+		json.NewDecoder(rr.Body).Decode(&notes)
+		if len(notes) != 1 || notes[0].Type != models.NoteHighlight || notes[0].Body != "I must not fear. Fear is the mind-killer." || notes[0].Location != "112-114" || notes[0].CreatedAt.Year() != 2025 {
+			t.Errorf("Expected the highlight as it was made on the Kindle, got %+v", notes)
+		}
+
+		// The file only ever grows, importing it again adds nothing twice
+		result = decode(t, importClippings(mux, "", kindleClippings))
+		if result.Imported != 0 || result.AlreadyImported != 1 {
+			t.Errorf("Expected everything to be imported already, got %+v", result)
+		}
+	})
+
+	t.Run("POST_ImportKindle_CreateBooks", func(t *testing.T) {
+		mux, closeDB := setupClippings(t)
+		defer closeDB()
+
+		result := decode(t, importClippings(mux, "?create=true", kindleClippings))
+		if result.Imported != 2 || result.BooksCreated != 2 || len(result.Unmatched) != 1 || result.Unmatched[0].Title != "Meeting notes" {
+			t.Errorf("Expected Dune and Walden to be created, got %+v", result)
+		}
+
+		rr := sendJSON(mux, "GET", "/api/v1/notes?q=again", nil)
+		var notes []models.Note
+		json.NewDecoder(rr.Body).Decode(&notes)
+		if len(notes) != 1 || notes[0].Type != models.NoteText {
+			t.Fatalf("Expected the Walden note, got %+v", notes)
+		}
+		rr = sendJSON(mux, "GET", "/api/v1/books", nil)
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		if len(books) != 2 || books[1].ID != notes[0].BookID || books[1].Title != "Walden" || books[1].Author != "Henry David Thoreau" || books[1].Status != models.BookReading {
+			t.Errorf("Expected Dune and Walden to be created, got %+v", books)
+		}
+	})
+
+	t.Run("POST_ImportKindle_Invalid", func(t *testing.T) {
+		mux, closeDB := setupClippings(t)
+		defer closeDB()
+
+		rr := importClippings(mux, "?create=maybe", kindleClippings)
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Code != models.CodeImportCreate {
+			t.Errorf("Expected create to be rejected, got %+v", p.Errors)
+		}
+
+		result := decode(t, importClippings(mux, "", "not a clippings file\n==========\n"))
+		if result.Imported != 0 || len(result.Errors) != 1 || result.Errors[0].Line != 1 {
+			t.Errorf("Expected one malformed entry, got %+v", result)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/import/kindle", nil); rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupGoals(t *testing.T) (*http.ServeMux, store.BookStore, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	bookStore := store.NewBookStore(db)
+	statsStore := store.NewStatsStore(db)
+	goalService := services.NewGoalService(store.NewGoalStore(db), statsStore)
+	statsService := services.NewStatsService(statsStore, goalService, 0)
+	mux := http.NewServeMux()
+	routes.SetupGoalsRoutes(mux, handlers.NewGoalHandler(goalService))
+	routes.SetupStatsRoutes(mux, handlers.NewStatsHandler(statsService))
+	return mux, bookStore, closeDB
+}
+
+func TestGoalsRoutes(t *testing.T) {
+	t.Run("POST_CreateGoal_WithProgress", func(t *testing.T) {
+		mux, bookStore, closeDB := setupGoals(t)
+		defer closeDB()
+
+		book := models.Book{Title: "Done Book", Author: "Some Author", Status: models.BookComplete, Pages: 250}
+		if err := book.GenerateID(); err != nil {
+			t.Fatalf("Failed to generate UUID: %v", err)
+		}
+		if err := bookStore.CreateBook(context.Background(), &book); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+
+		body, _ := json.Marshal(map[string]any{"metric": "pages", "target": 1000, "year": time.Now().UTC().Year()})
+		req, _ := http.NewRequest("POST", "/api/v1/goals", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var progress models.GoalProgress
+		if err := json.NewDecoder(rr.Body).Decode(&progress); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if progress.ID == "" || progress.Current != 250 || progress.Percent != 25 {
+			t.Errorf("Progress = %+v, want an id, current 250 and percent 25", progress)
+		}
+	})
+
+	t.Run("GET_Stats_IncludesActiveGoals", func(t *testing.T) {
+		mux, _, closeDB := setupGoals(t)
+		defer closeDB()
+
+		year := time.Now().UTC().Year()
+		for _, y := range []int{year, year - 1} {
+			body, _ := json.Marshal(map[string]any{"metric": "books", "target": 12, "year": y})
+			req, _ := http.NewRequest("POST", "/api/v1/goals", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats", nil)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		var stats models.Stats
+		if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(stats.Goals) != 1 || stats.Goals[0].Target != 12 {
+			t.Errorf("Stats goals = %+v, want only the goal for this year", stats.Goals)
+		}
+	})
+
+	t.Run("GET_Goal_NotFound", func(t *testing.T) {
+		mux, _, closeDB := setupGoals(t)
+		defer closeDB()
+
+		req, _ := http.NewRequest("GET", "/api/v1/goals/nonexistent", nil)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupHighlights(t *testing.T, perDay int) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	mux := http.NewServeMux()
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(store.NewBookStore(db))))
+	routes.SetupNotesRoutes(mux, handlers.NewNoteHandler(services.NewNoteService(store.NewNoteStore(db))))
+	routes.SetupHighlightsRoutes(mux, handlers.NewHighlightHandler(services.NewHighlightService(store.NewHighlightStore(db), perDay)))
+	return mux, closeDB
+}
+
+func TestHighlightsRoutes(t *testing.T) {
+	daily := func(t *testing.T, mux *http.ServeMux) models.DailyHighlights {
+		t.Helper()
+		rr := sendJSON(mux, "GET", "/api/v1/highlights/daily", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var d models.DailyHighlights
+		json.NewDecoder(rr.Body).Decode(&d)
+		return d
+	}
+
+	t.Run("Highlights_DailyReview", func(t *testing.T) {
+		mux, closeDB := setupHighlights(t, 2)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": "Walden", "author": "Henry David Thoreau", "status": "reading"})
+		var book models.Book
+		json.NewDecoder(rr.Body).Decode(&book)
+		var ids []string
+		for _, note := range []map[string]any{
+			{"type": "highlight", "body": "I went to the woods because I wished to live deliberately."},
+			{"type": "quote", "body": "Simplify, simplify."},
+			{"type": "highlight", "body": "Heaven is under our feet as well as over our heads."},
+			{"body": "Reread the chapter on Economy"},
+		} {
+			rr := sendJSON(mux, "POST", "/api/v1/books/"+book.ID+"/notes", note)
+			if rr.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+			}
+			var created models.Note
+			json.NewDecoder(rr.Body).Decode(&created)
+			ids = append(ids, created.ID)
+		}
+
+		d := daily(t, mux)
+		if d.PerDay != 2 || d.Reviewed != 0 || len(d.Highlights) != 2 {
+			t.Fatalf("Expected 2 of the 3 highlights today, got %+v", d)
+		}
+		if h := d.Highlights[0]; h.BookTitle != "Walden" || h.Type == models.NoteText || h.Schedule.DueOn != d.Date {
+			t.Errorf("Expected a highlight of Walden due today, got %+v", h)
+		}
+		// NOTE: The same queue until something is reviewed
+		if again := daily(t, mux); again.Highlights[0].ID != d.Highlights[0].ID || again.Highlights[1].ID != d.Highlights[1].ID {
+			t.Errorf("Expected the same queue twice, got %+v and %+v", d.Highlights, again.Highlights)
+		}
+
+		rr = sendJSON(mux, "POST", "/api/v1/highlights/"+d.Highlights[0].ID+"/review", map[string]string{"feedback": "favorite"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var schedule models.HighlightSchedule
+		json.NewDecoder(rr.Body).Decode(&schedule)
+		if !schedule.Favorite || schedule.Repetitions != 1 || schedule.IntervalDays != 1 || schedule.ReviewedOn != d.Date || schedule.DueOn <= d.Date {
+			t.Errorf("Expected a favorite due tomorrow, got %+v", schedule)
+		}
+		if rr := sendJSON(mux, "POST", "/api/v1/highlights/"+d.Highlights[1].ID+"/review", map[string]string{"feedback": "discard"}); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		// The third highlight waits for tomorrow, two a day
+		if d := daily(t, mux); d.Reviewed != 2 || len(d.Highlights) != 0 {
+			t.Errorf("Expected the day done, got %+v", d)
+		}
+	})
+
+	t.Run("Highlights_Invalid", func(t *testing.T) {
+		mux, closeDB := setupHighlights(t, 5)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": "Emma", "author": "Jane Austen", "status": "reading"})
+		var book models.Book
+		json.NewDecoder(rr.Body).Decode(&book)
+		rr = sendJSON(mux, "POST", "/api/v1/books/"+book.ID+"/notes", map[string]any{"body": "Not a highlight"})
+		var note models.Note
+		json.NewDecoder(rr.Body).Decode(&note)
+
+		rr = sendJSON(mux, "POST", "/api/v1/highlights/"+note.ID+"/review", map[string]string{"feedback": "skip"})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Code != models.CodeHighlightFeedbackInvalid {
+			t.Errorf("Expected a feedback violation, got %+v", p.Errors)
+		}
+		if rr := sendJSON(mux, "POST", "/api/v1/highlights/"+note.ID+"/review", map[string]string{"feedback": "keep"}); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 reviewing a plain note, got %d", rr.Code)
+		}
+		missing := "00000000-0000-4000-8000-000000000000"
+		if rr := sendJSON(mux, "POST", "/api/v1/highlights/"+missing+"/review", map[string]string{"feedback": "keep"}); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a missing note, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "POST", "/api/v1/highlights/daily", nil); rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+}
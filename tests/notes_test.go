@@ -0,0 +1,143 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupNotes(t *testing.T) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	mux := http.NewServeMux()
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(store.NewBookStore(db))))
+	routes.SetupNotesRoutes(mux, handlers.NewNoteHandler(services.NewNoteService(store.NewNoteStore(db))))
+	return mux, closeDB
+}
+
+func TestNotesRoutes(t *testing.T) {
+	createBook := func(t *testing.T, mux *http.ServeMux, title string) models.Book {
+		t.Helper()
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": title, "author": "Author", "status": "reading"})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var book models.Book
+		json.NewDecoder(rr.Body).Decode(&book)
+		return book
+	}
+	createNote := func(t *testing.T, mux *http.ServeMux, bookID string, note map[string]any) models.Note {
+		t.Helper()
+		rr := sendJSON(mux, "POST", "/api/v1/books/"+bookID+"/notes", note)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var created models.Note
+		json.NewDecoder(rr.Body).Decode(&created)
+		return created
+	}
+	list := func(t *testing.T, mux *http.ServeMux, path string) []models.Note {
+		t.Helper()
+		rr := sendJSON(mux, "GET", path, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for %s, got %d: %s", path, rr.Code, rr.Body.String())
+		}
+		var notes []models.Note
+		json.NewDecoder(rr.Body).Decode(&notes)
+		return notes
+	}
+
+	t.Run("Notes_CRUDAndSearch", func(t *testing.T) {
+		mux, closeDB := setupNotes(t)
+		defer closeDB()
+
+		moby := createBook(t, mux, "Moby-Dick")
+		walden := createBook(t, mux, "Walden")
+
+		note := createNote(t, mux, moby.ID, map[string]any{"body": "Ishmael is *unreliable*?"})
+		if note.ID == "" || note.BookID != moby.ID || note.Type != models.NoteText || note.CreatedAt.IsZero() {
+			t.Errorf("Expected a plain note on Moby-Dick, got %+v", note)
+		}
+		createNote(t, mux, moby.ID, map[string]any{"type": "highlight", "body": "Call me Ishmael.", "page": 1, "location": "12-13"})
+		createNote(t, mux, walden.ID, map[string]any{"type": "quote", "body": "Simplify, simplify."})
+
+		if notes := list(t, mux, "/api/v1/books/"+moby.ID+"/notes"); len(notes) != 2 || notes[0].Page != 1 || notes[0].Location != "12-13" {
+			t.Errorf("Expected the highlight on page 1 first, got %+v", notes)
+		}
+		if notes := list(t, mux, "/api/v1/notes?q=ishm"); len(notes) != 2 {
+			t.Errorf("Expected both Ishmael notes, got %+v", notes)
+		}
+		if notes := list(t, mux, "/api/v1/notes?q=ISHMAEL+call"); len(notes) != 1 || notes[0].Type != models.NoteHighlight {
+			t.Errorf("Expected only the highlight, got %+v", notes)
+		}
+		if notes := list(t, mux, "/api/v1/notes?q=simplify&type=quote"); len(notes) != 1 || notes[0].BookID != walden.ID {
+			t.Errorf("Expected the Walden quote, got %+v", notes)
+		}
+		// NOTE: Operators of the full text syntax are just separators, they must not break the query
+		if notes := list(t, mux, `/api/v1/notes?q=%22unreliable%22*+-ishm`); len(notes) != 1 || notes[0].ID != note.ID {
+			t.Errorf("Expected the query syntax to be ignored, got %+v", notes)
+		}
+
+		rr := sendJSON(mux, "PUT", "/api/v1/notes/"+note.ID, map[string]any{"type": "note", "body": "Queequeg", "book_id": walden.ID})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var updated models.Note
+		json.NewDecoder(rr.Body).Decode(&updated)
+		if updated.BookID != moby.ID || updated.Body != "Queequeg" || !updated.CreatedAt.Equal(note.CreatedAt) {
+			t.Errorf("Expected the note updated on its book, got %+v", updated)
+		}
+		if notes := list(t, mux, "/api/v1/notes?q=queequeg"); len(notes) != 1 {
+			t.Errorf("Expected the updated note to be found, got %+v", notes)
+		}
+
+		if rr := sendJSON(mux, "DELETE", "/api/v1/notes/"+note.ID, nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/notes/"+note.ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 after delete, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Notes_Invalid", func(t *testing.T) {
+		mux, closeDB := setupNotes(t)
+		defer closeDB()
+
+		book := createBook(t, mux, "Emma")
+		rr := sendJSON(mux, "POST", "/api/v1/books/"+book.ID+"/notes", map[string]any{"type": "bookmark", "body": " ", "page": -3})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 3 || p.Errors[0].Code != models.CodeNoteTypeInvalid || p.Errors[1].Code != models.CodeNoteBodyMissing || p.Errors[2].Code != models.CodeNotePageInvalid {
+			t.Errorf("Expected type, body and page violations, got %+v", p.Errors)
+		}
+
+		missing := "00000000-0000-4000-8000-000000000000"
+		if rr := sendJSON(mux, "POST", "/api/v1/books/"+missing+"/notes", map[string]any{"body": "Lost"}); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a note on a missing book, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/books/"+missing+"/notes", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 listing the notes of a missing book, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "PUT", "/api/v1/notes/"+missing, map[string]any{"body": "Nope"}); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 updating a missing note, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/notes?type=bookmark", nil); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 for an invalid type, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "POST", "/api/v1/notes", nil); rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,174 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"book-tracker/handlers"
+	"book-tracker/middleware"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupReviews(t *testing.T) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	// NOTE: With a cache so the test also covers that review writes invalidate it
+	statsService := services.NewStatsService(store.NewStatsStore(db), nil, time.Hour)
+	mux := http.NewServeMux()
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(store.NewBookStore(db), statsService)))
+	routes.SetupReviewsRoutes(mux, handlers.NewReviewHandler(services.NewReviewService(store.NewReviewStore(db), statsService)))
+	routes.SetupStatsRoutes(mux, handlers.NewStatsHandler(statsService))
+	// NOTE: Behind the user middleware like with USER_HEADER set, requests without the header are anonymous
+	withUser := http.NewServeMux()
+	withUser.Handle("/", middleware.User("X-Forwarded-User")(mux))
+	return withUser, closeDB
+}
+
+func TestReviewsRoutes(t *testing.T) {
+	createBook := func(t *testing.T, mux *http.ServeMux, title, status string) models.Book {
+		t.Helper()
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]string{"title": title, "author": "Author", "status": status})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var book models.Book
+		json.NewDecoder(rr.Body).Decode(&book)
+		return book
+	}
+	ratings := func(t *testing.T, mux *http.ServeMux) models.RatingStats {
+		t.Helper()
+		rr := sendJSON(mux, "GET", "/api/v1/stats", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var stats models.Stats
+		json.NewDecoder(rr.Body).Decode(&stats)
+		return stats.Ratings
+	}
+
+	t.Run("Review_CRUDAndStats", func(t *testing.T) {
+		mux, closeDB := setupReviews(t)
+		defer closeDB()
+
+		emma := createBook(t, mux, "Emma", "complete")
+		dune := createBook(t, mux, "Dune", "reading")
+		if r := ratings(t, mux); r.Average != nil || r.Count != 0 || len(r.Distribution) != 10 {
+			t.Fatalf("Expected no ratings yet, got %+v", r)
+		}
+
+		rr := sendJSON(mux, "PUT", "/api/v1/books/"+emma.ID+"/review", map[string]any{"rating": 4.2})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Field != "rating" || p.Errors[0].Code != models.CodeRatingInvalid {
+			t.Errorf("Expected an invalid rating, got %+v", p.Errors)
+		}
+		if rr := sendJSON(mux, "PUT", "/api/v1/books/"+dune.ID+"/review", map[string]any{"rating": 4}); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 rating a book that is not complete, got %d", rr.Code)
+		}
+
+		rr = sendJSON(mux, "PUT", "/api/v1/books/"+emma.ID+"/review", map[string]any{"rating": 4.5, "body": "*Badly done*, Emma!"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var review models.Review
+		json.NewDecoder(rr.Body).Decode(&review)
+		if review.BookID != emma.ID || review.Rating != 4.5 || review.Body != "*Badly done*, Emma!" || review.CreatedAt.IsZero() {
+			t.Errorf("Expected the review back, got %+v", review)
+		}
+		if r := ratings(t, mux); r.Average == nil || *r.Average != 4.5 || r.Count != 1 || r.Distribution[8].Books != 1 {
+			t.Errorf("Expected one 4.5 rating, got %+v", r)
+		}
+
+		// Replacing the review updates the cached stats
+		if rr := sendJSON(mux, "PUT", "/api/v1/books/"+emma.ID+"/review", map[string]any{"rating": 3}); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if r := ratings(t, mux); r.Average == nil || *r.Average != 3 || r.Count != 1 {
+			t.Errorf("Expected the replaced rating, got %+v", r)
+		}
+
+		rr = sendJSON(mux, "GET", "/api/v1/reviews", nil)
+		var reviews []models.Review
+		json.NewDecoder(rr.Body).Decode(&reviews)
+		if rr.Code != http.StatusOK || len(reviews) != 1 || reviews[0].Rating != 3 || reviews[0].Body != "" {
+			t.Errorf("Expected the one replaced review, got %d: %+v", rr.Code, reviews)
+		}
+
+		if rr := sendJSON(mux, "DELETE", "/api/v1/books/"+emma.ID+"/review", nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/books/"+emma.ID+"/review", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 after delete, got %d", rr.Code)
+		}
+		if r := ratings(t, mux); r.Count != 0 {
+			t.Errorf("Expected no ratings after delete, got %+v", r)
+		}
+	})
+
+	t.Run("Review_PerUser", func(t *testing.T) {
+		mux, closeDB := setupReviews(t)
+		defer closeDB()
+
+		dune := createBook(t, mux, "Dune", "abandoned")
+		rr := sendAs(mux, "alice", "PUT", "/api/v1/books/"+dune.ID+"/review", map[string]any{"rating": 2})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 rating an abandoned book, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var review models.Review
+		json.NewDecoder(rr.Body).Decode(&review)
+		if review.Owner != "alice" {
+			t.Errorf("Expected the review of alice, got %+v", review)
+		}
+		if rr := sendAs(mux, "bob", "GET", "/api/v1/books/"+dune.ID+"/review", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for the review of another user, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "DELETE", "/api/v1/books/"+dune.ID+"/review", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 deleting the review of another user, got %d", rr.Code)
+		}
+		if rr := sendAs(mux, "bob", "PUT", "/api/v1/books/"+dune.ID+"/review", map[string]any{"rating": 4}); rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		var reviews []models.Review
+		rr = sendAs(mux, "alice", "GET", "/api/v1/reviews", nil)
+		json.NewDecoder(rr.Body).Decode(&reviews)
+		if rr.Code != http.StatusOK || len(reviews) != 1 || reviews[0].Rating != 2 {
+			t.Errorf("Expected only the review of alice, got %d: %+v", rr.Code, reviews)
+		}
+		// NOTE: The stats are shared, they count the ratings of everyone
+		if r := ratings(t, mux); r.Count != 2 || r.Average == nil || *r.Average != 3 {
+			t.Errorf("Expected both ratings in the stats, got %+v", r)
+		}
+	})
+
+	t.Run("Review_NotFound", func(t *testing.T) {
+		mux, closeDB := setupReviews(t)
+		defer closeDB()
+
+		book := createBook(t, mux, "Walden", "complete")
+		if rr := sendJSON(mux, "GET", "/api/v1/books/"+book.ID+"/review", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a book without review, got %d", rr.Code)
+		}
+		missing := "00000000-0000-4000-8000-000000000000"
+		if rr := sendJSON(mux, "PUT", "/api/v1/books/"+missing+"/review", map[string]any{"rating": 5}); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 reviewing a missing book, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/reviews?limit=0", nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an invalid limit, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "POST", "/api/v1/books/"+book.ID+"/review", nil); rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/middleware"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupSearches(t *testing.T) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	bookStore := store.NewBookStore(db)
+	for _, book := range []models.Book{
+		{Title: "Animal Farm", Author: "George Orwell", Status: models.BookUnread, Pages: 112, Tags: []string{"classics"}},
+		{Title: "1984", Author: "George Orwell", Status: models.BookUnread, Pages: 328, Tags: []string{"classics", "dystopia"}},
+		{Title: "Burmese Days", Author: "George Orwell", Status: models.BookUnread, Pages: 300},
+		{Title: "Homage to Catalonia", Author: "George Orwell", Status: models.BookComplete, Pages: 232, Tags: []string{"classics"}},
+		{Title: "Emma", Author: "Jane Austen", Status: models.BookUnread, Tags: []string{"classics"}},
+	} {
+		if err := book.GenerateID(); err != nil {
+			t.Fatalf("Failed to generate UUID: %v", err)
+		}
+		if err := bookStore.CreateBook(context.Background(), &book); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+	mux := http.NewServeMux()
+	routes.SetupSearchesRoutes(mux, handlers.NewSearchHandler(services.NewSearchService(store.NewSearchStore(db), bookStore)))
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(bookStore)))
+	// NOTE: Behind the user middleware like with USER_HEADER set, requests without the header are anonymous
+	withUser := http.NewServeMux()
+	withUser.Handle("/", middleware.User("X-Forwarded-User")(mux))
+	return withUser, closeDB
+}
+
+// sendAs is sendJSON as user, the way an authenticating proxy would pass it on
+func sendAs(mux *http.ServeMux, user, method, path string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	req.Header.Set("X-Forwarded-User", user)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestSearchesRoutes(t *testing.T) {
+	bookTitles := func(t *testing.T, mux *http.ServeMux, path string) []string {
+		t.Helper()
+		rr := sendJSON(mux, "GET", path, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		titles := []string{}
+		for _, book := range books {
+			titles = append(titles, book.Title)
+		}
+		return titles
+	}
+
+	t.Run("SavedSearch_EvaluatedAtReadTime", func(t *testing.T) {
+		mux, closeDB := setupSearches(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/searches", map[string]any{
+			"name":   "Unread Orwell classics",
+			"filter": map[string]any{"status": "unread", "author": "george orwell", "tag": []string{"Classics"}, "sort": "-pages"},
+		})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var search models.SavedSearch
+		json.NewDecoder(rr.Body).Decode(&search)
+		if search.ID == "" || search.Books != 2 || !slices.Equal(search.Filter.Tags, []string{"classics"}) {
+			t.Fatalf("Expected an id, 2 matching books and the normalized tag, got %+v", search)
+		}
+
+		path := "/api/v1/searches/" + search.ID + "/books"
+		if got := bookTitles(t, mux, path); !slices.Equal(got, []string{"1984", "Animal Farm"}) {
+			t.Errorf("Expected [1984 Animal Farm], got %v", got)
+		}
+		if got := bookTitles(t, mux, path+"?limit=1&offset=1"); !slices.Equal(got, []string{"Animal Farm"}) {
+			t.Errorf("Expected the second page to be [Animal Farm], got %v", got)
+		}
+
+		// A book tagged later shows up without touching the search
+		rr = sendJSON(mux, "POST", "/api/v1/books", map[string]any{"title": "Coming Up for Air", "author": "George Orwell", "status": "unread", "pages": 400, "tags": []string{"classics"}})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		rr = sendJSON(mux, "GET", "/api/v1/searches", nil)
+		var searches []models.SavedSearch
+		json.NewDecoder(rr.Body).Decode(&searches)
+		if len(searches) != 1 || searches[0].Books != 3 {
+			t.Errorf("Expected the one search to match 3 books now, got %+v", searches)
+		}
+		if got := bookTitles(t, mux, path); !slices.Equal(got, []string{"Coming Up for Air", "1984", "Animal Farm"}) {
+			t.Errorf("Expected the new book first, got %v", got)
+		}
+	})
+
+	t.Run("SavedSearch_UpdateDelete", func(t *testing.T) {
+		mux, closeDB := setupSearches(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/searches", map[string]any{"name": "Everything", "filter": map[string]any{}})
+		var search models.SavedSearch
+		json.NewDecoder(rr.Body).Decode(&search)
+		if rr.Code != http.StatusCreated || search.Books != 5 {
+			t.Fatalf("Expected all 5 books, got %d: %+v", rr.Code, search)
+		}
+
+		rr = sendJSON(mux, "PUT", "/api/v1/searches/"+search.ID, map[string]any{"name": "Austen", "filter": map[string]any{"author": "Jane Austen"}})
+		var updated models.SavedSearch
+		json.NewDecoder(rr.Body).Decode(&updated)
+		if rr.Code != http.StatusOK || updated.Books != 1 || !updated.CreatedAt.Equal(search.CreatedAt) {
+			t.Errorf("Expected 1 book and the original created_at, got %d: %+v", rr.Code, updated)
+		}
+
+		if rr := sendJSON(mux, "DELETE", "/api/v1/searches/"+search.ID, nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/searches/"+search.ID+"/books", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 after delete, got %d", rr.Code)
+		}
+	})
+
+	t.Run("SavedSearch_PerUser", func(t *testing.T) {
+		mux, closeDB := setupSearches(t)
+		defer closeDB()
+
+		rr := sendAs(mux, "alice", "POST", "/api/v1/searches", map[string]any{"name": "Austen", "owner": "bob", "filter": map[string]any{"author": "Jane Austen"}})
+		var search models.SavedSearch
+		json.NewDecoder(rr.Body).Decode(&search)
+		if rr.Code != http.StatusCreated || search.Owner != "alice" {
+			t.Fatalf("Expected a search of alice whatever the body says, got %d: %+v", rr.Code, search)
+		}
+		if rr := sendAs(mux, "bob", "POST", "/api/v1/searches", map[string]any{"name": "Orwell", "filter": map[string]any{"author": "George Orwell"}}); rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+
+		for user, want := range map[string][]string{"alice": {"Austen"}, "bob": {"Orwell"}, "": {}} {
+			rr := sendAs(mux, user, "GET", "/api/v1/searches", nil)
+			var searches []models.SavedSearch
+			json.NewDecoder(rr.Body).Decode(&searches)
+			names := []string{}
+			for _, s := range searches {
+				names = append(names, s.Name)
+			}
+			if rr.Code != http.StatusOK || !slices.Equal(names, want) {
+				t.Errorf("Expected %q to see %v, got %d: %v", user, want, rr.Code, names)
+			}
+		}
+
+		path := "/api/v1/searches/" + search.ID
+		for _, req := range []struct{ method, path string }{{"GET", path}, {"GET", path + "/books"}, {"PUT", path}, {"DELETE", path}} {
+			if rr := sendAs(mux, "bob", req.method, req.path, map[string]any{"name": "Mine now", "filter": map[string]any{}}); rr.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404 for %s %s of another user, got %d", req.method, req.path, rr.Code)
+			}
+		}
+		if rr := sendAs(mux, "alice", "GET", path, nil); rr.Code != http.StatusOK || !bytes.Contains(rr.Body.Bytes(), []byte(`"name":"Austen"`)) {
+			t.Errorf("Expected the search of alice untouched, got %d: %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("POST_CreateSearch_InvalidFilter", func(t *testing.T) {
+		mux, closeDB := setupSearches(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/searches", map[string]any{"name": "Bad", "filter": map[string]any{"sort": "rating", "completed_from": "yesterday"}})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 2 || p.Errors[0].Field != "filter.completed_from" || p.Errors[1].Field != "filter.sort" {
+			t.Errorf("Expected violations on filter.completed_from and filter.sort, got %+v", p.Errors)
+		}
+	})
+
+	t.Run("GET_ListBooks_SortAndCompletedRange", func(t *testing.T) {
+		mux, closeDB := setupSearches(t)
+		defer closeDB()
+
+		if got := bookTitles(t, mux, "/api/v1/books?author=x&sort=-pages&tag=classics"); !slices.Equal(got, []string{"1984", "Homage to Catalonia", "Animal Farm", "Emma"}) {
+			t.Errorf("Expected the classics by pages, got %v", got)
+		}
+		if got := bookTitles(t, mux, "/api/v1/books?completed_from=2000-01-01"); !slices.Equal(got, []string{"Homage to Catalonia"}) {
+			t.Errorf("Expected only the completed book, got %v", got)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/books?sort=rating", nil); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 for an unknown sort, got %d", rr.Code)
+		}
+	})
+}
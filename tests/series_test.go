@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupSeries(t *testing.T) (*http.ServeMux, store.BookStore, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	bookStore := store.NewBookStore(db)
+	mux := http.NewServeMux()
+	routes.SetupSeriesRoutes(mux, handlers.NewSeriesHandler(services.NewSeriesService(store.NewSeriesStore(db))))
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(bookStore)))
+	return mux, bookStore, closeDB
+}
+
+func TestSeriesRoutes(t *testing.T) {
+	createBook := func(t *testing.T, bookStore store.BookStore, title string, status models.BookStatus) *models.Book {
+		t.Helper()
+		book := &models.Book{Title: title, Author: "James S. A. Corey", Status: status}
+		if err := book.GenerateID(); err != nil {
+			t.Fatalf("Failed to generate UUID: %v", err)
+		}
+		if err := bookStore.CreateBook(context.Background(), book); err != nil {
+			t.Fatalf("Failed to create book: %v", err)
+		}
+		return book
+	}
+
+	t.Run("Series_VolumesAndNextUnread", func(t *testing.T) {
+		mux, bookStore, closeDB := setupSeries(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/series", map[string]string{"name": " The Expanse "})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var series models.Series
+		json.NewDecoder(rr.Body).Decode(&series)
+		if series.ID == "" || series.Name != "The Expanse" || series.CreatedAt.IsZero() {
+			t.Fatalf("Expected the created series back, got %+v", series)
+		}
+
+		for _, v := range []struct {
+			title    string
+			status   models.BookStatus
+			position float64
+		}{
+			{"Caliban's War", models.BookUnread, 2},
+			{"Leviathan Wakes", models.BookComplete, 1},
+			{"Gods of Risk", models.BookUnread, 2.5},
+		} {
+			book := createBook(t, bookStore, v.title, v.status)
+			rr := sendJSON(mux, "PUT", "/api/v1/series/"+series.ID+"/books/"+book.ID, map[string]float64{"position": v.position})
+			if rr.Code != http.StatusNoContent {
+				t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+
+		rr = sendJSON(mux, "GET", "/api/v1/series/"+series.ID, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var detail models.SeriesDetail
+		json.NewDecoder(rr.Body).Decode(&detail)
+		got := []string{}
+		for _, book := range detail.Volumes {
+			got = append(got, book.Title)
+		}
+		if detail.Books != 3 || !slices.Equal(got, []string{"Leviathan Wakes", "Caliban's War", "Gods of Risk"}) {
+			t.Errorf("Expected 3 volumes in series order, got %d: %v", detail.Books, got)
+		}
+		if detail.NextUnread == nil || detail.NextUnread.Title != "Caliban's War" {
+			t.Errorf("Expected Caliban's War to be next, got %+v", detail.NextUnread)
+		}
+		if s := detail.Volumes[2].Series; s == nil || s.Position != 2.5 || s.Name != "The Expanse" {
+			t.Errorf("Expected the volume to carry its series, got %+v", s)
+		}
+
+		// The book list shows the series of a book too
+		rr = sendJSON(mux, "GET", "/api/v1/books?status=complete", nil)
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		if len(books) != 1 || books[0].Series == nil || books[0].Series.ID != series.ID || books[0].Series.Position != 1 {
+			t.Errorf("Expected the book to be volume 1 of the series, got %+v", books)
+		}
+	})
+
+	t.Run("GET_ListBooks_SortBySeries", func(t *testing.T) {
+		mux, bookStore, closeDB := setupSeries(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/series", map[string]string{"name": "The Expanse"})
+		var series models.Series
+		json.NewDecoder(rr.Body).Decode(&series)
+		createBook(t, bookStore, "A standalone", models.BookUnread)
+		for title, position := range map[string]float64{"Leviathan Wakes": 1, "Caliban's War": 2} {
+			book := createBook(t, bookStore, title, models.BookUnread)
+			sendJSON(mux, "PUT", "/api/v1/series/"+series.ID+"/books/"+book.ID, map[string]float64{"position": position})
+		}
+
+		rr = sendJSON(mux, "GET", "/api/v1/books?sort=series", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		got := []string{}
+		for _, book := range books {
+			got = append(got, book.Title)
+		}
+		if !slices.Equal(got, []string{"Leviathan Wakes", "Caliban's War", "A standalone"}) {
+			t.Errorf("Expected the series in order and the standalone last, got %v", got)
+		}
+	})
+
+	t.Run("Series_Errors", func(t *testing.T) {
+		mux, bookStore, closeDB := setupSeries(t)
+		defer closeDB()
+
+		var first, second models.Series
+		json.NewDecoder(sendJSON(mux, "POST", "/api/v1/series", map[string]string{"name": "First"}).Body).Decode(&first)
+		json.NewDecoder(sendJSON(mux, "POST", "/api/v1/series", map[string]string{"name": "Second"}).Body).Decode(&second)
+		book := createBook(t, bookStore, "Leviathan Wakes", models.BookUnread)
+		sendJSON(mux, "PUT", "/api/v1/series/"+first.ID+"/books/"+book.ID, map[string]float64{"position": 1})
+
+		rr := sendJSON(mux, "PUT", "/api/v1/series/"+second.ID+"/books/"+book.ID, map[string]float64{"position": 1})
+		if rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for a book in another series, got %d", rr.Code)
+		}
+
+		rr = sendJSON(mux, "PUT", "/api/v1/series/"+first.ID+"/books/"+book.ID, map[string]float64{"position": -1})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Code != models.CodeSeriesPositionInvalid {
+			t.Errorf("Expected a position violation, got %+v", p.Errors)
+		}
+
+		if rr := sendJSON(mux, "DELETE", "/api/v1/series/"+second.ID+"/books/"+book.ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a book not in the series, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "DELETE", "/api/v1/series/"+first.ID, nil); rr.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/series/"+first.ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 after delete, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "PATCH", "/api/v1/series/"+second.ID, nil); rr.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", rr.Code)
+		}
+	})
+}
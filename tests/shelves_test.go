@@ -0,0 +1,193 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupShelves(t *testing.T) (*http.ServeMux, store.BookStore, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	bookStore := store.NewBookStore(db)
+	mux := http.NewServeMux()
+	routes.SetupShelvesRoutes(mux, handlers.NewShelfHandler(services.NewShelfService(store.NewShelfStore(db))))
+	return mux, bookStore, closeDB
+}
+
+func TestShelvesRoutes(t *testing.T) {
+	createShelf := func(t *testing.T, mux *http.ServeMux, name string) models.Shelf {
+		t.Helper()
+		rr := sendJSON(mux, "POST", "/api/v1/shelves", map[string]string{"name": name})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var shelf models.Shelf
+		json.NewDecoder(rr.Body).Decode(&shelf)
+		return shelf
+	}
+	shelfTitles := func(t *testing.T, mux *http.ServeMux, path string) []string {
+		t.Helper()
+		rr := sendJSON(mux, "GET", path, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		titles := []string{}
+		for _, book := range books {
+			titles = append(titles, book.Title)
+		}
+		return titles
+	}
+
+	t.Run("Shelf_CRUD", func(t *testing.T) {
+		mux, _, closeDB := setupShelves(t)
+		defer closeDB()
+
+		shelf := createShelf(t, mux, " Team book club 2026 ")
+		if shelf.ID == "" || shelf.Name != "Team book club 2026" || shelf.CreatedAt.IsZero() {
+			t.Fatalf("Expected an id, the trimmed name and created_at, got %+v", shelf)
+		}
+
+		rr := sendJSON(mux, "PUT", "/api/v1/shelves/"+shelf.ID, map[string]string{"name": "Book club", "description": "Every other Friday"})
+		var updated models.Shelf
+		json.NewDecoder(rr.Body).Decode(&updated)
+		if rr.Code != http.StatusOK || updated.Name != "Book club" || !updated.CreatedAt.Equal(shelf.CreatedAt) {
+			t.Errorf("Expected the updated shelf, got %d: %+v", rr.Code, updated)
+		}
+
+		rr = sendJSON(mux, "GET", "/api/v1/shelves", nil)
+		var shelves []models.Shelf
+		json.NewDecoder(rr.Body).Decode(&shelves)
+		if rr.Code != http.StatusOK || len(shelves) != 1 || shelves[0].Description != "Every other Friday" {
+			t.Errorf("Expected the one shelf, got %d: %+v", rr.Code, shelves)
+		}
+
+		if rr := sendJSON(mux, "DELETE", "/api/v1/shelves/"+shelf.ID, nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := sendJSON(mux, "GET", "/api/v1/shelves/"+shelf.ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 after delete, got %d", rr.Code)
+		}
+	})
+
+	t.Run("POST_CreateShelf_MissingName", func(t *testing.T) {
+		mux, _, closeDB := setupShelves(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/shelves", map[string]string{"description": "no name"})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Field != "name" || p.Errors[0].Code != models.CodeShelfNameMissing {
+			t.Errorf("Expected %s on name, got %+v", models.CodeShelfNameMissing, p.Errors)
+		}
+	})
+
+	t.Run("ShelfBooks_AddMoveRemove", func(t *testing.T) {
+		mux, bookStore, closeDB := setupShelves(t)
+		defer closeDB()
+
+		shelf := createShelf(t, mux, "Onboarding reading")
+		books := map[string]models.Book{}
+		for _, title := range []string{"A", "B", "C"} {
+			book := models.Book{Title: title, Author: "Author", Status: models.BookUnread}
+			if err := book.GenerateID(); err != nil {
+				t.Fatalf("Failed to generate UUID: %v", err)
+			}
+			if err := bookStore.CreateBook(context.Background(), &book); err != nil {
+				t.Fatalf("Failed to seed book: %v", err)
+			}
+			books[title] = book
+		}
+		path := "/api/v1/shelves/" + shelf.ID + "/books"
+
+		for _, add := range []map[string]any{
+			{"book_id": books["A"].ID},
+			{"book_id": books["B"].ID},
+			{"book_id": books["C"].ID, "position": 0},
+		} {
+			if rr := sendJSON(mux, "POST", path, add); rr.Code != http.StatusNoContent {
+				t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+			}
+		}
+		if got := shelfTitles(t, mux, path); !slices.Equal(got, []string{"C", "A", "B"}) {
+			t.Errorf("Expected [C A B], got %v", got)
+		}
+		if rr := sendJSON(mux, "POST", path, map[string]any{"book_id": books["A"].ID}); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for a book already on the shelf, got %d", rr.Code)
+		}
+
+		if rr := sendJSON(mux, "PUT", path+"/"+books["C"].ID, map[string]any{"position": 2}); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if got := shelfTitles(t, mux, path+"?limit=2&offset=1"); !slices.Equal(got, []string{"B", "C"}) {
+			t.Errorf("Expected [B C] for the second page, got %v", got)
+		}
+		if rr := sendJSON(mux, "PUT", path+"/"+books["C"].ID, map[string]any{}); rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 for a move without a position, got %d", rr.Code)
+		}
+
+		if rr := sendJSON(mux, "DELETE", path+"/"+books["A"].ID, nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := sendJSON(mux, "DELETE", path+"/"+books["A"].ID, nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a book not on the shelf, got %d", rr.Code)
+		}
+
+		rr := sendJSON(mux, "GET", "/api/v1/shelves/"+shelf.ID, nil)
+		var got models.Shelf
+		json.NewDecoder(rr.Body).Decode(&got)
+		if got.Books != 2 {
+			t.Errorf("Expected 2 books on the shelf, got %+v", got)
+		}
+	})
+
+	t.Run("ShelfBooks_NotFound", func(t *testing.T) {
+		mux, _, closeDB := setupShelves(t)
+		defer closeDB()
+
+		shelf := createShelf(t, mux, "Empty")
+		if rr := sendJSON(mux, "GET", "/api/v1/shelves/00000000-0000-0000-0000-000000000000/books", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a missing shelf, got %d", rr.Code)
+		}
+		rr := sendJSON(mux, "POST", "/api/v1/shelves/"+shelf.ID+"/books", map[string]any{"book_id": "00000000-0000-0000-0000-000000000000"})
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a missing book, got %d", rr.Code)
+		}
+		if got := shelfTitles(t, mux, "/api/v1/shelves/"+shelf.ID+"/books"); len(got) != 0 {
+			t.Errorf("Expected an empty shelf, got %v", got)
+		}
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		mux, _, closeDB := setupShelves(t)
+		defer closeDB()
+
+		for _, tt := range []struct{ method, path, allow string }{
+			{"DELETE", "/api/v1/shelves", "GET, POST"},
+			{"POST", "/api/v1/shelves/x", "GET, PUT, DELETE"},
+			{"PUT", "/api/v1/shelves/x/books", "GET, POST"},
+			{"GET", "/api/v1/shelves/x/books/y", "PUT, DELETE"},
+		} {
+			rr := sendJSON(mux, tt.method, tt.path, nil)
+			if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != tt.allow {
+				t.Errorf("%s %s = %d (Allow %q), want 405 (Allow %q)", tt.method, tt.path, rr.Code, rr.Header().Get("Allow"), tt.allow)
+			}
+		}
+	})
+}
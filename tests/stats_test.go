@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"book-tracker/handlers"
 	"book-tracker/models"
@@ -21,7 +23,7 @@ func setupStats(t *testing.T) (*http.ServeMux, store.BookStore, func()) {
 	}
 	statsStore := store.NewStatsStore(db)
 	bookStore := store.NewBookStore(db)
-	statsService := services.NewStatsService(statsStore)
+	statsService := services.NewStatsService(statsStore, nil, 0)
 	statsHandler := handlers.NewStatsHandler(statsService)
 	mux := http.NewServeMux()
 	routes.SetupStatsRoutes(mux, statsHandler)
@@ -42,12 +44,16 @@ func TestStatsRoutes(t *testing.T) {
 			t.Errorf("Expected status 200, got %d", rr.Code)
 		}
 
+		body := rr.Body.String()
 		var stats models.Stats
-		if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+		if err := json.Unmarshal([]byte(body), &stats); err != nil {
 			t.Errorf("Failed to decode response: %v", err)
 		}
-		if stats.TotalRead != 0 || stats.ReadingProgress != 0 || stats.PopularAuthor != "N/A" {
-			t.Errorf("Expected empty stats {0, 0, 'N/A'}, got %+v", stats)
+		if stats.TotalRead != 0 || stats.ReadingProgress != 0 || stats.TopAuthors != nil {
+			t.Errorf("Expected empty stats {0, 0, nil}, got %+v", stats)
+		}
+		if !strings.Contains(body, `"top_authors":null`) {
+			t.Errorf("Expected top_authors to be null, got %s", body)
 		}
 	})
 
@@ -91,8 +97,9 @@ func TestStatsRoutes(t *testing.T) {
 		if stats.ReadingProgress != 33 { // 1 reading / 3 total = 33%
 			t.Errorf("Expected reading_progress 33, got %d", stats.ReadingProgress)
 		}
-		if stats.PopularAuthor != "Jane Austen" {
-			t.Errorf("Expected popular_author 'Jane Austen', got %s", stats.PopularAuthor)
+		// Mark Twain has fewer books but is still within the top 3 ranks
+		if len(stats.TopAuthors) != 2 || stats.TopAuthors[0].Author != "Jane Austen" || stats.TopAuthors[1].Rank != 2 {
+			t.Errorf("Expected top_authors [Jane Austen, Mark Twain], got %+v", stats.TopAuthors)
 		}
 	})
 
@@ -109,4 +116,216 @@ func TestStatsRoutes(t *testing.T) {
 			t.Errorf("Expected status 405, got %d", rr.Code)
 		}
 	})
+
+	t.Run("GET_Timeline", func(t *testing.T) {
+		mux, bookStore, closeDB := setupStats(t)
+		defer closeDB()
+
+		book := models.Book{Title: "Book A", Author: "Jane Austen", Status: models.BookComplete, Pages: 100}
+		if err := book.GenerateID(); err != nil {
+			t.Fatalf("Failed to generate UUID: %v", err)
+		}
+		if err := bookStore.CreateBook(context.Background(), &book); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats/timeline?granularity=year", nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var timeline models.Timeline
+		if err := json.NewDecoder(rr.Body).Decode(&timeline); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(timeline.Buckets) != 12 {
+			t.Fatalf("Expected 12 yearly buckets, got %d", len(timeline.Buckets))
+		}
+		last := timeline.Buckets[len(timeline.Buckets)-1]
+		if last.Finished != 1 || last.PagesRead != 100 {
+			t.Errorf("Current year bucket = %+v, want 1 finished and 100 pages", last)
+		}
+	})
+
+	t.Run("GET_Timeline_InvalidGranularity", func(t *testing.T) {
+		mux, _, closeDB := setupStats(t)
+		defer closeDB()
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats/timeline?granularity=day", nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rr.Code)
+		}
+	})
+
+	t.Run("GET_Activity", func(t *testing.T) {
+		mux, bookStore, closeDB := setupStats(t)
+		defer closeDB()
+
+		book := models.Book{Title: "Book A", Author: "Jane Austen", Status: models.BookReading}
+		if err := book.GenerateID(); err != nil {
+			t.Fatalf("Failed to generate UUID: %v", err)
+		}
+		if err := bookStore.CreateBook(context.Background(), &book); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats/activity?tz=Europe/Stockholm", nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var activity models.Activity
+		if err := json.NewDecoder(rr.Body).Decode(&activity); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if activity.Timezone != "Europe/Stockholm" {
+			t.Errorf("Expected timezone Europe/Stockholm, got %s", activity.Timezone)
+		}
+		if activity.CurrentStreak != 1 || activity.LongestStreak != 1 {
+			t.Errorf("Expected streaks of 1, got current %d longest %d", activity.CurrentStreak, activity.LongestStreak)
+		}
+		if activity.Days[activity.To] != 1 {
+			t.Errorf("Expected 1 event today (%s), got %v", activity.To, activity.Days)
+		}
+	})
+
+	t.Run("GET_Activity_InvalidTimezone", func(t *testing.T) {
+		mux, _, closeDB := setupStats(t)
+		defer closeDB()
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats/activity?tz=Mars/Olympus", nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", rr.Code)
+		}
+	})
+
+	t.Run("GET_Authors", func(t *testing.T) {
+		mux, bookStore, closeDB := setupStats(t)
+		defer closeDB()
+
+		books := []models.Book{
+			{Title: "Book A", Author: "Mark Twain", Status: models.BookComplete},
+			{Title: "Book B", Author: "Jane Austen", Status: models.BookComplete},
+			{Title: "Book C", Author: "Jane Austen", Status: models.BookUnread},
+			{Title: "Book D", Author: "Mark Twain", Status: models.BookReading},
+			{Title: "Book E", Author: "Leo Tolstoy", Status: models.BookUnread},
+		}
+		for i := range books {
+			if err := books[i].GenerateID(); err != nil {
+				t.Fatalf("Failed to generate UUID for book %d: %v", i, err)
+			}
+			if err := bookStore.CreateBook(context.Background(), &books[i]); err != nil {
+				t.Fatalf("Failed to seed book %d: %v", i, err)
+			}
+		}
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats/authors?limit=2", nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var page models.AuthorStatsPage
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if page.Total != 3 || len(page.Authors) != 2 {
+			t.Fatalf("Expected 2 of 3 authors, got %d of %d", len(page.Authors), page.Total)
+		}
+		// Tied on 2 books so both are rank 1, ordered by name
+		jane, mark := page.Authors[0], page.Authors[1]
+		if jane.Author != "Jane Austen" || jane.Rank != 1 || mark.Author != "Mark Twain" || mark.Rank != 1 {
+			t.Errorf("Expected Jane Austen and Mark Twain tied at rank 1, got %+v", page.Authors)
+		}
+		if jane.Complete != 1 || jane.ToRead != 1 || jane.CompletionRate != 0.5 {
+			t.Errorf("Unexpected counts for Jane Austen: %+v", jane)
+		}
+
+		req, _ = http.NewRequest("GET", "/api/v1/stats/authors?limit=2&offset=2", nil)
+		rr = httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		page = models.AuthorStatsPage{}
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(page.Authors) != 1 || page.Authors[0].Author != "Leo Tolstoy" || page.Authors[0].Rank != 3 {
+			t.Errorf("Expected Leo Tolstoy at rank 3 on the second page, got %+v", page.Authors)
+		}
+	})
+
+	t.Run("GET_Authors_InvalidLimit", func(t *testing.T) {
+		mux, _, closeDB := setupStats(t)
+		defer closeDB()
+
+		req, _ := http.NewRequest("GET", "/api/v1/stats/authors?limit=0", nil)
+		rr := httptest.NewRecorder()
+
+		mux.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", rr.Code)
+		}
+	})
+
+	t.Run("GET_CachedUntilBookWrite", func(t *testing.T) {
+		db, closeDB, err := store.NewDB(":memory:")
+		if err != nil {
+			t.Fatalf("Failed to initialize SQLite: %v", err)
+		}
+		defer closeDB()
+		statsService := services.NewStatsService(store.NewStatsStore(db), nil, time.Hour)
+		bookService := services.NewBookService(store.NewBookStore(db), statsService)
+		mux := http.NewServeMux()
+		routes.SetupStatsRoutes(mux, handlers.NewStatsHandler(statsService))
+		routes.SetupBooksRoutes(mux, handlers.NewBookHandler(bookService))
+
+		getStats := func() models.Stats {
+			t.Helper()
+			req, _ := http.NewRequest("GET", "/api/v1/stats", nil)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			var stats models.Stats
+			if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			return stats
+		}
+
+		getStats()
+		getStats()
+		if hits, misses := statsService.CacheStats(); hits != 1 || misses != 1 {
+			t.Errorf("Expected 1 hit and 1 miss, got %d and %d", hits, misses)
+		}
+
+		body := strings.NewReader(`{"title": "Book A", "author": "Jane Austen", "status": "complete"}`)
+		req, _ := http.NewRequest("POST", "/api/v1/books", body)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d", rr.Code)
+		}
+
+		if stats := getStats(); stats.TotalRead != 1 {
+			t.Errorf("Expected total_read 1 right after the write, got %d", stats.TotalRead)
+		}
+		if hits, misses := statsService.CacheStats(); hits != 1 || misses != 2 {
+			t.Errorf("Expected 1 hit and 2 misses, got %d and %d", hits, misses)
+		}
+	})
 }
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"book-tracker/handlers"
+	"book-tracker/models"
+	"book-tracker/problem"
+	"book-tracker/routes"
+	"book-tracker/services"
+	"book-tracker/store"
+)
+
+func setupTags(t *testing.T) (*http.ServeMux, func()) {
+	db, closeDB, err := store.NewDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to initialize SQLite: %v", err)
+	}
+	mux := http.NewServeMux()
+	routes.SetupBooksRoutes(mux, handlers.NewBookHandler(services.NewBookService(store.NewBookStore(db))))
+	routes.SetupTagsRoutes(mux, handlers.NewTagHandler(services.NewTagService(store.NewTagStore(db))))
+	return mux, closeDB
+}
+
+// sendJSON sends body as JSON (if not nil) and returns the recorded response
+func sendJSON(mux *http.ServeMux, method, path string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req, _ := http.NewRequest(method, path, &buf)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestTagsRoutes(t *testing.T) {
+	createTagged := func(t *testing.T, mux *http.ServeMux, title string, tags ...string) models.Book {
+		t.Helper()
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]any{"title": title, "author": "Author", "status": "unread", "tags": tags})
+		if rr.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var book models.Book
+		json.NewDecoder(rr.Body).Decode(&book)
+		return book
+	}
+	listTitles := func(t *testing.T, mux *http.ServeMux, query string) []string {
+		t.Helper()
+		rr := sendJSON(mux, "GET", "/api/v1/books?"+query, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var books []models.Book
+		json.NewDecoder(rr.Body).Decode(&books)
+		titles := []string{}
+		for _, book := range books {
+			titles = append(titles, book.Title)
+		}
+		return titles
+	}
+
+	t.Run("POST_CreateBook_NormalizesTags", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		book := createTagged(t, mux, "Designing Data-Intensive Applications", "Distributed Systems", "databases", "databases")
+		if want := []string{"databases", "distributed-systems"}; !slices.Equal(book.Tags, want) {
+			t.Errorf("Expected tags %v, got %v", want, book.Tags)
+		}
+	})
+
+	t.Run("POST_CreateBook_InvalidTag", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		rr := sendJSON(mux, "POST", "/api/v1/books", map[string]any{"title": "T", "author": "A", "status": "unread", "tags": []string{"ok", "a,b"}})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Field != "tags[1]" || p.Errors[0].Code != models.CodeTagInvalid {
+			t.Errorf("Expected tag.invalid on tags[1], got %+v", p.Errors)
+		}
+	})
+
+	t.Run("PUT_UpdateBook_KeepsTagsWithoutTags", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		book := createTagged(t, mux, "Walden", "classic", "nature")
+		// NOTE: A client from before tags only sends these
+		rr := sendJSON(mux, "PUT", "/api/v1/books/"+book.ID, map[string]any{"title": "Walden", "author": "Author", "status": "reading"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var updated models.Book
+		json.NewDecoder(rr.Body).Decode(&updated)
+		if want := []string{"classic", "nature"}; !slices.Equal(updated.Tags, want) {
+			t.Errorf("Expected tags %v kept, got %v", want, updated.Tags)
+		}
+		if got := listTitles(t, mux, "tag=nature"); !slices.Equal(got, []string{"Walden"}) {
+			t.Errorf("Expected Walden still tagged nature, got %v", got)
+		}
+
+		rr = sendJSON(mux, "PUT", "/api/v1/books/"+book.ID, map[string]any{"title": "Walden", "author": "Author", "status": "reading", "tags": []string{}})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if got := listTitles(t, mux, "tag=classic"); len(got) != 0 {
+			t.Errorf("Expected an empty tags list to remove them, got %v", got)
+		}
+	})
+
+	t.Run("GET_ListBooks_TagFilters", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		createTagged(t, mux, "Emma", "classic", "romance")
+		createTagged(t, mux, "Walden", "classic", "nature")
+		createTagged(t, mux, "Dune", "sci-fi")
+
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			{"tag=classic", []string{"Emma", "Walden"}},
+			{"tag=classic&tag=Nature", []string{"Walden"}},
+			{"tag=classic,nature", []string{"Walden"}},
+			{"tag_any=romance,sci-fi", []string{"Dune", "Emma"}},
+			{"tag=classic&tag_any=romance&tag_any=sci-fi", []string{"Emma"}},
+		}
+		for _, tt := range tests {
+			if got := listTitles(t, mux, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("GET /api/v1/books?%s = %v, want %v", tt.query, got, tt.want)
+			}
+		}
+
+		rr := sendJSON(mux, "GET", "/api/v1/books?tag=no!", nil)
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 for an invalid tag filter, got %d", rr.Code)
+		}
+	})
+
+	t.Run("Tags_ListRenameMergeDelete", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		createTagged(t, mux, "Emma", "classic", "classics")
+		createTagged(t, mux, "Walden", "classics", "nature")
+
+		rr := sendJSON(mux, "GET", "/api/v1/tags", nil)
+		var tags []models.TagCount
+		json.NewDecoder(rr.Body).Decode(&tags)
+		want := []models.TagCount{{Name: "classics", Books: 2}, {Name: "classic", Books: 1}, {Name: "nature", Books: 1}}
+		if rr.Code != http.StatusOK || !slices.Equal(tags, want) {
+			t.Fatalf("Expected %v, got %d: %v", want, rr.Code, tags)
+		}
+
+		if rr := sendJSON(mux, "POST", "/api/v1/tags/Classics/merge", map[string]string{"into": "classic"}); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for merge, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if got := listTitles(t, mux, "tag=classic"); !slices.Equal(got, []string{"Emma", "Walden"}) {
+			t.Errorf("Expected both books tagged classic after the merge, got %v", got)
+		}
+
+		if rr := sendJSON(mux, "PUT", "/api/v1/tags/nature", map[string]string{"name": "classic"}); rr.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 when renaming onto an existing tag, got %d", rr.Code)
+		}
+		if rr := sendJSON(mux, "PUT", "/api/v1/tags/nature", map[string]string{"name": "Outdoors"}); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for rename, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if got := listTitles(t, mux, "tag=outdoors"); !slices.Equal(got, []string{"Walden"}) {
+			t.Errorf("Expected Walden tagged outdoors, got %v", got)
+		}
+
+		if rr := sendJSON(mux, "DELETE", "/api/v1/tags/classic", nil); rr.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204 for delete, got %d: %s", rr.Code, rr.Body.String())
+		}
+		if rr := sendJSON(mux, "DELETE", "/api/v1/tags/classic", nil); rr.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a deleted tag, got %d", rr.Code)
+		}
+	})
+
+	t.Run("POST_MergeTags_IntoItself", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		createTagged(t, mux, "Emma", "classic")
+		rr := sendJSON(mux, "POST", "/api/v1/tags/classic/merge", map[string]string{"into": "Classic"})
+		if rr.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected status 422, got %d: %s", rr.Code, rr.Body.String())
+		}
+		var p problem.Problem
+		json.NewDecoder(rr.Body).Decode(&p)
+		if len(p.Errors) != 1 || p.Errors[0].Code != models.CodeTagMergeSelf {
+			t.Errorf("Expected %s, got %+v", models.CodeTagMergeSelf, p.Errors)
+		}
+	})
+
+	t.Run("MethodNotAllowed", func(t *testing.T) {
+		mux, closeDB := setupTags(t)
+		defer closeDB()
+
+		for _, tt := range []struct{ method, path, allow string }{
+			{"POST", "/api/v1/tags", "GET"},
+			{"GET", "/api/v1/tags/classic", "PUT, DELETE"},
+			{"GET", "/api/v1/tags/classic/merge", "POST"},
+		} {
+			rr := sendJSON(mux, tt.method, tt.path, nil)
+			if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != tt.allow {
+				t.Errorf("%s %s = %d (Allow %q), want 405 (Allow %q)", tt.method, tt.path, rr.Code, rr.Header().Get("Allow"), tt.allow)
+			}
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestLogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	ctx, span := sdktrace.NewTracerProvider().Tracer("test").Start(context.Background(), "op")
+	defer span.End()
+
+	logger.InfoContext(ctx, "with span")
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode log line: %v", err)
+	}
+	if entry["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("trace_id = %v, want %s", entry["trace_id"], span.SpanContext().TraceID())
+	}
+	if entry["span_id"] != span.SpanContext().SpanID().String() {
+		t.Errorf("span_id = %v, want %s", entry["span_id"], span.SpanContext().SpanID())
+	}
+
+	buf.Reset()
+	logger.InfoContext(context.Background(), "without span")
+	entry = map[string]any{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to decode log line: %v", err)
+	}
+	if _, ok := entry["trace_id"]; ok {
+		t.Errorf("trace_id should not be logged without a span, got %v", entry["trace_id"])
+	}
+}